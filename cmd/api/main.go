@@ -3,6 +3,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"os/signal"
 	"syscall"
@@ -11,16 +12,21 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/marcelojr/desafio-globo/internal/app/httpapi"
+	"github.com/marcelojr/desafio-globo/internal/app/live"
 	"github.com/marcelojr/desafio-globo/internal/app/voting"
 	"github.com/marcelojr/desafio-globo/internal/app/web"
 	"github.com/marcelojr/desafio-globo/internal/domain"
 	"github.com/marcelojr/desafio-globo/internal/platform/antifraude"
+	"github.com/marcelojr/desafio-globo/internal/platform/auditoria"
+	"github.com/marcelojr/desafio-globo/internal/platform/auth"
 	"github.com/marcelojr/desafio-globo/internal/platform/clock"
 	"github.com/marcelojr/desafio-globo/internal/platform/config"
 	"github.com/marcelojr/desafio-globo/internal/platform/health"
 	"github.com/marcelojr/desafio-globo/internal/platform/ids"
 	"github.com/marcelojr/desafio-globo/internal/platform/logger"
 	"github.com/marcelojr/desafio-globo/internal/platform/migrations"
+	"github.com/marcelojr/desafio-globo/internal/platform/oidc"
+	"github.com/marcelojr/desafio-globo/internal/platform/recibos"
 	postgresstorage "github.com/marcelojr/desafio-globo/internal/platform/storage/postgres"
 	redisstorage "github.com/marcelojr/desafio-globo/internal/platform/storage/redis"
 )
@@ -59,18 +65,60 @@ func main() {
 	}
 	defer redisClient.Close()
 
+	// Horas e séries são agrupadas e exibidas no fuso dos relatórios, não no da sessão do banco nem no do servidor.
+	fuso, err := carregarFuso(cfg.FusoRelatorios)
+	if err != nil {
+		logger.Fatal("FUSO_RELATORIOS invalido", "err", err)
+	}
+
 	dbParedao := postgresstorage.NewParedaoRepository(db)
 	dbParticipante := postgresstorage.NewParticipanteRepository(db)
-	dbVoto := postgresstorage.NewVotoRepository(db)
+	dbVoto := postgresstorage.NewVotoRepository(db, postgresstorage.ComFuso(fuso))
 	contador := redisstorage.NewContador(redisClient, cfg.ContadorKeyPrefix)
 	fila := redisstorage.NewFila(redisClient, cfg.FilaKeyPrefix)
 	clockSystem := clock.NewSystemClock()
 	idGen := ids.NewGenerator()
 
-	var antifraudeSvc domain.Antifraude = antifraude.NewNoop()
+	// O padrão vem do ambiente; cada paredão pode sobrescrevê-lo pela API de admin. O resolvedor existe
+	// mesmo com o rate limit desligado, porque o CAPTCHA de um paredão vale de qualquer forma: limite zero
+	// desliga só o limitador, e um paredão que defina o próprio limite continua limitado.
+	padrao := domain.PoliticaAntifraude{
+		JanelaSegundos: cfg.RateLimitWindowSeconds,
+		Algoritmo:      domain.AlgoritmoLimite(cfg.RateLimitAlgoritmo),
+		ExigeCaptcha:   cfg.CaptchaObrigatorio,
+		EscopoBloqueio: domain.EscopoBloqueio(cfg.RateLimitEscopo),
+	}
 	if cfg.RateLimitEnabled {
-		window := time.Duration(cfg.RateLimitWindowSeconds) * time.Second
-		antifraudeSvc = antifraude.NewRedisRateLimiter(redisClient, cfg.RateLimitMaxActions, window, cfg.RateLimitKeyPrefix)
+		padrao.Limite = cfg.RateLimitMaxActions
+	}
+	politicas := antifraude.NewResolvedorPoliticas(dbParedao, padrao, time.Duration(cfg.PoliticaCacheSeconds)*time.Second)
+	limiter := antifraude.NewRedisRateLimiter(redisClient, padrao.Limite, time.Duration(cfg.RateLimitWindowSeconds)*time.Second, cfg.RateLimitKeyPrefix)
+
+	var captcha antifraude.VerificadorCaptcha
+	if cfg.CaptchaVerifyURL != "" {
+		captcha = antifraude.NewSiteVerify(cfg.CaptchaVerifyURL, cfg.CaptchaSecret, 3*time.Second)
+	}
+	antifraudeSvc := antifraude.NewPorParedao(politicas, limiter, captcha)
+
+	// Sem chaves configuradas os recibos saem de uma chave efêmera, que não confere entre réplicas nem após restart.
+	var assinador *recibos.Assinador
+	if cfg.ReciboChaves != "" {
+		assinador, err = recibos.NewAssinador(cfg.ReciboChaves)
+	} else {
+		logger.L().Warn("RECIBO_CHAVES vazio: recibos assinados com chave efemera")
+		assinador, err = recibos.NewAssinadorEfemero()
+	}
+	if err != nil {
+		logger.Fatal("chaves de recibo invalidas", "err", err)
+	}
+
+	// Sem segredo os pseudônimos de IP dos pacotes de auditoria mudam a cada restart e entre réplicas.
+	if cfg.AuditoriaPseudonimoSegredo == "" {
+		logger.L().Warn("AUDITORIA_PSEUDONIMO_SEGREDO vazio: pseudonimos de IP gerados com segredo efemero")
+	}
+	pseudonimos, err := auditoria.NewPseudonimizador(cfg.AuditoriaPseudonimoSegredo)
+	if err != nil {
+		logger.Fatal("falha ao preparar pseudonimos de auditoria", "err", err)
 	}
 
 	// Serviço agrega repositórios, fila e antifraude para guardar a lógica de negócio.
@@ -83,15 +131,59 @@ func main() {
 		antifraudeSvc,
 		clockSystem,
 		idGen,
+		voting.ComReservaVotoUnico(redisstorage.NewReservaVotoUnico(redisClient, cfg.VotoUnicoKeyPrefix)),
+		voting.ComStatusVotos(redisstorage.NewStatusVotos(redisClient, cfg.VotoStatusKeyPrefix, time.Duration(cfg.VotoStatusTTLSeconds)*time.Second)),
+		voting.ComRecibos(assinador),
+		voting.ComAuditoria(postgresstorage.NewAuditoriaRepository(db)),
+		voting.ComPseudonimos(pseudonimos),
+		voting.ComViradas(postgresstorage.NewViradaRepository(db), int64(cfg.ViradasMinVotos)),
+		voting.ComWebhooks(postgresstorage.NewWebhookRepository(db)),
+		voting.ComPoliticas(politicas),
 	)
 
 	mux := http.NewServeMux()
 	checker := health.NewChecker(sqlDB, redisClient)
 
 	// HTTP expõe API, health check e métricas que o Prometheus coleta.
-	api := httpapi.New(servico, logger.L())
+	var apiOpts []httpapi.Option
+	if cfg.EleitorTokenSecret != "" {
+		apiOpts = append(apiOpts, httpapi.ComVerificadorEleitor(auth.NewTokens(cfg.EleitorTokenSecret)))
+	}
+	// Um hub por instância: cada paredão acompanhado é recalculado uma vez por ciclo, não uma vez por cliente.
+	hub := live.NewHub(servico, live.Config{
+		Cadencia:    time.Duration(cfg.LiveCadenciaMS) * time.Millisecond,
+		MaxConexoes: cfg.LiveMaxConexoes,
+		Revalidacao: time.Duration(cfg.LiveRevalidacaoSecs) * time.Second,
+	})
+	if cfg.AvisosEnabled {
+		// Os avisos do worker poupam o banco entre réplicas; sem eles o hub segue no polling.
+		go hub.Escutar(ctx, redisstorage.NewAvisosParciais(redisClient, cfg.AvisosCanal))
+	}
+	apiOpts = append(apiOpts,
+		httpapi.ComStream(hub, time.Duration(cfg.LiveKeepAliveSeconds)*time.Second),
+		httpapi.ComWebSocket(httpapi.ConfigWebSocket{MensagensPorSegundo: cfg.WSMensagensPorSeg, Rajada: cfg.WSRajada, Origens: cfg.WSOrigens}),
+	)
+	api := httpapi.New(servico, logger.L(), apiOpts...)
 	api.Register(mux)
-	frontend, err := web.New(servico, cfg.ConsultaToken)
+	if cfg.AdminToken != "" {
+		httpapi.NewAdmin(servico, cfg.AdminToken, logger.L()).Register(mux)
+	} else {
+		logger.L().Warn("ADMIN_TOKEN vazio: rotas /admin desabilitadas")
+	}
+	webOpts := []web.Option{web.ComFuso(fuso), web.ComCaptcha(cfg.CaptchaVerifyURL, cfg.CaptchaSiteKey)}
+	if cfg.OIDCIssuer != "" {
+		if cfg.SessionSecret == "" {
+			logger.Fatal("SESSION_SECRET obrigatorio quando OIDC_ISSUER esta definido")
+		}
+		cliente := oidc.NewCliente(oidc.Config{
+			Issuer:       cfg.OIDCIssuer,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+		})
+		webOpts = append(webOpts, web.ComLogin(cliente, cfg.SessionSecret))
+	}
+	frontend, err := web.New(servico, cfg.ConsultaToken, webOpts...)
 	if err != nil {
 		logger.Fatal("erro ao carregar templates", "err", err)
 	}
@@ -99,8 +191,30 @@ func main() {
 	mux.HandleFunc("/readyz", checker.ReadyHandler())
 	mux.Handle("/metrics", promhttp.Handler())
 
+	// Sem prazos, um cliente lento segura a conexão e a goroutine para sempre.
+	servidor := &http.Server{
+		Addr:              cfg.HTTPAddress,
+		Handler:           httpapi.Middleware(mux, logger.L(), httpapi.ConfigMiddleware{MaxCorpo: cfg.HTTPMaxBodyBytes}),
+		ReadHeaderTimeout: time.Duration(cfg.HTTPReadTimeoutSeconds) * time.Second,
+		ReadTimeout:       time.Duration(cfg.HTTPReadTimeoutSeconds) * time.Second,
+		WriteTimeout:      time.Duration(cfg.HTTPWriteTimeoutSeconds) * time.Second,
+		IdleTimeout:       time.Duration(cfg.HTTPIdleTimeoutSeconds) * time.Second,
+	}
 	logger.Info("api ouvindo", "addr", cfg.HTTPAddress)
-	if err := http.ListenAndServe(cfg.HTTPAddress, mux); err != nil {
+	if err := servidor.ListenAndServe(); err != nil {
 		logger.Fatal("erro no servidor", "err", err)
 	}
 }
+
+// carregarFuso só aceita nomes IANA: "Local" não é um deles e o Postgres não saberia interpretá-lo no
+// AT TIME ZONE.
+func carregarFuso(nome string) (*time.Location, error) {
+	fuso, err := time.LoadLocation(nome)
+	if err != nil {
+		return nil, err
+	}
+	if fuso == time.Local {
+		return nil, errors.New("use um nome IANA, como America/Sao_Paulo")
+	}
+	return fuso, nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCarregarFuso(t *testing.T) {
+	casos := []struct {
+		nome     string
+		fuso     string
+		esperado string
+		erro     bool
+	}{
+		{nome: "nome IANA", fuso: "America/Sao_Paulo", esperado: "America/Sao_Paulo"},
+		{nome: "UTC", fuso: "UTC", esperado: "UTC"},
+		{nome: "vazio vale UTC", fuso: "", esperado: "UTC"},
+		{nome: "Local recusado", fuso: "Local", erro: true},
+		{nome: "nome desconhecido", fuso: "America/Atlantida", erro: true},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			fuso, err := carregarFuso(c.fuso)
+			if c.erro {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.esperado, fuso.String())
+		})
+	}
+}
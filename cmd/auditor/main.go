@@ -0,0 +1,154 @@
+// Verificador da exportação de auditoria: recalcula a cadeia de hashes e as raízes de Merkle a partir dos
+// votos exportados e aponta votos inseridos, alterados ou apagados em relação aos checkpoints assinados.
+// Com um pacote de auditoria (.zip), confere também o manifesto assinado e reapura o resultado oficial a
+// partir dos votos do pacote.
+//
+//	go run ./cmd/auditor -chaves recibos-chaves.json auditoria-<paredao>.ndjson
+//	go run ./cmd/auditor -chaves recibos-chaves.json auditoria-<paredao>.zip
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/auditoria"
+	"github.com/marcelojr/desafio-globo/internal/platform/recibos"
+)
+
+func main() {
+	arquivoChaves := flag.String("chaves", "", "JSON de /.well-known/recibos-chaves.json para conferir as assinaturas dos checkpoints")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "uso: auditor [-chaves arquivo] [exportacao.ndjson | pacote.zip]  (sem arquivo, lê a exportação da entrada padrão)")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	os.Exit(run(*arquivoChaves, flag.Arg(0), os.Stdin, os.Stdout))
+}
+
+// run devolve o código de saída: 0 para exportação íntegra, 1 para adulteração, 2 para erro de leitura e 3
+// quando nada foi encontrado, mas sem chaves ou sem checkpoint conferido não há o que garanta a integridade.
+func run(arquivoChaves, arquivo string, stdin io.Reader, out io.Writer) int {
+	publicas, err := lerChaves(arquivoChaves)
+	if err != nil {
+		fmt.Fprintln(out, "erro:", err)
+		return 2
+	}
+
+	if strings.HasSuffix(arquivo, ".zip") {
+		return verificarPacote(arquivo, publicas, out)
+	}
+
+	entrada := stdin
+	if arquivo != "" && arquivo != "-" {
+		f, err := os.Open(arquivo)
+		if err != nil {
+			fmt.Fprintln(out, "erro:", err)
+			return 2
+		}
+		defer f.Close()
+		entrada = f
+	}
+
+	rel, err := auditoria.Verificar(entrada, publicas)
+	if err != nil {
+		fmt.Fprintln(out, "erro:", err)
+		return 2
+	}
+	return imprimir(out, rel)
+}
+
+// verificarPacote confere o zip do pacote de auditoria, que precisa de acesso aleatório ao arquivo.
+func verificarPacote(arquivo string, publicas map[string]ed25519.PublicKey, out io.Writer) int {
+	f, err := os.Open(arquivo)
+	if err != nil {
+		fmt.Fprintln(out, "erro:", err)
+		return 2
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		fmt.Fprintln(out, "erro:", err)
+		return 2
+	}
+
+	rel, err := auditoria.VerificarPacote(f, info.Size(), publicas)
+	if err != nil {
+		fmt.Fprintln(out, "erro:", err)
+		return 2
+	}
+	fmt.Fprintf(out, "formato:      %s\n", rel.Formato)
+	if !rel.ManifestoAssinado {
+		fmt.Fprintln(out, "manifesto:    assinatura nao conferida (informe -chaves)")
+	}
+	fmt.Fprintf(out, "recontagem:   %d votos", rel.Recontado.TotalVotos)
+	switch {
+	case rel.Recontado.Anulado:
+		fmt.Fprint(out, ", paredao anulado")
+	case rel.Recontado.Eliminado != "":
+		fmt.Fprintf(out, ", eliminado %s", rel.Recontado.Eliminado)
+	case rel.Recontado.Escapou != "":
+		fmt.Fprintf(out, ", escapou %s", rel.Recontado.Escapou)
+	case len(rel.Recontado.Empatados) > 0:
+		fmt.Fprintf(out, ", empate entre %v", rel.Recontado.Empatados)
+	}
+	fmt.Fprintln(out)
+	return imprimir(out, rel.Relatorio)
+}
+
+// imprimir resume o relatório e devolve o código de saída correspondente.
+func imprimir(out io.Writer, rel auditoria.Relatorio) int {
+	fmt.Fprintf(out, "paredao:      %s\n", rel.ParedaoID)
+	fmt.Fprintf(out, "votos:        %d (%d fora da cadeia)\n", rel.Votos, rel.ForaDaCadeia)
+	fmt.Fprintf(out, "ultimo elo:   %s\n", rel.HashCadeia)
+	fmt.Fprintf(out, "raiz merkle:  %s\n", rel.RaizMerkle)
+	fmt.Fprintf(out, "checkpoints:  %d conferidos de %d", rel.CheckpointsConferidos, rel.Checkpoints)
+	if !rel.AssinaturasConferidas {
+		fmt.Fprint(out, " (assinaturas nao conferidas: informe -chaves)")
+	}
+	fmt.Fprintln(out)
+
+	switch {
+	case !rel.Integra():
+		fmt.Fprintf(out, "resultado:    ADULTERADA (%d problemas)\n", rel.TotalProblemas)
+		for _, p := range rel.Problemas {
+			fmt.Fprintln(out, "  -", p)
+		}
+		if omitidos := rel.TotalProblemas - len(rel.Problemas); omitidos > 0 {
+			fmt.Fprintf(out, "  ... e mais %d\n", omitidos)
+		}
+		return 1
+	case !rel.AssinaturasConferidas:
+		fmt.Fprintln(out, "resultado:    NAO VERIFICADA (sem -chaves, a cadeia pode ter sido recalculada por inteiro)")
+		return 3
+	case !rel.Verificada():
+		fmt.Fprintln(out, "resultado:    NAO VERIFICADA (nenhum checkpoint assinado cobre os votos)")
+		return 3
+	}
+	fmt.Fprintln(out, "resultado:    INTEGRA")
+	return 0
+}
+
+// lerChaves carrega as chaves públicas publicadas pela API; sem arquivo, as assinaturas não são conferidas.
+func lerChaves(arquivo string) (map[string]ed25519.PublicKey, error) {
+	if arquivo == "" {
+		return nil, nil
+	}
+	conteudo, err := os.ReadFile(arquivo)
+	if err != nil {
+		return nil, err
+	}
+	var publicadas struct {
+		Chaves []domain.ChavePublicaRecibo `json:"chaves"`
+	}
+	if err := json.Unmarshal(conteudo, &publicadas); err != nil {
+		return nil, fmt.Errorf("chaves %s: %w", arquivo, err)
+	}
+	return recibos.ChavesPorID(publicadas.Chaves)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marcelojr/desafio-globo/internal/platform/auditoria"
+)
+
+func TestImprimir(t *testing.T) {
+	casos := []struct {
+		nome   string
+		rel    auditoria.Relatorio
+		codigo int
+		resumo string
+	}{
+		{nome: "integra", rel: auditoria.Relatorio{AssinaturasConferidas: true, Checkpoints: 2, CheckpointsConferidos: 2}, codigo: 0, resumo: "INTEGRA"},
+		{nome: "sem chaves", rel: auditoria.Relatorio{Checkpoints: 2, CheckpointsConferidos: 2}, codigo: 3, resumo: "NAO VERIFICADA"},
+		{nome: "sem checkpoints", rel: auditoria.Relatorio{AssinaturasConferidas: true}, codigo: 3, resumo: "NAO VERIFICADA"},
+		{nome: "adulterada", rel: auditoria.Relatorio{TotalProblemas: 1, Problemas: []string{"voto alterado"}}, codigo: 1, resumo: "ADULTERADA"},
+	}
+	for _, caso := range casos {
+		t.Run(caso.nome, func(t *testing.T) {
+			var out bytes.Buffer
+
+			codigo := imprimir(&out, caso.rel)
+
+			assert.Equal(t, caso.codigo, codigo)
+			assert.Contains(t, out.String(), "resultado:    "+caso.resumo)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Provedor OpenID Connect de desenvolvimento: permite exercitar o login do eleitor sem depender de um IdP real.
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/platform/logger"
+	"github.com/marcelojr/desafio-globo/internal/platform/oidc/mockidp"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	addr := getEnv("MOCKIDP_ADDRESS", ":9000")
+	issuer := getEnv("MOCKIDP_ISSUER", "http://localhost:9000")
+
+	idp, err := mockidp.New(issuer, getEnv("OIDC_CLIENT_ID", "votacao-bbb"), getEnv("OIDC_CLIENT_SECRET", "votacao-bbb-segredo"))
+	if err != nil {
+		logger.Fatal("falha ao iniciar mock idp", "err", err)
+	}
+
+	srv := &http.Server{Addr: addr, Handler: idp.Handler(), ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("mock idp ouvindo", "addr", addr, "issuer", issuer)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Fatal("erro no mock idp", "err", err)
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -7,18 +7,24 @@ import (
 	"net/http"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"github.com/marcelojr/desafio-globo/internal/app/voting"
 	"github.com/marcelojr/desafio-globo/internal/app/worker"
 	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/antifraude"
 	"github.com/marcelojr/desafio-globo/internal/platform/clock"
 	"github.com/marcelojr/desafio-globo/internal/platform/config"
 	"github.com/marcelojr/desafio-globo/internal/platform/health"
+	"github.com/marcelojr/desafio-globo/internal/platform/ids"
 	"github.com/marcelojr/desafio-globo/internal/platform/logger"
 	"github.com/marcelojr/desafio-globo/internal/platform/migrations"
+	"github.com/marcelojr/desafio-globo/internal/platform/recibos"
 	postgresstorage "github.com/marcelojr/desafio-globo/internal/platform/storage/postgres"
 	redisstorage "github.com/marcelojr/desafio-globo/internal/platform/storage/redis"
+	"github.com/marcelojr/desafio-globo/internal/platform/webhook"
 )
 
 func main() {
@@ -74,7 +80,91 @@ func main() {
 	}
 
 	votoRepo := postgresstorage.NewVotoRepository(db)
-	processor := worker.NewVoteProcessor(votoRepo, contador, clockSystem)
+
+	var observadores []worker.Observador
+	if cfg.AnomaliaEnabled {
+		// Guardamos algumas janelas extras para que votos atrasados na fila ainda entrem na contagem.
+		janela := time.Duration(cfg.AnomaliaJanelaSeconds) * time.Second
+		janelas := redisstorage.NewVelocidade(redisClient, cfg.AnomaliaKeyPrefix, 10*janela)
+		detector := worker.NewDetectorAnomalias(
+			janelas,
+			postgresstorage.NewParedaoRepository(db),
+			webhook.NewCliente(5*time.Second),
+			logger.L(),
+			worker.ConfigAnomalia{
+				Janela:     janela,
+				Alpha:      cfg.AnomaliaAlpha,
+				LimiarZ:    cfg.AnomaliaLimiarZ,
+				MinVotos:   int64(cfg.AnomaliaMinVotos),
+				WebhookURL: cfg.AnomaliaWebhookURL,
+			},
+		)
+		go detector.Rodar(ctx)
+		observadores = append(observadores, detector)
+	}
+
+	if cfg.AvisosEnabled {
+		avisos := redisstorage.NewAvisosParciais(redisClient, cfg.AvisosCanal)
+		intervalo := time.Duration(cfg.AvisosIntervaloMS) * time.Millisecond
+		observadores = append(observadores, worker.NewPublicadorAvisos(avisos, intervalo, logger.L()))
+	}
+
+	if cfg.AuditoriaCheckpointSeconds > 0 {
+		// Os checkpoints usam as chaves dos recibos; com chave efêmera eles só conferem enquanto o worker vive.
+		var assinador *recibos.Assinador
+		if cfg.ReciboChaves != "" {
+			assinador, err = recibos.NewAssinador(cfg.ReciboChaves)
+		} else {
+			logger.L().Warn("RECIBO_CHAVES vazio: checkpoints assinados com chave efemera")
+			assinador, err = recibos.NewAssinadorEfemero()
+		}
+		if err != nil {
+			logger.Fatal("chaves de recibo invalidas", "err", err)
+		}
+		intervalo := time.Duration(cfg.AuditoriaCheckpointSeconds) * time.Second
+		gerador := worker.NewGeradorCheckpoints(postgresstorage.NewAuditoriaRepository(db), assinador, clockSystem, intervalo, logger.L())
+		go gerador.Rodar(ctx)
+	}
+
+	// A ponta é apurada pelas mesmas regras da API; o serviço aqui só lê, sem fila nem antifraude. Com os
+	// webhooks, as viradas e a finalização também viram eventos para entrega.
+	webhooks := postgresstorage.NewWebhookRepository(db)
+	servico := voting.NewService(
+		postgresstorage.NewParedaoRepository(db),
+		postgresstorage.NewParticipanteRepository(db),
+		votoRepo,
+		contador,
+		nil,
+		antifraude.NewNoop(),
+		clockSystem,
+		ids.NewGenerator(),
+		voting.ComViradas(postgresstorage.NewViradaRepository(db), int64(cfg.ViradasMinVotos)),
+		voting.ComWebhooks(webhooks),
+	)
+
+	if cfg.ViradasIntervaloSeconds > 0 {
+		intervalo := time.Duration(cfg.ViradasIntervaloSeconds) * time.Second
+		monitor := worker.NewMonitorViradas(servico, intervalo, logger.L())
+		go monitor.Rodar(ctx)
+	}
+	if cfg.ViradasWebhookURL != "" {
+		// O POST direto, sem assinatura nem reenvio, deu lugar ao evento paredao.virada do cadastro de webhooks.
+		logger.L().Warn("VIRADAS_WEBHOOK_URL descontinuado e ignorado: assine o evento paredao.virada em POST /admin/webhooks")
+	}
+
+	if cfg.WebhooksIntervaloMS > 0 {
+		const timeoutWebhook = 10 * time.Second
+		entregador := worker.NewEntregadorWebhooks(webhooks, servico, webhook.NewCliente(timeoutWebhook), clockSystem, logger.L(), worker.ConfigEntregas{
+			Intervalo:     time.Duration(cfg.WebhooksIntervaloMS) * time.Millisecond,
+			MaxTentativas: cfg.WebhooksMaxTentativas,
+			Backoff:       time.Duration(cfg.WebhooksBackoffSeconds) * time.Second,
+			Timeout:       timeoutWebhook,
+		})
+		go entregador.Rodar(ctx)
+	}
+
+	statusVotos := redisstorage.NewStatusVotos(redisClient, cfg.VotoStatusKeyPrefix, time.Duration(cfg.VotoStatusTTLSeconds)*time.Second)
+	processor := worker.NewVoteProcessor(votoRepo, contador, statusVotos, clockSystem, observadores...)
 
 	logger.Info("worker iniciado, aguardando votos")
 	err = fila.ConsumirVotos(ctx, func(ctx context.Context, voto domain.Voto) error {
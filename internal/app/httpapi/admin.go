@@ -0,0 +1,523 @@
+package httpapi
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/app/voting"
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/auditoria"
+)
+
+// Admin expõe operações administrativas protegidas por token Bearer estático.
+type Admin struct {
+	service domain.AdminService
+	token   string
+	logger  *slog.Logger
+}
+
+func NewAdmin(service domain.AdminService, token string, logger *slog.Logger) *Admin {
+	return &Admin{service: service, token: token, logger: logger}
+}
+
+func (a *Admin) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/admin/paredoes", a.autenticar(a.criarParedao))
+	mux.HandleFunc("/admin/paredoes/", a.autenticar(a.handleParedao))
+	mux.HandleFunc("/admin/webhooks", a.autenticar(a.handleWebhooks))
+	mux.HandleFunc("/admin/webhooks/", a.autenticar(a.handleWebhook))
+}
+
+// autenticar recusa tudo quando nenhum token foi configurado, evitando um admin aberto por descuido.
+func (a *Admin) autenticar(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		recebido, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if a.token == "" || !ok || subtle.ConstantTimeCompare([]byte(recebido), []byte(a.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			responderErro(w, r, a.logger, FalhaNaoAutorizado)
+			return
+		}
+		next(w, r)
+	}
+}
+
+type etapaRequest struct {
+	Nome        string                 `json:"nome"`
+	Inicio      time.Time              `json:"inicio"`
+	Fim         time.Time              `json:"fim"`
+	Polaridade  domain.Polaridade      `json:"polaridade"`
+	ModoVotacao domain.ModoVotacao     `json:"modo_votacao"`
+	Pesos       domain.PesosModalidade `json:"pesos"`
+}
+
+type criarParedaoRequest struct {
+	Nome          string         `json:"nome"`
+	Descricao     string         `json:"descricao"`
+	Participantes []string       `json:"participantes"`
+	Etapas        []etapaRequest `json:"etapas"`
+}
+
+// criarParedao cria um paredão de uma ou mais etapas; só a primeira recebe os participantes informados.
+func (a *Admin) criarParedao(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		responderErro(w, r, a.logger, FalhaMetodoNaoSuportado)
+		return
+	}
+
+	var req criarParedaoRequest
+	if err := decodificarJSON(r, &req); err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	etapas := make([]domain.Paredao, len(req.Etapas))
+	for i, e := range req.Etapas {
+		etapas[i] = domain.Paredao{
+			Nome:        req.Nome,
+			Descricao:   req.Descricao,
+			EtapaNome:   e.Nome,
+			Inicio:      e.Inicio,
+			Fim:         e.Fim,
+			Polaridade:  e.Polaridade,
+			ModoVotacao: e.ModoVotacao,
+			Pesos:       e.Pesos,
+		}
+	}
+	participantes := make([]domain.Participante, len(req.Participantes))
+	for i, nome := range req.Participantes {
+		participantes[i] = domain.Participante{Nome: nome}
+	}
+
+	criadas, err := a.service.CriarEtapas(r.Context(), etapas, participantes)
+	if err != nil {
+		a.logger.Warn("falha ao criar paredao", "err", err)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	a.logger.Info("paredao criado", "paredao", criadas[0].ID, "etapas", len(criadas))
+	responderJSON(w, http.StatusCreated, criadas)
+}
+
+func (a *Admin) handleParedao(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/admin/paredoes/")
+	partes := strings.Split(path, "/")
+	if partes[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	id := domain.ParedaoID(partes[0])
+
+	// /admin/paredoes/{id}/participantes/{participante}/retirada
+	if len(partes) == 4 && partes[1] == "participantes" && partes[2] != "" && partes[3] == "retirada" {
+		if r.Method != http.MethodPost {
+			responderErro(w, r, a.logger, FalhaMetodoNaoSuportado)
+			return
+		}
+		a.retirarParticipante(w, r, id, domain.ParticipanteID(partes[2]))
+		return
+	}
+	if len(partes) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case partes[1] == "antifraude" && r.Method == http.MethodGet:
+		a.obterPolitica(w, r, id)
+	case partes[1] == "antifraude" && r.Method == http.MethodPut:
+		a.atualizarPolitica(w, r, id)
+	case partes[1] == "visibilidade" && r.Method == http.MethodGet:
+		a.obterVisibilidade(w, r, id)
+	case partes[1] == "visibilidade" && r.Method == http.MethodPut:
+		a.atualizarVisibilidade(w, r, id)
+	case partes[1] == "anomalia" && r.Method == http.MethodGet:
+		a.obterLimiaresAnomalia(w, r, id)
+	case partes[1] == "anomalia" && r.Method == http.MethodPut:
+		a.atualizarLimiaresAnomalia(w, r, id)
+	case partes[1] == "votacao" && r.Method == http.MethodPut:
+		a.atualizarVotacao(w, r, id)
+	case partes[1] == "finalizar" && r.Method == http.MethodPost:
+		a.finalizar(w, r, id)
+	case partes[1] == "desempate" && r.Method == http.MethodPost:
+		a.desempatar(w, r, id)
+	case partes[1] == "auditoria" && r.Method == http.MethodGet:
+		a.exportarAuditoria(w, r, id)
+	case partes[1] == "pacote-auditoria" && r.Method == http.MethodGet:
+		a.exportarPacote(w, r, id)
+	case partes[1] == "projecao" && r.Method == http.MethodGet:
+		a.obterProjecao(w, r, id)
+	case partes[1] == "antifraude", partes[1] == "visibilidade", partes[1] == "anomalia", partes[1] == "votacao", partes[1] == "finalizar", partes[1] == "desempate", partes[1] == "auditoria",
+		partes[1] == "pacote-auditoria", partes[1] == "projecao":
+		responderErro(w, r, a.logger, FalhaMetodoNaoSuportado)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (a *Admin) obterPolitica(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	politica, err := a.service.ObterPoliticaAntifraude(r.Context(), id)
+	if err != nil {
+		a.logger.Error("erro ao obter politica antifraude", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, politica)
+}
+
+func (a *Admin) atualizarPolitica(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	var politica domain.PoliticaAntifraude
+	if err := decodificarJSON(r, &politica); err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	atualizada, err := a.service.AtualizarPoliticaAntifraude(r.Context(), id, politica)
+	if err != nil {
+		a.logger.Warn("falha ao atualizar politica antifraude", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	a.logger.Info("politica antifraude atualizada", "paredao", id, "politica", atualizada)
+	responderJSON(w, http.StatusOK, atualizada)
+}
+
+func (a *Admin) obterVisibilidade(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	politica, err := a.service.ObterVisibilidade(r.Context(), id)
+	if err != nil {
+		a.logger.Error("erro ao obter visibilidade", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, politica)
+}
+
+func (a *Admin) atualizarVisibilidade(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	var politica domain.PoliticaVisibilidade
+	if err := decodificarJSON(r, &politica); err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	atualizada, err := a.service.AtualizarVisibilidade(r.Context(), id, politica)
+	if err != nil {
+		a.logger.Warn("falha ao atualizar visibilidade", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	a.logger.Info("visibilidade atualizada", "paredao", id, "modo", atualizada.Modo, "atraso_minutos", atualizada.AtrasoMinutos)
+	responderJSON(w, http.StatusOK, atualizada)
+}
+
+func (a *Admin) obterLimiaresAnomalia(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	limiares, err := a.service.ObterLimiaresAnomalia(r.Context(), id)
+	if err != nil {
+		a.logger.Error("erro ao obter limiares de anomalia", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, limiares)
+}
+
+func (a *Admin) atualizarLimiaresAnomalia(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	var limiares domain.LimiaresAnomalia
+	if err := decodificarJSON(r, &limiares); err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	atualizados, err := a.service.AtualizarLimiaresAnomalia(r.Context(), id, limiares)
+	if err != nil {
+		a.logger.Warn("falha ao atualizar limiares de anomalia", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	a.logger.Info("limiares de anomalia atualizados", "paredao", id, "limiar_z", atualizados.LimiarZ, "min_votos", atualizados.MinVotos)
+	responderJSON(w, http.StatusOK, atualizados)
+}
+
+// votacaoRequest deixa pesos como ponteiro: sem o campo, os pesos atuais ficam, como modo e polaridade vazios.
+type votacaoRequest struct {
+	ModoVotacao domain.ModoVotacao      `json:"modo_votacao"`
+	Pesos       *domain.PesosModalidade `json:"pesos,omitempty"`
+	Polaridade  domain.Polaridade       `json:"polaridade,omitempty"`
+}
+
+func (a *Admin) atualizarVotacao(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	var req votacaoRequest
+	if err := decodificarJSON(r, &req); err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	paredao, err := a.service.AtualizarVotacao(r.Context(), id, req.ModoVotacao, req.Pesos, req.Polaridade)
+	if err != nil {
+		a.logger.Warn("falha ao atualizar modo de votacao", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	a.logger.Info("modo de votacao atualizado", "paredao", id, "modo", paredao.ModoVotacao, "pesos", paredao.Pesos, "polaridade", paredao.Polaridade)
+	responderJSON(w, http.StatusOK, votacaoRequest{ModoVotacao: paredao.ModoVotacao, Pesos: &paredao.Pesos, Polaridade: paredao.PolaridadeEfetiva()})
+}
+
+type retiradaRequest struct {
+	Politica domain.PoliticaRetirada `json:"politica"`
+}
+
+func (a *Admin) retirarParticipante(w http.ResponseWriter, r *http.Request, id domain.ParedaoID, participanteID domain.ParticipanteID) {
+	var req retiradaRequest
+	if err := decodificarJSON(r, &req); err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	paredao, err := a.service.RetirarParticipante(r.Context(), id, participanteID, req.Politica)
+	if err != nil {
+		a.logger.Warn("falha ao retirar participante", "err", err, "paredao", id, "participante", participanteID)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	a.logger.Info("participante retirado", "paredao", id, "participante", participanteID, "politica", req.Politica, "anulado", paredao.Anulado)
+	responderJSON(w, http.StatusOK, paredao)
+}
+
+func (a *Admin) finalizar(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	resultado, err := a.service.Finalizar(r.Context(), id)
+	if err != nil {
+		a.logger.Error("erro ao finalizar paredao", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	a.logger.Info("paredao finalizado", "paredao", id, "total_votos", resultado.TotalVotos, "eliminado", resultado.Eliminado)
+	responderJSON(w, http.StatusOK, resultado)
+}
+
+type desempateRequest struct {
+	Escapou domain.ParticipanteID `json:"escapou"`
+}
+
+func (a *Admin) desempatar(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	var req desempateRequest
+	if err := decodificarJSON(r, &req); err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	resultado, err := a.service.Desempatar(r.Context(), id, req.Escapou)
+	if err != nil {
+		a.logger.Warn("falha ao desempatar etapa", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	a.logger.Info("etapa desempatada", "paredao", id, "escapou", resultado.Escapou, "proxima_etapa", resultado.ProximaEtapa)
+	responderJSON(w, http.StatusOK, resultado)
+}
+
+// obterProjecao devolve a estimativa do resultado final, calculada sobre a série no intervalo de ?bucket=.
+func (a *Admin) obterProjecao(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	intervalo, err := voting.ParseIntervaloSerie(r.URL.Query().Get("bucket"))
+	if err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	projecao, err := a.service.ProjecaoFinal(r.Context(), id, intervalo)
+	if err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, projecao)
+}
+
+// exportarAuditoria transmite em NDJSON o cabeçalho, os checkpoints e todos os votos do paredão em ordem de
+// cadeia, no formato lido por cmd/auditor. Erros depois do primeiro byte só podem ser registrados no log.
+func (a *Admin) exportarAuditoria(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	checkpoints, err := a.service.Checkpoints(r.Context(), id)
+	if err != nil {
+		a.logger.Error("erro ao listar checkpoints", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	semPrazos(w)
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="auditoria-`+string(id)+`.ndjson"`)
+	exportador := auditoria.NewExportador(w)
+	if err := exportador.Cabecalho(id, time.Now()); err != nil {
+		return
+	}
+	for _, c := range checkpoints {
+		if err := exportador.Checkpoint(c); err != nil {
+			return
+		}
+	}
+	if err := a.service.ExportarVotos(r.Context(), id, exportador.Voto); err != nil {
+		a.logger.Error("exportacao de auditoria interrompida", "err", err, "paredao", id)
+		return
+	}
+	a.logger.Info("exportacao de auditoria concluida", "paredao", id, "checkpoints", len(checkpoints))
+}
+
+// exportarPacote transmite o pacote de auditoria de um paredão encerrado como zip: paredão, participantes,
+// resultado oficial, checkpoints, votos (CSV ou NDJSON, com IPs pseudonimizados) e o manifesto assinado com
+// o SHA-256 de cada arquivo. Os votos seguem do Postgres para a resposta em lotes; se a transmissão cair
+// no meio, o zip fica sem manifesto e o verificador o recusa.
+func (a *Admin) exportarPacote(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	formato, err := auditoria.LerFormato(r.URL.Query().Get("formato"))
+	if err != nil {
+		responderErro(w, r, a.logger, fmt.Errorf("%w: %v", FalhaParametroInvalido, err))
+		return
+	}
+	dados, err := a.service.PacoteAuditoria(r.Context(), id)
+	if err != nil {
+		a.logger.Error("erro ao montar pacote de auditoria", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	semPrazos(w)
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="auditoria-`+string(id)+`.zip"`)
+	pacote := auditoria.NewPacote(w, id, time.Now())
+	if err := escreverPacote(r, pacote, dados, formato, a.service); err != nil {
+		a.logger.Error("pacote de auditoria interrompido", "err", err, "paredao", id)
+		return
+	}
+	a.logger.Info("pacote de auditoria exportado", "paredao", id, "formato", formato)
+}
+
+func escreverPacote(r *http.Request, pacote *auditoria.Pacote, dados domain.PacoteAuditoria, formato auditoria.FormatoVotos, service domain.AdminService) error {
+	for _, arquivo := range []struct {
+		nome     string
+		conteudo any
+	}{
+		{auditoria.ArquivoParedao, dados.Paredao},
+		{auditoria.ArquivoParticipantes, dados.Participantes},
+		{auditoria.ArquivoResultado, dados.Resultado},
+		{auditoria.ArquivoCheckpoints, dados.Checkpoints},
+	} {
+		if err := pacote.JSON(arquivo.nome, arquivo.conteudo); err != nil {
+			return err
+		}
+	}
+
+	w, err := pacote.Criar(formato.Arquivo())
+	if err != nil {
+		return err
+	}
+	votos := auditoria.NewEscritorVotos(formato, w)
+	if err := service.ExportarVotos(r.Context(), dados.Paredao.ID, votos.Escrever); err != nil {
+		return err
+	}
+	if err := votos.Concluir(); err != nil {
+		return err
+	}
+	return pacote.Fechar(service.AssinarMensagem)
+}
+
+type criarWebhookRequest struct {
+	URL     string              `json:"url"`
+	Segredo string              `json:"segredo"`
+	Eventos []domain.TipoEvento `json:"eventos"`
+}
+
+// handleWebhooks cadastra (POST) e lista (GET) as assinaturas. O segredo só volta na criação.
+func (a *Admin) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		webhooks, err := a.service.ListarWebhooks(r.Context())
+		if err != nil {
+			responderErro(w, r, a.logger, err)
+			return
+		}
+		if webhooks == nil {
+			webhooks = []domain.Webhook{}
+		}
+		responderJSON(w, http.StatusOK, webhooks)
+	case http.MethodPost:
+		var req criarWebhookRequest
+		if err := decodificarJSON(r, &req); err != nil {
+			responderErro(w, r, a.logger, err)
+			return
+		}
+		criado, err := a.service.CriarWebhook(r.Context(), domain.Webhook{URL: req.URL, Segredo: req.Segredo, Eventos: req.Eventos})
+		if err != nil {
+			a.logger.Warn("falha ao criar webhook", "err", err)
+			responderErro(w, r, a.logger, err)
+			return
+		}
+		a.logger.Info("webhook criado", "webhook", criado.ID, "eventos", criado.Eventos)
+		responderJSON(w, http.StatusCreated, criado)
+	default:
+		responderErro(w, r, a.logger, FalhaMetodoNaoSuportado)
+	}
+}
+
+func (a *Admin) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	partes := strings.Split(strings.TrimPrefix(r.URL.Path, "/admin/webhooks/"), "/")
+	if partes[0] == "" {
+		http.NotFound(w, r)
+		return
+	}
+	id := domain.WebhookID(partes[0])
+
+	switch {
+	// /admin/webhooks/{id}
+	case len(partes) == 1 && r.Method == http.MethodDelete:
+		if err := a.service.DesativarWebhook(r.Context(), id); err != nil {
+			responderErro(w, r, a.logger, err)
+			return
+		}
+		a.logger.Info("webhook desativado", "webhook", id)
+		w.WriteHeader(http.StatusNoContent)
+	// /admin/webhooks/{id}/entregas
+	case len(partes) == 2 && partes[1] == "entregas" && r.Method == http.MethodGet:
+		a.listarEntregas(w, r, id)
+	// /admin/webhooks/{id}/entregas/{entrega}/reenviar
+	case len(partes) == 4 && partes[1] == "entregas" && partes[2] != "" && partes[3] == "reenviar" && r.Method == http.MethodPost:
+		entrega, err := a.service.ReenviarEntrega(r.Context(), id, domain.EntregaID(partes[2]))
+		if err != nil {
+			responderErro(w, r, a.logger, err)
+			return
+		}
+		a.logger.Info("entrega de webhook reenviada", "webhook", id, "entrega", entrega.ID)
+		responderJSON(w, http.StatusAccepted, entrega)
+	case len(partes) == 1, len(partes) == 2 && partes[1] == "entregas", len(partes) == 4 && partes[1] == "entregas" && partes[3] == "reenviar":
+		responderErro(w, r, a.logger, FalhaMetodoNaoSuportado)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// listarEntregas devolve o registro de entregas da assinatura, das mais recentes para as mais antigas.
+func (a *Admin) listarEntregas(w http.ResponseWriter, r *http.Request, id domain.WebhookID) {
+	limite := 0
+	if s := r.URL.Query().Get("limite"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			responderErro(w, r, a.logger, fmt.Errorf("%w: limite deve ser um inteiro positivo", FalhaParametroInvalido))
+			return
+		}
+		limite = n
+	}
+	entregas, err := a.service.EntregasWebhook(r.Context(), id, limite)
+	if err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	if entregas == nil {
+		entregas = []domain.EntregaWebhook{}
+	}
+	responderJSON(w, http.StatusOK, entregas)
+}
@@ -0,0 +1,501 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/app/voting"
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/auditoria"
+	"github.com/marcelojr/desafio-globo/internal/platform/recibos"
+)
+
+// MockAdminService implementa a interface administrativa para testes
+type MockAdminService struct {
+	mock.Mock
+}
+
+func (m *MockAdminService) ObterPoliticaAntifraude(ctx context.Context, id domain.ParedaoID) (domain.PoliticaAntifraude, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.PoliticaAntifraude), args.Error(1)
+}
+
+func (m *MockAdminService) AtualizarPoliticaAntifraude(ctx context.Context, id domain.ParedaoID, politica domain.PoliticaAntifraude) (domain.PoliticaAntifraude, error) {
+	args := m.Called(ctx, id, politica)
+	return args.Get(0).(domain.PoliticaAntifraude), args.Error(1)
+}
+
+func (m *MockAdminService) ObterVisibilidade(ctx context.Context, id domain.ParedaoID) (domain.PoliticaVisibilidade, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.PoliticaVisibilidade), args.Error(1)
+}
+
+func (m *MockAdminService) ObterLimiaresAnomalia(ctx context.Context, id domain.ParedaoID) (domain.LimiaresAnomalia, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.LimiaresAnomalia), args.Error(1)
+}
+
+func (m *MockAdminService) AtualizarLimiaresAnomalia(ctx context.Context, id domain.ParedaoID, limiares domain.LimiaresAnomalia) (domain.LimiaresAnomalia, error) {
+	args := m.Called(ctx, id, limiares)
+	return args.Get(0).(domain.LimiaresAnomalia), args.Error(1)
+}
+
+func (m *MockAdminService) AtualizarVisibilidade(ctx context.Context, id domain.ParedaoID, politica domain.PoliticaVisibilidade) (domain.PoliticaVisibilidade, error) {
+	args := m.Called(ctx, id, politica)
+	return args.Get(0).(domain.PoliticaVisibilidade), args.Error(1)
+}
+
+func (m *MockAdminService) CriarEtapas(ctx context.Context, etapas []domain.Paredao, participantes []domain.Participante) ([]domain.Paredao, error) {
+	args := m.Called(ctx, etapas, participantes)
+	return args.Get(0).([]domain.Paredao), args.Error(1)
+}
+
+func (m *MockAdminService) AtualizarVotacao(ctx context.Context, id domain.ParedaoID, modo domain.ModoVotacao, pesos *domain.PesosModalidade, polaridade domain.Polaridade) (domain.Paredao, error) {
+	args := m.Called(ctx, id, modo, pesos, polaridade)
+	return args.Get(0).(domain.Paredao), args.Error(1)
+}
+
+func (m *MockAdminService) RetirarParticipante(ctx context.Context, id domain.ParedaoID, participanteID domain.ParticipanteID, politica domain.PoliticaRetirada) (domain.Paredao, error) {
+	args := m.Called(ctx, id, participanteID, politica)
+	return args.Get(0).(domain.Paredao), args.Error(1)
+}
+
+func (m *MockAdminService) Finalizar(ctx context.Context, id domain.ParedaoID) (domain.Resultado, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Resultado), args.Error(1)
+}
+
+func (m *MockAdminService) Desempatar(ctx context.Context, id domain.ParedaoID, escapou domain.ParticipanteID) (domain.Resultado, error) {
+	args := m.Called(ctx, id, escapou)
+	return args.Get(0).(domain.Resultado), args.Error(1)
+}
+
+func (m *MockAdminService) Checkpoints(ctx context.Context, id domain.ParedaoID) ([]domain.Checkpoint, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]domain.Checkpoint), args.Error(1)
+}
+
+func (m *MockAdminService) ExportarVotos(ctx context.Context, id domain.ParedaoID, emitir func(domain.VotoAuditado) error) error {
+	args := m.Called(ctx, id, emitir)
+	if votos, ok := args.Get(0).([]domain.VotoAuditado); ok {
+		for _, v := range votos {
+			if err := emitir(v); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockAdminService) PacoteAuditoria(ctx context.Context, id domain.ParedaoID) (domain.PacoteAuditoria, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.PacoteAuditoria), args.Error(1)
+}
+
+func (m *MockAdminService) AssinarMensagem(msg []byte) (string, string) {
+	args := m.Called(msg)
+	if assinar, ok := args.Get(0).(func([]byte) (string, string)); ok {
+		return assinar(msg)
+	}
+	return args.String(0), args.String(1)
+}
+
+func (m *MockAdminService) ProjecaoFinal(ctx context.Context, id domain.ParedaoID, intervalo time.Duration) (domain.Projecao, error) {
+	args := m.Called(ctx, id, intervalo)
+	return args.Get(0).(domain.Projecao), args.Error(1)
+}
+
+func (m *MockAdminService) CriarWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	args := m.Called(ctx, webhook)
+	return args.Get(0).(domain.Webhook), args.Error(1)
+}
+
+func (m *MockAdminService) ListarWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]domain.Webhook), args.Error(1)
+}
+
+func (m *MockAdminService) DesativarWebhook(ctx context.Context, id domain.WebhookID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAdminService) EntregasWebhook(ctx context.Context, id domain.WebhookID, limite int) ([]domain.EntregaWebhook, error) {
+	args := m.Called(ctx, id, limite)
+	return args.Get(0).([]domain.EntregaWebhook), args.Error(1)
+}
+
+func (m *MockAdminService) ReenviarEntrega(ctx context.Context, id domain.WebhookID, entrega domain.EntregaID) (domain.EntregaWebhook, error) {
+	args := m.Called(ctx, id, entrega)
+	return args.Get(0).(domain.EntregaWebhook), args.Error(1)
+}
+
+func setupAdmin(t *testing.T) (*http.ServeMux, *MockAdminService) {
+	mockService := new(MockAdminService)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{}))
+	mux := http.NewServeMux()
+	NewAdmin(mockService, "segredo", logger).Register(mux)
+
+	t.Cleanup(func() {
+		mockService.AssertExpectations(t)
+	})
+
+	return mux, mockService
+}
+
+func TestAdmin_QuandoTokenAusente_DeveRetornar401(t *testing.T) {
+	mux, _ := setupAdmin(t)
+
+	req := httptest.NewRequest("GET", "/admin/paredoes/p1/antifraude", nil)
+	req.Header.Set("Authorization", "Bearer errado")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAdmin_AtualizarPolitica_QuandoValida_DeveRetornarPoliticaGravada(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	politica := domain.PoliticaAntifraude{Limite: 5, JanelaSegundos: 300, Algoritmo: domain.AlgoritmoJanelaDeslizante, ExigeCaptcha: true}
+	mockService.On("AtualizarPoliticaAntifraude", mock.Anything, domain.ParedaoID("p1"), politica).Return(politica, nil)
+
+	body := `{"limite":5,"janela_segundos":300,"algoritmo":"janela_deslizante","exige_captcha":true}`
+	req := httptest.NewRequest("PUT", "/admin/paredoes/p1/antifraude", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response domain.PoliticaAntifraude
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, politica, response)
+}
+
+func TestAdmin_AtualizarPolitica_QuandoInvalida_DeveRetornar400(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	mockService.On("AtualizarPoliticaAntifraude", mock.Anything, domain.ParedaoID("p1"), mock.Anything).
+		Return(domain.PoliticaAntifraude{}, voting.ErrParedaoInvalido)
+
+	req := httptest.NewRequest("PUT", "/admin/paredoes/p1/antifraude", strings.NewReader(`{"algoritmo":"token_bucket"}`))
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdmin_Finalizar_QuandoParedaoExiste_DeveRetornarResultado(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	resultado := domain.Resultado{ParedaoID: "p1", TotalVotos: 10, Parciais: []domain.Parcial{{ParedaoID: "p1", ParticipanteID: "a", Total: 10, Percentual: 100}}}
+	mockService.On("Finalizar", mock.Anything, domain.ParedaoID("p1")).Return(resultado, nil)
+
+	req := httptest.NewRequest("POST", "/admin/paredoes/p1/finalizar", nil)
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response domain.Resultado
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, int64(10), response.TotalVotos)
+}
+
+func TestAdmin_AtualizarVotacao_QuandoValida_DeveRetornarModoEPesos(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	pesos := domain.PesosModalidade{Unico: 50, Torcida: 50}
+	mockService.On("AtualizarVotacao", mock.Anything, domain.ParedaoID("p1"), domain.ModoMisto, &pesos, domain.PolaridadeSalvar).
+		Return(domain.Paredao{ID: "p1", ModoVotacao: domain.ModoMisto, Pesos: pesos, Polaridade: domain.PolaridadeSalvar}, nil)
+
+	req := httptest.NewRequest("PUT", "/admin/paredoes/p1/votacao", strings.NewReader(`{"modo_votacao":"misto","pesos":{"unico":50,"torcida":50},"polaridade":"salvar"}`))
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"modo_votacao":"misto"`)
+	assert.Contains(t, w.Body.String(), `"polaridade":"salvar"`)
+}
+
+func TestAdmin_CriarParedao_QuandoEmEtapas_DeveRepassarEtapasEParticipantes(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	mockService.On("CriarEtapas", mock.Anything, mock.MatchedBy(func(etapas []domain.Paredao) bool {
+		return len(etapas) == 2 && etapas[0].EtapaNome == "Bate-volta" && etapas[0].Nome == "Paredão 10" &&
+			etapas[0].Polaridade == domain.PolaridadeSalvar && etapas[1].EtapaNome == "Paredão"
+	}), []domain.Participante{{Nome: "Ana"}, {Nome: "Bia"}, {Nome: "Caio"}}).
+		Return([]domain.Paredao{{ID: "g1", GrupoID: "g1", Etapa: 1}, {ID: "e2", GrupoID: "g1", Etapa: 2}}, nil)
+
+	body := `{"nome":"Paredão 10","participantes":["Ana","Bia","Caio"],"etapas":[
+		{"nome":"Bate-volta","inicio":"2024-11-10T20:00:00Z","fim":"2024-11-10T23:00:00Z","polaridade":"salvar"},
+		{"nome":"Paredão","inicio":"2024-11-10T23:00:00Z","fim":"2024-11-12T22:00:00Z"}]}`
+	req := httptest.NewRequest("POST", "/admin/paredoes", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"GrupoID":"g1"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestAdmin_RetirarParticipante_QuandoPoliticaValida_DeveRepassarAoServico(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	mockService.On("RetirarParticipante", mock.Anything, domain.ParedaoID("p1"), domain.ParticipanteID("a"), domain.RetiradaManter).
+		Return(domain.Paredao{ID: "p1", Ativo: true}, nil)
+
+	req := httptest.NewRequest("POST", "/admin/paredoes/p1/participantes/a/retirada", strings.NewReader(`{"politica":"manter"}`))
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAdmin_RetirarParticipante_QuandoJaRetirado_DeveRetornar409(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	mockService.On("RetirarParticipante", mock.Anything, domain.ParedaoID("p1"), domain.ParticipanteID("a"), domain.RetiradaDescartar).
+		Return(domain.Paredao{}, voting.ErrParticipanteRetirado)
+
+	req := httptest.NewRequest("POST", "/admin/paredoes/p1/participantes/a/retirada", strings.NewReader(`{"politica":"descartar"}`))
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAdmin_AtualizarVisibilidade_QuandoAtrasada_DeveRetornarPoliticaGravada(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	politica := domain.PoliticaVisibilidade{Modo: domain.VisibilidadeAtrasada, AtrasoMinutos: 15}
+	mockService.On("AtualizarVisibilidade", mock.Anything, domain.ParedaoID("p1"), politica).Return(politica, nil)
+
+	req := httptest.NewRequest("PUT", "/admin/paredoes/p1/visibilidade", strings.NewReader(`{"modo":"atrasada","atraso_minutos":15}`))
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"atraso_minutos":15`)
+}
+
+func TestAdmin_AtualizarLimiaresAnomalia_QuandoValidos_DeveRetornarLimiaresGravados(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	limiares := domain.LimiaresAnomalia{LimiarZ: 6, MinVotos: 500}
+	mockService.On("AtualizarLimiaresAnomalia", mock.Anything, domain.ParedaoID("p1"), limiares).Return(limiares, nil)
+
+	req := httptest.NewRequest("PUT", "/admin/paredoes/p1/anomalia", strings.NewReader(`{"limiar_z":6,"min_votos":500}`))
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"limiar_z":6,"min_votos":500}`, w.Body.String())
+}
+
+func TestAdmin_ExportarAuditoria_QuandoParedaoExiste_DeveTransmitirNDJSONVerificavel(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	var cadeia auditoria.Cadeia
+	voto := domain.VotoAuditado{VotoID: "v1", ParedaoID: "p1", ParticipanteID: "a", Modalidade: domain.ModalidadeTorcida}
+	seq, elo := cadeia.Anexar(auditoria.Folha(voto))
+	voto.Seq, voto.Hash = seq, elo.String()
+	checkpoint := domain.Checkpoint{ParedaoID: "p1", Tamanho: 1, HashCadeia: elo.String(), RaizMerkle: cadeia.Raiz().String()}
+	mockService.On("Checkpoints", mock.Anything, domain.ParedaoID("p1")).Return([]domain.Checkpoint{checkpoint}, nil)
+	mockService.On("ExportarVotos", mock.Anything, domain.ParedaoID("p1"), mock.Anything).Return([]domain.VotoAuditado{voto}, nil)
+
+	req := httptest.NewRequest("GET", "/admin/paredoes/p1/auditoria", nil)
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	rel, err := auditoria.Verificar(w.Body, nil)
+	require.NoError(t, err)
+	assert.True(t, rel.Integra(), rel.Problemas)
+	assert.Equal(t, 1, rel.CheckpointsConferidos)
+}
+
+func TestAdmin_ExportarPacote_QuandoParedaoFinalizado_DeveTransmitirZipVerificavel(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+	assinador, err := recibos.NewAssinadorEfemero()
+	require.NoError(t, err)
+
+	var cadeia auditoria.Cadeia
+	voto := domain.VotoAuditado{VotoID: "v1", ParedaoID: "p1", ParticipanteID: "a", Modalidade: domain.ModalidadeTorcida, OrigemIP: "pseudonimo"}
+	voto.Seq, _ = cadeia.Anexar(auditoria.Folha(voto))
+	voto.Hash = cadeia.Elo.String()
+	dados := domain.PacoteAuditoria{
+		Paredao:       domain.Paredao{ID: "p1", ModoVotacao: domain.ModoTorcida},
+		Participantes: []domain.Participante{{ID: "a", ParedaoID: "p1"}, {ID: "b", ParedaoID: "p1"}},
+		Resultado: domain.Resultado{ParedaoID: "p1", TotalVotos: 1, Eliminado: "a", Parciais: []domain.Parcial{
+			{ParedaoID: "p1", ParticipanteID: "a", Total: 1, Percentual: 100},
+			{ParedaoID: "p1", ParticipanteID: "b"},
+		}},
+	}
+	mockService.On("PacoteAuditoria", mock.Anything, domain.ParedaoID("p1")).Return(dados, nil)
+	mockService.On("ExportarVotos", mock.Anything, domain.ParedaoID("p1"), mock.Anything).Return([]domain.VotoAuditado{voto}, nil)
+	mockService.On("AssinarMensagem", mock.Anything).Return(assinador.AssinarMensagem)
+
+	req := httptest.NewRequest("GET", "/admin/paredoes/p1/pacote-auditoria?formato=ndjson", nil)
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	chaves, err := recibos.ChavesPorID(assinador.ChavesPublicas())
+	require.NoError(t, err)
+	pacote := bytes.NewReader(w.Body.Bytes())
+	rel, err := auditoria.VerificarPacote(pacote, pacote.Size(), chaves)
+	require.NoError(t, err)
+	assert.True(t, rel.Integra(), rel.Problemas)
+	assert.Equal(t, auditoria.FormatoNDJSON, rel.Formato)
+}
+
+func TestAdmin_ExportarPacote_QuandoParedaoAberto_DeveRetornar409(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+	mockService.On("PacoteAuditoria", mock.Anything, domain.ParedaoID("p1")).Return(domain.PacoteAuditoria{}, voting.ErrParedaoAberto)
+
+	req := httptest.NewRequest("GET", "/admin/paredoes/p1/pacote-auditoria", nil)
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestAdmin_CriarWebhook_QuandoEventoDesconhecido_DeveRetornar400(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	mockService.On("CriarWebhook", mock.Anything, domain.Webhook{URL: "https://x.example/hook", Eventos: []domain.TipoEvento{"paredao.outro"}}).
+		Return(domain.Webhook{}, voting.ErrWebhookInvalido)
+
+	req := httptest.NewRequest("POST", "/admin/webhooks", strings.NewReader(`{"url":"https://x.example/hook","eventos":["paredao.outro"]}`))
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdmin_ListarEntregas_QuandoLimiteInformado_DeveRepassarAoServico(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	entregas := []domain.EntregaWebhook{{ID: "ent-1", WebhookID: "wh-1", Status: domain.EntregaFalhou, Tentativas: 8, UltimoStatusHTTP: 500}}
+	mockService.On("EntregasWebhook", mock.Anything, domain.WebhookID("wh-1"), 20).Return(entregas, nil)
+
+	req := httptest.NewRequest("GET", "/admin/webhooks/wh-1/entregas?limite=20", nil)
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []domain.EntregaWebhook
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response, 1)
+	assert.Equal(t, domain.EntregaFalhou, response[0].Status)
+}
+
+func TestAdmin_ReenviarEntrega_QuandoEntregaDeOutroWebhook_DeveRetornar404(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	mockService.On("ReenviarEntrega", mock.Anything, domain.WebhookID("wh-1"), domain.EntregaID("ent-9")).
+		Return(domain.EntregaWebhook{}, voting.ErrEntregaNaoEncontrada)
+
+	req := httptest.NewRequest("POST", "/admin/webhooks/wh-1/entregas/ent-9/reenviar", nil)
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdmin_ObterProjecao_QuandoBucketInformado_DeveRetornarEstimativa(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	projecao := domain.Projecao{ParedaoID: "p1", Estimativa: true, Confianca: 0.9, Participantes: []domain.ProjecaoParticipante{
+		{ParticipanteID: "a", Atual: 60, Projetada: 58, Minima: 55, Maxima: 61},
+	}}
+	mockService.On("ProjecaoFinal", mock.Anything, domain.ParedaoID("p1"), 15*time.Minute).Return(projecao, nil)
+
+	req := httptest.NewRequest("GET", "/admin/paredoes/p1/projecao?bucket=15m", nil)
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, true, response["estimativa"])
+	assert.Len(t, response["participantes"], 1)
+}
+
+func TestAdmin_ObterProjecao_QuandoBucketInvalido_DeveRetornar400(t *testing.T) {
+	mux, _ := setupAdmin(t)
+
+	req := httptest.NewRequest("GET", "/admin/paredoes/p1/projecao?bucket=7m", nil)
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAdmin_Desempatar_QuandoEscolhaValida_DeveRetornarResultado(t *testing.T) {
+	mux, mockService := setupAdmin(t)
+
+	resultado := domain.Resultado{ParedaoID: "p1", Escapou: "ana", ProximaEtapa: "p2"}
+	mockService.On("Desempatar", mock.Anything, domain.ParedaoID("p1"), domain.ParticipanteID("ana")).Return(resultado, nil)
+
+	req := httptest.NewRequest("POST", "/admin/paredoes/p1/desempate", strings.NewReader(`{"escapou":"ana"}`))
+	req.Header.Set("Authorization", "Bearer segredo")
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response domain.Resultado
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, domain.ParticipanteID("ana"), response.Escapou)
+}
@@ -5,23 +5,50 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/marcelojr/desafio-globo/internal/app/live"
 	"github.com/marcelojr/desafio-globo/internal/app/voting"
 	"github.com/marcelojr/desafio-globo/internal/domain"
 	"github.com/marcelojr/desafio-globo/internal/platform/antifraude"
 	"github.com/marcelojr/desafio-globo/internal/platform/metrics"
 )
 
+// VerificadorEleitor extrai a conta do eleitor do token Bearer enviado junto com o voto.
+type VerificadorEleitor interface {
+	Verificar(token string) (domain.EleitorID, error)
+}
+
 // API empacota handlers HTTP ligados ao serviço de votação e ao logger.
 type API struct {
-	service domain.VotingService
-	logger  *slog.Logger
+	service   domain.VotingService
+	logger    *slog.Logger
+	eleitores VerificadorEleitor
+	hub       *live.Hub
+	keepAlive time.Duration
+	ws        *ConfigWebSocket
+}
+
+// Option ajusta dependências opcionais da API.
+type Option func(*API)
+
+// ComVerificadorEleitor habilita votos autenticados; sem ele todo voto é tratado como anônimo.
+func ComVerificadorEleitor(v VerificadorEleitor) Option {
+	return func(a *API) {
+		a.eleitores = v
+	}
 }
 
-func New(service domain.VotingService, logger *slog.Logger) *API {
-	return &API{service: service, logger: logger}
+func New(service domain.VotingService, logger *slog.Logger, opts ...Option) *API {
+	a := &API{service: service, logger: logger, keepAlive: keepAlivePadrao}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 func (a *API) Register(mux *http.ServeMux) {
@@ -29,7 +56,16 @@ func (a *API) Register(mux *http.ServeMux) {
 	mux.HandleFunc("/healthz", a.handleHealthz)
 	mux.HandleFunc("/paredoes", a.listarParedoes)
 	mux.HandleFunc("/votos", a.handleVotos)
+	mux.HandleFunc("/votos/", a.handleStatusVoto)
 	mux.HandleFunc("/paredoes/", a.handleParedaoDetalhes)
+	mux.HandleFunc("/recibos/verificar", a.verificarRecibo)
+	mux.HandleFunc("/.well-known/recibos-chaves.json", a.chavesRecibo)
+	for rota, handler := range a.rotasV1() {
+		mux.HandleFunc(rota, handler)
+	}
+	if a.ws != nil {
+		mux.HandleFunc("/ws", a.handleWebSocket)
+	}
 }
 
 func (a *API) handleHealthz(w http.ResponseWriter, r *http.Request) {
@@ -52,6 +88,14 @@ func (a *API) handleParedaoDetalhes(w http.ResponseWriter, r *http.Request) {
 		a.obterParciais(w, r, id)
 	case len(partes) == 2 && partes[1] == "hora" && r.Method == http.MethodGet:
 		a.obterTotaisHora(w, r, id)
+	case len(partes) == 2 && partes[1] == "serie" && r.Method == http.MethodGet:
+		a.obterSerie(w, r, id)
+	case len(partes) == 2 && partes[1] == "viradas" && r.Method == http.MethodGet:
+		a.listarViradas(w, r, id)
+	case len(partes) == 2 && partes[1] == "stream" && r.Method == http.MethodGet:
+		a.transmitirParciais(w, r, id)
+	case len(partes) == 2 && partes[1] == "checkpoints" && r.Method == http.MethodGet:
+		a.listarCheckpoints(w, r, id)
 	default:
 		http.NotFound(w, r)
 	}
@@ -59,17 +103,39 @@ func (a *API) handleParedaoDetalhes(w http.ResponseWriter, r *http.Request) {
 
 func (a *API) handleVotos(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "metodo nao suportado", http.StatusMethodNotAllowed)
+		responderErro(w, r, a.logger, FalhaMetodoNaoSuportado)
+		return
+	}
+	a.registrarVoto(w, r, "/votos/")
+}
+
+func (a *API) handleStatusVoto(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/votos/")
+	if id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		responderErro(w, r, a.logger, FalhaMetodoNaoSuportado)
+		return
+	}
+
+	status, err := a.service.StatusVoto(r.Context(), domain.VotoID(id))
+	if err != nil {
+		if !errors.Is(err, voting.ErrVotoNaoEncontrado) {
+			a.logger.Error("erro ao consultar status do voto", "err", err, "voto", id)
+		}
+		responderErro(w, r, a.logger, err)
 		return
 	}
-	a.registrarVoto(w, r)
+	responderJSON(w, http.StatusOK, status)
 }
 
 func (a *API) listarParedoes(w http.ResponseWriter, r *http.Request) {
 	resultado, err := a.service.ListarAtivos(r.Context())
 	if err != nil {
 		a.logger.Error("erro ao listar paredoes", "err", err)
-		responderErro(w, err)
+		responderErro(w, r, a.logger, err)
 		return
 	}
 
@@ -79,94 +145,203 @@ func (a *API) listarParedoes(w http.ResponseWriter, r *http.Request) {
 type votoRequest struct {
 	ParedaoID      string `json:"paredao_id"`
 	ParticipanteID string `json:"participante_id"`
+	Modalidade     string `json:"modalidade"`
+	CaptchaToken   string `json:"captcha_token"`
 }
 
-func (a *API) registrarVoto(w http.ResponseWriter, r *http.Request) {
+type votoAceitoResponse struct {
+	Status string         `json:"status"`
+	VotoID domain.VotoID  `json:"voto_id"`
+	Recibo *domain.Recibo `json:"recibo,omitempty"`
+}
+
+// registrarVoto atende o POST das duas versões da API; o Location aponta para a rota de status de cada uma.
+func (a *API) registrarVoto(w http.ResponseWriter, r *http.Request, rotaStatus string) {
 	var req votoRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := decodificarJSON(r, &req); err != nil {
 		metrics.ObserveVoteRequest("invalid_payload")
 		a.logger.Warn("payload invalido ao registrar voto", "err", err)
-		http.Error(w, "payload invalido", http.StatusBadRequest)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	eleitor, err := a.identificarEleitor(r)
+	if err != nil {
+		metrics.ObserveVoteRequest("unauthorized")
+		a.logger.Warn("token de eleitor recusado", "err", err, "paredao", req.ParedaoID)
+		responderErro(w, r, a.logger, err)
 		return
 	}
 
 	voto := domain.Voto{
 		ParedaoID:      domain.ParedaoID(req.ParedaoID),
 		ParticipanteID: domain.ParticipanteID(req.ParticipanteID),
-		OrigemIP:       r.Header.Get("X-Forwarded-For"),
+		OrigemIP:       origemIP(r),
 		UserAgent:      r.UserAgent(),
+		EleitorID:      eleitor,
+		Modalidade:     domain.Modalidade(req.Modalidade),
+		CaptchaToken:   req.CaptchaToken,
 	}
 
-	if voto.OrigemIP == "" {
-		voto.OrigemIP = strings.Split(r.RemoteAddr, ":")[0]
-	}
-
-	if err := a.service.RegistrarVoto(r.Context(), voto); err != nil {
+	resultado, err := a.service.RegistrarVoto(r.Context(), voto)
+	escreverCabecalhosLimite(w, resultado.Limite, err)
+	if err != nil {
 		status := statusFromError(err)
 		metrics.ObserveVoteRequest(status)
 		a.logger.Warn("falha ao registrar voto", "err", err, "paredao", req.ParedaoID, "participante", req.ParticipanteID, "status", status)
-		responderErro(w, err)
+		responderErro(w, r, a.logger, err)
 		return
 	}
 
 	metrics.ObserveVoteRequest("accepted")
-	responderJSON(w, http.StatusAccepted, map[string]string{"status": "recebido"})
+	w.Header().Set("Location", rotaStatus+string(resultado.VotoID))
+	responderJSON(w, http.StatusAccepted, votoAceitoResponse{Status: "recebido", VotoID: resultado.VotoID, Recibo: resultado.Recibo})
 	a.logger.Info("voto recebido", "paredao", req.ParedaoID, "participante", req.ParticipanteID)
 }
 
+// origemIP usa o X-Forwarded-For do balanceador e, na falta dele, o endereço da conexão.
+func origemIP(r *http.Request) string {
+	if ip := r.Header.Get("X-Forwarded-For"); ip != "" {
+		return ip
+	}
+	return strings.Split(r.RemoteAddr, ":")[0]
+}
+
+// identificarEleitor devolve a conta do token Bearer; sem token (ou sem verificador configurado) o voto é anônimo.
+func (a *API) identificarEleitor(r *http.Request) (domain.EleitorID, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || a.eleitores == nil {
+		return "", nil
+	}
+	return a.eleitores.Verificar(strings.TrimSpace(token))
+}
+
 func (a *API) obterParciais(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
-	parciais, err := a.service.Parciais(r.Context(), id)
+	parciais, publicacao, err := a.service.ParciaisPublicas(r.Context(), id)
 	if err != nil {
 		a.logger.Error("erro ao obter parciais", "err", err, "paredao", id)
-		responderErro(w, err)
+		responderErro(w, r, a.logger, err)
 		return
 	}
 
-	responderJSON(w, http.StatusOK, parciais)
+	a.responderPublicacao(w, r, publicacao, parciais)
 }
 
 func (a *API) obterTotaisHora(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
-	totais, err := a.service.TotaisPorHora(r.Context(), id)
+	totais, publicacao, err := a.service.TotaisPorHoraPublicos(r.Context(), id)
 	if err != nil {
 		a.logger.Error("erro ao obter totais por hora", "err", err, "paredao", id)
-		responderErro(w, err)
+		responderErro(w, r, a.logger, err)
 		return
 	}
 
-	responderJSON(w, http.StatusOK, totais)
+	a.responderPublicacao(w, r, publicacao, totais)
 }
 
-func responderJSON(w http.ResponseWriter, status int, body any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(body)
+// obterSerie devolve a série de votos de cada participante no intervalo de ?bucket= (1m, 5m, 15m ou 1h),
+// com a participação acumulada ao fim de cada intervalo.
+func (a *API) obterSerie(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	intervalo, err := voting.ParseIntervaloSerie(r.URL.Query().Get("bucket"))
+	if err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	series, publicacao, err := a.service.SeriePublica(r.Context(), id, intervalo)
+	if err != nil {
+		a.logger.Error("erro ao obter serie", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	a.responderPublicacao(w, r, publicacao, series)
 }
 
-func responderErro(w http.ResponseWriter, err error) {
-	status := http.StatusInternalServerError
+// listarViradas é o feed das trocas de liderança do paredão, da primeira para a mais recente.
+func (a *API) listarViradas(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	viradas, publicacao, err := a.service.ViradasPublicas(r.Context(), id)
+	if err != nil {
+		a.logger.Error("erro ao listar viradas", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	if viradas == nil {
+		viradas = []domain.Virada{}
+	}
+	a.responderPublicacao(w, r, publicacao, viradas)
+}
 
-	switch {
-	case errors.Is(err, voting.ErrParedaoInvalido):
-		status = http.StatusBadRequest
-	case errors.Is(err, voting.ErrParticipanteDesconhecido):
-		status = http.StatusBadRequest
-	case errors.Is(err, voting.ErrPeriodoEncerrado):
-		status = http.StatusConflict
-	case errors.Is(err, voting.ErrParedaoNaoEncontrado):
-		status = http.StatusNotFound
-	case errors.Is(err, antifraude.ErrRateLimitExceeded):
-		status = http.StatusTooManyRequests
+// listarCheckpoints publica as raízes assinadas da cadeia de votos; qualquer um pode guardá-las para
+// confrontar depois com a exportação de auditoria.
+func (a *API) listarCheckpoints(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	checkpoints, err := a.service.Checkpoints(r.Context(), id)
+	if err != nil {
+		a.logger.Error("erro ao listar checkpoints", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	if checkpoints == nil {
+		checkpoints = []domain.Checkpoint{}
+	}
+	responderJSON(w, http.StatusOK, checkpoints)
+}
+
+// responderPublicacao troca os números por um 403 explicativo quando as parciais estão ocultas e, no modo
+// atrasado, informa no cabeçalho X-Parciais-Ate até quando os votos foram contados.
+func (a *API) responderPublicacao(w http.ResponseWriter, r *http.Request, publicacao domain.Publicacao, body any) {
+	if publicacao.Oculta {
+		responderProblema(w, http.StatusForbidden, struct {
+			problema
+			Visibilidade domain.ModoVisibilidade `json:"visibilidade"`
+			LiberaEm     time.Time               `json:"libera_em"`
+		}{novoProblema(w, r, a.logger, FalhaParciaisOcultas), publicacao.Modo, publicacao.LiberaEm})
+		return
+	}
+	if !publicacao.Ate.IsZero() {
+		w.Header().Set("X-Parciais-Ate", publicacao.Ate.UTC().Format(time.RFC3339))
+	}
+	responderJSON(w, http.StatusOK, body)
+}
+
+// escreverCabecalhosLimite expõe a cota do antifraude nos cabeçalhos RateLimit-* e, no 429, o Retry-After.
+func escreverCabecalhosLimite(w http.ResponseWriter, decisao domain.DecisaoLimite, err error) {
+	if decisao.Limite <= 0 {
+		return
 	}
 
-	responderJSON(w, status, map[string]string{"erro": err.Error()})
+	reset := int64(math.Ceil(time.Until(decisao.Reset).Seconds()))
+	if reset < 0 {
+		reset = 0
+	}
+
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(decisao.Limite))
+	h.Set("RateLimit-Remaining", strconv.Itoa(decisao.Restante))
+	h.Set("RateLimit-Reset", strconv.FormatInt(reset, 10))
+	if errors.Is(err, antifraude.ErrRateLimitExceeded) {
+		h.Set("Retry-After", strconv.FormatInt(reset, 10))
+	}
+}
+
+func responderJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
 }
 
 func statusFromError(err error) string {
 	switch {
 	case errors.Is(err, antifraude.ErrRateLimitExceeded):
 		return "rate_limited"
+	case errors.Is(err, antifraude.ErrCaptchaInvalido):
+		return "captcha"
 	case errors.Is(err, voting.ErrPeriodoEncerrado):
 		return "closed"
+	case errors.Is(err, voting.ErrVotoJaRegistrado):
+		return "duplicate"
+	case errors.Is(err, voting.ErrParticipanteRetirado):
+		return "withdrawn"
+	case errors.Is(err, voting.ErrEleitorObrigatorio):
+		return "unauthorized"
 	case errors.Is(err, voting.ErrParticipanteDesconhecido):
 		return "invalid"
 	case errors.Is(err, voting.ErrParedaoNaoEncontrado):
@@ -17,6 +17,7 @@ import (
 	"github.com/marcelojr/desafio-globo/internal/app/voting"
 	"github.com/marcelojr/desafio-globo/internal/domain"
 	"github.com/marcelojr/desafio-globo/internal/platform/antifraude"
+	"github.com/marcelojr/desafio-globo/internal/platform/auth"
 )
 
 // MockVotingService implementa a interface do serviço de votação para testes
@@ -24,9 +25,30 @@ type MockVotingService struct {
 	mock.Mock
 }
 
-func (m *MockVotingService) RegistrarVoto(ctx context.Context, voto domain.Voto) error {
+func (m *MockVotingService) RegistrarVoto(ctx context.Context, voto domain.Voto) (domain.ResultadoVoto, error) {
 	args := m.Called(ctx, voto)
-	return args.Error(0)
+	return args.Get(0).(domain.ResultadoVoto), args.Error(1)
+}
+
+func (m *MockVotingService) StatusVoto(ctx context.Context, id domain.VotoID) (domain.StatusVoto, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.StatusVoto), args.Error(1)
+}
+
+func (m *MockVotingService) VerificarRecibo(ctx context.Context, recibo domain.Recibo) (domain.VerificacaoRecibo, error) {
+	args := m.Called(ctx, recibo)
+	return args.Get(0).(domain.VerificacaoRecibo), args.Error(1)
+}
+
+func (m *MockVotingService) ChavesRecibo() []domain.ChavePublicaRecibo {
+	args := m.Called()
+	chaves, _ := args.Get(0).([]domain.ChavePublicaRecibo)
+	return chaves
+}
+
+func (m *MockVotingService) Checkpoints(ctx context.Context, id domain.ParedaoID) ([]domain.Checkpoint, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]domain.Checkpoint), args.Error(1)
 }
 
 func (m *MockVotingService) ListarAtivos(ctx context.Context) ([]domain.Paredao, error) {
@@ -34,6 +56,11 @@ func (m *MockVotingService) ListarAtivos(ctx context.Context) ([]domain.Paredao,
 	return args.Get(0).([]domain.Paredao), args.Error(1)
 }
 
+func (m *MockVotingService) ObterParedao(ctx context.Context, id domain.ParedaoID) (domain.Paredao, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(domain.Paredao), args.Error(1)
+}
+
 func (m *MockVotingService) Parciais(ctx context.Context, id domain.ParedaoID) ([]domain.Parcial, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).([]domain.Parcial), args.Error(1)
@@ -44,6 +71,26 @@ func (m *MockVotingService) TotaisPorHora(ctx context.Context, id domain.Paredao
 	return args.Get(0).([]domain.ParcialHora), args.Error(1)
 }
 
+func (m *MockVotingService) ParciaisPublicas(ctx context.Context, id domain.ParedaoID) ([]domain.Parcial, domain.Publicacao, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]domain.Parcial), args.Get(1).(domain.Publicacao), args.Error(2)
+}
+
+func (m *MockVotingService) TotaisPorHoraPublicos(ctx context.Context, id domain.ParedaoID) ([]domain.ParcialHora, domain.Publicacao, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]domain.ParcialHora), args.Get(1).(domain.Publicacao), args.Error(2)
+}
+
+func (m *MockVotingService) SeriePublica(ctx context.Context, id domain.ParedaoID, intervalo time.Duration) ([]domain.SerieParticipante, domain.Publicacao, error) {
+	args := m.Called(ctx, id, intervalo)
+	return args.Get(0).([]domain.SerieParticipante), args.Get(1).(domain.Publicacao), args.Error(2)
+}
+
+func (m *MockVotingService) ViradasPublicas(ctx context.Context, id domain.ParedaoID) ([]domain.Virada, domain.Publicacao, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).([]domain.Virada), args.Get(1).(domain.Publicacao), args.Error(2)
+}
+
 func (m *MockVotingService) CriarParedao(ctx context.Context, paredao domain.Paredao, participantes []domain.Participante) (domain.Paredao, error) {
 	args := m.Called(ctx, paredao, participantes)
 	return args.Get(0).(domain.Paredao), args.Error(1)
@@ -103,6 +150,34 @@ func TestListarParedoes_QuandoExistemParedoesAtivos_DeveRetornarListaComSucesso(
 	assert.Equal(t, "Paredão 2", response[1].Nome)
 }
 
+func TestListarParedoes_QuandoParedaoTemAjustesAdmin_NaoDeveExporNoJSON(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	paredoes := []domain.Paredao{{
+		ID:           "01HXXXXXXXXXXXXXXXXXXXXX",
+		Nome:         "Paredão 1",
+		Pesos:        domain.PesosModalidade{Unico: 0.7, Torcida: 0.3},
+		Anomalia:     domain.LimiaresAnomalia{LimiarZ: 6, MinVotos: 500},
+		Antifraude:   domain.PoliticaAntifraude{Limite: 5, JanelaSegundos: 60},
+		Visibilidade: domain.PoliticaVisibilidade{Modo: domain.VisibilidadeAtrasada, AtrasoMinutos: 15},
+	}}
+	mockService.On("ListarAtivos", mock.Anything).Return(paredoes, nil)
+
+	req := httptest.NewRequest("GET", "/paredoes", nil)
+	w := httptest.NewRecorder()
+
+	api.listarParedoes(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response, 1)
+	for _, campo := range []string{"Pesos", "Anomalia", "Antifraude", "Visibilidade"} {
+		assert.NotContains(t, response[0], campo)
+	}
+	assert.Equal(t, "Paredão 1", response[0]["Nome"])
+}
+
 func TestListarParedoes_QuandoNaoExistemParedoes_DeveRetornarListaVazia(t *testing.T) {
 	api, mockService := setupAPI(t)
 
@@ -133,10 +208,40 @@ func TestListarParedoes_QuandoServicoFalha_DeveRetornar500(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
-	var response map[string]string
-	err := json.NewDecoder(w.Body).Decode(&response)
-	require.NoError(t, err)
-	assert.Contains(t, response, "erro")
+	assert.Equal(t, "ERRO_INTERNO", lerProblema(t, w).Codigo)
+}
+
+// === TESTES GET /votos/{id} ===
+
+func TestStatusVoto_QuandoVotoProcessado_DeveRetornarEstado(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	status := domain.StatusVoto{VotoID: "01HVOTOXXXXXXXXXXXXXXXXXXX", ParedaoID: "01HXXXXXXXXXXXXXXXXXXXXX", Estado: domain.VotoProcessado}
+	mockService.On("StatusVoto", mock.Anything, domain.VotoID("01HVOTOXXXXXXXXXXXXXXXXXXX")).Return(status, nil)
+
+	req := httptest.NewRequest("GET", "/votos/01HVOTOXXXXXXXXXXXXXXXXXXX", nil)
+	w := httptest.NewRecorder()
+
+	api.handleStatusVoto(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response domain.StatusVoto
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, domain.VotoProcessado, response.Estado)
+	assert.Equal(t, domain.ParedaoID("01HXXXXXXXXXXXXXXXXXXXXX"), response.ParedaoID)
+}
+
+func TestStatusVoto_QuandoVotoDesconhecido_DeveRetornar404(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	mockService.On("StatusVoto", mock.Anything, domain.VotoID("inexistente")).Return(domain.StatusVoto{}, voting.ErrVotoNaoEncontrado)
+
+	req := httptest.NewRequest("GET", "/votos/inexistente", nil)
+	w := httptest.NewRecorder()
+
+	api.handleStatusVoto(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
 // === TESTES POST /votos ===
@@ -148,20 +253,43 @@ func TestRegistrarVoto_QuandoVotoValido_DeveRetornar202Accepted(t *testing.T) {
 	mockService.On("RegistrarVoto", mock.Anything, mock.MatchedBy(func(voto domain.Voto) bool {
 		return string(voto.ParedaoID) == "01HXXXXXXXXXXXXXXXXXXXXX" &&
 			string(voto.ParticipanteID) == "01HXXXXXXXXXXXXXXXXXXXXY"
-	})).Return(nil)
+	})).Return(domain.ResultadoVoto{VotoID: "01HVOTOXXXXXXXXXXXXXXXXXXX"}, nil)
 
 	req := httptest.NewRequest("POST", "/votos", bytes.NewReader([]byte(payload)))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	api.registrarVoto(w, req)
+	api.registrarVoto(w, req, "/votos/")
 
 	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/votos/01HVOTOXXXXXXXXXXXXXXXXXXX", w.Header().Get("Location"))
 
 	var response map[string]string
 	err := json.NewDecoder(w.Body).Decode(&response)
 	require.NoError(t, err)
 	assert.Equal(t, "recebido", response["status"])
+	assert.Equal(t, "01HVOTOXXXXXXXXXXXXXXXXXXX", response["voto_id"])
+}
+
+func TestRegistrarVoto_QuandoLimiteAtivo_DeveExporCotaRestante(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	payload := `{"paredao_id":"01HXXXXXXXXXXXXXXXXXXXXX","participante_id":"01HXXXXXXXXXXXXXXXXXXXXY"}`
+	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(domain.ResultadoVoto{
+		Limite: domain.DecisaoLimite{Limite: 5, Restante: 3, Reset: time.Now().Add(45 * time.Second)},
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/votos", bytes.NewReader([]byte(payload)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	api.registrarVoto(w, req, "/votos/")
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "5", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "3", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "45", w.Header().Get("RateLimit-Reset"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
 }
 
 func TestRegistrarVoto_QuandoPayloadInvalido_DeveRetornar400BadRequest(t *testing.T) {
@@ -173,90 +301,136 @@ func TestRegistrarVoto_QuandoPayloadInvalido_DeveRetornar400BadRequest(t *testin
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	api.registrarVoto(w, req)
+	api.registrarVoto(w, req, "/votos/")
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
-	assert.Equal(t, "payload invalido\n", w.Body.String())
+	assert.Equal(t, "PAYLOAD_INVALIDO", lerProblema(t, w).Codigo)
 }
 
 func TestRegistrarVoto_QuandoParedaoInvalido_DeveRetornar400BadRequest(t *testing.T) {
 	api, mockService := setupAPI(t)
 
 	payload := `{"paredao_id":"invalid","participante_id":"01HXXXXXXXXXXXXXXXXXXXXY"}`
-	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(voting.ErrParedaoInvalido)
+	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(domain.ResultadoVoto{}, voting.ErrParedaoInvalido)
 
 	req := httptest.NewRequest("POST", "/votos", bytes.NewReader([]byte(payload)))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	api.registrarVoto(w, req)
+	api.registrarVoto(w, req, "/votos/")
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 
-	var response map[string]string
-	err := json.NewDecoder(w.Body).Decode(&response)
-	require.NoError(t, err)
-	assert.Contains(t, response, "erro")
+	assert.Equal(t, "PAREDAO_INVALIDO", lerProblema(t, w).Codigo)
 }
 
 func TestRegistrarVoto_QuandoParticipanteDesconhecido_DeveRetornar400BadRequest(t *testing.T) {
 	api, mockService := setupAPI(t)
 
 	payload := `{"paredao_id":"01HXXXXXXXXXXXXXXXXXXXXX","participante_id":"unknown"}`
-	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(voting.ErrParticipanteDesconhecido)
+	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(domain.ResultadoVoto{}, voting.ErrParticipanteDesconhecido)
 
 	req := httptest.NewRequest("POST", "/votos", bytes.NewReader([]byte(payload)))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	api.registrarVoto(w, req)
+	api.registrarVoto(w, req, "/votos/")
 
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 
-	var response map[string]string
-	err := json.NewDecoder(w.Body).Decode(&response)
-	require.NoError(t, err)
-	assert.Contains(t, response, "erro")
+	assert.Equal(t, "PARTICIPANTE_DESCONHECIDO", lerProblema(t, w).Codigo)
 }
 
 func TestRegistrarVoto_QuandoParedaoEncerrado_DeveRetornar409Conflict(t *testing.T) {
 	api, mockService := setupAPI(t)
 
 	payload := `{"paredao_id":"01HXXXXXXXXXXXXXXXXXXXXX","participante_id":"01HXXXXXXXXXXXXXXXXXXXXY"}`
-	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(voting.ErrPeriodoEncerrado)
+	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(domain.ResultadoVoto{}, voting.ErrPeriodoEncerrado)
 
 	req := httptest.NewRequest("POST", "/votos", bytes.NewReader([]byte(payload)))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	api.registrarVoto(w, req)
+	api.registrarVoto(w, req, "/votos/")
 
 	assert.Equal(t, http.StatusConflict, w.Code)
 
-	var response map[string]string
-	err := json.NewDecoder(w.Body).Decode(&response)
-	require.NoError(t, err)
-	assert.Contains(t, response, "erro")
+	assert.Equal(t, "PAREDAO_ENCERRADO", lerProblema(t, w).Codigo)
 }
 
 func TestRegistrarVoto_QuandoRateLimitExcedido_DeveRetornar429TooManyRequests(t *testing.T) {
 	api, mockService := setupAPI(t)
 
 	payload := `{"paredao_id":"01HXXXXXXXXXXXXXXXXXXXXX","participante_id":"01HXXXXXXXXXXXXXXXXXXXXY"}`
-	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(antifraude.ErrRateLimitExceeded)
+	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(domain.ResultadoVoto{
+		Limite: domain.DecisaoLimite{Limite: 5, Restante: 0, Reset: time.Now().Add(30 * time.Second)},
+	}, antifraude.ErrRateLimitExceeded)
 
 	req := httptest.NewRequest("POST", "/votos", bytes.NewReader([]byte(payload)))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	api.registrarVoto(w, req)
+	api.registrarVoto(w, req, "/votos/")
 
 	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "5", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
 
-	var response map[string]string
-	err := json.NewDecoder(w.Body).Decode(&response)
+	assert.Equal(t, "RATE_LIMITED", lerProblema(t, w).Codigo)
+}
+
+func TestRegistrarVoto_QuandoTokenDeEleitorValido_DeveRepassarConta(t *testing.T) {
+	mockService := new(MockVotingService)
+	tokens := auth.NewTokens("segredo")
+	api := New(mockService, slog.New(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{})), ComVerificadorEleitor(tokens))
+	t.Cleanup(func() { mockService.AssertExpectations(t) })
+
+	token, err := tokens.Emitir("conta-1", time.Hour)
 	require.NoError(t, err)
-	assert.Contains(t, response, "erro")
+
+	payload := `{"paredao_id":"01HXXXXXXXXXXXXXXXXXXXXX","participante_id":"01HXXXXXXXXXXXXXXXXXXXXY","modalidade":"unico"}`
+	mockService.On("RegistrarVoto", mock.Anything, mock.MatchedBy(func(voto domain.Voto) bool {
+		return voto.EleitorID == "conta-1" && voto.Modalidade == domain.ModalidadeUnico
+	})).Return(domain.ResultadoVoto{}, nil)
+
+	req := httptest.NewRequest("POST", "/votos", bytes.NewReader([]byte(payload)))
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	api.registrarVoto(w, req, "/votos/")
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+}
+
+func TestRegistrarVoto_QuandoTokenDeEleitorInvalido_DeveRetornar401(t *testing.T) {
+	mockService := new(MockVotingService)
+	api := New(mockService, slog.New(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{})), ComVerificadorEleitor(auth.NewTokens("segredo")))
+
+	payload := `{"paredao_id":"01HXXXXXXXXXXXXXXXXXXXXX","participante_id":"01HXXXXXXXXXXXXXXXXXXXXY"}`
+	req := httptest.NewRequest("POST", "/votos", bytes.NewReader([]byte(payload)))
+	req.Header.Set("Authorization", "Bearer forjado.token")
+	w := httptest.NewRecorder()
+
+	api.registrarVoto(w, req, "/votos/")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertNotCalled(t, "RegistrarVoto", mock.Anything, mock.Anything)
+}
+
+func TestRegistrarVoto_QuandoVotoUnicoRepetido_DeveRetornar409Conflict(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	payload := `{"paredao_id":"01HXXXXXXXXXXXXXXXXXXXXX","participante_id":"01HXXXXXXXXXXXXXXXXXXXXY"}`
+	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(domain.ResultadoVoto{}, voting.ErrVotoJaRegistrado)
+
+	req := httptest.NewRequest("POST", "/votos", bytes.NewReader([]byte(payload)))
+	w := httptest.NewRecorder()
+
+	api.registrarVoto(w, req, "/votos/")
+
+	assert.Equal(t, http.StatusConflict, w.Code)
 }
 
 func TestRegistrarVoto_QuandoMetodoNaoSuportado_DeveRetornar405(t *testing.T) {
@@ -268,7 +442,7 @@ func TestRegistrarVoto_QuandoMetodoNaoSuportado_DeveRetornar405(t *testing.T) {
 	api.handleVotos(w, req)
 
 	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
-	assert.Equal(t, "metodo nao suportado\n", w.Body.String())
+	assert.Equal(t, "METODO_NAO_SUPORTADO", lerProblema(t, w).Codigo)
 }
 
 func TestRegistrarVoto_QuandoXForwardedForPresente_DeveUsarComoOrigemIP(t *testing.T) {
@@ -277,14 +451,14 @@ func TestRegistrarVoto_QuandoXForwardedForPresente_DeveUsarComoOrigemIP(t *testi
 	payload := `{"paredao_id":"01HXXXXXXXXXXXXXXXXXXXXX","participante_id":"01HXXXXXXXXXXXXXXXXXXXXY"}`
 	mockService.On("RegistrarVoto", mock.Anything, mock.MatchedBy(func(voto domain.Voto) bool {
 		return voto.OrigemIP == "192.168.1.100"
-	})).Return(nil)
+	})).Return(domain.ResultadoVoto{}, nil)
 
 	req := httptest.NewRequest("POST", "/votos", bytes.NewReader([]byte(payload)))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-Forwarded-For", "192.168.1.100")
 	w := httptest.NewRecorder()
 
-	api.registrarVoto(w, req)
+	api.registrarVoto(w, req, "/votos/")
 
 	assert.Equal(t, http.StatusAccepted, w.Code)
 }
@@ -295,14 +469,14 @@ func TestRegistrarVoto_QuandoXForwardedForAusente_DeveUsarRemoteAddr(t *testing.
 	payload := `{"paredao_id":"01HXXXXXXXXXXXXXXXXXXXXX","participante_id":"01HXXXXXXXXXXXXXXXXXXXXY"}`
 	mockService.On("RegistrarVoto", mock.Anything, mock.MatchedBy(func(voto domain.Voto) bool {
 		return voto.OrigemIP == "127.0.0.1"
-	})).Return(nil)
+	})).Return(domain.ResultadoVoto{}, nil)
 
 	req := httptest.NewRequest("POST", "/votos", bytes.NewReader([]byte(payload)))
 	req.Header.Set("Content-Type", "application/json")
 	req.RemoteAddr = "127.0.0.1:12345"
 	w := httptest.NewRecorder()
 
-	api.registrarVoto(w, req)
+	api.registrarVoto(w, req, "/votos/")
 
 	assert.Equal(t, http.StatusAccepted, w.Code)
 }
@@ -318,7 +492,7 @@ func TestObterParciais_QuandoParedaoExiste_DeveRetornarParciais(t *testing.T) {
 		{ParedaoID: paredaoID, ParticipanteID: "01HXXXXXXXXXXXXXXXXXXXXZ", Total: 100, Percentual: 50.0},
 	}
 
-	mockService.On("Parciais", mock.Anything, paredaoID).Return(parciais, nil)
+	mockService.On("ParciaisPublicas", mock.Anything, paredaoID).Return(parciais, domain.Publicacao{Modo: domain.VisibilidadePublica}, nil)
 
 	req := httptest.NewRequest("GET", "/paredoes/01HXXXXXXXXXXXXXXXXXXXXX", nil)
 	w := httptest.NewRecorder()
@@ -339,7 +513,7 @@ func TestObterParciais_QuandoParedaoNaoEncontrado_DeveRetornar404(t *testing.T)
 	api, mockService := setupAPI(t)
 
 	paredaoID := domain.ParedaoID("01HXXXXXXXXXXXXXXXXXXXXX")
-	mockService.On("Parciais", mock.Anything, paredaoID).Return([]domain.Parcial(nil), voting.ErrParedaoNaoEncontrado)
+	mockService.On("ParciaisPublicas", mock.Anything, paredaoID).Return([]domain.Parcial(nil), domain.Publicacao{}, voting.ErrParedaoNaoEncontrado)
 
 	req := httptest.NewRequest("GET", "/paredoes/01HXXXXXXXXXXXXXXXXXXXXX", nil)
 	w := httptest.NewRecorder()
@@ -348,10 +522,46 @@ func TestObterParciais_QuandoParedaoNaoEncontrado_DeveRetornar404(t *testing.T)
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
 
-	var response map[string]string
-	err := json.NewDecoder(w.Body).Decode(&response)
-	require.NoError(t, err)
-	assert.Contains(t, response, "erro")
+	assert.Equal(t, "PAREDAO_NAO_ENCONTRADO", lerProblema(t, w).Codigo)
+}
+
+func TestObterParciais_QuandoOcultas_DeveRetornar403SemNumeros(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	paredaoID := domain.ParedaoID("01HXXXXXXXXXXXXXXXXXXXXX")
+	fim := time.Date(2024, 1, 2, 22, 0, 0, 0, time.UTC)
+	mockService.On("ParciaisPublicas", mock.Anything, paredaoID).
+		Return([]domain.Parcial(nil), domain.Publicacao{Modo: domain.VisibilidadeOculta, Oculta: true, LiberaEm: fim}, nil)
+
+	req := httptest.NewRequest("GET", "/paredoes/01HXXXXXXXXXXXXXXXXXXXXX", nil)
+	w := httptest.NewRecorder()
+
+	api.handleParedaoDetalhes(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var response map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "PARCIAIS_OCULTAS", response["codigo"])
+	assert.Equal(t, "oculta", response["visibilidade"])
+	assert.Equal(t, "2024-01-02T22:00:00Z", response["libera_em"])
+}
+
+func TestObterParciais_QuandoAtrasadas_DeveInformarCorte(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	paredaoID := domain.ParedaoID("01HXXXXXXXXXXXXXXXXXXXXX")
+	ate := time.Date(2024, 1, 1, 20, 30, 0, 0, time.UTC)
+	mockService.On("ParciaisPublicas", mock.Anything, paredaoID).
+		Return([]domain.Parcial{{ParedaoID: paredaoID, Total: 10}}, domain.Publicacao{Modo: domain.VisibilidadeAtrasada, Ate: ate}, nil)
+
+	req := httptest.NewRequest("GET", "/paredoes/01HXXXXXXXXXXXXXXXXXXXXX", nil)
+	w := httptest.NewRecorder()
+
+	api.handleParedaoDetalhes(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2024-01-01T20:30:00Z", w.Header().Get("X-Parciais-Ate"))
 }
 
 func TestObterParciais_QuandoIDVazio_DeveRetornar404(t *testing.T) {
@@ -376,7 +586,7 @@ func TestObterTotaisHora_QuandoParedaoExiste_DeveRetornarTotaisHora(t *testing.T
 		{ParedaoID: paredaoID, Hora: time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC), Total: 75},
 	}
 
-	mockService.On("TotaisPorHora", mock.Anything, paredaoID).Return(totais, nil)
+	mockService.On("TotaisPorHoraPublicos", mock.Anything, paredaoID).Return(totais, domain.Publicacao{Modo: domain.VisibilidadePublica}, nil)
 
 	req := httptest.NewRequest("GET", "/paredoes/01HXXXXXXXXXXXXXXXXXXXXX/hora", nil)
 	w := httptest.NewRecorder()
@@ -393,11 +603,87 @@ func TestObterTotaisHora_QuandoParedaoExiste_DeveRetornarTotaisHora(t *testing.T
 	assert.Equal(t, int64(75), response[1].Total)
 }
 
+func TestObterSerie_QuandoBucketInformado_DeveRetornarSerie(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	paredaoID := domain.ParedaoID("01HXXXXXXXXXXXXXXXXXXXXX")
+	inicio := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	series := []domain.SerieParticipante{
+		{ParedaoID: paredaoID, ParticipanteID: "p1", Pontos: []domain.PontoSerie{{Inicio: inicio, Votos: 3, Acumulado: 3, Participacao: 75}}},
+		{ParedaoID: paredaoID, ParticipanteID: "p2", Pontos: []domain.PontoSerie{{Inicio: inicio, Votos: 1, Acumulado: 1, Participacao: 25}}},
+	}
+	mockService.On("SeriePublica", mock.Anything, paredaoID, 15*time.Minute).Return(series, domain.Publicacao{Modo: domain.VisibilidadePublica}, nil)
+
+	req := httptest.NewRequest("GET", "/paredoes/01HXXXXXXXXXXXXXXXXXXXXX/serie?bucket=15m", nil)
+	w := httptest.NewRecorder()
+
+	api.handleParedaoDetalhes(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []domain.SerieParticipante
+	err := json.NewDecoder(w.Body).Decode(&response)
+	require.NoError(t, err)
+	require.Len(t, response, 2)
+	assert.Equal(t, 75.0, response[0].Pontos[0].Participacao)
+}
+
+func TestObterSerie_QuandoBucketNaoSuportado_DeveRetornar400(t *testing.T) {
+	api, _ := setupAPI(t)
+
+	req := httptest.NewRequest("GET", "/paredoes/01HXXXXXXXXXXXXXXXXXXXXX/serie?bucket=2m", nil)
+	w := httptest.NewRecorder()
+
+	api.handleParedaoDetalhes(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestListarViradas_QuandoParciaisOcultas_DeveRetornar403(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	paredaoID := domain.ParedaoID("01HXXXXXXXXXXXXXXXXXXXXX")
+	mockService.On("ViradasPublicas", mock.Anything, paredaoID).
+		Return([]domain.Virada(nil), domain.Publicacao{Modo: domain.VisibilidadeOculta, Oculta: true}, nil)
+
+	req := httptest.NewRequest("GET", "/paredoes/01HXXXXXXXXXXXXXXXXXXXXX/viradas", nil)
+	w := httptest.NewRecorder()
+
+	api.handleParedaoDetalhes(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestListarViradas_QuandoExistem_DeveRetornarFeed(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	paredaoID := domain.ParedaoID("01HXXXXXXXXXXXXXXXXXXXXX")
+	viradas := []domain.Virada{
+		{ParedaoID: paredaoID, Ordem: 1, NovoLider: "p1", Margem: 4, TotalVotos: 120},
+		{ParedaoID: paredaoID, Ordem: 2, LiderAnterior: "p1", NovoLider: "p2", Margem: 0.3, TotalVotos: 900},
+	}
+	mockService.On("ViradasPublicas", mock.Anything, paredaoID).Return(viradas, domain.Publicacao{Modo: domain.VisibilidadePublica}, nil)
+
+	req := httptest.NewRequest("GET", "/paredoes/01HXXXXXXXXXXXXXXXXXXXXX/viradas", nil)
+	w := httptest.NewRecorder()
+
+	api.handleParedaoDetalhes(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response []map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response, 2)
+	assert.NotContains(t, response[0], "lider_anterior")
+	assert.Equal(t, "p1", response[1]["lider_anterior"])
+	assert.Equal(t, "p2", response[1]["novo_lider"])
+}
+
 func TestObterTotaisHora_QuandoParedaoNaoEncontrado_DeveRetornar404(t *testing.T) {
 	api, mockService := setupAPI(t)
 
 	paredaoID := domain.ParedaoID("01HXXXXXXXXXXXXXXXXXXXXX")
-	mockService.On("TotaisPorHora", mock.Anything, paredaoID).Return([]domain.ParcialHora(nil), voting.ErrParedaoNaoEncontrado)
+	mockService.On("TotaisPorHoraPublicos", mock.Anything, paredaoID).Return([]domain.ParcialHora(nil), domain.Publicacao{}, voting.ErrParedaoNaoEncontrado)
 
 	req := httptest.NewRequest("GET", "/paredoes/01HXXXXXXXXXXXXXXXXXXXXX/hora", nil)
 	w := httptest.NewRecorder()
@@ -406,10 +692,7 @@ func TestObterTotaisHora_QuandoParedaoNaoEncontrado_DeveRetornar404(t *testing.T
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
 
-	var response map[string]string
-	err := json.NewDecoder(w.Body).Decode(&response)
-	require.NoError(t, err)
-	assert.Contains(t, response, "erro")
+	assert.Equal(t, "PAREDAO_NAO_ENCONTRADO", lerProblema(t, w).Codigo)
 }
 
 // === TESTES ROTAS NÃO ENCONTRADAS ===
@@ -424,3 +707,21 @@ func TestHandleParedaoDetalhes_QuandoRotaInvalida_DeveRetornar404(t *testing.T)
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
+
+func TestListarCheckpoints_QuandoParedaoExiste_DeveRetornarRaizesAssinadas(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	checkpoints := []domain.Checkpoint{{ParedaoID: "par-1", Tamanho: 42, HashCadeia: "aa", RaizMerkle: "bb", ChaveID: "k1", Assinatura: "cc"}}
+	mockService.On("Checkpoints", mock.Anything, domain.ParedaoID("par-1")).Return(checkpoints, nil)
+
+	req := httptest.NewRequest("GET", "/paredoes/par-1/checkpoints", nil)
+	w := httptest.NewRecorder()
+
+	api.handleParedaoDetalhes(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response []domain.Checkpoint
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response, 1)
+	assert.Equal(t, int64(42), response[0].Tamanho)
+}
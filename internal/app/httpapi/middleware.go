@@ -0,0 +1,244 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/platform/ids"
+	"github.com/marcelojr/desafio-globo/internal/platform/metrics"
+	"github.com/marcelojr/desafio-globo/internal/platform/requisicao"
+)
+
+// maxCorpoPadrao vale quando ConfigMiddleware não informa o limite do corpo.
+const maxCorpoPadrao = 1 << 20
+
+// idRecebidoValido aceita o X-Request-Id de um balanceador à frente; o resto é trocado por um ID novo.
+var idRecebidoValido = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// rotasSilenciosas vão para o log de acesso só em nível debug: sondas e coleta de métricas batem a cada poucos segundos.
+var rotasSilenciosas = map[string]bool{"/healthz": true, "/readyz": true, "/metrics": true}
+
+// metodosConhecidos limitam o rótulo de método da métrica: o cliente manda o token que quiser, e cada
+// um viraria uma série nova.
+var metodosConhecidos = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+// metodoMetrica devolve o método para o rótulo da métrica, com os desconhecidos agrupados em OTHER.
+func metodoMetrica(metodo string) string {
+	if metodosConhecidos[metodo] {
+		return metodo
+	}
+	return "OTHER"
+}
+
+// rotasLegadas detalha as rotas de subárvore (padrão terminado em /), que o mux devolveria todas com o mesmo
+// rótulo. A lista acompanha os switches de handleParedaoDetalhes, Admin.handleParedao, handleWebhook e
+// handleStatusVoto; caminho fora dela fica com o padrão da subárvore, então o cliente não cria séries novas.
+var rotasLegadas = map[string][]string{
+	"/paredoes/": {
+		"/paredoes/{id}", "/paredoes/{id}/hora", "/paredoes/{id}/serie", "/paredoes/{id}/viradas",
+		"/paredoes/{id}/stream", "/paredoes/{id}/checkpoints",
+	},
+	"/admin/paredoes/": {
+		"/admin/paredoes/{id}/antifraude", "/admin/paredoes/{id}/visibilidade", "/admin/paredoes/{id}/anomalia",
+		"/admin/paredoes/{id}/votacao", "/admin/paredoes/{id}/finalizar", "/admin/paredoes/{id}/desempate",
+		"/admin/paredoes/{id}/auditoria", "/admin/paredoes/{id}/pacote-auditoria", "/admin/paredoes/{id}/projecao",
+		"/admin/paredoes/{id}/participantes/{participante}/retirada",
+	},
+	"/admin/webhooks/": {
+		"/admin/webhooks/{id}", "/admin/webhooks/{id}/entregas", "/admin/webhooks/{id}/entregas/{entrega}/reenviar",
+	},
+	"/votos/": {"/votos/{id}"},
+}
+
+// rotasStreaming ficam abertas enquanto o cliente quiser (ou enquanto o export é gerado): a duração delas
+// distorceria o histograma de latência e vai para uma métrica própria.
+var rotasStreaming = map[string]bool{
+	"/paredoes/{id}/stream":                 true,
+	"/ws":                                   true,
+	"/admin/paredoes/{id}/auditoria":        true,
+	"/admin/paredoes/{id}/pacote-auditoria": true,
+}
+
+// rotaMetrica troca o padrão de subárvore pela rota legada que casa com o caminho, com os IDs no lugar.
+func rotaMetrica(padrao, caminho string) string {
+	segmentos := strings.Split(caminho, "/")
+	for _, rota := range rotasLegadas[padrao] {
+		if casaRota(strings.Split(rota, "/"), segmentos) {
+			return rota
+		}
+	}
+	return padrao
+}
+
+func casaRota(rota, caminho []string) bool {
+	if len(rota) != len(caminho) {
+		return false
+	}
+	for i, segmento := range rota {
+		if strings.HasPrefix(segmento, "{") {
+			if caminho[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segmento != caminho[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ConfigMiddleware limita o corpo das requisições; os prazos de leitura e escrita ficam no http.Server.
+type ConfigMiddleware struct {
+	MaxCorpo int64
+}
+
+// Middleware envolve o mux com, de fora para dentro: ID da requisição, log de acesso e latência por rota,
+// recuperação de panics e limite do corpo. A rota das métricas é o padrão registrado no mux (ou a rota
+// legada de rotasLegadas), não o caminho: /paredoes/{id} conta como uma rota só.
+func Middleware(mux *http.ServeMux, logger *slog.Logger, cfg ConfigMiddleware) http.Handler {
+	if cfg.MaxCorpo <= 0 {
+		cfg.MaxCorpo = maxCorpoPadrao
+	}
+	handler := recuperar(mux, logger)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inicio := time.Now()
+		id := r.Header.Get(requisicao.Cabecalho)
+		if !idRecebidoValido.MatchString(id) {
+			id = ids.DefaultGenerator().New()
+		}
+		w.Header().Set(requisicao.Cabecalho, id)
+		r = r.WithContext(requisicao.ComID(r.Context(), id))
+
+		_, rota := mux.Handler(r)
+		if rota == "" {
+			rota = "desconhecida"
+		}
+		rota = rotaMetrica(rota, r.URL.Path)
+		resposta := &respostaRegistrada{ResponseWriter: w}
+		defer func() {
+			status := resposta.status
+			if status == 0 && r.Header.Get("Upgrade") != "" {
+				// A conexão foi sequestrada pelo websocket, que responde 101 por fora do ResponseWriter.
+				status = http.StatusSwitchingProtocols
+			} else if status == 0 {
+				status = http.StatusOK
+			}
+			duracao := time.Since(inicio)
+			if rotasStreaming[rota] {
+				metrics.ObserveHTTPStream(rota, strconv.Itoa(status), duracao.Seconds())
+			} else {
+				metrics.ObserveHTTPRequest(rota, metodoMetrica(r.Method), strconv.Itoa(status), duracao.Seconds())
+			}
+
+			nivel := slog.LevelInfo
+			if rotasSilenciosas[r.URL.Path] {
+				nivel = slog.LevelDebug
+			}
+			logger.Log(r.Context(), nivel, "requisicao",
+				"request_id", id,
+				"metodo", r.Method,
+				"rota", rota,
+				"caminho", r.URL.Path,
+				"status", status,
+				"bytes", resposta.bytes,
+				"duracao_ms", duracao.Milliseconds(),
+				"origem", origemIP(r),
+			)
+		}()
+
+		r.Body = http.MaxBytesReader(resposta, r.Body, cfg.MaxCorpo)
+		handler.ServeHTTP(resposta, r)
+	})
+}
+
+// recuperar transforma um panic do handler em 500 para o cliente e pilha no log, sem derrubar o processo.
+func recuperar(next http.Handler, logger *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				// Sinal do próprio net/http para abortar a resposta; precisa seguir adiante.
+				panic(p)
+			}
+			if resposta, ok := w.(*respostaRegistrada); ok && resposta.status != 0 {
+				// Cabeçalho já enviado: não há como trocar a resposta, só registrar e interromper.
+				logger.Error("panic no handler", "panic", p, "request_id", requisicao.ID(r.Context()), "pilha", string(debug.Stack()))
+				panic(http.ErrAbortHandler)
+			}
+			// responderErro registra a causa, com a pilha, junto do request_id.
+			responderErro(w, r, logger, fmt.Errorf("panic: %v\n%s", p, debug.Stack()))
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// respostaRegistrada guarda status e bytes para o log de acesso. Unwrap mantém Flush, Hijack e os prazos
+// do http.ResponseController funcionando através dela.
+type respostaRegistrada struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *respostaRegistrada) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *respostaRegistrada) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += int64(n)
+	return n, err
+}
+
+func (r *respostaRegistrada) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// decodificarJSON lê um único objeto JSON do corpo e recusa campos desconhecidos, que costumam ser erro de
+// digitação do cliente silenciosamente ignorado. Os erros já vêm classificados para responderErro.
+func decodificarJSON(r *http.Request, destino any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	err := dec.Decode(destino)
+	if err == nil && dec.Decode(&struct{}{}) != io.EOF {
+		err = errors.New("conteudo apos o objeto JSON")
+	}
+	if err == nil {
+		return nil
+	}
+	var grande *http.MaxBytesError
+	if errors.As(err, &grande) {
+		return fmt.Errorf("%w: limite de %d bytes", FalhaCorpoGrande, grande.Limit)
+	}
+	return fmt.Errorf("%w: %v", FalhaPayloadInvalido, err)
+}
+
+// semPrazos libera respostas longas (SSE e exportações) dos prazos do servidor. Os dois importam: vencido o
+// de leitura, o net/http dá a conexão por perdida e cancela o contexto da requisição.
+func semPrazos(w http.ResponseWriter) {
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+}
@@ -0,0 +1,174 @@
+package httpapi
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/requisicao"
+)
+
+// servirComMiddleware monta a API atrás do Middleware e devolve também o log de acesso.
+func servirComMiddleware(t *testing.T, cfg ConfigMiddleware, registrar func(*http.ServeMux)) (http.Handler, *MockVotingService, *bytes.Buffer) {
+	t.Helper()
+	api, mockService := setupAPI(t)
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	mux := http.NewServeMux()
+	api.Register(mux)
+	if registrar != nil {
+		registrar(mux)
+	}
+	return Middleware(mux, logger, cfg), mockService, &logs
+}
+
+func TestMiddleware_QuandoSemIDRecebido_DeveGerarEPropagar(t *testing.T) {
+	var noContexto string
+	handler, _, logs := servirComMiddleware(t, ConfigMiddleware{}, func(mux *http.ServeMux) {
+		mux.HandleFunc("GET /eco", func(w http.ResponseWriter, r *http.Request) {
+			noContexto = requisicao.ID(r.Context())
+		})
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/eco", nil))
+
+	id := w.Header().Get(requisicao.Cabecalho)
+	require.NotEmpty(t, id)
+	assert.Equal(t, id, noContexto)
+	assert.Contains(t, logs.String(), "request_id="+id)
+}
+
+func TestMiddleware_QuandoIDRecebido_DeveReaproveitarSoSeValido(t *testing.T) {
+	handler, _, _ := servirComMiddleware(t, ConfigMiddleware{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set(requisicao.Cabecalho, "lb-123")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.Equal(t, "lb-123", w.Header().Get(requisicao.Cabecalho))
+
+	req = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set(requisicao.Cabecalho, "id com espaço\n")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	assert.NotEqual(t, "id com espaço\n", w.Header().Get(requisicao.Cabecalho))
+	assert.NotEmpty(t, w.Header().Get(requisicao.Cabecalho))
+}
+
+func TestMiddleware_QuandoHandlerEntraEmPanic_DeveResponder500ERegistrarPilha(t *testing.T) {
+	handler, _, logs := servirComMiddleware(t, ConfigMiddleware{}, func(mux *http.ServeMux) {
+		mux.HandleFunc("GET /quebra", func(http.ResponseWriter, *http.Request) {
+			panic("indice fora do intervalo")
+		})
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/quebra", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	p := lerProblema(t, w)
+	assert.Equal(t, "ERRO_INTERNO", p.Codigo)
+	assert.Equal(t, w.Header().Get(requisicao.Cabecalho), p.RequestID)
+	assert.NotContains(t, p.Titulo+p.Detalhe, "indice")
+	assert.Contains(t, logs.String(), "panic: indice fora do intervalo")
+	assert.Contains(t, logs.String(), "status=500")
+}
+
+func TestMiddleware_DeveRegistrarPadraoDaRotaENaoOCaminho(t *testing.T) {
+	handler, mockService, logs := servirComMiddleware(t, ConfigMiddleware{}, nil)
+	mockService.On("ObterParedao", mock.Anything, domain.ParedaoID("par-1")).Return(paredaoV1Teste(), nil)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/paredoes/par-1", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, logs.String(), `rota="GET /v1/paredoes/{id}"`)
+	assert.Contains(t, logs.String(), "caminho=/v1/paredoes/par-1")
+	assert.Contains(t, logs.String(), "status=200")
+}
+
+func TestMiddleware_QuandoRotaLegada_DeveRegistrarSubrotaComIDs(t *testing.T) {
+	handler, mockService, logs := servirComMiddleware(t, ConfigMiddleware{}, nil)
+	mockService.On("Checkpoints", mock.Anything, domain.ParedaoID("par-1")).Return([]domain.Checkpoint{}, nil)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/paredoes/par-1/checkpoints", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, logs.String(), "rota=/paredoes/{id}/checkpoints")
+}
+
+func TestRotaMetrica_DeveDetalharSubarvoresSemCriarSeriesPorCaminho(t *testing.T) {
+	assert.Equal(t, "/paredoes/{id}", rotaMetrica("/paredoes/", "/paredoes/par-1"))
+	assert.Equal(t, "/admin/paredoes/{id}/participantes/{participante}/retirada",
+		rotaMetrica("/admin/paredoes/", "/admin/paredoes/par-1/participantes/p-1/retirada"))
+	assert.Equal(t, "/admin/webhooks/{id}/entregas", rotaMetrica("/admin/webhooks/", "/admin/webhooks/wh-1/entregas"))
+	assert.Equal(t, "/paredoes/", rotaMetrica("/paredoes/", "/paredoes/par-1/qualquer-coisa"))
+	assert.Equal(t, "/paredoes/", rotaMetrica("/paredoes/", "/paredoes//hora"))
+	assert.Equal(t, "GET /v1/paredoes/{id}", rotaMetrica("GET /v1/paredoes/{id}", "/v1/paredoes/par-1"))
+}
+
+func TestMiddleware_QuandoCorpoAcimaDoLimite_DeveRetornar413(t *testing.T) {
+	handler, _, _ := servirComMiddleware(t, ConfigMiddleware{MaxCorpo: 32}, nil)
+
+	payload := `{"paredao_id":"` + strings.Repeat("x", 64) + `","participante_id":"a"}`
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/votos", strings.NewReader(payload)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Equal(t, "CORPO_GRANDE", lerProblema(t, w).Codigo)
+}
+
+func TestRegistrarVoto_QuandoCampoDesconhecido_DeveRetornar400(t *testing.T) {
+	handler, _, _ := servirComMiddleware(t, ConfigMiddleware{}, nil)
+
+	payload := `{"paredao_id":"par-1","participante_id":"a","participante":"b"}`
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/votos", strings.NewReader(payload)))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	p := lerProblema(t, w)
+	assert.Equal(t, "PAYLOAD_INVALIDO", p.Codigo)
+	assert.Contains(t, p.Detalhe, `unknown field "participante"`)
+}
+
+func TestSemPrazos_DeveManterRespostaLongaViva(t *testing.T) {
+	servidor := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		semPrazos(w)
+		time.Sleep(150 * time.Millisecond)
+		if r.Context().Err() != nil {
+			http.Error(w, "contexto cancelado", http.StatusInternalServerError)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	servidor.Config.ReadTimeout = 50 * time.Millisecond
+	servidor.Config.WriteTimeout = 50 * time.Millisecond
+	servidor.Start()
+	defer servidor.Close()
+
+	resp, err := http.Get(servidor.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	corpo, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(corpo))
+}
+
+func TestMetodoMetrica_QuandoMetodoArbitrario_DeveAgruparEmOther(t *testing.T) {
+	assert.Equal(t, http.MethodGet, metodoMetrica(http.MethodGet))
+	assert.Equal(t, http.MethodDelete, metodoMetrica(http.MethodDelete))
+	assert.Equal(t, "OTHER", metodoMetrica("XPTO"))
+	assert.Equal(t, "OTHER", metodoMetrica("get"))
+}
@@ -0,0 +1,144 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/marcelojr/desafio-globo/internal/app/live"
+	"github.com/marcelojr/desafio-globo/internal/app/voting"
+	"github.com/marcelojr/desafio-globo/internal/platform/antifraude"
+	"github.com/marcelojr/desafio-globo/internal/platform/auth"
+	"github.com/marcelojr/desafio-globo/internal/platform/ids"
+	"github.com/marcelojr/desafio-globo/internal/platform/requisicao"
+)
+
+// tipoProblema prefixa o campo type do problema; o código estável completa a URN.
+const tipoProblema = "urn:desafio-globo:problema:"
+
+// Falha é o erro como o cliente o vê: um código estável para comparar em vez da mensagem, o status HTTP e
+// uma mensagem que pode ser publicada. O erro que a originou fica só no log.
+type Falha struct {
+	Codigo   string
+	Status   int
+	Mensagem string
+}
+
+func (f *Falha) Error() string {
+	return f.Mensagem
+}
+
+var (
+	FalhaInterna             = &Falha{"ERRO_INTERNO", http.StatusInternalServerError, "erro interno"}
+	FalhaPayloadInvalido     = &Falha{"PAYLOAD_INVALIDO", http.StatusBadRequest, "payload invalido"}
+	FalhaCorpoGrande         = &Falha{"CORPO_GRANDE", http.StatusRequestEntityTooLarge, "corpo da requisicao acima do limite"}
+	FalhaParametroInvalido   = &Falha{"PARAMETRO_INVALIDO", http.StatusBadRequest, "parametro invalido"}
+	FalhaMetodoNaoSuportado  = &Falha{"METODO_NAO_SUPORTADO", http.StatusMethodNotAllowed, "metodo nao suportado"}
+	FalhaNaoAutorizado       = &Falha{"NAO_AUTORIZADO", http.StatusUnauthorized, "nao autorizado"}
+	FalhaParciaisOcultas     = &Falha{"PARCIAIS_OCULTAS", http.StatusForbidden, "parciais ocultas ate o encerramento do paredao"}
+	FalhaConexoesEsgotadas   = &Falha{"CONEXOES_ESGOTADAS", http.StatusServiceUnavailable, "limite de conexoes ao vivo atingido"}
+	FalhaRecursoDesabilitado = &Falha{"RECURSO_DESABILITADO", http.StatusNotFound, "recurso desabilitado nesta instalacao"}
+)
+
+// falhasConhecidas traduz os erros do serviço; a ordem importa só para erros que embrulham mais de um.
+var falhasConhecidas = []struct {
+	alvo  error
+	falha *Falha
+}{
+	{voting.ErrParedaoInvalido, &Falha{"PAREDAO_INVALIDO", http.StatusBadRequest, "paredao invalido"}},
+	{voting.ErrParticipanteDesconhecido, &Falha{"PARTICIPANTE_DESCONHECIDO", http.StatusBadRequest, "participante nao encontrado"}},
+	{voting.ErrModalidadeInvalida, &Falha{"MODALIDADE_INVALIDA", http.StatusBadRequest, "modalidade de voto nao aceita neste paredao"}},
+	{voting.ErrIntervaloInvalido, &Falha{"INTERVALO_INVALIDO", http.StatusBadRequest, "intervalo nao suportado"}},
+	{voting.ErrWebhookInvalido, &Falha{"WEBHOOK_INVALIDO", http.StatusBadRequest, "webhook invalido"}},
+	{voting.ErrPeriodoEncerrado, &Falha{"PAREDAO_ENCERRADO", http.StatusConflict, "paredao encerrado"}},
+	{voting.ErrVotoJaRegistrado, &Falha{"VOTO_JA_REGISTRADO", http.StatusConflict, "eleitor ja votou neste paredao"}},
+	{voting.ErrParticipanteRetirado, &Falha{"PARTICIPANTE_RETIRADO", http.StatusConflict, "participante retirado do paredao"}},
+	{voting.ErrParedaoAberto, &Falha{"PAREDAO_ABERTO", http.StatusConflict, "paredao ainda nao foi finalizado"}},
+	{voting.ErrEleitorObrigatorio, &Falha{"ELEITOR_OBRIGATORIO", http.StatusUnauthorized, "voto unico exige eleitor autenticado"}},
+	{auth.ErrTokenInvalido, &Falha{"TOKEN_INVALIDO", http.StatusUnauthorized, "token de eleitor invalido"}},
+	{voting.ErrParedaoNaoEncontrado, &Falha{"PAREDAO_NAO_ENCONTRADO", http.StatusNotFound, "paredao nao encontrado"}},
+	{voting.ErrVotoNaoEncontrado, &Falha{"VOTO_NAO_ENCONTRADO", http.StatusNotFound, "voto nao encontrado"}},
+	{voting.ErrWebhookNaoEncontrado, &Falha{"WEBHOOK_NAO_ENCONTRADO", http.StatusNotFound, "webhook nao encontrado"}},
+	{voting.ErrEntregaNaoEncontrada, &Falha{"ENTREGA_NAO_ENCONTRADA", http.StatusNotFound, "entrega nao encontrada"}},
+	{voting.ErrRecibosDesabilitados, FalhaRecursoDesabilitado},
+	{voting.ErrAuditoriaDesabilitada, FalhaRecursoDesabilitado},
+	{voting.ErrViradasDesabilitadas, FalhaRecursoDesabilitado},
+	{voting.ErrWebhooksDesabilitados, FalhaRecursoDesabilitado},
+	{antifraude.ErrRateLimitExceeded, &Falha{"RATE_LIMITED", http.StatusTooManyRequests, "limite de votos atingido"}},
+	{antifraude.ErrCaptchaInvalido, &Falha{"CAPTCHA_INVALIDO", http.StatusForbidden, "captcha invalido"}},
+	{live.ErrLotado, FalhaConexoesEsgotadas},
+}
+
+// classificar encontra a Falha do erro; o que não for conhecido é erro interno.
+func classificar(err error) *Falha {
+	var falha *Falha
+	if errors.As(err, &falha) {
+		return falha
+	}
+	for _, c := range falhasConhecidas {
+		if errors.Is(err, c.alvo) {
+			return c.falha
+		}
+	}
+	return FalhaInterna
+}
+
+// problema segue a RFC 9457. Detail só aparece nas falhas do cliente, em que o texto do erro descreve o que
+// ele enviou; nas internas, a causa vai para o log com o mesmo request_id.
+type problema struct {
+	Tipo      string `json:"type"`
+	Titulo    string `json:"title"`
+	Status    int    `json:"status"`
+	Detalhe   string `json:"detail,omitempty"`
+	Instancia string `json:"instance,omitempty"`
+	Codigo    string `json:"codigo"`
+	RequestID string `json:"request_id"`
+}
+
+// responderErro responde o erro como application/problem+json.
+func responderErro(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error) {
+	p := novoProblema(w, r, logger, err)
+	responderProblema(w, p.Status, p)
+}
+
+// novoProblema classifica o erro e registra a causa das falhas internas; quem precisa de membros de
+// extensão embute o problema num struct próprio antes de responder.
+func novoProblema(w http.ResponseWriter, r *http.Request, logger *slog.Logger, err error) problema {
+	falha := classificar(err)
+	id := idRequisicao(w, r)
+	p := problema{
+		Tipo:      tipoProblema + falha.Codigo,
+		Titulo:    falha.Mensagem,
+		Status:    falha.Status,
+		Instancia: r.URL.Path,
+		Codigo:    falha.Codigo,
+		RequestID: id,
+	}
+	switch detalhe := err.Error(); {
+	case falha == FalhaInterna:
+		logger.Error("erro interno", "err", err, "request_id", id, "metodo", r.Method, "caminho", r.URL.Path)
+	case falha.Status < http.StatusInternalServerError && detalhe != falha.Mensagem:
+		p.Detalhe = detalhe
+	}
+	return p
+}
+
+func responderProblema(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// idRequisicao usa o ID posto no contexto pelo servidor; sem ele, gera um e o devolve no cabeçalho.
+func idRequisicao(w http.ResponseWriter, r *http.Request) string {
+	if id := requisicao.ID(r.Context()); id != "" {
+		return id
+	}
+	if id := w.Header().Get(requisicao.Cabecalho); id != "" {
+		return id
+	}
+	id := ids.DefaultGenerator().New()
+	w.Header().Set(requisicao.Cabecalho, id)
+	return id
+}
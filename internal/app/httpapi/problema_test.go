@@ -0,0 +1,65 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/app/voting"
+	"github.com/marcelojr/desafio-globo/internal/platform/requisicao"
+)
+
+// lerProblema confere o content type da RFC 9457 e decodifica o corpo.
+func lerProblema(t *testing.T, w *httptest.ResponseRecorder) problema {
+	t.Helper()
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	var p problema
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+	assert.Equal(t, w.Code, p.Status)
+	return p
+}
+
+func TestResponderErro_QuandoErroInterno_NaoDeveVazarCausaMasDeveLogar(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	req := httptest.NewRequest(http.MethodGet, "/paredoes", nil)
+	w := httptest.NewRecorder()
+
+	responderErro(w, req, logger, errors.New("gorm paredao: buscar id: conexao recusada"))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	id := w.Header().Get(requisicao.Cabecalho)
+	require.NotEmpty(t, id)
+	p := lerProblema(t, w)
+	assert.Equal(t, "ERRO_INTERNO", p.Codigo)
+	assert.Equal(t, "erro interno", p.Titulo)
+	assert.Empty(t, p.Detalhe)
+	assert.Equal(t, id, p.RequestID)
+	assert.NotContains(t, fmt.Sprint(p), "gorm")
+	assert.Contains(t, logs.String(), "gorm paredao: buscar id: conexao recusada")
+	assert.Contains(t, logs.String(), id)
+}
+
+func TestResponderErro_QuandoErroDoClienteEmbrulhado_DeveManterCodigoETrazerDetalhe(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/admin/webhooks", nil)
+	req = req.WithContext(requisicao.ComID(req.Context(), "req-1"))
+	w := httptest.NewRecorder()
+
+	responderErro(w, req, slog.Default(), fmt.Errorf("%w: url deve ser http ou https absoluta", voting.ErrWebhookInvalido))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	p := lerProblema(t, w)
+	assert.Equal(t, "WEBHOOK_INVALIDO", p.Codigo)
+	assert.Equal(t, "urn:desafio-globo:problema:WEBHOOK_INVALIDO", p.Tipo)
+	assert.Equal(t, "webhook invalido: url deve ser http ou https absoluta", p.Detalhe)
+	assert.Equal(t, "/admin/webhooks", p.Instancia)
+	assert.Equal(t, "req-1", p.RequestID)
+}
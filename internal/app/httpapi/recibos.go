@@ -0,0 +1,54 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// verificarRecibo atende GET /recibos/verificar com os campos do recibo na query string. A resposta é 200
+// mesmo para recibos inválidos: o corpo diz o que não conferiu.
+func (a *API) verificarRecibo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		responderErro(w, r, a.logger, FalhaMetodoNaoSuportado)
+		return
+	}
+
+	q := r.URL.Query()
+	emitidoEm, err := time.Parse(time.RFC3339Nano, q.Get("emitido_em"))
+	if err != nil || q.Get("voto_id") == "" || q.Get("paredao_id") == "" || q.Get("chave_id") == "" || q.Get("assinatura") == "" {
+		responderErro(w, r, a.logger, fmt.Errorf("%w: informe voto_id, paredao_id, emitido_em (RFC 3339), chave_id e assinatura do recibo", FalhaParametroInvalido))
+		return
+	}
+	recibo := domain.Recibo{
+		VotoID:     domain.VotoID(q.Get("voto_id")),
+		ParedaoID:  domain.ParedaoID(q.Get("paredao_id")),
+		EmitidoEm:  emitidoEm,
+		ChaveID:    q.Get("chave_id"),
+		Assinatura: q.Get("assinatura"),
+	}
+
+	verificacao, err := a.service.VerificarRecibo(r.Context(), recibo)
+	if err != nil {
+		a.logger.Error("erro ao verificar recibo", "err", err, "voto", recibo.VotoID)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, verificacao)
+}
+
+// chavesRecibo publica as chaves públicas para que auditores confiram recibos por conta própria.
+func (a *API) chavesRecibo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		responderErro(w, r, a.logger, FalhaMetodoNaoSuportado)
+		return
+	}
+	chaves := a.service.ChavesRecibo()
+	if chaves == nil {
+		chaves = []domain.ChavePublicaRecibo{}
+	}
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	responderJSON(w, http.StatusOK, map[string]any{"chaves": chaves})
+}
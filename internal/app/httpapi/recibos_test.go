@@ -0,0 +1,86 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/app/voting"
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+func TestVerificarRecibo_QuandoReciboCompleto_DeveRepassarAoServico(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	emitido := time.Date(2024, 1, 1, 20, 0, 0, 500, time.UTC)
+	mockService.On("VerificarRecibo", mock.Anything, mock.MatchedBy(func(r domain.Recibo) bool {
+		return r.VotoID == "voto-1" && r.ParedaoID == "par-1" && r.EmitidoEm.Equal(emitido) && r.ChaveID == "k1" && r.Assinatura == "abc"
+	})).Return(domain.VerificacaoRecibo{Valido: true, AssinaturaValida: true, VotoEncontrado: true}, nil)
+
+	q := url.Values{
+		"voto_id":    {"voto-1"},
+		"paredao_id": {"par-1"},
+		"emitido_em": {emitido.Format(time.RFC3339Nano)},
+		"chave_id":   {"k1"},
+		"assinatura": {"abc"},
+	}
+	req := httptest.NewRequest("GET", "/recibos/verificar?"+q.Encode(), nil)
+	w := httptest.NewRecorder()
+
+	api.verificarRecibo(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response domain.VerificacaoRecibo
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.True(t, response.Valido)
+}
+
+func TestVerificarRecibo_QuandoFaltaCampo_DeveRetornar400(t *testing.T) {
+	api, _ := setupAPI(t)
+
+	req := httptest.NewRequest("GET", "/recibos/verificar?voto_id=voto-1&emitido_em=ontem", nil)
+	w := httptest.NewRecorder()
+
+	api.verificarRecibo(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestVerificarRecibo_QuandoRecibosDesabilitados_DeveRetornar404(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	mockService.On("VerificarRecibo", mock.Anything, mock.Anything).Return(domain.VerificacaoRecibo{}, voting.ErrRecibosDesabilitados)
+
+	req := httptest.NewRequest("GET", "/recibos/verificar?voto_id=v&paredao_id=p&emitido_em=2024-01-01T20:00:00Z&chave_id=k1&assinatura=abc", nil)
+	w := httptest.NewRecorder()
+
+	api.verificarRecibo(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestChavesRecibo_QuandoSolicitado_DevePublicarChaves(t *testing.T) {
+	api, mockService := setupAPI(t)
+
+	mockService.On("ChavesRecibo").Return([]domain.ChavePublicaRecibo{{ChaveID: "k1", Algoritmo: "Ed25519", Chave: "cHVi", Ativa: true}})
+
+	req := httptest.NewRequest("GET", "/.well-known/recibos-chaves.json", nil)
+	w := httptest.NewRecorder()
+
+	api.chavesRecibo(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Chaves []domain.ChavePublicaRecibo `json:"chaves"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Chaves, 1)
+	assert.Equal(t, "k1", response.Chaves[0].ChaveID)
+}
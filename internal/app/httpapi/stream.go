@@ -0,0 +1,87 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/app/live"
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+const (
+	keepAlivePadrao = 15 * time.Second
+	// retryStream é o intervalo de reconexão sugerido ao EventSource e também o Retry-After quando a instância lota.
+	retryStream = 3 * time.Second
+)
+
+// ComStream habilita GET /paredoes/{id}/stream, que envia as parciais via Server-Sent Events a partir do hub;
+// keepAlive é o intervalo dos comentários que mantêm a conexão viva em proxies ociosos.
+func ComStream(hub *live.Hub, keepAlive time.Duration) Option {
+	return func(a *API) {
+		a.hub = hub
+		if keepAlive > 0 {
+			a.keepAlive = keepAlive
+		}
+	}
+}
+
+func (a *API) transmitirParciais(w http.ResponseWriter, r *http.Request, id domain.ParedaoID) {
+	if a.hub == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	assinatura, err := a.hub.Assinar(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, live.ErrLotado) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(retryStream.Seconds())))
+			responderErro(w, r, a.logger, err)
+			return
+		}
+		a.logger.Error("erro ao assinar parciais ao vivo", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	defer assinatura.Cancelar()
+
+	// A conexão dura enquanto o cliente quiser; os prazos do servidor a derrubariam.
+	semPrazos(w)
+	rc := http.NewResponseController(w)
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	// Desliga o buffer de proxies como o nginx, que segurariam os eventos.
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", retryStream.Milliseconds()); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(a.keepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case payload := <-assinatura.C:
+			if _, err := fmt.Fprintf(w, "event: parciais\ndata: %s\n\n", payload); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
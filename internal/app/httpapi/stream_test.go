@@ -0,0 +1,119 @@
+package httpapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/app/live"
+	"github.com/marcelojr/desafio-globo/internal/app/voting"
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+func setupStream(t *testing.T, cfg live.Config) (*httptest.Server, *MockVotingService, *live.Hub) {
+	mockService := new(MockVotingService)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{}))
+	hub := live.NewHub(mockService, cfg)
+	api := New(mockService, logger, ComStream(hub, 20*time.Millisecond))
+
+	mux := http.NewServeMux()
+	api.Register(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, mockService, hub
+}
+
+func TestTransmitirParciais_QuandoParedaoExiste_DeveEnviarEventosEKeepAlive(t *testing.T) {
+	srv, mockService, hub := setupStream(t, live.Config{Cadencia: time.Hour})
+
+	paredaoID := domain.ParedaoID("01HXXXXXXXXXXXXXXXXXXXXX")
+	parciais := []domain.Parcial{{ParedaoID: paredaoID, ParticipanteID: "01HXXXXXXXXXXXXXXXXXXXXY", Total: 7, Percentual: 100}}
+	mockService.On("ParciaisPublicas", mock.Anything, paredaoID).Return(parciais, domain.Publicacao{Modo: domain.VisibilidadePublica}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/paredoes/"+string(paredaoID)+"/stream", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	assert.Equal(t, 1, hub.Conexoes())
+
+	leitor := bufio.NewReader(resp.Body)
+	var evento, dados string
+	keepAlive := false
+	for dados == "" || !keepAlive {
+		linha, err := leitor.ReadString('\n')
+		require.NoError(t, err)
+		switch {
+		case strings.HasPrefix(linha, "event: "):
+			evento = strings.TrimSpace(strings.TrimPrefix(linha, "event: "))
+		case strings.HasPrefix(linha, "data: "):
+			dados = strings.TrimPrefix(linha, "data: ")
+		case strings.HasPrefix(linha, ": keep-alive"):
+			keepAlive = true
+		}
+	}
+
+	assert.Equal(t, "parciais", evento)
+	var snapshot live.Snapshot
+	require.NoError(t, json.Unmarshal([]byte(dados), &snapshot))
+	assert.Equal(t, paredaoID, snapshot.ParedaoID)
+	assert.Equal(t, int64(7), snapshot.TotalVotos)
+
+	cancel()
+	assert.Eventually(t, func() bool { return hub.Conexoes() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestTransmitirParciais_QuandoParedaoNaoEncontrado_DeveRetornar404(t *testing.T) {
+	srv, mockService, _ := setupStream(t, live.Config{})
+
+	mockService.On("ParciaisPublicas", mock.Anything, domain.ParedaoID("inexistente")).
+		Return([]domain.Parcial(nil), domain.Publicacao{}, voting.ErrParedaoNaoEncontrado)
+
+	resp, err := http.Get(srv.URL + "/paredoes/inexistente/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestTransmitirParciais_QuandoInstanciaLotada_DeveRetornar503(t *testing.T) {
+	srv, mockService, hub := setupStream(t, live.Config{Cadencia: time.Hour, MaxConexoes: 1})
+
+	paredaoID := domain.ParedaoID("01HXXXXXXXXXXXXXXXXXXXXX")
+	mockService.On("ParciaisPublicas", mock.Anything, paredaoID).Return([]domain.Parcial{}, domain.Publicacao{Modo: domain.VisibilidadePublica}, nil)
+
+	ocupada, err := hub.Assinar(context.Background(), paredaoID)
+	require.NoError(t, err)
+	defer ocupada.Cancelar()
+
+	resp, err := http.Get(srv.URL + "/paredoes/" + string(paredaoID) + "/stream")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+}
+
+func TestTransmitirParciais_QuandoStreamDesabilitado_DeveRetornar404(t *testing.T) {
+	api, _ := setupAPI(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/paredoes/01HXXXXXXXXXXXXXXXXXXXXX/stream", nil)
+	w := httptest.NewRecorder()
+	api.handleParedaoDetalhes(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,398 @@
+package httpapi
+
+import (
+	_ "embed"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/app/voting"
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// openAPIV1 descreve as rotas /v1; o teste confere que toda rota registrada aparece no documento.
+//
+//go:embed openapi.json
+var openAPIV1 []byte
+
+// rotasV1 lista as rotas da API versionada. Os handlers respondem com DTOs próprios, em snake_case, em
+// vez de serializar os structs do domínio: mudar o domínio não muda o contrato.
+func (a *API) rotasV1() map[string]http.HandlerFunc {
+	return map[string]http.HandlerFunc{
+		"GET /v1/openapi.json":              a.openAPI,
+		"GET /v1/paredoes":                  a.listarParedoesV1,
+		"GET /v1/paredoes/{id}":             a.obterParedaoV1,
+		"GET /v1/paredoes/{id}/parciais":    a.obterParciaisV1,
+		"GET /v1/paredoes/{id}/hora":        a.obterTotaisHoraV1,
+		"GET /v1/paredoes/{id}/serie":       a.obterSerieV1,
+		"GET /v1/paredoes/{id}/viradas":     a.listarViradasV1,
+		"GET /v1/paredoes/{id}/checkpoints": a.listarCheckpointsV1,
+		"POST /v1/votos":                    a.registrarVotoV1,
+		"GET /v1/votos/{id}":                a.obterStatusVotoV1,
+		"GET /v1/recibos/chaves":            a.chavesReciboV1,
+	}
+}
+
+func (a *API) openAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(openAPIV1)
+}
+
+type participanteV1 struct {
+	ID       domain.ParticipanteID `json:"id"`
+	Nome     string                `json:"nome"`
+	FotoURL  string                `json:"foto_url,omitempty"`
+	Retirado bool                  `json:"retirado"`
+}
+
+type paredaoV1 struct {
+	ID            domain.ParedaoID   `json:"id"`
+	Nome          string             `json:"nome"`
+	Descricao     string             `json:"descricao,omitempty"`
+	GrupoID       domain.ParedaoID   `json:"grupo_id,omitempty"`
+	Etapa         int                `json:"etapa"`
+	EtapaNome     string             `json:"etapa_nome,omitempty"`
+	Inicio        time.Time          `json:"inicio"`
+	Fim           time.Time          `json:"fim"`
+	Ativo         bool               `json:"ativo"`
+	Anulado       bool               `json:"anulado"`
+	ModoVotacao   domain.ModoVotacao `json:"modo_votacao"`
+	Polaridade    domain.Polaridade  `json:"polaridade"`
+	Participantes []participanteV1   `json:"participantes"`
+}
+
+func novoParedaoV1(p domain.Paredao) paredaoV1 {
+	modo := p.ModoVotacao
+	if modo == "" {
+		modo = domain.ModoTorcida
+	}
+	dto := paredaoV1{
+		ID:            p.ID,
+		Nome:          p.Nome,
+		Descricao:     p.Descricao,
+		GrupoID:       p.GrupoID,
+		Etapa:         max(p.Etapa, 1),
+		EtapaNome:     p.EtapaNome,
+		Inicio:        p.Inicio,
+		Fim:           p.Fim,
+		Ativo:         p.Ativo,
+		Anulado:       p.Anulado,
+		ModoVotacao:   modo,
+		Polaridade:    p.PolaridadeEfetiva(),
+		Participantes: make([]participanteV1, len(p.Participantes)),
+	}
+	for i, part := range p.Participantes {
+		dto.Participantes[i] = participanteV1{ID: part.ID, Nome: part.Nome, FotoURL: part.FotoURL, Retirado: part.Retirado()}
+	}
+	return dto
+}
+
+// nomesParticipantes indexa os nomes do paredão para completar parciais, séries e viradas.
+func nomesParticipantes(p domain.Paredao) map[domain.ParticipanteID]string {
+	nomes := make(map[domain.ParticipanteID]string, len(p.Participantes))
+	for _, part := range p.Participantes {
+		nomes[part.ID] = part.Nome
+	}
+	return nomes
+}
+
+// publicacaoV1 diz como os números foram publicados: ao vivo, atrasados até Ate ou ocultos até LiberaEm.
+type publicacaoV1 struct {
+	Visibilidade domain.ModoVisibilidade `json:"visibilidade"`
+	Oculta       bool                    `json:"oculta"`
+	Ate          *time.Time              `json:"ate,omitempty"`
+	LiberaEm     *time.Time              `json:"libera_em,omitempty"`
+}
+
+func novaPublicacaoV1(p domain.Publicacao) publicacaoV1 {
+	dto := publicacaoV1{Visibilidade: p.Modo, Oculta: p.Oculta}
+	if dto.Visibilidade == "" {
+		dto.Visibilidade = domain.VisibilidadePublica
+	}
+	if !p.Ate.IsZero() {
+		dto.Ate = &p.Ate
+	}
+	if !p.LiberaEm.IsZero() {
+		dto.LiberaEm = &p.LiberaEm
+	}
+	return dto
+}
+
+type urnaV1 struct {
+	Modalidade domain.Modalidade `json:"modalidade"`
+	Total      int64             `json:"total"`
+	Percentual float64           `json:"percentual"`
+	Peso       float64           `json:"peso"`
+}
+
+type parcialV1 struct {
+	ParticipanteID domain.ParticipanteID `json:"participante_id"`
+	Nome           string                `json:"nome"`
+	Total          int64                 `json:"total"`
+	Percentual     float64               `json:"percentual"`
+	Retirado       bool                  `json:"retirado"`
+	Urnas          []urnaV1              `json:"urnas,omitempty"`
+}
+
+type parciaisV1 struct {
+	Paredao       paredaoV1    `json:"paredao"`
+	Publicacao    publicacaoV1 `json:"publicacao"`
+	TotalVotos    int64        `json:"total_votos"`
+	Participantes []parcialV1  `json:"participantes"`
+}
+
+type horaV1 struct {
+	Hora  time.Time `json:"hora"`
+	Total int64     `json:"total"`
+}
+
+type totaisHoraV1 struct {
+	ParedaoID  domain.ParedaoID `json:"paredao_id"`
+	Publicacao publicacaoV1     `json:"publicacao"`
+	Horas      []horaV1         `json:"horas"`
+}
+
+type pontoSerieV1 struct {
+	Inicio       time.Time `json:"inicio"`
+	Votos        int64     `json:"votos"`
+	Acumulado    int64     `json:"acumulado"`
+	Participacao float64   `json:"participacao"`
+}
+
+type serieParticipanteV1 struct {
+	ParticipanteID domain.ParticipanteID `json:"participante_id"`
+	Nome           string                `json:"nome"`
+	Pontos         []pontoSerieV1        `json:"pontos"`
+}
+
+type seriesV1 struct {
+	ParedaoID     domain.ParedaoID      `json:"paredao_id"`
+	Bucket        string                `json:"bucket"`
+	Publicacao    publicacaoV1          `json:"publicacao"`
+	Participantes []serieParticipanteV1 `json:"participantes"`
+}
+
+type viradaV1 struct {
+	Ordem             int                   `json:"ordem"`
+	OcorridaEm        time.Time             `json:"ocorrida_em"`
+	LiderAnterior     domain.ParticipanteID `json:"lider_anterior,omitempty"`
+	LiderAnteriorNome string                `json:"lider_anterior_nome,omitempty"`
+	NovoLider         domain.ParticipanteID `json:"novo_lider"`
+	NovoLiderNome     string                `json:"novo_lider_nome"`
+	Margem            float64               `json:"margem"`
+	TotalVotos        int64                 `json:"total_votos"`
+}
+
+type viradasV1 struct {
+	ParedaoID  domain.ParedaoID `json:"paredao_id"`
+	Publicacao publicacaoV1     `json:"publicacao"`
+	Viradas    []viradaV1       `json:"viradas"`
+}
+
+type statusVotoV1 struct {
+	VotoID       domain.VotoID     `json:"voto_id"`
+	ParedaoID    domain.ParedaoID  `json:"paredao_id,omitempty"`
+	Estado       domain.EstadoVoto `json:"estado"`
+	Motivo       string            `json:"motivo,omitempty"`
+	AtualizadoEm time.Time         `json:"atualizado_em"`
+}
+
+type chaveReciboV1 struct {
+	ChaveID   string `json:"chave_id"`
+	Algoritmo string `json:"algoritmo"`
+	Chave     string `json:"chave_publica"`
+	Ativa     bool   `json:"ativa"`
+}
+
+type checkpointV1 struct {
+	Tamanho    int64     `json:"tamanho"`
+	HashCadeia string    `json:"hash_cadeia"`
+	RaizMerkle string    `json:"raiz_merkle"`
+	ChaveID    string    `json:"chave_id"`
+	Assinatura string    `json:"assinatura"`
+	CriadoEm   time.Time `json:"criado_em"`
+}
+
+func (a *API) listarParedoesV1(w http.ResponseWriter, r *http.Request) {
+	paredoes, err := a.service.ListarAtivos(r.Context())
+	if err != nil {
+		a.logger.Error("erro ao listar paredoes", "err", err)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	dtos := make([]paredaoV1, len(paredoes))
+	for i, p := range paredoes {
+		dtos[i] = novoParedaoV1(p)
+	}
+	responderJSON(w, http.StatusOK, dtos)
+}
+
+func (a *API) obterParedaoV1(w http.ResponseWriter, r *http.Request) {
+	paredao, err := a.service.ObterParedao(r.Context(), domain.ParedaoID(r.PathValue("id")))
+	if err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, novoParedaoV1(paredao))
+}
+
+// obterParciaisV1 junta às parciais o paredão e os nomes dos participantes, que a rota antiga não trazia.
+func (a *API) obterParciaisV1(w http.ResponseWriter, r *http.Request) {
+	id := domain.ParedaoID(r.PathValue("id"))
+	paredao, err := a.service.ObterParedao(r.Context(), id)
+	if err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	parciais, publicacao, err := a.service.ParciaisPublicas(r.Context(), id)
+	if err != nil {
+		a.logger.Error("erro ao obter parciais", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	nomes := nomesParticipantes(paredao)
+	dto := parciaisV1{
+		Paredao:       novoParedaoV1(paredao),
+		Publicacao:    novaPublicacaoV1(publicacao),
+		Participantes: make([]parcialV1, len(parciais)),
+	}
+	for i, p := range parciais {
+		parcial := parcialV1{ParticipanteID: p.ParticipanteID, Nome: nomes[p.ParticipanteID], Total: p.Total, Percentual: p.Percentual, Retirado: p.Retirado}
+		for _, m := range p.Modalidades {
+			parcial.Urnas = append(parcial.Urnas, urnaV1{Modalidade: m.Modalidade, Total: m.Total, Percentual: m.Percentual, Peso: m.Peso})
+		}
+		dto.TotalVotos += p.Total
+		dto.Participantes[i] = parcial
+	}
+	a.responderPublicacao(w, r, publicacao, dto)
+}
+
+func (a *API) obterTotaisHoraV1(w http.ResponseWriter, r *http.Request) {
+	id := domain.ParedaoID(r.PathValue("id"))
+	totais, publicacao, err := a.service.TotaisPorHoraPublicos(r.Context(), id)
+	if err != nil {
+		a.logger.Error("erro ao obter totais por hora", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	dto := totaisHoraV1{ParedaoID: id, Publicacao: novaPublicacaoV1(publicacao), Horas: make([]horaV1, len(totais))}
+	for i, t := range totais {
+		dto.Horas[i] = horaV1{Hora: t.Hora, Total: t.Total}
+	}
+	a.responderPublicacao(w, r, publicacao, dto)
+}
+
+func (a *API) obterSerieV1(w http.ResponseWriter, r *http.Request) {
+	id := domain.ParedaoID(r.PathValue("id"))
+	bucket := r.URL.Query().Get("bucket")
+	if bucket == "" {
+		bucket = voting.IntervaloSeriePadrao
+	}
+	intervalo, err := voting.ParseIntervaloSerie(bucket)
+	if err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	paredao, err := a.service.ObterParedao(r.Context(), id)
+	if err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	series, publicacao, err := a.service.SeriePublica(r.Context(), id, intervalo)
+	if err != nil {
+		a.logger.Error("erro ao obter serie", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	nomes := nomesParticipantes(paredao)
+	dto := seriesV1{ParedaoID: id, Bucket: bucket, Publicacao: novaPublicacaoV1(publicacao), Participantes: make([]serieParticipanteV1, len(series))}
+	for i, s := range series {
+		serie := serieParticipanteV1{ParticipanteID: s.ParticipanteID, Nome: nomes[s.ParticipanteID], Pontos: make([]pontoSerieV1, len(s.Pontos))}
+		for j, p := range s.Pontos {
+			serie.Pontos[j] = pontoSerieV1{Inicio: p.Inicio, Votos: p.Votos, Acumulado: p.Acumulado, Participacao: p.Participacao}
+		}
+		dto.Participantes[i] = serie
+	}
+	a.responderPublicacao(w, r, publicacao, dto)
+}
+
+func (a *API) listarViradasV1(w http.ResponseWriter, r *http.Request) {
+	id := domain.ParedaoID(r.PathValue("id"))
+	paredao, err := a.service.ObterParedao(r.Context(), id)
+	if err != nil {
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	viradas, publicacao, err := a.service.ViradasPublicas(r.Context(), id)
+	if err != nil {
+		a.logger.Error("erro ao listar viradas", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	nomes := nomesParticipantes(paredao)
+	dto := viradasV1{ParedaoID: id, Publicacao: novaPublicacaoV1(publicacao), Viradas: make([]viradaV1, len(viradas))}
+	for i, v := range viradas {
+		dto.Viradas[i] = viradaV1{
+			Ordem:             v.Ordem,
+			OcorridaEm:        v.OcorridaEm,
+			LiderAnterior:     v.LiderAnterior,
+			LiderAnteriorNome: nomes[v.LiderAnterior],
+			NovoLider:         v.NovoLider,
+			NovoLiderNome:     nomes[v.NovoLider],
+			Margem:            v.Margem,
+			TotalVotos:        v.TotalVotos,
+		}
+	}
+	a.responderPublicacao(w, r, publicacao, dto)
+}
+
+func (a *API) registrarVotoV1(w http.ResponseWriter, r *http.Request) {
+	a.registrarVoto(w, r, "/v1/votos/")
+}
+
+func (a *API) obterStatusVotoV1(w http.ResponseWriter, r *http.Request) {
+	id := domain.VotoID(r.PathValue("id"))
+	status, err := a.service.StatusVoto(r.Context(), id)
+	if err != nil {
+		if !errors.Is(err, voting.ErrVotoNaoEncontrado) {
+			a.logger.Error("erro ao consultar status do voto", "err", err, "voto", id)
+		}
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, statusVotoV1{
+		VotoID:       status.VotoID,
+		ParedaoID:    status.ParedaoID,
+		Estado:       status.Estado,
+		Motivo:       status.Motivo,
+		AtualizadoEm: status.AtualizadoEm,
+	})
+}
+
+func (a *API) chavesReciboV1(w http.ResponseWriter, _ *http.Request) {
+	chaves := a.service.ChavesRecibo()
+	dtos := make([]chaveReciboV1, len(chaves))
+	for i, c := range chaves {
+		dtos[i] = chaveReciboV1{ChaveID: c.ChaveID, Algoritmo: c.Algoritmo, Chave: c.Chave, Ativa: c.Ativa}
+	}
+	responderJSON(w, http.StatusOK, map[string]any{"chaves": dtos})
+}
+
+func (a *API) listarCheckpointsV1(w http.ResponseWriter, r *http.Request) {
+	id := domain.ParedaoID(r.PathValue("id"))
+	checkpoints, err := a.service.Checkpoints(r.Context(), id)
+	if err != nil {
+		a.logger.Error("erro ao listar checkpoints", "err", err, "paredao", id)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+	dtos := make([]checkpointV1, len(checkpoints))
+	for i, c := range checkpoints {
+		dtos[i] = checkpointV1{Tamanho: c.Tamanho, HashCadeia: c.HashCadeia, RaizMerkle: c.RaizMerkle, ChaveID: c.ChaveID, Assinatura: c.Assinatura, CriadoEm: c.CriadoEm}
+	}
+	responderJSON(w, http.StatusOK, dtos)
+}
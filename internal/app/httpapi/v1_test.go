@@ -0,0 +1,177 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/app/voting"
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// servirV1 passa a requisição pelo mux, para que os padrões de rota preencham os PathValue.
+func servirV1(api *API, req *http.Request) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	api.Register(mux)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func paredaoV1Teste() domain.Paredao {
+	return domain.Paredao{
+		ID:     "par-1",
+		Nome:   "Paredão da semana",
+		Inicio: time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC),
+		Fim:    time.Date(2024, 1, 3, 22, 0, 0, 0, time.UTC),
+		Ativo:  true,
+		Participantes: []domain.Participante{
+			{ID: "a", ParedaoID: "par-1", Nome: "Ana"},
+			{ID: "b", ParedaoID: "par-1", Nome: "Bruno", Retirada: domain.RetiradaManter},
+		},
+	}
+}
+
+func TestV1ObterParedao_QuandoExiste_DeveRetornarDTOEmSnakeCase(t *testing.T) {
+	api, mockService := setupAPI(t)
+	mockService.On("ObterParedao", mock.Anything, domain.ParedaoID("par-1")).Return(paredaoV1Teste(), nil)
+
+	w := servirV1(api, httptest.NewRequest(http.MethodGet, "/v1/paredoes/par-1", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "par-1", response["id"])
+	assert.Equal(t, "torcida", response["modo_votacao"])
+	assert.Equal(t, "eliminar", response["polaridade"])
+	assert.EqualValues(t, 1, response["etapa"])
+	assert.NotContains(t, response, "Nome")
+	participantes := response["participantes"].([]any)
+	require.Len(t, participantes, 2)
+	assert.Equal(t, map[string]any{"id": "b", "nome": "Bruno", "retirado": true}, participantes[1])
+}
+
+func TestV1ObterParedao_QuandoNaoEncontrado_DeveRetornar404(t *testing.T) {
+	api, mockService := setupAPI(t)
+	mockService.On("ObterParedao", mock.Anything, domain.ParedaoID("par-x")).Return(domain.Paredao{}, voting.ErrParedaoNaoEncontrado)
+
+	w := servirV1(api, httptest.NewRequest(http.MethodGet, "/v1/paredoes/par-x", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestV1ObterParciais_QuandoPublicas_DeveTrazerNomesEParedao(t *testing.T) {
+	api, mockService := setupAPI(t)
+	mockService.On("ObterParedao", mock.Anything, domain.ParedaoID("par-1")).Return(paredaoV1Teste(), nil)
+	mockService.On("ParciaisPublicas", mock.Anything, domain.ParedaoID("par-1")).Return([]domain.Parcial{
+		{ParedaoID: "par-1", ParticipanteID: "a", Total: 30, Percentual: 75},
+		{ParedaoID: "par-1", ParticipanteID: "b", Total: 10, Percentual: 25, Retirado: true},
+	}, domain.Publicacao{Modo: domain.VisibilidadePublica}, nil)
+
+	w := servirV1(api, httptest.NewRequest(http.MethodGet, "/v1/paredoes/par-1/parciais", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response parciaisV1
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "Paredão da semana", response.Paredao.Nome)
+	assert.Equal(t, domain.VisibilidadePublica, response.Publicacao.Visibilidade)
+	assert.Equal(t, int64(40), response.TotalVotos)
+	require.Len(t, response.Participantes, 2)
+	assert.Equal(t, "Ana", response.Participantes[0].Nome)
+	assert.Equal(t, "Bruno", response.Participantes[1].Nome)
+	assert.True(t, response.Participantes[1].Retirado)
+}
+
+func TestV1ObterParciais_QuandoOcultas_DeveRetornar403(t *testing.T) {
+	api, mockService := setupAPI(t)
+	mockService.On("ObterParedao", mock.Anything, domain.ParedaoID("par-1")).Return(paredaoV1Teste(), nil)
+	mockService.On("ParciaisPublicas", mock.Anything, domain.ParedaoID("par-1")).Return([]domain.Parcial(nil), domain.Publicacao{Modo: domain.VisibilidadeOculta, Oculta: true}, nil)
+
+	w := servirV1(api, httptest.NewRequest(http.MethodGet, "/v1/paredoes/par-1/parciais", nil))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NotContains(t, w.Body.String(), "participantes")
+}
+
+func TestV1ListarViradas_QuandoExistem_DeveTrazerNomesDosLideres(t *testing.T) {
+	api, mockService := setupAPI(t)
+	mockService.On("ObterParedao", mock.Anything, domain.ParedaoID("par-1")).Return(paredaoV1Teste(), nil)
+	mockService.On("ViradasPublicas", mock.Anything, domain.ParedaoID("par-1")).Return([]domain.Virada{
+		{ParedaoID: "par-1", Ordem: 1, NovoLider: "a", Margem: 10, TotalVotos: 10},
+		{ParedaoID: "par-1", Ordem: 2, LiderAnterior: "a", NovoLider: "b", Margem: 2, TotalVotos: 50},
+	}, domain.Publicacao{Modo: domain.VisibilidadePublica}, nil)
+
+	w := servirV1(api, httptest.NewRequest(http.MethodGet, "/v1/paredoes/par-1/viradas", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response viradasV1
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Viradas, 2)
+	assert.Equal(t, "Ana", response.Viradas[0].NovoLiderNome)
+	assert.Empty(t, response.Viradas[0].LiderAnteriorNome)
+	assert.Equal(t, "Ana", response.Viradas[1].LiderAnteriorNome)
+	assert.Equal(t, "Bruno", response.Viradas[1].NovoLiderNome)
+}
+
+func TestV1RegistrarVoto_QuandoAceito_DeveApontarStatusDaV1(t *testing.T) {
+	api, mockService := setupAPI(t)
+	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(domain.ResultadoVoto{VotoID: "voto-1"}, nil)
+
+	payload := `{"paredao_id":"par-1","participante_id":"a"}`
+	w := servirV1(api, httptest.NewRequest(http.MethodPost, "/v1/votos", bytes.NewReader([]byte(payload))))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/v1/votos/voto-1", w.Header().Get("Location"))
+}
+
+func TestV1StatusVoto_QuandoProcessado_DeveRetornarEstado(t *testing.T) {
+	api, mockService := setupAPI(t)
+	mockService.On("StatusVoto", mock.Anything, domain.VotoID("voto-1")).Return(domain.StatusVoto{VotoID: "voto-1", Estado: domain.VotoProcessado}, nil)
+
+	w := servirV1(api, httptest.NewRequest(http.MethodGet, "/v1/votos/voto-1", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]any
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Equal(t, "processado", response["estado"])
+}
+
+func TestV1_QuandoMetodoNaoSuportado_DeveRetornar405(t *testing.T) {
+	api, _ := setupAPI(t)
+
+	w := servirV1(api, httptest.NewRequest(http.MethodDelete, "/v1/votos", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestV1OpenAPI_DeveDescreverTodasAsRotasRegistradas(t *testing.T) {
+	api, _ := setupAPI(t)
+
+	w := servirV1(api, httptest.NewRequest(http.MethodGet, "/v1/openapi.json", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var spec struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&spec))
+	assert.True(t, strings.HasPrefix(spec.OpenAPI, "3."))
+
+	for rota := range api.rotasV1() {
+		metodo, caminho, _ := strings.Cut(rota, " ")
+		operacoes, ok := spec.Paths[caminho]
+		if assert.True(t, ok, "rota %s fora da especificacao", caminho) {
+			assert.Contains(t, operacoes, strings.ToLower(metodo), "rota %s", rota)
+		}
+	}
+	assert.Len(t, spec.Paths, len(api.rotasV1()))
+}
@@ -0,0 +1,335 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/app/live"
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/metrics"
+	"github.com/marcelojr/desafio-globo/internal/platform/websocket"
+)
+
+const (
+	prazoEscritaWS    = 10 * time.Second
+	limiteMensagemWS  = 4 << 10
+	maxAssinaturasWS  = 10
+	mensagensPadraoWS = 5
+	rajadaPadraoWS    = 10
+)
+
+// Tipos de mensagem do protocolo WebSocket. O cliente envia assinar, cancelar e votar; o servidor responde
+// com assinado, cancelado, parciais, ack e erro. O campo ref é ecoado para o cliente casar a resposta.
+const (
+	wsAssinar   = "assinar"
+	wsCancelar  = "cancelar"
+	wsVotar     = "votar"
+	wsAssinado  = "assinado"
+	wsCancelado = "cancelado"
+	wsParciais  = "parciais"
+	wsAck       = "ack"
+	wsErro      = "erro"
+)
+
+// ConfigWebSocket limita o que cada conexão WebSocket pode enviar.
+type ConfigWebSocket struct {
+	// MensagensPorSegundo é a taxa sustentada de mensagens aceitas por conexão.
+	MensagensPorSegundo float64
+	// Rajada é quantas mensagens seguidas a conexão pode mandar antes de a taxa valer; também é o número de
+	// recusas consecutivas toleradas antes de a conexão ser encerrada.
+	Rajada int
+	// Origens lista as origens de outros sites que podem abrir a conexão pelo navegador (ex.:
+	// "https://gshow.globo.com"); a do próprio servidor e clientes sem Origin são sempre aceitos.
+	Origens []string
+}
+
+// ComWebSocket habilita GET /ws, que concentra assinatura de parciais e envio de votos numa única conexão.
+func ComWebSocket(cfg ConfigWebSocket) Option {
+	return func(a *API) {
+		if cfg.MensagensPorSegundo <= 0 {
+			cfg.MensagensPorSegundo = mensagensPadraoWS
+		}
+		if cfg.Rajada <= 0 {
+			cfg.Rajada = rajadaPadraoWS
+		}
+		a.ws = &cfg
+	}
+}
+
+type mensagemWS struct {
+	Tipo           string `json:"tipo"`
+	Ref            string `json:"ref,omitempty"`
+	ParedaoID      string `json:"paredao_id,omitempty"`
+	ParticipanteID string `json:"participante_id,omitempty"`
+	Modalidade     string `json:"modalidade,omitempty"`
+	CaptchaToken   string `json:"captcha_token,omitempty"`
+}
+
+type respostaWS struct {
+	Tipo      string           `json:"tipo"`
+	Ref       string           `json:"ref,omitempty"`
+	ParedaoID domain.ParedaoID `json:"paredao_id,omitempty"`
+	VotoID    domain.VotoID    `json:"voto_id,omitempty"`
+	Status    string           `json:"status,omitempty"`
+	Codigo    string           `json:"codigo,omitempty"`
+	Erro      string           `json:"erro,omitempty"`
+	Dados     json.RawMessage  `json:"dados,omitempty"`
+	Recibo    *domain.Recibo   `json:"recibo,omitempty"`
+}
+
+func (a *API) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// O token do eleitor vale para a conexão inteira e é conferido antes do upgrade, como no REST.
+	eleitor, err := a.identificarEleitor(r)
+	if err != nil {
+		a.logger.Warn("token de eleitor recusado no websocket", "err", err)
+		responderErro(w, r, a.logger, err)
+		return
+	}
+
+	id := idRequisicao(w, r)
+	conn, err := websocket.Atualizar(w, r, websocket.OrigensPermitidas(a.ws.Origens))
+	if err != nil {
+		a.logger.Warn("upgrade para websocket recusado", "err", err)
+		return
+	}
+	conn.DefinirLimiteLeitura(limiteMensagemWS)
+
+	s := &sessaoWS{
+		id:          id,
+		api:         a,
+		conn:        conn,
+		eleitor:     eleitor,
+		origemIP:    origemIP(r),
+		userAgent:   r.UserAgent(),
+		balde:       novoBalde(a.ws.MensagensPorSegundo, a.ws.Rajada),
+		assinaturas: make(map[domain.ParedaoID]*assinaturaWS),
+	}
+	s.rodar()
+}
+
+// sessaoWS guarda o estado de uma conexão. Só a goroutine de leitura mexe nas assinaturas; as escritas
+// vêm também das goroutines que repassam parciais e são serializadas pela Conn.
+type sessaoWS struct {
+	id        string
+	api       *API
+	conn      *websocket.Conn
+	eleitor   domain.EleitorID
+	origemIP  string
+	userAgent string
+	balde     *baldeMensagens
+
+	assinaturas map[domain.ParedaoID]*assinaturaWS
+	repasses    sync.WaitGroup
+}
+
+type assinaturaWS struct {
+	assinatura *live.Assinatura
+	parar      context.CancelFunc
+}
+
+func (s *sessaoWS) rodar() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		for _, a := range s.assinaturas {
+			a.assinatura.Cancelar()
+		}
+		s.repasses.Wait()
+		_ = s.conn.Fechar(websocket.FechamentoSaindo, "")
+	}()
+
+	// Ping periódico detecta clientes que sumiram sem fechar; qualquer tráfego do cliente renova o prazo.
+	prazoLeitura := 2 * s.api.keepAlive
+	_ = s.conn.DefinirPrazoLeitura(time.Now().Add(prazoLeitura))
+	s.conn.AoReceberPong(func() { _ = s.conn.DefinirPrazoLeitura(time.Now().Add(prazoLeitura)) })
+	go s.pingar(ctx)
+
+	recusas := 0
+	for {
+		_, payload, err := s.conn.LerMensagem()
+		if err != nil {
+			return
+		}
+		_ = s.conn.DefinirPrazoLeitura(time.Now().Add(prazoLeitura))
+
+		if !s.balde.permitir(time.Now()) {
+			metrics.ObserveWebSocketMessage("rate_limited")
+			if recusas++; recusas >= s.api.ws.Rajada {
+				_ = s.conn.Fechar(websocket.FechamentoViolacao, "limite de mensagens excedido")
+				return
+			}
+			s.enviar(respostaWS{Tipo: wsErro, Status: "rate_limited", Erro: "limite de mensagens da conexao excedido"})
+			continue
+		}
+		recusas = 0
+
+		var msg mensagemWS
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			metrics.ObserveWebSocketMessage("invalid_payload")
+			s.enviar(respostaWS{Tipo: wsErro, Status: "invalid", Erro: "payload invalido"})
+			continue
+		}
+
+		switch msg.Tipo {
+		case wsAssinar:
+			metrics.ObserveWebSocketMessage(msg.Tipo)
+			s.assinar(ctx, msg)
+		case wsCancelar:
+			metrics.ObserveWebSocketMessage(msg.Tipo)
+			s.cancelar(msg)
+		case wsVotar:
+			metrics.ObserveWebSocketMessage(msg.Tipo)
+			s.votar(ctx, msg)
+		default:
+			metrics.ObserveWebSocketMessage("unknown")
+			s.enviar(respostaWS{Tipo: wsErro, Ref: msg.Ref, Status: "invalid", Erro: "tipo de mensagem desconhecido"})
+		}
+	}
+}
+
+func (s *sessaoWS) pingar(ctx context.Context) {
+	ticker := time.NewTicker(s.api.keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.conn.Ping(time.Now().Add(prazoEscritaWS)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *sessaoWS) assinar(ctx context.Context, msg mensagemWS) {
+	id := domain.ParedaoID(msg.ParedaoID)
+	switch {
+	case s.api.hub == nil:
+		s.enviar(respostaWS{Tipo: wsErro, Ref: msg.Ref, ParedaoID: id, Status: "unavailable", Erro: "parciais ao vivo desabilitadas"})
+		return
+	case id == "":
+		s.enviar(respostaWS{Tipo: wsErro, Ref: msg.Ref, Status: "invalid", Erro: "paredao_id obrigatorio"})
+		return
+	case s.assinaturas[id] != nil:
+		s.enviar(respostaWS{Tipo: wsAssinado, Ref: msg.Ref, ParedaoID: id})
+		return
+	case len(s.assinaturas) >= maxAssinaturasWS:
+		s.enviar(respostaWS{Tipo: wsErro, Ref: msg.Ref, ParedaoID: id, Status: "invalid", Erro: "limite de assinaturas da conexao atingido"})
+		return
+	}
+
+	assinatura, err := s.api.hub.Assinar(ctx, id)
+	if err != nil {
+		status := statusFromError(err)
+		if errors.Is(err, live.ErrLotado) {
+			status = "unavailable"
+		}
+		s.enviarErro(respostaWS{Ref: msg.Ref, ParedaoID: id, Status: status}, err)
+		return
+	}
+
+	repasseCtx, parar := context.WithCancel(ctx)
+	s.assinaturas[id] = &assinaturaWS{assinatura: assinatura, parar: parar}
+	// A confirmação sai antes do repasse começar, para que "assinado" sempre preceda as parciais.
+	s.enviar(respostaWS{Tipo: wsAssinado, Ref: msg.Ref, ParedaoID: id})
+
+	s.repasses.Add(1)
+	go func() {
+		defer s.repasses.Done()
+		for {
+			select {
+			case <-repasseCtx.Done():
+				return
+			case payload := <-assinatura.C:
+				if !s.enviar(respostaWS{Tipo: wsParciais, ParedaoID: id, Dados: payload}) {
+					return
+				}
+			}
+		}
+	}()
+}
+
+func (s *sessaoWS) cancelar(msg mensagemWS) {
+	id := domain.ParedaoID(msg.ParedaoID)
+	if a := s.assinaturas[id]; a != nil {
+		a.parar()
+		a.assinatura.Cancelar()
+		delete(s.assinaturas, id)
+	}
+	s.enviar(respostaWS{Tipo: wsCancelado, Ref: msg.Ref, ParedaoID: id})
+}
+
+// votar passa pelo mesmo VotingService do REST, com IP, user agent e eleitor capturados no handshake, de
+// modo que antifraude, captcha e voto único se aplicam igualmente.
+func (s *sessaoWS) votar(ctx context.Context, msg mensagemWS) {
+	voto := domain.Voto{
+		ParedaoID:      domain.ParedaoID(msg.ParedaoID),
+		ParticipanteID: domain.ParticipanteID(msg.ParticipanteID),
+		OrigemIP:       s.origemIP,
+		UserAgent:      s.userAgent,
+		EleitorID:      s.eleitor,
+		Modalidade:     domain.Modalidade(msg.Modalidade),
+		CaptchaToken:   msg.CaptchaToken,
+	}
+
+	resultado, err := s.api.service.RegistrarVoto(ctx, voto)
+	if err != nil {
+		status := statusFromError(err)
+		metrics.ObserveVoteRequest(status)
+		s.api.logger.Warn("falha ao registrar voto via websocket", "err", err, "sessao", s.id, "paredao", msg.ParedaoID, "participante", msg.ParticipanteID, "status", status)
+		s.enviarErro(respostaWS{Ref: msg.Ref, ParedaoID: voto.ParedaoID, Status: status}, err)
+		return
+	}
+
+	metrics.ObserveVoteRequest("accepted")
+	s.enviar(respostaWS{Tipo: wsAck, Ref: msg.Ref, ParedaoID: voto.ParedaoID, VotoID: resultado.VotoID, Status: "recebido", Recibo: resultado.Recibo})
+}
+
+// enviarErro classifica o erro como o REST faz: o cliente recebe o código estável e a mensagem publicável,
+// e a causa das falhas internas fica no log com o ID da sessão.
+func (s *sessaoWS) enviarErro(resp respostaWS, err error) {
+	falha := classificar(err)
+	if falha == FalhaInterna {
+		s.api.logger.Error("erro interno no websocket", "err", err, "sessao", s.id, "paredao", resp.ParedaoID)
+	}
+	resp.Tipo = wsErro
+	resp.Codigo = falha.Codigo
+	resp.Erro = falha.Mensagem
+	s.enviar(resp)
+}
+
+func (s *sessaoWS) enviar(resp respostaWS) bool {
+	payload, err := json.Marshal(resp)
+	if err != nil {
+		s.api.logger.Error("erro ao serializar mensagem websocket", "err", err)
+		return false
+	}
+	return s.conn.EscreverMensagem(websocket.MensagemTexto, payload, time.Now().Add(prazoEscritaWS)) == nil
+}
+
+// baldeMensagens é um token bucket local da conexão: não precisa de Redis porque a conexão vive numa só réplica.
+type baldeMensagens struct {
+	taxa       float64
+	capacidade float64
+	fichas     float64
+	ultimo     time.Time
+}
+
+func novoBalde(porSegundo float64, rajada int) *baldeMensagens {
+	return &baldeMensagens{taxa: porSegundo, capacidade: float64(rajada), fichas: float64(rajada), ultimo: time.Now()}
+}
+
+func (b *baldeMensagens) permitir(agora time.Time) bool {
+	b.fichas = min(b.capacidade, b.fichas+agora.Sub(b.ultimo).Seconds()*b.taxa)
+	b.ultimo = agora
+	if b.fichas < 1 {
+		return false
+	}
+	b.fichas--
+	return true
+}
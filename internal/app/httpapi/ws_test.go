@@ -0,0 +1,175 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/app/live"
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/antifraude"
+	"github.com/marcelojr/desafio-globo/internal/platform/auth"
+	"github.com/marcelojr/desafio-globo/internal/platform/websocket"
+)
+
+func setupWebSocket(t *testing.T, cfg ConfigWebSocket) (*websocket.Conn, *MockVotingService) {
+	mockService := new(MockVotingService)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{}))
+	hub := live.NewHub(mockService, live.Config{Cadencia: time.Hour})
+	api := New(mockService, logger, ComStream(hub, time.Minute), ComWebSocket(cfg))
+
+	mux := http.NewServeMux()
+	api.Register(mux)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	conn, _, err := websocket.Conectar(ctx, "ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", http.Header{"User-Agent": {"app-movel"}})
+	require.NoError(t, err)
+	require.NoError(t, conn.DefinirPrazoLeitura(time.Now().Add(2*time.Second)))
+	t.Cleanup(func() { _ = conn.Fechar(websocket.FechamentoNormal, "") })
+	return conn, mockService
+}
+
+func enviarWS(t *testing.T, conn *websocket.Conn, msg mensagemWS) {
+	t.Helper()
+	payload, err := json.Marshal(msg)
+	require.NoError(t, err)
+	require.NoError(t, conn.EscreverMensagem(websocket.MensagemTexto, payload, time.Now().Add(time.Second)))
+}
+
+func receberWS(t *testing.T, conn *websocket.Conn) respostaWS {
+	t.Helper()
+	_, payload, err := conn.LerMensagem()
+	require.NoError(t, err)
+	var resp respostaWS
+	require.NoError(t, json.Unmarshal(payload, &resp))
+	return resp
+}
+
+func TestWebSocket_QuandoVotoAceito_DeveResponderAckComVotoID(t *testing.T) {
+	conn, mockService := setupWebSocket(t, ConfigWebSocket{})
+
+	mockService.On("RegistrarVoto", mock.Anything, mock.MatchedBy(func(v domain.Voto) bool {
+		return v.ParedaoID == "par-1" && v.ParticipanteID == "p1" && v.UserAgent == "app-movel" && v.OrigemIP == "127.0.0.1"
+	})).Return(domain.ResultadoVoto{VotoID: "voto-123"}, nil)
+
+	enviarWS(t, conn, mensagemWS{Tipo: wsVotar, Ref: "r1", ParedaoID: "par-1", ParticipanteID: "p1"})
+	resp := receberWS(t, conn)
+
+	assert.Equal(t, wsAck, resp.Tipo)
+	assert.Equal(t, "r1", resp.Ref)
+	assert.Equal(t, domain.VotoID("voto-123"), resp.VotoID)
+}
+
+func TestWebSocket_QuandoAntifraudeRecusa_DeveResponderErroComStatus(t *testing.T) {
+	conn, mockService := setupWebSocket(t, ConfigWebSocket{})
+
+	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(domain.ResultadoVoto{}, antifraude.ErrRateLimitExceeded)
+
+	enviarWS(t, conn, mensagemWS{Tipo: wsVotar, Ref: "r2", ParedaoID: "par-1", ParticipanteID: "p1"})
+	resp := receberWS(t, conn)
+
+	assert.Equal(t, wsErro, resp.Tipo)
+	assert.Equal(t, "r2", resp.Ref)
+	assert.Equal(t, "rate_limited", resp.Status)
+	assert.Equal(t, "RATE_LIMITED", resp.Codigo)
+	assert.Empty(t, resp.VotoID)
+}
+
+func TestWebSocket_QuandoErroInterno_NaoDeveVazarCausa(t *testing.T) {
+	conn, mockService := setupWebSocket(t, ConfigWebSocket{})
+
+	mockService.On("RegistrarVoto", mock.Anything, mock.Anything).Return(domain.ResultadoVoto{}, errors.New("gorm votos: inserir: conexao recusada"))
+
+	enviarWS(t, conn, mensagemWS{Tipo: wsVotar, Ref: "r3", ParedaoID: "par-1", ParticipanteID: "p1"})
+	resp := receberWS(t, conn)
+
+	assert.Equal(t, wsErro, resp.Tipo)
+	assert.Equal(t, "ERRO_INTERNO", resp.Codigo)
+	assert.Equal(t, "erro interno", resp.Erro)
+}
+
+func TestWebSocket_QuandoAssina_DeveReceberParciais(t *testing.T) {
+	conn, mockService := setupWebSocket(t, ConfigWebSocket{})
+
+	parciais := []domain.Parcial{{ParedaoID: "par-1", ParticipanteID: "p1", Total: 42, Percentual: 100}}
+	mockService.On("ParciaisPublicas", mock.Anything, domain.ParedaoID("par-1")).Return(parciais, domain.Publicacao{Modo: domain.VisibilidadePublica}, nil)
+
+	enviarWS(t, conn, mensagemWS{Tipo: wsAssinar, Ref: "a1", ParedaoID: "par-1"})
+
+	resp := receberWS(t, conn)
+	assert.Equal(t, wsAssinado, resp.Tipo)
+	assert.Equal(t, "a1", resp.Ref)
+
+	resp = receberWS(t, conn)
+	require.Equal(t, wsParciais, resp.Tipo)
+	var snapshot live.Snapshot
+	require.NoError(t, json.Unmarshal(resp.Dados, &snapshot))
+	assert.Equal(t, int64(42), snapshot.TotalVotos)
+
+	enviarWS(t, conn, mensagemWS{Tipo: wsCancelar, ParedaoID: "par-1"})
+	assert.Equal(t, wsCancelado, receberWS(t, conn).Tipo)
+}
+
+func TestWebSocket_QuandoExcedeTaxaDeMensagens_DeveRecusarEFechar(t *testing.T) {
+	conn, _ := setupWebSocket(t, ConfigWebSocket{MensagensPorSegundo: 0.001, Rajada: 2})
+
+	for range 3 {
+		enviarWS(t, conn, mensagemWS{Tipo: "desconhecido"})
+	}
+	assert.Equal(t, "invalid", receberWS(t, conn).Status)
+	assert.Equal(t, "invalid", receberWS(t, conn).Status)
+	assert.Equal(t, "rate_limited", receberWS(t, conn).Status)
+
+	enviarWS(t, conn, mensagemWS{Tipo: "desconhecido"})
+	_, _, err := conn.LerMensagem()
+	var fechamento *websocket.ErroFechamento
+	require.ErrorAs(t, err, &fechamento)
+	assert.Equal(t, websocket.FechamentoViolacao, fechamento.Codigo)
+}
+
+func TestWebSocket_QuandoTokenDeEleitorInvalido_DeveRecusarUpgrade(t *testing.T) {
+	mockService := new(MockVotingService)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{}))
+	api := New(mockService, logger, ComWebSocket(ConfigWebSocket{}), ComVerificadorEleitor(auth.NewTokens("segredo")))
+
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Authorization", "Bearer forjado.token")
+	w := httptest.NewRecorder()
+	api.handleWebSocket(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestWebSocket_QuandoOrigemDeOutroSite_DeveRecusarUpgrade(t *testing.T) {
+	mockService := new(MockVotingService)
+	logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, &slog.HandlerOptions{}))
+	api := New(mockService, logger, ComWebSocket(ConfigWebSocket{Origens: []string{"https://gshow.globo.com"}}))
+
+	for origem, status := range map[string]int{"https://malicioso.example": http.StatusForbidden, "https://gshow.globo.com": http.StatusInternalServerError} {
+		req := httptest.NewRequest(http.MethodGet, "http://api.bbb/ws", nil)
+		req.Header.Set("Connection", "Upgrade")
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Sec-WebSocket-Version", "13")
+		req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		req.Header.Set("Origin", origem)
+		w := httptest.NewRecorder()
+		api.handleWebSocket(w, req)
+
+		// A origem permitida passa da verificação e só falha no hijack, que o ResponseRecorder não suporta.
+		assert.Equal(t, status, w.Code, origem)
+	}
+}
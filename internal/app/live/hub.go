@@ -0,0 +1,335 @@
+// Pacote live distribui as parciais ao vivo para conexões de streaming: cada paredão acompanhado tem um
+// único laço que calcula o snapshot por ciclo e o repassa a todos os assinantes.
+package live
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/logger"
+	"github.com/marcelojr/desafio-globo/internal/platform/metrics"
+)
+
+// ErrLotado indica que a instância já atingiu o limite de conexões de streaming.
+var ErrLotado = errors.New("live: limite de conexoes atingido")
+
+// Fonte calcula as parciais publicadas de um paredão; *voting.Service é a implementação usada em produção.
+type Fonte interface {
+	ParciaisPublicas(ctx context.Context, id domain.ParedaoID) ([]domain.Parcial, domain.Publicacao, error)
+}
+
+// Snapshot é o estado das parciais enviado aos assinantes. Com as parciais ocultas, só Publicacao vem preenchida.
+type Snapshot struct {
+	ParedaoID  domain.ParedaoID  `json:"paredao_id"`
+	TotalVotos int64             `json:"total_votos"`
+	Parciais   []domain.Parcial  `json:"parciais,omitempty"`
+	Publicacao domain.Publicacao `json:"publicacao"`
+	GeradoEm   time.Time         `json:"gerado_em"`
+}
+
+// Config ajusta o hub; valores zerados usam os padrões.
+type Config struct {
+	// Cadencia é o intervalo entre dois cálculos do snapshot de um paredão.
+	Cadencia time.Duration
+	// MaxConexoes limita as assinaturas simultâneas da instância; zero desliga o limite.
+	MaxConexoes int
+	// Revalidacao é o maior intervalo sem recalcular um paredão enquanto os avisos do worker estão ativos;
+	// cobre mudanças que não passam pelo worker, como a visibilidade alterada pelo admin.
+	Revalidacao time.Duration
+}
+
+const (
+	cadenciaPadrao    = time.Second
+	revalidacaoPadrao = 30 * time.Second
+
+	esperaReconexaoMin = time.Second
+	esperaReconexaoMax = 30 * time.Second
+)
+
+// Hub mantém um tópico por paredão com assinantes; o tópico nasce com o primeiro e morre com o último.
+// Sem avisos o hub recalcula cada paredão a cada ciclo (polling); com os avisos do worker ativos, só
+// recalcula quando o paredão foi avisado ou passou o intervalo de revalidação.
+type Hub struct {
+	fonte        Fonte
+	cadencia     time.Duration
+	revalidacao  time.Duration
+	maxConexoes  int64
+	conexoes     atomic.Int64
+	avisosAtivos atomic.Bool
+
+	mu      sync.Mutex
+	topicos map[domain.ParedaoID]*topico
+}
+
+func NewHub(fonte Fonte, cfg Config) *Hub {
+	if cfg.Cadencia <= 0 {
+		cfg.Cadencia = cadenciaPadrao
+	}
+	if cfg.Revalidacao <= 0 {
+		cfg.Revalidacao = revalidacaoPadrao
+	}
+	return &Hub{
+		fonte:       fonte,
+		cadencia:    cfg.Cadencia,
+		revalidacao: cfg.Revalidacao,
+		maxConexoes: int64(cfg.MaxConexoes),
+		topicos:     make(map[domain.ParedaoID]*topico),
+	}
+}
+
+// Conexoes devolve quantas assinaturas estão abertas na instância.
+func (h *Hub) Conexoes() int {
+	return int(h.conexoes.Load())
+}
+
+// Notificar marca o paredão para ser recalculado no próximo ciclo; paredões sem assinantes são ignorados.
+func (h *Hub) Notificar(id domain.ParedaoID) {
+	h.mu.Lock()
+	t := h.topicos[id]
+	h.mu.Unlock()
+	if t != nil {
+		t.sujo.Store(true)
+	}
+}
+
+// Escutar liga o hub aos avisos do worker até ctx terminar. Enquanto a inscrição está de pé o polling é
+// suspenso; se ela cai, o hub volta a recalcular todo ciclo e tenta reconectar com espera crescente.
+func (h *Hub) Escutar(ctx context.Context, avisos domain.AvisosParciais) {
+	espera := esperaReconexaoMin
+	for {
+		err := avisos.Escutar(ctx, func() {
+			h.avisosAtivos.Store(true)
+			espera = esperaReconexaoMin
+			logger.Info("avisos de parciais conectados")
+		}, h.Notificar)
+		if h.avisosAtivos.Swap(false) {
+			// Nada garante que não perdemos avisos durante a queda: recalcula tudo no próximo ciclo.
+			h.marcarTodos()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		logger.L().Warn("avisos de parciais indisponiveis, usando polling", "err", err, "nova_tentativa", espera)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(espera):
+		}
+		espera = min(2*espera, esperaReconexaoMax)
+	}
+}
+
+func (h *Hub) marcarTodos() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, t := range h.topicos {
+		t.sujo.Store(true)
+	}
+}
+
+// Assinatura entrega em C o JSON de cada snapshot novo. O canal guarda só o mais recente: um cliente lento
+// perde estados intermediários, mas nunca atrasa os demais.
+type Assinatura struct {
+	C <-chan []byte
+
+	c       chan []byte
+	hub     *Hub
+	topico  *topico
+	cancela sync.Once
+}
+
+// Assinar registra um assinante no paredão; o snapshot atual chega em C imediatamente.
+func (h *Hub) Assinar(ctx context.Context, id domain.ParedaoID) (*Assinatura, error) {
+	if n := h.conexoes.Add(1); h.maxConexoes > 0 && n > h.maxConexoes {
+		h.conexoes.Add(-1)
+		return nil, ErrLotado
+	}
+
+	c := make(chan []byte, 1)
+	a := &Assinatura{C: c, c: c, hub: h}
+	if err := h.inscrever(ctx, id, a); err != nil {
+		h.conexoes.Add(-1)
+		return nil, err
+	}
+	metrics.SetLiveConnections(h.Conexoes())
+	return a, nil
+}
+
+// Cancelar remove a assinatura; pode ser chamado mais de uma vez.
+func (a *Assinatura) Cancelar() {
+	a.cancela.Do(func() {
+		a.hub.remover(a)
+		metrics.SetLiveConnections(int(a.hub.conexoes.Add(-1)))
+	})
+}
+
+// inscrever liga a assinatura ao tópico do paredão. O tópico novo nasce com o primeiro snapshot calculado
+// de forma síncrona, para que erros como paredão inexistente cheguem a quem assinou.
+func (h *Hub) inscrever(ctx context.Context, id domain.ParedaoID, a *Assinatura) error {
+	h.mu.Lock()
+	t, ok := h.topicos[id]
+	if ok {
+		t.adicionar(a)
+		h.mu.Unlock()
+		return nil
+	}
+	h.mu.Unlock()
+
+	snap, err := h.snapshot(ctx, id)
+	if err != nil {
+		return err
+	}
+	estado, payload, err := codificar(snap)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if t, ok := h.topicos[id]; ok {
+		t.adicionar(a)
+		return nil
+	}
+	t = &topico{
+		id:         id,
+		assinantes: make(map[*Assinatura]struct{}),
+		ultimo:     payload,
+		estado:     estado,
+		parar:      make(chan struct{}),
+	}
+	h.topicos[id] = t
+	t.adicionar(a)
+	go h.rodar(t)
+	return nil
+}
+
+func (h *Hub) remover(a *Assinatura) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	t := a.topico
+	t.mu.Lock()
+	delete(t.assinantes, a)
+	vazio := len(t.assinantes) == 0
+	t.mu.Unlock()
+	if vazio && h.topicos[t.id] == t {
+		delete(h.topicos, t.id)
+		close(t.parar)
+	}
+}
+
+// rodar recalcula o snapshot a cada ciclo e só o repassa quando algo mudou.
+func (h *Hub) rodar(t *topico) {
+	ticker := time.NewTicker(h.cadencia)
+	defer ticker.Stop()
+	calculadoEm := time.Now()
+
+	for {
+		select {
+		case <-t.parar:
+			return
+		case <-ticker.C:
+		}
+
+		sujo := t.sujo.Swap(false)
+		if h.avisosAtivos.Load() && !sujo && time.Since(calculadoEm) < h.revalidacao {
+			continue
+		}
+		calculadoEm = time.Now()
+
+		ctx, cancel := context.WithTimeout(context.Background(), h.cadencia)
+		snap, err := h.snapshot(ctx, t.id)
+		cancel()
+		if err != nil {
+			logger.L().Warn("falha ao calcular parciais ao vivo", "paredao", t.id, "err", err)
+			continue
+		}
+		estado, payload, err := codificar(snap)
+		if err != nil {
+			logger.L().Warn("falha ao serializar parciais ao vivo", "paredao", t.id, "err", err)
+			continue
+		}
+		t.publicar(estado, payload)
+	}
+}
+
+func (h *Hub) snapshot(ctx context.Context, id domain.ParedaoID) (Snapshot, error) {
+	parciais, publicacao, err := h.fonte.ParciaisPublicas(ctx, id)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	s := Snapshot{ParedaoID: id, Parciais: parciais, Publicacao: publicacao}
+	for _, parcial := range parciais {
+		s.TotalVotos += parcial.Total
+	}
+	return s, nil
+}
+
+// codificar devolve o estado (snapshot sem carimbo de tempo, usado para detectar mudanças reais) e o
+// payload enviado aos assinantes.
+func codificar(s Snapshot) (estado, payload []byte, err error) {
+	estado, err = json.Marshal(s)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.GeradoEm = time.Now().UTC()
+	payload, err = json.Marshal(s)
+	return estado, payload, err
+}
+
+type topico struct {
+	id    domain.ParedaoID
+	parar chan struct{}
+	sujo  atomic.Bool
+
+	mu         sync.Mutex
+	assinantes map[*Assinatura]struct{}
+	ultimo     []byte
+	estado     []byte
+}
+
+// adicionar registra a assinatura e já lhe entrega o snapshot mais recente.
+func (t *topico) adicionar(a *Assinatura) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	a.topico = t
+	t.assinantes[a] = struct{}{}
+	if t.ultimo != nil {
+		entregar(a.c, t.ultimo)
+	}
+}
+
+func (t *topico) publicar(estado, payload []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if bytes.Equal(estado, t.estado) {
+		return
+	}
+	t.estado = estado
+	t.ultimo = payload
+	for a := range t.assinantes {
+		entregar(a.c, payload)
+	}
+}
+
+// entregar troca o snapshot pendente pelo novo quando o assinante ainda não consumiu o anterior.
+func entregar(c chan []byte, payload []byte) {
+	for {
+		select {
+		case c <- payload:
+			return
+		default:
+		}
+		select {
+		case <-c:
+		default:
+		}
+	}
+}
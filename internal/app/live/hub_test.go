@@ -0,0 +1,195 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// fonteContadora devolve sempre o total atual e conta quantas vezes foi consultada.
+type fonteContadora struct {
+	mu        sync.Mutex
+	total     int64
+	consultas atomic.Int64
+	err       error
+}
+
+func (f *fonteContadora) ParciaisPublicas(_ context.Context, id domain.ParedaoID) ([]domain.Parcial, domain.Publicacao, error) {
+	f.consultas.Add(1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return nil, domain.Publicacao{}, f.err
+	}
+	return []domain.Parcial{{ParedaoID: id, ParticipanteID: "p1", Total: f.total}}, domain.Publicacao{Modo: domain.VisibilidadePublica}, nil
+}
+
+func (f *fonteContadora) definirTotal(n int64) {
+	f.mu.Lock()
+	f.total = n
+	f.mu.Unlock()
+}
+
+func receber(t *testing.T, a *Assinatura) Snapshot {
+	t.Helper()
+	select {
+	case payload := <-a.C:
+		var s Snapshot
+		require.NoError(t, json.Unmarshal(payload, &s))
+		return s
+	case <-time.After(time.Second):
+		t.Fatal("snapshot nao chegou")
+		return Snapshot{}
+	}
+}
+
+func TestHubCompartilhaSnapshotEntreAssinantes(t *testing.T) {
+	fonte := &fonteContadora{total: 10}
+	hub := NewHub(fonte, Config{Cadencia: 20 * time.Millisecond})
+
+	assinaturas := make([]*Assinatura, 50)
+	for i := range assinaturas {
+		a, err := hub.Assinar(context.Background(), "par-1")
+		require.NoError(t, err)
+		defer a.Cancelar()
+		assinaturas[i] = a
+		assert.Equal(t, int64(10), receber(t, a).TotalVotos)
+	}
+	// Só o primeiro assinante calcula o snapshot inicial; os demais reaproveitam o do tópico.
+	assert.Equal(t, int64(1), fonte.consultas.Load())
+
+	fonte.definirTotal(11)
+	for _, a := range assinaturas {
+		assert.Equal(t, int64(11), receber(t, a).TotalVotos)
+	}
+	ciclos := fonte.consultas.Load()
+	assert.Less(t, ciclos, int64(len(assinaturas)), "o snapshot deveria ser calculado por ciclo, nao por cliente")
+}
+
+func TestHubNaoRepeteSnapshotSemMudanca(t *testing.T) {
+	fonte := &fonteContadora{total: 3}
+	hub := NewHub(fonte, Config{Cadencia: 10 * time.Millisecond})
+
+	a, err := hub.Assinar(context.Background(), "par-1")
+	require.NoError(t, err)
+	defer a.Cancelar()
+	receber(t, a)
+
+	time.Sleep(60 * time.Millisecond)
+	select {
+	case <-a.C:
+		t.Fatal("nao deveria enviar snapshot identico")
+	default:
+	}
+	assert.Greater(t, fonte.consultas.Load(), int64(1))
+}
+
+func TestHubLimitaConexoes(t *testing.T) {
+	hub := NewHub(&fonteContadora{}, Config{Cadencia: time.Hour, MaxConexoes: 2})
+
+	a1, err := hub.Assinar(context.Background(), "par-1")
+	require.NoError(t, err)
+	a2, err := hub.Assinar(context.Background(), "par-2")
+	require.NoError(t, err)
+
+	_, err = hub.Assinar(context.Background(), "par-1")
+	assert.ErrorIs(t, err, ErrLotado)
+	assert.Equal(t, 2, hub.Conexoes())
+
+	a1.Cancelar()
+	a1.Cancelar()
+	assert.Equal(t, 1, hub.Conexoes())
+
+	a3, err := hub.Assinar(context.Background(), "par-1")
+	require.NoError(t, err)
+	a2.Cancelar()
+	a3.Cancelar()
+	assert.Equal(t, 0, hub.Conexoes())
+}
+
+func TestHubEncerraTopicoSemAssinantes(t *testing.T) {
+	fonte := &fonteContadora{}
+	hub := NewHub(fonte, Config{Cadencia: 5 * time.Millisecond})
+
+	a, err := hub.Assinar(context.Background(), "par-1")
+	require.NoError(t, err)
+	a.Cancelar()
+
+	hub.mu.Lock()
+	assert.Empty(t, hub.topicos)
+	hub.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+	consultas := fonte.consultas.Load()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, consultas, fonte.consultas.Load(), "o laço do paredão deveria ter parado")
+}
+
+func TestHubPropagaErroDaFonte(t *testing.T) {
+	erro := errors.New("paredao inexistente")
+	hub := NewHub(&fonteContadora{err: erro}, Config{MaxConexoes: 1})
+
+	_, err := hub.Assinar(context.Background(), "par-x")
+	assert.ErrorIs(t, err, erro)
+	assert.Equal(t, 0, hub.Conexoes())
+}
+
+// avisosManuais simula o canal do worker: fica conectado até ctx terminar ou queda ser fechado.
+type avisosManuais struct {
+	receber chan domain.ParedaoID
+	queda   chan struct{}
+}
+
+func (a *avisosManuais) Publicar(context.Context, domain.ParedaoID) error { return nil }
+
+func (a *avisosManuais) Escutar(ctx context.Context, conectado func(), receber func(domain.ParedaoID)) error {
+	conectado()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-a.queda:
+			return errors.New("conexao perdida")
+		case id := <-a.receber:
+			receber(id)
+		}
+	}
+}
+
+func TestHubComAvisosSoRecalculaParedaoAvisado(t *testing.T) {
+	fonte := &fonteContadora{total: 1}
+	hub := NewHub(fonte, Config{Cadencia: 5 * time.Millisecond, Revalidacao: time.Hour})
+	avisos := &avisosManuais{receber: make(chan domain.ParedaoID), queda: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Escutar(ctx, avisos)
+	require.Eventually(t, hub.avisosAtivos.Load, time.Second, time.Millisecond)
+
+	a, err := hub.Assinar(context.Background(), "par-1")
+	require.NoError(t, err)
+	defer a.Cancelar()
+	receber(t, a)
+
+	fonte.definirTotal(2)
+	time.Sleep(40 * time.Millisecond)
+	assert.Equal(t, int64(1), fonte.consultas.Load(), "sem aviso o paredao nao deveria ser recalculado")
+
+	avisos.receber <- "par-1"
+	assert.Equal(t, int64(2), receber(t, a).TotalVotos)
+
+	// Com o canal fora do ar, o hub volta ao polling.
+	close(avisos.queda)
+	require.Eventually(t, func() bool { return !hub.avisosAtivos.Load() }, time.Second, time.Millisecond)
+	fonte.definirTotal(3)
+	assert.Equal(t, int64(3), receber(t, a).TotalVotos)
+}
@@ -0,0 +1,271 @@
+package voting
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+const (
+	// janelaProjecao é quantos intervalos recentes da série definem o ritmo e a divisão dos votos que faltam.
+	janelaProjecao = 6
+	// confiancaProjecao e zProjecao dão a faixa de 90% pela aproximação normal.
+	confiancaProjecao = 0.9
+	zProjecao         = 1.645
+)
+
+// ProjecaoFinal estima a participação de cada participante no fim da votação. É uma estimativa para a
+// produção, sem política de visibilidade: não deve ir para as rotas públicas.
+func (s *Service) ProjecaoFinal(ctx context.Context, paredaoID domain.ParedaoID, intervalo time.Duration) (domain.Projecao, error) {
+	if intervalo <= 0 {
+		return domain.Projecao{}, ErrIntervaloInvalido
+	}
+	paredao, err := s.paredoes.FindByID(ctx, paredaoID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Projecao{}, ErrParedaoNaoEncontrado
+		}
+		return domain.Projecao{}, err
+	}
+	participantes, err := s.participantes.ListByParedao(ctx, paredaoID)
+	if err != nil {
+		return domain.Projecao{}, err
+	}
+	parciais, err := s.votos.TotalPorIntervalo(ctx, paredaoID, intervalo)
+	if err != nil {
+		return domain.Projecao{}, err
+	}
+	return projetar(paredao, participantes, parciais, intervalo, s.clock.Agora()), nil
+}
+
+// projetar segue a mesma regra das parciais oficiais: cada urna é projetada à parte, só com os votos de
+// quem segue na disputa, e as participações projetadas são combinadas com os pesos efetivos das urnas
+// que já têm votos. Descartados ficam fora; os demais retirados aparecem zerados. A faixa combinada soma
+// os extremos de cada urna, como se todas errassem para o mesmo lado.
+func projetar(paredao domain.Paredao, participantes []domain.Participante, parciais []domain.ParcialIntervalo, intervalo time.Duration, agora time.Time) domain.Projecao {
+	projecao := domain.Projecao{
+		ParedaoID:   paredao.ID,
+		Estimativa:  true,
+		CalculadaEm: agora,
+		Fim:         paredao.Fim,
+		Confianca:   confiancaProjecao,
+	}
+
+	emDisputa := make(map[domain.ParticipanteID]bool, len(participantes))
+	for _, part := range participantes {
+		if !part.Retirado() {
+			emDisputa[part.ID] = true
+		}
+	}
+	// Votos antigos, gravados antes das modalidades, contam como torcida.
+	porUrna := make(map[domain.Modalidade][]domain.ParcialIntervalo)
+	for _, p := range parciais {
+		if !emDisputa[p.ParticipanteID] {
+			continue
+		}
+		m := p.Modalidade
+		if m == "" {
+			m = domain.ModalidadeTorcida
+		}
+		porUrna[m] = append(porUrna[m], p)
+	}
+
+	pesos := paredao.PesosEfetivos()
+	var urnas []urnaProjetada
+	pesoAtivo := 0.0
+	for _, m := range []domain.Modalidade{domain.ModalidadeUnico, domain.ModalidadeTorcida} {
+		if pesos[m] <= 0 && len(porUrna[m]) == 0 {
+			continue
+		}
+		urna := projetarUrna(paredao, montarSerie(paredao.ID, participantes, porUrna[m], intervalo), intervalo, agora)
+		urna.peso = pesos[m]
+		if urna.votosAtuais > 0 {
+			pesoAtivo += urna.peso
+		}
+		projecao.VotosAtuais += urna.votosAtuais
+		projecao.VotosProjetados += urna.votosProjetados
+		urnas = append(urnas, urna)
+	}
+
+	for _, part := range participantes {
+		if part.Retirada == domain.RetiradaDescartar {
+			continue
+		}
+		projecao.Participantes = append(projecao.Participantes, domain.ProjecaoParticipante{ParticipanteID: part.ID})
+	}
+	if pesoAtivo <= 0 {
+		return projecao
+	}
+	for _, urna := range urnas {
+		if urna.votosAtuais == 0 {
+			continue
+		}
+		fator := urna.peso / pesoAtivo
+		for i, p := range urna.participantes {
+			combinado := &projecao.Participantes[i]
+			combinado.Atual += p.Atual * fator
+			combinado.Projetada += p.Projetada * fator
+			combinado.Minima += p.Minima * fator
+			combinado.Maxima += p.Maxima * fator
+		}
+	}
+	return projecao
+}
+
+// urnaProjetada é a projeção de uma única modalidade, com participações sobre os votos dela.
+type urnaProjetada struct {
+	peso            float64
+	votosAtuais     int64
+	votosProjetados int64
+	participantes   []domain.ProjecaoParticipante
+}
+
+// projetarUrna supõe que os votos até o fim chegam no ritmo dos últimos janelaProjecao intervalos e se
+// dividem como os votos desses intervalos. A faixa combina a incerteza da divisão (a maior entre a
+// variação de intervalo para intervalo e o erro binomial) com a do ritmo, e sai do pior e do melhor caso
+// das duas. Sem votos recentes, ou depois do fim, a projeção é a participação atual.
+func projetarUrna(paredao domain.Paredao, series []domain.SerieParticipante, intervalo time.Duration, agora time.Time) urnaProjetada {
+	var urna urnaProjetada
+	if len(series) == 0 || len(series[0].Pontos) == 0 {
+		urna.participantes = make([]domain.ProjecaoParticipante, len(series))
+		return urna
+	}
+
+	pontos := series[0].Pontos
+	ultimo := len(pontos) - 1
+	var total int64
+	for _, serie := range series {
+		total += serie.Pontos[ultimo].Acumulado
+	}
+	urna.votosAtuais = total
+
+	// Intervalos da janela recente; o último costuma estar incompleto, por isso o ritmo usa a duração real.
+	corte := agora.Add(-janelaProjecao * intervalo)
+	primeiro := len(pontos)
+	for j := ultimo; j >= 0 && !pontos[j].Inicio.Before(corte); j-- {
+		primeiro = j
+	}
+	recentes := make([]int64, len(series))
+	volumes := make([]float64, 0, len(pontos)-primeiro)
+	var votosRecentes int64
+	for j := primeiro; j < len(pontos); j++ {
+		var volume int64
+		for i, serie := range series {
+			recentes[i] += serie.Pontos[j].Votos
+			volume += serie.Pontos[j].Votos
+		}
+		votosRecentes += volume
+		volumes = append(volumes, float64(volume)/duracaoIntervalo(pontos, j, paredao.Inicio, agora).Seconds())
+	}
+
+	restante := paredao.Fim.Sub(agora)
+	var faltam, faltamMin, faltamMax float64
+	if restante > 0 && votosRecentes > 0 {
+		inicioJanela := pontos[primeiro].Inicio
+		if inicioJanela.Before(paredao.Inicio) {
+			inicioJanela = paredao.Inicio
+		}
+		if duracao := agora.Sub(inicioJanela); duracao > 0 {
+			faltam = float64(votosRecentes) / duracao.Seconds() * restante.Seconds()
+		}
+		// Erro relativo do ritmo: a variação entre intervalos, ou o de Poisson quando ela é menor.
+		erro := math.Max(desvioRelativo(volumes), 1/math.Sqrt(float64(votosRecentes)))
+		faltamMin = math.Max(0, faltam*(1-zProjecao*erro))
+		faltamMax = faltam * (1 + zProjecao*erro)
+	}
+	urna.votosProjetados = total + int64(math.Round(faltam))
+
+	final := func(acumulado int64, divisao, faltam float64) float64 {
+		if float64(total)+faltam == 0 {
+			return 0
+		}
+		return 100 * (float64(acumulado) + divisao*faltam) / (float64(total) + faltam)
+	}
+	for i, serie := range series {
+		acumulado := serie.Pontos[ultimo].Acumulado
+		p := domain.ProjecaoParticipante{ParticipanteID: serie.ParticipanteID}
+		if total > 0 {
+			p.Atual = 100 * float64(acumulado) / float64(total)
+		}
+		divisao := p.Atual / 100
+		var erro float64
+		if votosRecentes > 0 {
+			divisao = float64(recentes[i]) / float64(votosRecentes)
+			erro = math.Max(desvioDivisao(serie.Pontos[primeiro:], series, primeiro), math.Sqrt(divisao*(1-divisao)/float64(votosRecentes)))
+		}
+		divisaoMin := math.Max(0, divisao-zProjecao*erro)
+		divisaoMax := math.Min(1, divisao+zProjecao*erro)
+
+		p.Projetada = final(acumulado, divisao, faltam)
+		p.Minima = math.Min(final(acumulado, divisaoMin, faltamMin), final(acumulado, divisaoMin, faltamMax))
+		p.Maxima = math.Max(final(acumulado, divisaoMax, faltamMin), final(acumulado, divisaoMax, faltamMax))
+		urna.participantes = append(urna.participantes, p)
+	}
+	return urna
+}
+
+// duracaoIntervalo é quanto do intervalo j já passou dentro da votação: o último termina em agora e o
+// primeiro pode ter começado antes da abertura.
+func duracaoIntervalo(pontos []domain.PontoSerie, j int, abertura, agora time.Time) time.Duration {
+	inicio := pontos[j].Inicio
+	if inicio.Before(abertura) {
+		inicio = abertura
+	}
+	fim := agora
+	if j+1 < len(pontos) {
+		fim = pontos[j+1].Inicio
+	}
+	if d := fim.Sub(inicio); d > time.Second {
+		return d
+	}
+	return time.Second
+}
+
+// desvioRelativo é o erro padrão relativo da média: desvio das amostras sobre a média, dividido por √n.
+func desvioRelativo(amostras []float64) float64 {
+	if len(amostras) < 2 {
+		return 0
+	}
+	var soma float64
+	for _, a := range amostras {
+		soma += a
+	}
+	media := soma / float64(len(amostras))
+	if media == 0 {
+		return 0
+	}
+	return desvio(amostras, media) / media / math.Sqrt(float64(len(amostras)))
+}
+
+// desvioDivisao é o erro padrão da divisão dos votos do participante entre os intervalos recentes com votos.
+func desvioDivisao(pontos []domain.PontoSerie, series []domain.SerieParticipante, primeiro int) float64 {
+	var divisoes []float64
+	for j, ponto := range pontos {
+		var volume int64
+		for _, serie := range series {
+			volume += serie.Pontos[primeiro+j].Votos
+		}
+		if volume > 0 {
+			divisoes = append(divisoes, float64(ponto.Votos)/float64(volume))
+		}
+	}
+	if len(divisoes) < 2 {
+		return 0
+	}
+	var soma float64
+	for _, d := range divisoes {
+		soma += d
+	}
+	return desvio(divisoes, soma/float64(len(divisoes))) / math.Sqrt(float64(len(divisoes)))
+}
+
+func desvio(amostras []float64, media float64) float64 {
+	var quadrados float64
+	for _, a := range amostras {
+		quadrados += (a - media) * (a - media)
+	}
+	return math.Sqrt(quadrados / float64(len(amostras)-1))
+}
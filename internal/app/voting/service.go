@@ -5,6 +5,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/marcelojr/desafio-globo/internal/domain"
 	"github.com/marcelojr/desafio-globo/internal/platform/ids"
@@ -15,8 +19,21 @@ var (
 	ErrPeriodoEncerrado         = errors.New("paredao encerrado")
 	ErrParticipanteDesconhecido = errors.New("participante nao encontrado")
 	ErrParedaoNaoEncontrado     = errors.New("paredao nao encontrado")
+	ErrModalidadeInvalida       = errors.New("modalidade de voto nao aceita neste paredao")
+	ErrEleitorObrigatorio       = errors.New("voto unico exige eleitor autenticado")
+	ErrVotoJaRegistrado         = errors.New("eleitor ja votou neste paredao")
+	ErrParticipanteRetirado     = errors.New("participante retirado do paredao")
+	ErrVotoNaoEncontrado        = errors.New("voto nao encontrado")
+	ErrRecibosDesabilitados     = errors.New("emissao de recibos desabilitada")
+	ErrAuditoriaDesabilitada    = errors.New("cadeia de auditoria indisponivel")
+	ErrViradasDesabilitadas     = errors.New("registro de viradas indisponivel")
+	ErrParedaoAberto            = errors.New("paredao ainda nao foi finalizado")
+	ErrIntervaloInvalido        = errors.New("intervalo da serie nao suportado (use 1m, 5m, 15m ou 1h)")
 )
 
+// margemReservaVotoUnico mantém a reserva no Redis além do fim do paredão para cobrir votos ainda na fila.
+const margemReservaVotoUnico = 24 * time.Hour
+
 // Service concentra as regras de votação e delega acesso a repositórios/fila.
 type Service struct {
 	paredoes      domain.ParedaoRepository
@@ -27,6 +44,70 @@ type Service struct {
 	antifraude    domain.Antifraude
 	clock         domain.Clock
 	ids           *ids.Generator
+	reservas      domain.ReservaVotoUnico
+	status        domain.StatusVotos
+	recibos       domain.AssinadorRecibos
+	auditoria     domain.CadeiaAuditoria
+	pseudonimos   domain.Pseudonimizador
+	politicas     domain.PoliticasAntifraude
+	viradas       domain.ViradaRepository
+	viradasMin    int64
+	webhooks      domain.WebhookRepository
+}
+
+// Option ajusta dependências opcionais do Service.
+type Option func(*Service)
+
+// ComReservaVotoUnico barra votos únicos repetidos já na API; sem ela, só o índice do Postgres (no worker) impede a duplicidade.
+func ComReservaVotoUnico(reservas domain.ReservaVotoUnico) Option {
+	return func(s *Service) {
+		s.reservas = reservas
+	}
+}
+
+// ComStatusVotos registra o andamento de cada voto enfileirado para consulta em StatusVoto.
+func ComStatusVotos(status domain.StatusVotos) Option {
+	return func(s *Service) {
+		s.status = status
+	}
+}
+
+// ComRecibos entrega um recibo assinado a cada voto aceito e habilita VerificarRecibo.
+func ComRecibos(assinador domain.AssinadorRecibos) Option {
+	return func(s *Service) {
+		s.recibos = assinador
+	}
+}
+
+// ComAuditoria expõe os checkpoints assinados e a exportação da cadeia de hashes dos votos.
+func ComAuditoria(cadeia domain.CadeiaAuditoria) Option {
+	return func(s *Service) {
+		s.auditoria = cadeia
+	}
+}
+
+// ComPseudonimos inclui nas exportações de auditoria o pseudônimo do IP de origem; sem ele o IP é omitido.
+func ComPseudonimos(pseudonimos domain.Pseudonimizador) Option {
+	return func(s *Service) {
+		s.pseudonimos = pseudonimos
+	}
+}
+
+// ComPoliticas dá acesso à política antifraude efetiva, para as telas saberem quando pedir CAPTCHA, e
+// descarta o cache dela quando o admin a altera.
+func ComPoliticas(politicas domain.PoliticasAntifraude) Option {
+	return func(s *Service) {
+		s.politicas = politicas
+	}
+}
+
+// ComViradas registra as trocas de liderança encontradas por DetectarViradas e habilita o feed de viradas.
+// Enquanto o paredão tiver menos de minVotos votos, a ponta oscila demais e não é acompanhada.
+func ComViradas(viradas domain.ViradaRepository, minVotos int64) Option {
+	return func(s *Service) {
+		s.viradas = viradas
+		s.viradasMin = minVotos
+	}
 }
 
 func NewService(
@@ -38,11 +119,12 @@ func NewService(
 	antifraude domain.Antifraude,
 	clock domain.Clock,
 	idsGen *ids.Generator,
+	opts ...Option,
 ) *Service {
 	if idsGen == nil {
 		idsGen = ids.DefaultGenerator()
 	}
-	return &Service{
+	s := &Service{
 		paredoes:      paredoes,
 		participantes: participantes,
 		votos:         votos,
@@ -52,6 +134,10 @@ func NewService(
 		clock:         clock,
 		ids:           idsGen,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // CriarParedao centraliza a validação e a criação das entidades principais dentro de uma única transação lógica.
@@ -62,6 +148,12 @@ func (s *Service) CriarParedao(ctx context.Context, p domain.Paredao, participan
 	agora := s.clock.Agora()
 
 	p.ID = domain.ParedaoID(s.ids.New())
+	if p.ModoVotacao == "" {
+		p.ModoVotacao = domain.ModoTorcida
+	}
+	if p.Polaridade == "" {
+		p.Polaridade = domain.PolaridadeEliminar
+	}
 	if p.Inicio.IsZero() {
 		p.Inicio = agora
 	}
@@ -76,6 +168,7 @@ func (s *Service) CriarParedao(ctx context.Context, p domain.Paredao, participan
 	for i, part := range participantes {
 		part.ID = domain.ParticipanteID(s.ids.New())
 		part.ParedaoID = p.ID
+		part.OrigemID = part.ID
 		part.CriadoEm = agora
 		part.AtualizadoEm = agora
 		participantesCriados[i] = part
@@ -93,6 +186,85 @@ func (s *Service) CriarParedao(ctx context.Context, p domain.Paredao, participan
 	return p, nil
 }
 
+// CriarEtapas cria um paredão em várias etapas (por exemplo, bate-volta seguido do paredão principal).
+// Só a primeira etapa nasce com participantes e ativa; as seguintes recebem os remanescentes quando a
+// anterior é finalizada. Toda etapa intermediária é uma votação para salvar em que o mais votado escapa.
+func (s *Service) CriarEtapas(ctx context.Context, etapas []domain.Paredao, participantes []domain.Participante) ([]domain.Paredao, error) {
+	if len(etapas) == 1 {
+		p, err := s.CriarParedao(ctx, etapas[0], participantes)
+		if err != nil {
+			return nil, err
+		}
+		return []domain.Paredao{p}, nil
+	}
+	if err := validarEtapas(etapas, participantes); err != nil {
+		return nil, err
+	}
+
+	agora := s.clock.Agora()
+	grupo := domain.ParedaoID(s.ids.New())
+	criadas := make([]domain.Paredao, len(etapas))
+	for i, etapa := range etapas {
+		etapa.ID = grupo
+		if i > 0 {
+			etapa.ID = domain.ParedaoID(s.ids.New())
+		}
+		etapa.GrupoID = grupo
+		etapa.Etapa = i + 1
+		if etapa.ModoVotacao == "" {
+			etapa.ModoVotacao = domain.ModoTorcida
+		}
+		if etapa.Polaridade == "" {
+			etapa.Polaridade = domain.PolaridadeEliminar
+			if i < len(etapas)-1 {
+				etapa.Polaridade = domain.PolaridadeSalvar
+			}
+		}
+		if etapa.Inicio.IsZero() {
+			etapa.Inicio = agora
+			if i > 0 {
+				etapa.Inicio = criadas[i-1].Fim
+			}
+		}
+		etapa.Ativo = i == 0
+		etapa.CriadoEm = agora
+		etapa.AtualizadoEm = agora
+		etapa.Participantes = nil
+		criadas[i] = etapa
+	}
+
+	primeira := make([]domain.Participante, len(participantes))
+	for i, part := range participantes {
+		part.ID = domain.ParticipanteID(s.ids.New())
+		part.ParedaoID = grupo
+		part.OrigemID = part.ID
+		part.CriadoEm = agora
+		part.AtualizadoEm = agora
+		primeira[i] = part
+	}
+	criadas[0].Participantes = primeira
+	// Etapas e participantes vão juntos numa transação: uma falha no meio não deixa grupo pela metade.
+	if err := s.paredoes.CreateEtapas(ctx, criadas); err != nil {
+		return nil, err
+	}
+	return criadas, nil
+}
+
+// Etapas devolve todas as etapas do grupo a que o paredão pertence; paredões simples são a própria etapa única.
+func (s *Service) Etapas(ctx context.Context, id domain.ParedaoID) ([]domain.Paredao, error) {
+	paredao, err := s.paredoes.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrParedaoNaoEncontrado
+		}
+		return nil, err
+	}
+	if paredao.GrupoID == "" {
+		return []domain.Paredao{paredao}, nil
+	}
+	return s.paredoes.ListByGrupo(ctx, paredao.GrupoID)
+}
+
 func (s *Service) ListarAtivos(ctx context.Context) ([]domain.Paredao, error) {
 	paredoes, err := s.paredoes.ListAtivos(ctx)
 	if err != nil {
@@ -111,44 +283,431 @@ func (s *Service) ListarAtivos(ctx context.Context) ([]domain.Paredao, error) {
 }
 
 // RegistrarVoto aplica as regras de negócio antes de delegar à fila (modo assíncrono) ou ao repositório.
-func (s *Service) RegistrarVoto(ctx context.Context, voto domain.Voto) error {
+// O resultado carrega a decisão do antifraude mesmo quando o voto é barrado pelo limite.
+func (s *Service) RegistrarVoto(ctx context.Context, voto domain.Voto) (domain.ResultadoVoto, error) {
+	var resultado domain.ResultadoVoto
+
 	if voto.ParedaoID == "" || voto.ParticipanteID == "" {
-		return ErrParticipanteDesconhecido
+		return resultado, ErrParticipanteDesconhecido
 	}
 	paredao, err := s.paredoes.FindByID(ctx, voto.ParedaoID)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
-			return ErrParedaoNaoEncontrado
+			return resultado, ErrParedaoNaoEncontrado
 		}
-		return err
+		return resultado, err
 	}
 
 	agora := s.clock.Agora()
 	if !paredao.Ativo || agora.Before(paredao.Inicio) || agora.After(paredao.Fim) {
-		return ErrPeriodoEncerrado
+		return resultado, ErrPeriodoEncerrado
 	}
 
 	participantes, err := s.participantes.ListByParedao(ctx, voto.ParedaoID)
 	if err != nil {
-		return err
+		return resultado, err
+	}
+
+	participante, ok := buscarParticipante(participantes, voto.ParticipanteID)
+	if !ok {
+		return resultado, ErrParticipanteDesconhecido
+	}
+	if participante.Retirado() {
+		return resultado, ErrParticipanteRetirado
 	}
 
-	if !participanteExiste(participantes, voto.ParticipanteID) {
-		return ErrParticipanteDesconhecido
+	modalidade, err := resolverModalidade(paredao.ModoVotacao, voto.Modalidade)
+	if err != nil {
+		return resultado, err
+	}
+	voto.Modalidade = modalidade
+	if voto.Modalidade == domain.ModalidadeUnico && voto.EleitorID == "" {
+		return resultado, ErrEleitorObrigatorio
 	}
 
 	if s.antifraude != nil {
-		if err := s.antifraude.Validar(ctx, voto); err != nil {
-			return err
+		decisao, err := s.antifraude.Validar(ctx, voto)
+		resultado.Limite = decisao
+		if err != nil {
+			return resultado, err
+		}
+	}
+
+	reservado := false
+	if voto.Modalidade == domain.ModalidadeUnico && s.reservas != nil {
+		ok, err := s.reservas.Reservar(ctx, voto.ParedaoID, voto.EleitorID, paredao.Fim.Sub(agora)+margemReservaVotoUnico)
+		if err != nil {
+			return resultado, err
 		}
+		if !ok {
+			return resultado, ErrVotoJaRegistrado
+		}
+		reservado = true
 	}
 
 	voto.ID = domain.VotoID(s.ids.New())
 	voto.CriadoEm = agora
 
+	if err := s.persistir(ctx, voto); err != nil {
+		if errors.Is(err, domain.ErrVotoDuplicado) {
+			return resultado, ErrVotoJaRegistrado
+		}
+		if reservado {
+			// O voto não foi aceito; devolvemos a reserva para a conta poder tentar de novo.
+			// Se a falha ocorreu depois do INSERT, o índice único do Postgres segue barrando a repetição.
+			if lErr := s.reservas.Liberar(ctx, voto.ParedaoID, voto.EleitorID); lErr != nil {
+				err = errors.Join(err, lErr)
+			}
+		}
+		return resultado, err
+	}
+
+	resultado.VotoID = voto.ID
+	if s.recibos != nil {
+		recibo := s.recibos.Assinar(domain.Recibo{VotoID: voto.ID, ParedaoID: voto.ParedaoID, EmitidoEm: agora})
+		resultado.Recibo = &recibo
+	}
+	return resultado, nil
+}
+
+// VerificarRecibo confere a assinatura e, sendo ela válida, se o voto foi de fato gravado e continua
+// contando: votos de paredão anulado ou de participante retirado com descarte não contam mais.
+func (s *Service) VerificarRecibo(ctx context.Context, recibo domain.Recibo) (domain.VerificacaoRecibo, error) {
+	if s.recibos == nil {
+		return domain.VerificacaoRecibo{}, ErrRecibosDesabilitados
+	}
+	var verificacao domain.VerificacaoRecibo
+	if err := s.recibos.Verificar(recibo); err != nil {
+		verificacao.Motivo = err.Error()
+		return verificacao, nil
+	}
+	verificacao.AssinaturaValida = true
+
+	voto, err := s.votos.FindByID(ctx, recibo.VotoID)
+	if err != nil {
+		if !errors.Is(err, domain.ErrNotFound) {
+			return domain.VerificacaoRecibo{}, err
+		}
+		// Ainda não gravado: o status de curta duração diz se está na fila ou se o worker o rejeitou.
+		verificacao.Motivo = "voto nao encontrado"
+		if s.status != nil {
+			if status, err := s.status.Obter(ctx, recibo.VotoID); err == nil {
+				verificacao.Estado = status.Estado
+				if status.Estado == domain.VotoEnfileirado {
+					verificacao.Motivo = "voto ainda na fila de processamento"
+				} else if status.Motivo != "" {
+					verificacao.Motivo = status.Motivo
+				}
+			}
+		}
+		return verificacao, nil
+	}
+	if voto.ParedaoID != recibo.ParedaoID {
+		verificacao.Motivo = "voto pertence a outro paredao"
+		return verificacao, nil
+	}
+	verificacao.VotoEncontrado = true
+	verificacao.Estado = domain.VotoProcessado
+
+	paredao, err := s.paredoes.FindByID(ctx, voto.ParedaoID)
+	if err != nil {
+		return domain.VerificacaoRecibo{}, err
+	}
+	if paredao.Anulado {
+		verificacao.Anulado = true
+		verificacao.Motivo = "paredao anulado"
+		return verificacao, nil
+	}
+	participantes, err := s.participantes.ListByParedao(ctx, voto.ParedaoID)
+	if err != nil {
+		return domain.VerificacaoRecibo{}, err
+	}
+	if part, ok := buscarParticipante(participantes, voto.ParticipanteID); ok && part.Retirada == domain.RetiradaDescartar {
+		verificacao.Anulado = true
+		verificacao.Motivo = "votos do participante descartados apos a retirada"
+		return verificacao, nil
+	}
+
+	verificacao.Valido = true
+	return verificacao, nil
+}
+
+// Checkpoints lista as raízes assinadas da cadeia de votos do paredão, da mais antiga para a mais recente.
+func (s *Service) Checkpoints(ctx context.Context, id domain.ParedaoID) ([]domain.Checkpoint, error) {
+	if s.auditoria == nil {
+		return nil, ErrAuditoriaDesabilitada
+	}
+	if _, err := s.paredoes.FindByID(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrParedaoNaoEncontrado
+		}
+		return nil, err
+	}
+	return s.auditoria.Checkpoints(ctx, id)
+}
+
+// DetectarViradas compara a ponta atual de cada paredão aberto com a última registrada e grava uma virada
+// quando ela muda. Empates na ponta não trocam o líder. Devolve só as viradas gravadas por esta chamada.
+func (s *Service) DetectarViradas(ctx context.Context) ([]domain.Virada, error) {
+	if s.viradas == nil {
+		return nil, ErrViradasDesabilitadas
+	}
+	paredoes, err := s.paredoes.ListAtivos(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		novas []domain.Virada
+		errs  []error
+	)
+	for _, paredao := range paredoes {
+		virada, ok, err := s.detectarVirada(ctx, paredao)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("paredao %s: %w", paredao.ID, err))
+			continue
+		}
+		if ok {
+			novas = append(novas, virada)
+		}
+	}
+	return novas, errors.Join(errs...)
+}
+
+func (s *Service) detectarVirada(ctx context.Context, paredao domain.Paredao) (domain.Virada, bool, error) {
+	resultado, _, err := s.apurar(ctx, paredao)
+	if err != nil {
+		return domain.Virada{}, false, err
+	}
+	lider := resultado.Eliminado
+	if lider == "" {
+		lider = resultado.Escapou
+	}
+	if resultado.Anulado || lider == "" || resultado.TotalVotos < s.viradasMin {
+		return domain.Virada{}, false, nil
+	}
+
+	ultima, err := s.viradas.Ultima(ctx, paredao.ID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return domain.Virada{}, false, err
+	}
+	if ultima.NovoLider == lider {
+		return domain.Virada{}, false, nil
+	}
+
+	virada := domain.Virada{
+		ParedaoID:     paredao.ID,
+		Ordem:         ultima.Ordem + 1,
+		OcorridaEm:    s.clock.Agora(),
+		LiderAnterior: ultima.NovoLider,
+		NovoLider:     lider,
+		Margem:        margemLider(resultado.Parciais, lider),
+		TotalVotos:    resultado.TotalVotos,
+	}
+	ok, err := s.viradas.Registrar(ctx, virada)
+	if err != nil || !ok {
+		return virada, false, err
+	}
+	if virada.LiderAnterior != "" {
+		err = s.publicarEvento(ctx, domain.EventoVirada, paredao.ID, strconv.Itoa(virada.Ordem), virada)
+	}
+	return virada, true, err
+}
+
+// margemLider é a distância, em pontos percentuais, entre o líder e o participante mais próximo dele.
+func margemLider(parciais []domain.Parcial, lider domain.ParticipanteID) float64 {
+	var percentual float64
+	for _, p := range parciais {
+		if p.ParticipanteID == lider {
+			percentual = p.Percentual
+		}
+	}
+	margem := math.Inf(1)
+	for _, p := range parciais {
+		if p.ParticipanteID != lider && !p.Retirado {
+			margem = math.Min(margem, math.Abs(percentual-p.Percentual))
+		}
+	}
+	if math.IsInf(margem, 1) {
+		return 0
+	}
+	return margem
+}
+
+// Viradas lista as trocas de liderança do paredão, da primeira para a última, sem política de
+// visibilidade, para a produção.
+func (s *Service) Viradas(ctx context.Context, id domain.ParedaoID) ([]domain.Virada, error) {
+	if s.viradas == nil {
+		return nil, ErrViradasDesabilitadas
+	}
+	if _, err := s.paredoes.FindByID(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrParedaoNaoEncontrado
+		}
+		return nil, err
+	}
+	return s.viradas.Listar(ctx, id)
+}
+
+// ViradasPublicas aplica ao feed de viradas a política de visibilidade: ocultas não devolvem nada e, no
+// modo atrasado, só entram as viradas até o corte.
+func (s *Service) ViradasPublicas(ctx context.Context, id domain.ParedaoID) ([]domain.Virada, domain.Publicacao, error) {
+	if s.viradas == nil {
+		return nil, domain.Publicacao{}, ErrViradasDesabilitadas
+	}
+	paredao, err := s.paredoes.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.Publicacao{}, ErrParedaoNaoEncontrado
+		}
+		return nil, domain.Publicacao{}, err
+	}
+
+	publicacao := paredao.Publicacao(s.clock.Agora())
+	if publicacao.Oculta {
+		return nil, publicacao, nil
+	}
+	viradas, err := s.viradas.Listar(ctx, id)
+	if err != nil {
+		return nil, publicacao, err
+	}
+	if !publicacao.Ate.IsZero() {
+		visiveis := viradas[:0]
+		for _, v := range viradas {
+			if !v.OcorridaEm.After(publicacao.Ate) {
+				visiveis = append(visiveis, v)
+			}
+		}
+		viradas = visiveis
+	}
+	return viradas, publicacao, nil
+}
+
+// ExportarVotos entrega os votos do paredão em ordem de cadeia para a exportação de auditoria, com o IP de
+// origem trocado pelo pseudônimo (ou omitido, sem pseudonimizador).
+func (s *Service) ExportarVotos(ctx context.Context, id domain.ParedaoID, emitir func(domain.VotoAuditado) error) error {
+	if s.auditoria == nil {
+		return ErrAuditoriaDesabilitada
+	}
+	return s.auditoria.ExportarVotos(ctx, id, func(v domain.VotoAuditado) error {
+		if s.pseudonimos != nil && v.OrigemIP != "" {
+			v.OrigemIP = s.pseudonimos.Pseudonimo(id, v.OrigemIP)
+		} else {
+			v.OrigemIP = ""
+		}
+		return emitir(v)
+	})
+}
+
+// PacoteAuditoria reúne paredão, participantes, resultado oficial e checkpoints de um paredão já
+// finalizado. O resultado é reapurado sem efeitos colaterais: a etapa seguinte não é tocada.
+func (s *Service) PacoteAuditoria(ctx context.Context, id domain.ParedaoID) (domain.PacoteAuditoria, error) {
+	if s.auditoria == nil {
+		return domain.PacoteAuditoria{}, ErrAuditoriaDesabilitada
+	}
+	if s.recibos == nil {
+		return domain.PacoteAuditoria{}, ErrRecibosDesabilitados
+	}
+	paredao, err := s.paredoes.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.PacoteAuditoria{}, ErrParedaoNaoEncontrado
+		}
+		return domain.PacoteAuditoria{}, err
+	}
+	if paredao.Ativo {
+		return domain.PacoteAuditoria{}, ErrParedaoAberto
+	}
+
+	participantes, err := s.participantes.ListByParedao(ctx, id)
+	if err != nil {
+		return domain.PacoteAuditoria{}, err
+	}
+	resultado, _, err := s.apurar(ctx, paredao)
+	if err != nil {
+		return domain.PacoteAuditoria{}, err
+	}
+	checkpoints, err := s.auditoria.Checkpoints(ctx, id)
+	if err != nil {
+		return domain.PacoteAuditoria{}, err
+	}
+	paredao.Participantes = nil
+	return domain.PacoteAuditoria{
+		Paredao:       paredao,
+		Participantes: participantes,
+		Resultado:     resultado,
+		Checkpoints:   checkpoints,
+	}, nil
+}
+
+// AssinarMensagem assina o manifesto do pacote de auditoria com a chave ativa dos recibos.
+func (s *Service) AssinarMensagem(msg []byte) (chaveID, assinatura string) {
+	if s.recibos == nil {
+		return "", ""
+	}
+	return s.recibos.AssinarMensagem(msg)
+}
+
+// ChavesRecibo lista as chaves públicas que conferem os recibos; vazia quando os recibos estão desligados.
+func (s *Service) ChavesRecibo() []domain.ChavePublicaRecibo {
+	if s.recibos == nil {
+		return nil
+	}
+	return s.recibos.ChavesPublicas()
+}
+
+// StatusVoto informa se o voto ainda está na fila, já foi gravado ou foi rejeitado pelo worker. O registro
+// de curta duração no Redis responde primeiro; na falta dele, um voto presente no Postgres está processado.
+func (s *Service) StatusVoto(ctx context.Context, id domain.VotoID) (domain.StatusVoto, error) {
+	if id == "" {
+		return domain.StatusVoto{}, ErrVotoNaoEncontrado
+	}
+	if s.status != nil {
+		if status, err := s.status.Obter(ctx, id); err == nil {
+			return status, nil
+		}
+	}
+
+	voto, err := s.votos.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.StatusVoto{}, ErrVotoNaoEncontrado
+		}
+		return domain.StatusVoto{}, err
+	}
+	return domain.StatusVoto{
+		VotoID:       voto.ID,
+		ParedaoID:    voto.ParedaoID,
+		Estado:       domain.VotoProcessado,
+		AtualizadoEm: voto.CriadoEm,
+	}, nil
+}
+
+func (s *Service) salvarStatus(ctx context.Context, voto domain.Voto, estado domain.EstadoVoto, motivo string) {
+	if s.status == nil {
+		return
+	}
+	_ = s.status.Salvar(ctx, domain.StatusVoto{
+		VotoID:       voto.ID,
+		ParedaoID:    voto.ParedaoID,
+		Estado:       estado,
+		Motivo:       motivo,
+		AtualizadoEm: s.clock.Agora(),
+	})
+}
+
+// persistir publica na fila (modo assíncrono) ou grava direto no repositório e nos contadores.
+func (s *Service) persistir(ctx context.Context, voto domain.Voto) error {
 	if s.fila != nil {
+		// O status nasce antes da publicação: depois dela o worker pode marcar o voto como processado a qualquer momento.
+		// Falhar ao gravá-lo não recusa o voto; a consulta cai no Postgres.
+		s.salvarStatus(ctx, voto, domain.VotoEnfileirado, "")
 		// No modo assíncrono basta publicar; o worker cuidará da persistência e contadores.
-		return s.fila.PublicarVoto(ctx, voto)
+		if err := s.fila.PublicarVoto(ctx, voto); err != nil {
+			s.salvarStatus(ctx, voto, domain.VotoRejeitado, "falha ao enfileirar")
+			return err
+		}
+		return nil
 	}
 
 	if err := s.votos.Registrar(ctx, voto); err != nil {
@@ -168,8 +727,9 @@ func (s *Service) RegistrarVoto(ctx context.Context, voto domain.Voto) error {
 }
 
 // Parciais lê contadores do Postgres para manter consistência mesmo sem Redis.
+// Em paredões mistos o percentual oficial pondera o percentual de cada urna pelos pesos configurados.
 func (s *Service) Parciais(ctx context.Context, paredaoID domain.ParedaoID) ([]domain.Parcial, error) {
-	_, err := s.paredoes.FindByID(ctx, paredaoID)
+	paredao, err := s.paredoes.FindByID(ctx, paredaoID)
 	if err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			return nil, ErrParedaoNaoEncontrado
@@ -182,44 +742,439 @@ func (s *Service) Parciais(ctx context.Context, paredaoID domain.ParedaoID) ([]d
 		return nil, err
 	}
 
-	totais, err := s.votos.TotalPorParticipante(ctx, paredaoID)
+	totais, err := s.votos.TotalPorModalidade(ctx, paredaoID)
 	if err != nil {
 		return nil, err
 	}
 
-	var totalGeral int64
-	for _, total := range totais {
-		totalGeral += total
+	return calcularParciais(paredaoID, participantes, totais, paredao.PesosEfetivos()), nil
+}
+
+// ParciaisPublicas é a leitura dos endpoints públicos: no modo oculto não devolve números e no modo
+// atrasado conta só os votos registrados até o instante indicado na Publicacao.
+func (s *Service) ParciaisPublicas(ctx context.Context, paredaoID domain.ParedaoID) ([]domain.Parcial, domain.Publicacao, error) {
+	paredao, err := s.paredoes.FindByID(ctx, paredaoID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.Publicacao{}, ErrParedaoNaoEncontrado
+		}
+		return nil, domain.Publicacao{}, err
+	}
+
+	publicacao := paredao.Publicacao(s.clock.Agora())
+	switch {
+	case publicacao.Oculta:
+		return nil, publicacao, nil
+	case publicacao.Ate.IsZero():
+		parciais, err := s.Parciais(ctx, paredaoID)
+		return parciais, publicacao, err
+	}
+
+	participantes, err := s.participantes.ListByParedao(ctx, paredaoID)
+	if err != nil {
+		return nil, publicacao, err
+	}
+	totais, err := s.votos.TotalPorModalidadeAte(ctx, paredaoID, publicacao.Ate)
+	if err != nil {
+		return nil, publicacao, err
+	}
+	return calcularParciais(paredaoID, participantes, totais, paredao.PesosEfetivos()), publicacao, nil
+}
+
+// calcularParciais monta o detalhamento por urna e o percentual ponderado de cada participante.
+// Urnas ainda sem votos ficam fora da ponderação para não puxar todos os percentuais para baixo.
+// Percentuais consideram só quem segue na disputa: votos de retirados descartados somem da parcial e os
+// dos demais retirados aparecem no total, mas fora da base dos percentuais.
+func calcularParciais(
+	paredaoID domain.ParedaoID,
+	participantes []domain.Participante,
+	totais map[domain.Modalidade]map[domain.ParticipanteID]int64,
+	pesos map[domain.Modalidade]float64,
+) []domain.Parcial {
+	// Votos antigos, gravados antes das modalidades, contam como torcida.
+	if semModalidade, ok := totais[""]; ok {
+		torcida := totais[domain.ModalidadeTorcida]
+		if torcida == nil {
+			torcida = make(map[domain.ParticipanteID]int64)
+			totais[domain.ModalidadeTorcida] = torcida
+		}
+		for id, total := range semModalidade {
+			torcida[id] += total
+		}
+		delete(totais, "")
 	}
 
-	if totalGeral == 0 {
-		resultado := make([]domain.Parcial, len(participantes))
-		for i, part := range participantes {
-			resultado[i] = domain.Parcial{
-				ParedaoID:      paredaoID,
-				ParticipanteID: part.ID,
-				Total:          0,
-				Percentual:     0,
+	modalidades := make([]domain.Modalidade, 0, 2)
+	for _, m := range []domain.Modalidade{domain.ModalidadeUnico, domain.ModalidadeTorcida} {
+		if pesos[m] > 0 || len(totais[m]) > 0 {
+			modalidades = append(modalidades, m)
+		}
+	}
+
+	totalUrna := make(map[domain.Modalidade]int64, len(modalidades))
+	pesoAtivo := 0.0
+	for _, m := range modalidades {
+		for _, part := range participantes {
+			if !part.Retirado() {
+				totalUrna[m] += totais[m][part.ID]
 			}
 		}
-		return resultado, nil
+		if totalUrna[m] > 0 {
+			pesoAtivo += pesos[m]
+		}
 	}
 
-	resultado := make([]domain.Parcial, len(participantes))
-	for i, part := range participantes {
-		total := totais[part.ID]
-		percentual := (float64(total) / float64(totalGeral)) * 100
-		resultado[i] = domain.Parcial{
+	resultado := make([]domain.Parcial, 0, len(participantes))
+	for _, part := range participantes {
+		if part.Retirada == domain.RetiradaDescartar {
+			continue
+		}
+		parcial := domain.Parcial{
 			ParedaoID:      paredaoID,
 			ParticipanteID: part.ID,
-			Total:          total,
-			Percentual:     percentual,
+			Retirado:       part.Retirado(),
+		}
+		for _, m := range modalidades {
+			urna := domain.ParcialModalidade{
+				Modalidade: m,
+				Total:      totais[m][part.ID],
+				Peso:       pesos[m],
+			}
+			if totalUrna[m] > 0 && !parcial.Retirado {
+				urna.Percentual = (float64(urna.Total) / float64(totalUrna[m])) * 100
+				if pesoAtivo > 0 {
+					parcial.Percentual += urna.Percentual * pesos[m] / pesoAtivo
+				}
+			}
+			parcial.Total += urna.Total
+			parcial.Modalidades = append(parcial.Modalidades, urna)
 		}
+		resultado = append(resultado, parcial)
 	}
 
+	return resultado
+}
+
+// Finalizar encerra a votação do paredão e devolve o resultado oficial com os votos já persistidos.
+func (s *Service) Finalizar(ctx context.Context, id domain.ParedaoID) (domain.Resultado, error) {
+	paredao, err := s.paredoes.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Resultado{}, ErrParedaoNaoEncontrado
+		}
+		return domain.Resultado{}, err
+	}
+
+	agora := s.clock.Agora()
+	if paredao.Ativo {
+		paredao.Ativo = false
+		if paredao.Fim.After(agora) {
+			paredao.Fim = agora
+		}
+		paredao.AtualizadoEm = agora
+		if err := s.paredoes.Update(ctx, paredao); err != nil {
+			return domain.Resultado{}, err
+		}
+	}
+
+	resultado, proxima, err := s.apurar(ctx, paredao)
+	if err != nil {
+		return domain.Resultado{}, err
+	}
+	if proxima != nil {
+		if resultado.Escapou != "" {
+			if err := s.avancarEtapa(ctx, paredao, *proxima, resultado.Escapou, agora); err != nil {
+				return domain.Resultado{}, err
+			}
+		} else {
+			// Empate na ponta (ou etapa sem votos) não avança sozinho; a etapa seguinte fica vazia até o desempate.
+			seguintes, err := s.participantes.ListByParedao(ctx, proxima.ID)
+			if err != nil {
+				return domain.Resultado{}, err
+			}
+			resultado.DesempatePendente = len(seguintes) == 0
+		}
+	}
+	// Repetir a finalização não duplica o evento; se a publicação falhar, repetir publica.
+	if err := s.publicarEvento(ctx, domain.EventoParedaoFinalizado, paredao.ID, "", resultado); err != nil {
+		return domain.Resultado{}, err
+	}
 	return resultado, nil
 }
 
+// Desempatar abre a etapa seguinte de uma etapa intermediária finalizada com empate na ponta (ou sem
+// votos), com quem a produção escolheu para escapar. Com empate, a escolha precisa estar entre os empatados.
+func (s *Service) Desempatar(ctx context.Context, id domain.ParedaoID, escapou domain.ParticipanteID) (domain.Resultado, error) {
+	paredao, err := s.paredoes.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Resultado{}, ErrParedaoNaoEncontrado
+		}
+		return domain.Resultado{}, err
+	}
+	if paredao.Ativo {
+		return domain.Resultado{}, ErrParedaoAberto
+	}
+
+	resultado, proxima, err := s.apurar(ctx, paredao)
+	if err != nil {
+		return domain.Resultado{}, err
+	}
+	if proxima == nil {
+		return domain.Resultado{}, fmt.Errorf("%w: paredao nao e etapa intermediaria", ErrParedaoInvalido)
+	}
+	if resultado.Escapou != "" {
+		return domain.Resultado{}, fmt.Errorf("%w: etapa sem empate a desfazer", ErrParedaoInvalido)
+	}
+	seguintes, err := s.participantes.ListByParedao(ctx, proxima.ID)
+	if err != nil {
+		return domain.Resultado{}, err
+	}
+	if len(seguintes) > 0 {
+		return domain.Resultado{}, fmt.Errorf("%w: desempate ja aplicado", ErrParedaoInvalido)
+	}
+
+	elegivel := false
+	if len(resultado.Empatados) > 0 {
+		elegivel = slices.Contains(resultado.Empatados, escapou)
+	} else {
+		atuais, err := s.participantes.ListByParedao(ctx, paredao.ID)
+		if err != nil {
+			return domain.Resultado{}, err
+		}
+		for _, part := range atuais {
+			if part.ID == escapou && !part.Retirado() {
+				elegivel = true
+			}
+		}
+	}
+	if !elegivel {
+		return domain.Resultado{}, fmt.Errorf("%w: participante %s nao pode escapar desta etapa", ErrParedaoInvalido, escapou)
+	}
+
+	if err := s.avancarEtapa(ctx, paredao, *proxima, escapou, s.clock.Agora()); err != nil {
+		return domain.Resultado{}, err
+	}
+	resultado.Escapou = escapou
+	if err := s.publicarEvento(ctx, domain.EventoParedaoFinalizado, paredao.ID, "desempate", resultado); err != nil {
+		return domain.Resultado{}, err
+	}
+	return resultado, nil
+}
+
+// apurar calcula o resultado oficial do paredão com os votos já persistidos. Numa etapa intermediária
+// ninguém deixa a casa: o destaque escapa e a etapa seguinte é devolvida para quem precisar preenchê-la.
+func (s *Service) apurar(ctx context.Context, paredao domain.Paredao) (domain.Resultado, *domain.Paredao, error) {
+	parciais, err := s.Parciais(ctx, paredao.ID)
+	if err != nil {
+		return domain.Resultado{}, nil, err
+	}
+
+	resultado := domain.Resultado{
+		ParedaoID:    paredao.ID,
+		Polaridade:   paredao.PolaridadeEfetiva(),
+		Anulado:      paredao.Anulado,
+		Parciais:     parciais,
+		FinalizadoEm: paredao.Fim,
+	}
+	for _, parcial := range parciais {
+		resultado.TotalVotos += parcial.Total
+	}
+	if paredao.Anulado {
+		return resultado, nil, nil
+	}
+	resultado.Eliminado, resultado.Empatados = domain.Apurar(resultado.Polaridade, parciais)
+	if paredao.GrupoID == "" {
+		return resultado, nil, nil
+	}
+
+	etapas, err := s.paredoes.ListByGrupo(ctx, paredao.GrupoID)
+	if err != nil {
+		return domain.Resultado{}, nil, err
+	}
+	for i := range etapas {
+		if etapas[i].Etapa == paredao.Etapa+1 {
+			resultado.Eliminado = ""
+			resultado.Escapou, resultado.Empatados = domain.Destaque(resultado.Parciais)
+			resultado.ProximaEtapa = etapas[i].ID
+			return resultado, &etapas[i], nil
+		}
+	}
+	return resultado, nil, nil
+}
+
+// avancarEtapa leva os participantes de uma etapa intermediária para a seguinte, menos quem escapou.
+// Repetir a finalização, mesmo em paralelo, não duplica participantes: o repositório trava a etapa
+// seguinte e mantém como está a que já foi preenchida.
+func (s *Service) avancarEtapa(ctx context.Context, paredao, proxima domain.Paredao, escapou domain.ParticipanteID, agora time.Time) error {
+	atuais, err := s.participantes.ListByParedao(ctx, paredao.ID)
+	if err != nil {
+		return err
+	}
+	seguintes := make([]domain.Participante, 0, len(atuais))
+	for _, part := range atuais {
+		if part.ID == escapou || part.Retirado() {
+			continue
+		}
+		origem := part.OrigemID
+		if origem == "" {
+			origem = part.ID
+		}
+		seguintes = append(seguintes, domain.Participante{
+			ID:           domain.ParticipanteID(s.ids.New()),
+			ParedaoID:    proxima.ID,
+			Nome:         part.Nome,
+			FotoURL:      part.FotoURL,
+			OrigemID:     origem,
+			CriadoEm:     agora,
+			AtualizadoEm: agora,
+		})
+	}
+	proxima.AtualizadoEm = agora
+	return s.paredoes.AbrirEtapa(ctx, proxima, seguintes)
+}
+
+// RetirarParticipante aplica a política escolhida a quem deixa o paredão com a votação aberta. O
+// participante deixa de receber votos; descartar e manter recalculam os percentuais entre os que
+// ficam, enquanto anular encerra o paredão sem resultado.
+func (s *Service) RetirarParticipante(ctx context.Context, id domain.ParedaoID, participanteID domain.ParticipanteID, politica domain.PoliticaRetirada) (domain.Paredao, error) {
+	switch politica {
+	case domain.RetiradaDescartar, domain.RetiradaManter, domain.RetiradaAnular:
+	default:
+		return domain.Paredao{}, fmt.Errorf("%w: politica de retirada %q desconhecida", ErrParedaoInvalido, politica)
+	}
+
+	paredao, err := s.paredoes.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Paredao{}, ErrParedaoNaoEncontrado
+		}
+		return domain.Paredao{}, err
+	}
+	if !paredao.Ativo {
+		return domain.Paredao{}, ErrPeriodoEncerrado
+	}
+
+	participantes, err := s.participantes.ListByParedao(ctx, id)
+	if err != nil {
+		return domain.Paredao{}, err
+	}
+	participante, ok := buscarParticipante(participantes, participanteID)
+	if !ok {
+		return domain.Paredao{}, ErrParticipanteDesconhecido
+	}
+	if participante.Retirado() {
+		return domain.Paredao{}, ErrParticipanteRetirado
+	}
+
+	if politica != domain.RetiradaAnular {
+		minimo, err := s.minimoNaDisputa(ctx, paredao)
+		if err != nil {
+			return domain.Paredao{}, err
+		}
+		restantes := 0
+		for _, part := range participantes {
+			if !part.Retirado() && part.ID != participanteID {
+				restantes++
+			}
+		}
+		if restantes < minimo {
+			return domain.Paredao{}, fmt.Errorf("%w: restariam %d participantes na disputa; anule o paredao", ErrParedaoInvalido, restantes)
+		}
+	}
+
+	agora := s.clock.Agora()
+	participante.Retirada = politica
+	participante.RetiradoEm = &agora
+	participante.AtualizadoEm = agora
+	if err := s.participantes.Update(ctx, participante); err != nil {
+		return domain.Paredao{}, err
+	}
+
+	if politica == domain.RetiradaAnular {
+		paredao.Ativo = false
+		paredao.Anulado = true
+		if paredao.Fim.After(agora) {
+			paredao.Fim = agora
+		}
+		paredao.AtualizadoEm = agora
+		if err := s.paredoes.Update(ctx, paredao); err != nil {
+			return domain.Paredao{}, err
+		}
+	}
+
+	for i := range participantes {
+		if participantes[i].ID == participanteID {
+			participantes[i] = participante
+		}
+	}
+	paredao.Participantes = participantes
+	return paredao, nil
+}
+
+// minimoNaDisputa é quantos participantes o paredão precisa manter: dois na etapa final e um a mais
+// para cada etapa intermediária que ainda falta, já que cada uma delas tira quem escapa.
+func (s *Service) minimoNaDisputa(ctx context.Context, paredao domain.Paredao) (int, error) {
+	if paredao.GrupoID == "" {
+		return 2, nil
+	}
+	etapas, err := s.paredoes.ListByGrupo(ctx, paredao.GrupoID)
+	if err != nil {
+		return 0, err
+	}
+	return len(etapas) - paredao.Etapa + 2, nil
+}
+
+// AtualizarVotacao troca o modo de votação, os pesos das urnas e a polaridade de um paredão.
+// Modo e polaridade vazios e pesos nil mantêm os valores atuais.
+func (s *Service) AtualizarVotacao(ctx context.Context, id domain.ParedaoID, modo domain.ModoVotacao, pesos *domain.PesosModalidade, polaridade domain.Polaridade) (domain.Paredao, error) {
+	var novos domain.PesosModalidade
+	if pesos != nil {
+		novos = *pesos
+	}
+	if err := validarVotacao(modo, novos, polaridade); err != nil {
+		return domain.Paredao{}, err
+	}
+
+	paredao, err := s.paredoes.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Paredao{}, ErrParedaoNaoEncontrado
+		}
+		return domain.Paredao{}, err
+	}
+
+	if modo != "" {
+		paredao.ModoVotacao = modo
+	}
+	if polaridade != "" {
+		paredao.Polaridade = polaridade
+	}
+	if pesos != nil {
+		paredao.Pesos = *pesos
+	}
+	paredao.AtualizadoEm = s.clock.Agora()
+	if err := s.paredoes.AtualizarVotacao(ctx, id, paredao.ModoVotacao, paredao.Pesos, paredao.Polaridade, paredao.AtualizadoEm); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Paredao{}, ErrParedaoNaoEncontrado
+		}
+		return domain.Paredao{}, err
+	}
+	return paredao, nil
+}
+
+// ObterParedao devolve o paredão com participantes, esteja ele ativo ou já encerrado.
+func (s *Service) ObterParedao(ctx context.Context, id domain.ParedaoID) (domain.Paredao, error) {
+	paredao, err := s.paredoes.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.Paredao{}, ErrParedaoNaoEncontrado
+		}
+		return domain.Paredao{}, err
+	}
+	return paredao, nil
+}
+
 func (s *Service) TotaisPorHora(ctx context.Context, paredaoID domain.ParedaoID) ([]domain.ParcialHora, error) {
 	_, err := s.paredoes.FindByID(ctx, paredaoID)
 	if err != nil {
@@ -231,21 +1186,382 @@ func (s *Service) TotaisPorHora(ctx context.Context, paredaoID domain.ParedaoID)
 	return s.votos.TotalPorHora(ctx, paredaoID)
 }
 
+// TotaisPorHoraPublicos aplica à série por hora a mesma política de visibilidade de ParciaisPublicas.
+func (s *Service) TotaisPorHoraPublicos(ctx context.Context, paredaoID domain.ParedaoID) ([]domain.ParcialHora, domain.Publicacao, error) {
+	paredao, err := s.paredoes.FindByID(ctx, paredaoID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.Publicacao{}, ErrParedaoNaoEncontrado
+		}
+		return nil, domain.Publicacao{}, err
+	}
+
+	publicacao := paredao.Publicacao(s.clock.Agora())
+	switch {
+	case publicacao.Oculta:
+		return nil, publicacao, nil
+	case publicacao.Ate.IsZero():
+		totais, err := s.votos.TotalPorHora(ctx, paredaoID)
+		return totais, publicacao, err
+	}
+	totais, err := s.votos.TotalPorHoraAte(ctx, paredaoID, publicacao.Ate)
+	return totais, publicacao, err
+}
+
+// IntervaloSeriePadrao é o intervalo da série por participante quando nenhum é pedido.
+const IntervaloSeriePadrao = "5m"
+
+var intervalosSerie = map[string]time.Duration{
+	"1m":  time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"1h":  time.Hour,
+}
+
+// ParseIntervaloSerie traduz o intervalo pedido (1m, 5m, 15m ou 1h); vazio vale IntervaloSeriePadrao.
+func ParseIntervaloSerie(s string) (time.Duration, error) {
+	if s == "" {
+		s = IntervaloSeriePadrao
+	}
+	intervalo, ok := intervalosSerie[s]
+	if !ok {
+		return 0, ErrIntervaloInvalido
+	}
+	return intervalo, nil
+}
+
+// SeriePorParticipante monta a série completa de cada participante, sem política de visibilidade, para a
+// produção.
+func (s *Service) SeriePorParticipante(ctx context.Context, paredaoID domain.ParedaoID, intervalo time.Duration) ([]domain.SerieParticipante, error) {
+	if intervalo <= 0 {
+		return nil, ErrIntervaloInvalido
+	}
+	if _, err := s.paredoes.FindByID(ctx, paredaoID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, ErrParedaoNaoEncontrado
+		}
+		return nil, err
+	}
+	participantes, err := s.participantes.ListByParedao(ctx, paredaoID)
+	if err != nil {
+		return nil, err
+	}
+	parciais, err := s.votos.TotalPorIntervalo(ctx, paredaoID, intervalo)
+	if err != nil {
+		return nil, err
+	}
+	return montarSerie(paredaoID, participantes, parciais, intervalo), nil
+}
+
+// SeriePublica aplica à série por participante a mesma política de visibilidade de ParciaisPublicas.
+func (s *Service) SeriePublica(ctx context.Context, paredaoID domain.ParedaoID, intervalo time.Duration) ([]domain.SerieParticipante, domain.Publicacao, error) {
+	if intervalo <= 0 {
+		return nil, domain.Publicacao{}, ErrIntervaloInvalido
+	}
+	paredao, err := s.paredoes.FindByID(ctx, paredaoID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, domain.Publicacao{}, ErrParedaoNaoEncontrado
+		}
+		return nil, domain.Publicacao{}, err
+	}
+
+	publicacao := paredao.Publicacao(s.clock.Agora())
+	if publicacao.Oculta {
+		return nil, publicacao, nil
+	}
+	participantes, err := s.participantes.ListByParedao(ctx, paredaoID)
+	if err != nil {
+		return nil, publicacao, err
+	}
+	var parciais []domain.ParcialIntervalo
+	if publicacao.Ate.IsZero() {
+		parciais, err = s.votos.TotalPorIntervalo(ctx, paredaoID, intervalo)
+	} else {
+		parciais, err = s.votos.TotalPorIntervaloAte(ctx, paredaoID, intervalo, publicacao.Ate)
+	}
+	if err != nil {
+		return nil, publicacao, err
+	}
+	return montarSerie(paredaoID, participantes, parciais, intervalo), publicacao, nil
+}
+
+// montarSerie preenche com zero os intervalos sem votos entre o primeiro e o último, para que todas as
+// séries tenham os mesmos pontos. Votos de retirados com descarte ficam de fora, como nas parciais.
+func montarSerie(paredaoID domain.ParedaoID, participantes []domain.Participante, parciais []domain.ParcialIntervalo, intervalo time.Duration) []domain.SerieParticipante {
+	series := make([]domain.SerieParticipante, 0, len(participantes))
+	indice := make(map[domain.ParticipanteID]int, len(participantes))
+	for _, part := range participantes {
+		if part.Retirada == domain.RetiradaDescartar {
+			continue
+		}
+		indice[part.ID] = len(series)
+		series = append(series, domain.SerieParticipante{ParedaoID: paredaoID, ParticipanteID: part.ID})
+	}
+
+	votos := make(map[time.Time][]int64)
+	var primeiro, ultimo time.Time
+	for _, p := range parciais {
+		i, ok := indice[p.ParticipanteID]
+		if !ok {
+			continue
+		}
+		inicio := p.Inicio
+		if votos[inicio] == nil {
+			votos[inicio] = make([]int64, len(series))
+		}
+		votos[inicio][i] += p.Total
+		if primeiro.IsZero() || inicio.Before(primeiro) {
+			primeiro = inicio
+		}
+		if inicio.After(ultimo) {
+			ultimo = inicio
+		}
+	}
+	if primeiro.IsZero() {
+		return series
+	}
+
+	acumulado := make([]int64, len(series))
+	for inicio := primeiro; !inicio.After(ultimo); inicio = inicio.Add(intervalo) {
+		var total int64
+		for i, n := range votos[inicio] {
+			acumulado[i] += n
+		}
+		for _, n := range acumulado {
+			total += n
+		}
+		for i := range series {
+			ponto := domain.PontoSerie{Inicio: inicio, Acumulado: acumulado[i]}
+			if v := votos[inicio]; v != nil {
+				ponto.Votos = v[i]
+			}
+			if total > 0 {
+				ponto.Participacao = float64(acumulado[i]) / float64(total) * 100
+			}
+			series[i].Pontos = append(series[i].Pontos, ponto)
+		}
+	}
+	return series
+}
+
+func (s *Service) ObterVisibilidade(ctx context.Context, id domain.ParedaoID) (domain.PoliticaVisibilidade, error) {
+	paredao, err := s.paredoes.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.PoliticaVisibilidade{}, ErrParedaoNaoEncontrado
+		}
+		return domain.PoliticaVisibilidade{}, err
+	}
+	if paredao.Visibilidade.Modo == "" {
+		paredao.Visibilidade.Modo = domain.VisibilidadePublica
+	}
+	return paredao.Visibilidade, nil
+}
+
+// AtualizarVisibilidade troca a política das parciais públicas; vale imediatamente, inclusive com o paredão aberto.
+func (s *Service) AtualizarVisibilidade(ctx context.Context, id domain.ParedaoID, politica domain.PoliticaVisibilidade) (domain.PoliticaVisibilidade, error) {
+	if err := validarVisibilidade(politica); err != nil {
+		return domain.PoliticaVisibilidade{}, err
+	}
+
+	if politica.Modo != domain.VisibilidadeAtrasada {
+		politica.AtrasoMinutos = 0
+	}
+	if err := s.paredoes.AtualizarVisibilidade(ctx, id, politica, s.clock.Agora()); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.PoliticaVisibilidade{}, ErrParedaoNaoEncontrado
+		}
+		return domain.PoliticaVisibilidade{}, err
+	}
+	return politica, nil
+}
+
+func (s *Service) ObterLimiaresAnomalia(ctx context.Context, id domain.ParedaoID) (domain.LimiaresAnomalia, error) {
+	paredao, err := s.paredoes.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.LimiaresAnomalia{}, ErrParedaoNaoEncontrado
+		}
+		return domain.LimiaresAnomalia{}, err
+	}
+	return paredao.Anomalia, nil
+}
+
+// AtualizarLimiaresAnomalia troca os limiares de alerta do paredão. O worker relê o paredão a cada janela
+// encerrada, então a mudança vale a partir da próxima.
+func (s *Service) AtualizarLimiaresAnomalia(ctx context.Context, id domain.ParedaoID, limiares domain.LimiaresAnomalia) (domain.LimiaresAnomalia, error) {
+	if limiares.LimiarZ < 0 || limiares.MinVotos < 0 {
+		return domain.LimiaresAnomalia{}, fmt.Errorf("%w: limiar_z e min_votos nao podem ser negativos", ErrParedaoInvalido)
+	}
+
+	if err := s.paredoes.AtualizarLimiares(ctx, id, limiares, s.clock.Agora()); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.LimiaresAnomalia{}, ErrParedaoNaoEncontrado
+		}
+		return domain.LimiaresAnomalia{}, err
+	}
+	return limiares, nil
+}
+
+func (s *Service) ObterPoliticaAntifraude(ctx context.Context, id domain.ParedaoID) (domain.PoliticaAntifraude, error) {
+	paredao, err := s.paredoes.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.PoliticaAntifraude{}, ErrParedaoNaoEncontrado
+		}
+		return domain.PoliticaAntifraude{}, err
+	}
+	return paredao.Antifraude, nil
+}
+
+// AtualizarPoliticaAntifraude grava os ajustes do paredão; campos zerados voltam a herdar o padrão global.
+func (s *Service) AtualizarPoliticaAntifraude(ctx context.Context, id domain.ParedaoID, politica domain.PoliticaAntifraude) (domain.PoliticaAntifraude, error) {
+	if err := validarPolitica(politica); err != nil {
+		return domain.PoliticaAntifraude{}, err
+	}
+
+	if err := s.paredoes.AtualizarAntifraude(ctx, id, politica, s.clock.Agora()); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.PoliticaAntifraude{}, ErrParedaoNaoEncontrado
+		}
+		return domain.PoliticaAntifraude{}, err
+	}
+	if s.politicas != nil {
+		s.politicas.Invalidar(id)
+	}
+	return politica, nil
+}
+
+// PoliticaEfetiva devolve a política antifraude que vale para os votos do paredão (padrão global mais os
+// ajustes dele). Sem ComPoliticas não há política configurada e volta a política vazia.
+func (s *Service) PoliticaEfetiva(ctx context.Context, id domain.ParedaoID) (domain.PoliticaAntifraude, error) {
+	if s.politicas == nil {
+		return domain.PoliticaAntifraude{}, nil
+	}
+	return s.politicas.Resolver(ctx, id)
+}
+
+func validarPolitica(p domain.PoliticaAntifraude) error {
+	if p.Limite < 0 || p.JanelaSegundos < 0 {
+		return fmt.Errorf("%w: limite e janela nao podem ser negativos", ErrParedaoInvalido)
+	}
+	switch p.Algoritmo {
+	case "", domain.AlgoritmoJanelaFixa, domain.AlgoritmoJanelaDeslizante:
+	default:
+		return fmt.Errorf("%w: algoritmo %q desconhecido", ErrParedaoInvalido, p.Algoritmo)
+	}
+	switch p.EscopoBloqueio {
+	case "", domain.EscopoParedao, domain.EscopoGlobal:
+	default:
+		return fmt.Errorf("%w: escopo %q desconhecido", ErrParedaoInvalido, p.EscopoBloqueio)
+	}
+	return nil
+}
+
+func validarVisibilidade(p domain.PoliticaVisibilidade) error {
+	switch p.Modo {
+	case domain.VisibilidadePublica, domain.VisibilidadeOculta:
+	case domain.VisibilidadeAtrasada:
+		if p.AtrasoMinutos <= 0 {
+			return fmt.Errorf("%w: modo atrasado exige atraso_minutos positivo", ErrParedaoInvalido)
+		}
+	default:
+		return fmt.Errorf("%w: visibilidade %q desconhecida", ErrParedaoInvalido, p.Modo)
+	}
+	return nil
+}
+
+// resolverModalidade cruza o modo do paredão com a modalidade pedida; pedido vazio assume o padrão do modo.
+func resolverModalidade(modo domain.ModoVotacao, pedida domain.Modalidade) (domain.Modalidade, error) {
+	switch modo {
+	case domain.ModoUnico:
+		if pedida != "" && pedida != domain.ModalidadeUnico {
+			return "", ErrModalidadeInvalida
+		}
+		return domain.ModalidadeUnico, nil
+	case domain.ModoMisto:
+		switch pedida {
+		case "", domain.ModalidadeTorcida:
+			return domain.ModalidadeTorcida, nil
+		case domain.ModalidadeUnico:
+			return domain.ModalidadeUnico, nil
+		}
+		return "", ErrModalidadeInvalida
+	default:
+		if pedida != "" && pedida != domain.ModalidadeTorcida {
+			return "", ErrModalidadeInvalida
+		}
+		return domain.ModalidadeTorcida, nil
+	}
+}
+
 func validarParedao(p domain.Paredao, participantes []domain.Participante) error {
 	if p.Nome == "" {
 		return fmt.Errorf("%w: nome obrigatorio", ErrParedaoInvalido)
 	}
+	if err := validarVotacao(p.ModoVotacao, p.Pesos, p.Polaridade); err != nil {
+		return err
+	}
 	if len(participantes) < 2 {
 		return fmt.Errorf("%w: minimo de dois participantes", ErrParedaoInvalido)
 	}
 	return nil
 }
 
-func participanteExiste(participantes []domain.Participante, id domain.ParticipanteID) bool {
+func validarEtapas(etapas []domain.Paredao, participantes []domain.Participante) error {
+	if len(etapas) == 0 {
+		return fmt.Errorf("%w: informe ao menos uma etapa", ErrParedaoInvalido)
+	}
+	// Cada etapa intermediária tira um participante e a última precisa de pelo menos dois.
+	if len(participantes) < len(etapas)+1 {
+		return fmt.Errorf("%w: %d etapas exigem ao menos %d participantes", ErrParedaoInvalido, len(etapas), len(etapas)+1)
+	}
+	for i, etapa := range etapas {
+		if etapa.Nome == "" {
+			return fmt.Errorf("%w: etapa %d sem nome", ErrParedaoInvalido, i+1)
+		}
+		if err := validarVotacao(etapa.ModoVotacao, etapa.Pesos, etapa.Polaridade); err != nil {
+			return err
+		}
+		if i < len(etapas)-1 && etapa.Polaridade == domain.PolaridadeEliminar {
+			return fmt.Errorf("%w: etapa %d e intermediaria e precisa ser votacao para salvar", ErrParedaoInvalido, i+1)
+		}
+		if etapa.Fim.IsZero() || (!etapa.Inicio.IsZero() && !etapa.Fim.After(etapa.Inicio)) {
+			return fmt.Errorf("%w: etapa %d com intervalo invalido", ErrParedaoInvalido, i+1)
+		}
+		if i > 0 {
+			anterior := etapas[i-1]
+			if (!etapa.Inicio.IsZero() && etapa.Inicio.Before(anterior.Fim)) || !etapa.Fim.After(anterior.Fim) {
+				return fmt.Errorf("%w: etapa %d precisa comecar depois do fim da etapa %d", ErrParedaoInvalido, i+1, i)
+			}
+		}
+	}
+	return nil
+}
+
+func validarVotacao(modo domain.ModoVotacao, pesos domain.PesosModalidade, polaridade domain.Polaridade) error {
+	switch modo {
+	case "", domain.ModoTorcida, domain.ModoUnico, domain.ModoMisto:
+	default:
+		return fmt.Errorf("%w: modo de votacao %q desconhecido", ErrParedaoInvalido, modo)
+	}
+	switch polaridade {
+	case "", domain.PolaridadeEliminar, domain.PolaridadeSalvar:
+	default:
+		return fmt.Errorf("%w: polaridade %q desconhecida", ErrParedaoInvalido, polaridade)
+	}
+	if pesos.Unico < 0 || pesos.Torcida < 0 {
+		return fmt.Errorf("%w: pesos nao podem ser negativos", ErrParedaoInvalido)
+	}
+	return nil
+}
+
+func buscarParticipante(participantes []domain.Participante, id domain.ParticipanteID) (domain.Participante, bool) {
 	for _, part := range participantes {
 		if part.ID == id {
-			return true
+			return part, true
 		}
 	}
-	return false
+	return domain.Participante{}, false
 }
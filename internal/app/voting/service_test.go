@@ -2,12 +2,19 @@ package voting
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"sort"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/antifraude"
 	"github.com/marcelojr/desafio-globo/internal/platform/ids"
+	"github.com/marcelojr/desafio-globo/internal/platform/recibos"
 )
 
 func TestServiceCriarParedao(t *testing.T) {
@@ -81,7 +88,7 @@ func TestServiceRegistrarVotoEnfileira(t *testing.T) {
 		t.Fatalf("falha ao criar paredao: %v", err)
 	}
 
-	err = service.RegistrarVoto(context.Background(), domain.Voto{
+	resultado, err := service.RegistrarVoto(context.Background(), domain.Voto{
 		ParedaoID:      paredao.Participantes[0].ParedaoID,
 		ParticipanteID: paredao.Participantes[0].ID,
 		OrigemIP:       "127.0.0.1",
@@ -94,6 +101,9 @@ func TestServiceRegistrarVotoEnfileira(t *testing.T) {
 	if deps.queue.Len() != 1 {
 		t.Fatalf("voto deveria ter sido enfileirado; total esperado 1, veio %d", deps.queue.Len())
 	}
+	if resultado.VotoID == "" || resultado.VotoID != deps.queue.votos[0].ID {
+		t.Fatalf("resultado deveria trazer o ID do voto enfileirado, veio %q", resultado.VotoID)
+	}
 	if len(deps.votoRepo.lista) != 0 {
 		t.Fatalf("voto não deveria ter sido persistido antes do worker, total persistido %d", len(deps.votoRepo.lista))
 	}
@@ -131,7 +141,7 @@ func TestServiceParciaisComWorker(t *testing.T) {
 		OrigemIP:       "127.0.0.1",
 		UserAgent:      "teste",
 	}
-	if err := service.RegistrarVoto(context.Background(), voto); err != nil {
+	if _, err := service.RegistrarVoto(context.Background(), voto); err != nil {
 		t.Fatalf("erro registrando voto: %v", err)
 	}
 	voto2 := domain.Voto{
@@ -140,7 +150,7 @@ func TestServiceParciaisComWorker(t *testing.T) {
 		OrigemIP:       "127.0.0.2",
 		UserAgent:      "teste",
 	}
-	if err := service.RegistrarVoto(context.Background(), voto2); err != nil {
+	if _, err := service.RegistrarVoto(context.Background(), voto2); err != nil {
 		t.Fatalf("erro registrando segundo voto: %v", err)
 	}
 
@@ -188,9 +198,12 @@ type serviceDependencies struct {
 func newServiceDeps() serviceDependencies {
 	base := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
 
+	paredaoRepo := newInMemoryParedaoRepo()
+	participanteRepo := newInMemoryParticipanteRepo()
+	paredaoRepo.participantes = participanteRepo
 	return serviceDependencies{
-		paredaoRepo:      newInMemoryParedaoRepo(),
-		participanteRepo: newInMemoryParticipanteRepo(),
+		paredaoRepo:      paredaoRepo,
+		participanteRepo: participanteRepo,
 		votoRepo:         newInMemoryVotoRepo(),
 		contador:         newInMemoryContador(),
 		queue:            newRecordingQueue(),
@@ -204,6 +217,10 @@ func newServiceDeps() serviceDependencies {
 type inMemoryParedaoRepo struct {
 	mu   sync.Mutex
 	data map[domain.ParedaoID]domain.Paredao
+	// participantes recebe os participantes das etapas, como a transação do repositório real faz.
+	participantes *inMemoryParticipanteRepo
+	// falharEtapa simula uma falha ao gravar a etapa com esse número.
+	falharEtapa int
 }
 
 func newInMemoryParedaoRepo() *inMemoryParedaoRepo {
@@ -217,13 +234,111 @@ func (r *inMemoryParedaoRepo) Create(_ context.Context, p domain.Paredao) error
 	return nil
 }
 
+func (r *inMemoryParedaoRepo) CreateEtapas(ctx context.Context, etapas []domain.Paredao) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, etapa := range etapas {
+		if etapa.Etapa == r.falharEtapa {
+			return errors.New("falha simulada ao gravar etapa")
+		}
+	}
+	for _, etapa := range etapas {
+		r.data[etapa.ID] = etapa
+		if len(etapa.Participantes) > 0 {
+			_ = r.participantes.BulkCreate(ctx, etapa.ID, etapa.Participantes)
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryParedaoRepo) AbrirEtapa(ctx context.Context, etapa domain.Paredao, participantes []domain.Participante) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	atual, ok := r.data[etapa.ID]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	existentes, _ := r.participantes.ListByParedao(ctx, etapa.ID)
+	if len(existentes) > 0 {
+		return nil
+	}
+	_ = r.participantes.BulkCreate(ctx, etapa.ID, participantes)
+	atual.Ativo = true
+	atual.AtualizadoEm = etapa.AtualizadoEm
+	r.data[etapa.ID] = atual
+	return nil
+}
+
+func (r *inMemoryParedaoRepo) AtualizarAntifraude(_ context.Context, id domain.ParedaoID, politica domain.PoliticaAntifraude, em time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	atual, ok := r.data[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	atual.Antifraude = politica
+	atual.AtualizadoEm = em
+	r.data[id] = atual
+	return nil
+}
+
+func (r *inMemoryParedaoRepo) AtualizarLimiares(_ context.Context, id domain.ParedaoID, limiares domain.LimiaresAnomalia, em time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	atual, ok := r.data[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	atual.Anomalia = limiares
+	atual.AtualizadoEm = em
+	r.data[id] = atual
+	return nil
+}
+
+func (r *inMemoryParedaoRepo) AtualizarVotacao(_ context.Context, id domain.ParedaoID, modo domain.ModoVotacao, pesos domain.PesosModalidade, polaridade domain.Polaridade, em time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	atual, ok := r.data[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	atual.ModoVotacao = modo
+	atual.Pesos = pesos
+	atual.Polaridade = polaridade
+	atual.AtualizadoEm = em
+	r.data[id] = atual
+	return nil
+}
+
+func (r *inMemoryParedaoRepo) AtualizarVisibilidade(_ context.Context, id domain.ParedaoID, politica domain.PoliticaVisibilidade, em time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	atual, ok := r.data[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	atual.Visibilidade = politica
+	atual.AtualizadoEm = em
+	r.data[id] = atual
+	return nil
+}
+
 func (r *inMemoryParedaoRepo) Update(_ context.Context, p domain.Paredao) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	if _, ok := r.data[p.ID]; !ok {
+	atual, ok := r.data[p.ID]
+	if !ok {
 		return domain.ErrNotFound
 	}
-	r.data[p.ID] = p
+	// Como o repositório real, só as colunas do ciclo de vida são gravadas.
+	atual.Nome = p.Nome
+	atual.Descricao = p.Descricao
+	atual.Inicio = p.Inicio
+	atual.Fim = p.Fim
+	atual.Ativo = p.Ativo
+	atual.Anulado = p.Anulado
+	atual.AtualizadoEm = p.AtualizadoEm
+	r.data[p.ID] = atual
 	return nil
 }
 
@@ -249,6 +364,32 @@ func (r *inMemoryParedaoRepo) ListAtivos(_ context.Context) ([]domain.Paredao, e
 	return result, nil
 }
 
+func (r *inMemoryParedaoRepo) ListComMarco(_ context.Context, desde, ate time.Time) ([]domain.Paredao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	dentro := func(t time.Time) bool { return t.After(desde) && !t.After(ate) }
+	var result []domain.Paredao
+	for _, p := range r.data {
+		if dentro(p.Inicio) || dentro(p.Fim) {
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+func (r *inMemoryParedaoRepo) ListByGrupo(_ context.Context, grupo domain.ParedaoID) ([]domain.Paredao, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var result []domain.Paredao
+	for _, p := range r.data {
+		if p.GrupoID == grupo {
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Etapa < result[j].Etapa })
+	return result, nil
+}
+
 type inMemoryParticipanteRepo struct {
 	mu        sync.Mutex
 	porParedo map[domain.ParedaoID][]domain.Participante
@@ -274,6 +415,18 @@ func (r *inMemoryParticipanteRepo) ListByParedao(_ context.Context, paredaoID do
 	return copia, nil
 }
 
+func (r *inMemoryParticipanteRepo) Update(_ context.Context, p domain.Participante) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, part := range r.porParedo[p.ParedaoID] {
+		if part.ID == p.ID {
+			r.porParedo[p.ParedaoID][i] = p
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
 type inMemoryVotoRepo struct {
 	mu    sync.Mutex
 	lista []domain.Voto
@@ -290,6 +443,17 @@ func (r *inMemoryVotoRepo) Registrar(_ context.Context, voto domain.Voto) error
 	return nil
 }
 
+func (r *inMemoryVotoRepo) FindByID(_ context.Context, id domain.VotoID) (domain.Voto, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.lista {
+		if v.ID == id {
+			return v, nil
+		}
+	}
+	return domain.Voto{}, domain.ErrNotFound
+}
+
 func (r *inMemoryVotoRepo) TotalPorParedao(_ context.Context, id domain.ParedaoID) (int64, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -314,12 +478,36 @@ func (r *inMemoryVotoRepo) TotalPorParticipante(_ context.Context, paredaoID dom
 	return result, nil
 }
 
-func (r *inMemoryVotoRepo) TotalPorHora(_ context.Context, paredaoID domain.ParedaoID) ([]domain.ParcialHora, error) {
+func (r *inMemoryVotoRepo) TotalPorModalidade(ctx context.Context, paredaoID domain.ParedaoID) (map[domain.Modalidade]map[domain.ParticipanteID]int64, error) {
+	return r.TotalPorModalidadeAte(ctx, paredaoID, time.Time{})
+}
+
+func (r *inMemoryVotoRepo) TotalPorModalidadeAte(_ context.Context, paredaoID domain.ParedaoID, ate time.Time) (map[domain.Modalidade]map[domain.ParticipanteID]int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make(map[domain.Modalidade]map[domain.ParticipanteID]int64)
+	for _, voto := range r.lista {
+		if voto.ParedaoID != paredaoID || (!ate.IsZero() && voto.CriadoEm.After(ate)) {
+			continue
+		}
+		if result[voto.Modalidade] == nil {
+			result[voto.Modalidade] = make(map[domain.ParticipanteID]int64)
+		}
+		result[voto.Modalidade][voto.ParticipanteID]++
+	}
+	return result, nil
+}
+
+func (r *inMemoryVotoRepo) TotalPorHora(ctx context.Context, paredaoID domain.ParedaoID) ([]domain.ParcialHora, error) {
+	return r.TotalPorHoraAte(ctx, paredaoID, time.Time{})
+}
+
+func (r *inMemoryVotoRepo) TotalPorHoraAte(_ context.Context, paredaoID domain.ParedaoID, ate time.Time) ([]domain.ParcialHora, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	porHora := make(map[time.Time]int64)
 	for _, voto := range r.lista {
-		if voto.ParedaoID != paredaoID {
+		if voto.ParedaoID != paredaoID || (!ate.IsZero() && voto.CriadoEm.After(ate)) {
 			continue
 		}
 		hora := voto.CriadoEm.Truncate(time.Hour)
@@ -336,6 +524,92 @@ func (r *inMemoryVotoRepo) TotalPorHora(_ context.Context, paredaoID domain.Pare
 	return resultado, nil
 }
 
+func (r *inMemoryVotoRepo) TotalPorIntervalo(ctx context.Context, paredaoID domain.ParedaoID, intervalo time.Duration) ([]domain.ParcialIntervalo, error) {
+	return r.TotalPorIntervaloAte(ctx, paredaoID, intervalo, time.Time{})
+}
+
+func (r *inMemoryVotoRepo) TotalPorIntervaloAte(_ context.Context, paredaoID domain.ParedaoID, intervalo time.Duration, ate time.Time) ([]domain.ParcialIntervalo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	type chave struct {
+		participante domain.ParticipanteID
+		modalidade   domain.Modalidade
+		inicio       time.Time
+	}
+	totais := make(map[chave]int64)
+	for _, voto := range r.lista {
+		if voto.ParedaoID != paredaoID || (!ate.IsZero() && voto.CriadoEm.After(ate)) {
+			continue
+		}
+		totais[chave{voto.ParticipanteID, voto.Modalidade, voto.CriadoEm.UTC().Truncate(intervalo)}]++
+	}
+	var resultado []domain.ParcialIntervalo
+	for c, total := range totais {
+		resultado = append(resultado, domain.ParcialIntervalo{
+			ParedaoID:      paredaoID,
+			ParticipanteID: c.participante,
+			Modalidade:     c.modalidade,
+			Inicio:         c.inicio,
+			Total:          total,
+		})
+	}
+	return resultado, nil
+}
+
+type inMemoryViradas struct {
+	lista []domain.Virada
+}
+
+func (r *inMemoryViradas) Registrar(_ context.Context, v domain.Virada) (bool, error) {
+	for _, existente := range r.lista {
+		if existente.ParedaoID == v.ParedaoID && existente.Ordem == v.Ordem {
+			return false, nil
+		}
+	}
+	r.lista = append(r.lista, v)
+	return true, nil
+}
+
+func (r *inMemoryViradas) Ultima(ctx context.Context, paredaoID domain.ParedaoID) (domain.Virada, error) {
+	viradas, _ := r.Listar(ctx, paredaoID)
+	if len(viradas) == 0 {
+		return domain.Virada{}, domain.ErrNotFound
+	}
+	return viradas[len(viradas)-1], nil
+}
+
+func (r *inMemoryViradas) Listar(_ context.Context, paredaoID domain.ParedaoID) ([]domain.Virada, error) {
+	var viradas []domain.Virada
+	for _, v := range r.lista {
+		if v.ParedaoID == paredaoID {
+			viradas = append(viradas, v)
+		}
+	}
+	return viradas, nil
+}
+
+// inMemoryEventos guarda webhooks e eventos publicados; a fila de entregas é testada no repositório Postgres.
+type inMemoryEventos struct {
+	domain.WebhookRepository
+	webhooks []domain.Webhook
+	eventos  []domain.EventoParedao
+}
+
+func (r *inMemoryEventos) Criar(_ context.Context, w domain.Webhook) error {
+	r.webhooks = append(r.webhooks, w)
+	return nil
+}
+
+func (r *inMemoryEventos) Publicar(_ context.Context, e domain.EventoParedao) (bool, error) {
+	for _, existente := range r.eventos {
+		if existente.Chave == e.Chave {
+			return false, nil
+		}
+	}
+	r.eventos = append(r.eventos, e)
+	return true, nil
+}
+
 type inMemoryContador struct {
 	mu      sync.Mutex
 	valores map[string]int64
@@ -410,7 +684,9 @@ func (r *recordingQueue) Drain() []domain.Voto {
 
 type antifraudeNoop struct{}
 
-func (antifraudeNoop) Validar(_ context.Context, _ domain.Voto) error { return nil }
+func (antifraudeNoop) Validar(_ context.Context, _ domain.Voto) (domain.DecisaoLimite, error) {
+	return domain.DecisaoLimite{}, nil
+}
 
 type staticClock struct {
 	now time.Time
@@ -525,7 +801,7 @@ func TestServiceTotaisPorHora(t *testing.T) {
 			OrigemIP:       "127.0.0.1",
 			UserAgent:      "test",
 		}
-		if err := service.RegistrarVoto(context.Background(), voto); err != nil {
+		if _, err := service.RegistrarVoto(context.Background(), voto); err != nil {
 			t.Fatalf("erro registrando voto: %v", err)
 		}
 	}
@@ -614,10 +890,1460 @@ func TestServiceRegistrarVotoComValidacoes(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := service.RegistrarVoto(context.Background(), tt.voto)
+			_, err := service.RegistrarVoto(context.Background(), tt.voto)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("RegistrarVoto() erro = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
 }
+
+func TestServiceAtualizarPoliticaAntifraude(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		deps.queue,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+	)
+
+	paredao, err := service.CriarParedao(context.Background(), domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(1 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	politica := domain.PoliticaAntifraude{Limite: 3, JanelaSegundos: 120, Algoritmo: domain.AlgoritmoJanelaDeslizante}
+	if _, err := service.AtualizarPoliticaAntifraude(context.Background(), paredao.ID, politica); err != nil {
+		t.Fatalf("politica valida deveria ser aceita: %v", err)
+	}
+	gravada, err := service.ObterPoliticaAntifraude(context.Background(), paredao.ID)
+	if err != nil {
+		t.Fatalf("erro lendo politica: %v", err)
+	}
+	if gravada != politica {
+		t.Fatalf("politica gravada divergente: %+v", gravada)
+	}
+
+	_, err = service.AtualizarPoliticaAntifraude(context.Background(), paredao.ID, domain.PoliticaAntifraude{Algoritmo: "token_bucket"})
+	if !errors.Is(err, ErrParedaoInvalido) {
+		t.Fatalf("algoritmo desconhecido deveria ser recusado, veio %v", err)
+	}
+	_, err = service.AtualizarPoliticaAntifraude(context.Background(), "inexistente", politica)
+	if !errors.Is(err, ErrParedaoNaoEncontrado) {
+		t.Fatalf("paredao inexistente deveria retornar nao encontrado, veio %v", err)
+	}
+}
+
+func TestServiceAtualizarPoliticaAntifraudeValeNaHoraNestaInstancia(t *testing.T) {
+	deps := newServiceDeps()
+	politicas := antifraude.NewResolvedorPoliticas(deps.paredaoRepo, domain.PoliticaAntifraude{Limite: 30, JanelaSegundos: 60}, time.Hour)
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		deps.queue,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+		ComPoliticas(politicas),
+	)
+
+	ctx := context.Background()
+	paredao, err := service.CriarParedao(ctx, domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(1 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	// A primeira leitura põe o padrão em cache por uma hora.
+	if efetiva, err := service.PoliticaEfetiva(ctx, paredao.ID); err != nil || efetiva.ExigeCaptcha || efetiva.Limite != 30 {
+		t.Fatalf("politica efetiva inicial inesperada: %+v (%v)", efetiva, err)
+	}
+
+	if _, err := service.AtualizarPoliticaAntifraude(ctx, paredao.ID, domain.PoliticaAntifraude{Limite: 5, ExigeCaptcha: true}); err != nil {
+		t.Fatalf("erro atualizando politica: %v", err)
+	}
+	efetiva, err := service.PoliticaEfetiva(ctx, paredao.ID)
+	if err != nil {
+		t.Fatalf("erro lendo politica efetiva: %v", err)
+	}
+	if !efetiva.ExigeCaptcha || efetiva.Limite != 5 {
+		t.Fatalf("a edicao deveria valer sem esperar o TTL do cache, veio %+v", efetiva)
+	}
+}
+
+func TestServiceAtualizarLimiaresAnomalia(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		deps.queue,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+	)
+
+	ctx := context.Background()
+	paredao, err := service.CriarParedao(ctx, domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(1 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	limiares := domain.LimiaresAnomalia{LimiarZ: 6, MinVotos: 500}
+	if _, err := service.AtualizarLimiaresAnomalia(ctx, paredao.ID, limiares); err != nil {
+		t.Fatalf("limiares validos deveriam ser aceitos: %v", err)
+	}
+	gravados, err := service.ObterLimiaresAnomalia(ctx, paredao.ID)
+	if err != nil {
+		t.Fatalf("erro lendo limiares: %v", err)
+	}
+	if gravados != limiares {
+		t.Fatalf("limiares gravados divergentes: %+v", gravados)
+	}
+
+	if _, err := service.AtualizarLimiaresAnomalia(ctx, paredao.ID, domain.LimiaresAnomalia{LimiarZ: -1}); !errors.Is(err, ErrParedaoInvalido) {
+		t.Fatalf("limiar negativo deveria ser recusado, veio %v", err)
+	}
+	if _, err := service.AtualizarLimiaresAnomalia(ctx, "inexistente", limiares); !errors.Is(err, ErrParedaoNaoEncontrado) {
+		t.Fatalf("paredao inexistente deveria retornar nao encontrado, veio %v", err)
+	}
+}
+
+func TestServiceRegistrarVotoUnico(t *testing.T) {
+	deps := newServiceDeps()
+	reservas := newInMemoryReservas()
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		deps.queue,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+		ComReservaVotoUnico(reservas),
+	)
+
+	paredao, err := service.CriarParedao(context.Background(), domain.Paredao{
+		Nome:        "Paredão",
+		Inicio:      deps.baseTime.Add(-1 * time.Hour),
+		Fim:         deps.baseTime.Add(1 * time.Hour),
+		ModoVotacao: domain.ModoMisto,
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	voto := domain.Voto{
+		ParedaoID:      paredao.ID,
+		ParticipanteID: paredao.Participantes[0].ID,
+		Modalidade:     domain.ModalidadeUnico,
+	}
+	ctx := context.Background()
+
+	if _, err := service.RegistrarVoto(ctx, voto); !errors.Is(err, ErrEleitorObrigatorio) {
+		t.Fatalf("voto unico anonimo deveria ser recusado, veio %v", err)
+	}
+
+	voto.EleitorID = "conta-1"
+	if _, err := service.RegistrarVoto(ctx, voto); err != nil {
+		t.Fatalf("primeiro voto unico deveria ser aceito: %v", err)
+	}
+	if _, err := service.RegistrarVoto(ctx, voto); !errors.Is(err, ErrVotoJaRegistrado) {
+		t.Fatalf("segundo voto unico deveria ser recusado, veio %v", err)
+	}
+
+	// No modo misto a mesma conta continua livre para votar na torcida.
+	voto.Modalidade = ""
+	if _, err := service.RegistrarVoto(ctx, voto); err != nil {
+		t.Fatalf("voto de torcida deveria ser aceito: %v", err)
+	}
+
+	votos := deps.queue.Drain()
+	if len(votos) != 2 {
+		t.Fatalf("esperava 2 votos enfileirados, veio %d", len(votos))
+	}
+	if votos[0].Modalidade != domain.ModalidadeUnico || votos[1].Modalidade != domain.ModalidadeTorcida {
+		t.Fatalf("modalidades enfileiradas inesperadas: %s, %s", votos[0].Modalidade, votos[1].Modalidade)
+	}
+}
+
+func TestServiceRegistrarVotoRecusaModalidadeForaDoModo(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		deps.queue,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+	)
+
+	paredao, err := service.CriarParedao(context.Background(), domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(1 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	_, err = service.RegistrarVoto(context.Background(), domain.Voto{
+		ParedaoID:      paredao.ID,
+		ParticipanteID: paredao.Participantes[0].ID,
+		EleitorID:      "conta-1",
+		Modalidade:     domain.ModalidadeUnico,
+	})
+	if !errors.Is(err, ErrModalidadeInvalida) {
+		t.Fatalf("paredao so de torcida deveria recusar voto unico, veio %v", err)
+	}
+}
+
+type inMemoryReservas struct {
+	mu       sync.Mutex
+	reservas map[string]bool
+}
+
+func newInMemoryReservas() *inMemoryReservas {
+	return &inMemoryReservas{reservas: make(map[string]bool)}
+}
+
+func (r *inMemoryReservas) Reservar(_ context.Context, paredaoID domain.ParedaoID, eleitorID domain.EleitorID, _ time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := string(paredaoID) + ":" + string(eleitorID)
+	if r.reservas[key] {
+		return false, nil
+	}
+	r.reservas[key] = true
+	return true, nil
+}
+
+func (r *inMemoryReservas) Liberar(_ context.Context, paredaoID domain.ParedaoID, eleitorID domain.EleitorID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.reservas, string(paredaoID)+":"+string(eleitorID))
+	return nil
+}
+
+func TestServiceParciaisPonderaUrnasDoParedaoMisto(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		nil,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+	)
+
+	paredao, err := service.CriarParedao(context.Background(), domain.Paredao{
+		Nome:        "Paredão",
+		Inicio:      deps.baseTime.Add(-1 * time.Hour),
+		Fim:         deps.baseTime.Add(1 * time.Hour),
+		ModoVotacao: domain.ModoMisto,
+		Pesos:       domain.PesosModalidade{Unico: 50, Torcida: 50},
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+	alice, bruno := paredao.Participantes[0].ID, paredao.Participantes[1].ID
+
+	ctx := context.Background()
+	votar := func(participante domain.ParticipanteID, eleitor domain.EleitorID, modalidade domain.Modalidade) {
+		t.Helper()
+		if _, err := service.RegistrarVoto(ctx, domain.Voto{
+			ParedaoID:      paredao.ID,
+			ParticipanteID: participante,
+			EleitorID:      eleitor,
+			Modalidade:     modalidade,
+		}); err != nil {
+			t.Fatalf("erro registrando voto: %v", err)
+		}
+	}
+
+	// Voto único: Alice 3 x 1 Bruno (75% / 25%). Torcida: Alice 1 x 9 Bruno (10% / 90%).
+	votar(alice, "c1", domain.ModalidadeUnico)
+	votar(alice, "c2", domain.ModalidadeUnico)
+	votar(alice, "c3", domain.ModalidadeUnico)
+	votar(bruno, "c4", domain.ModalidadeUnico)
+	votar(alice, "", domain.ModalidadeTorcida)
+	for i := 0; i < 9; i++ {
+		votar(bruno, "", domain.ModalidadeTorcida)
+	}
+
+	parciais, err := service.Parciais(ctx, paredao.ID)
+	if err != nil {
+		t.Fatalf("erro obtendo parciais: %v", err)
+	}
+
+	porParticipante := make(map[domain.ParticipanteID]domain.Parcial)
+	for _, p := range parciais {
+		porParticipante[p.ParticipanteID] = p
+	}
+
+	// A soma bruta daria Alice 4/14 (28,6%); a média ponderada dá 0,5*75 + 0,5*10 = 42,5%.
+	if got := porParticipante[alice].Percentual; got < 42.49 || got > 42.51 {
+		t.Fatalf("percentual ponderado de Alice deveria ser 42.5, veio %.2f", got)
+	}
+	if got := porParticipante[bruno].Percentual; got < 57.49 || got > 57.51 {
+		t.Fatalf("percentual ponderado de Bruno deveria ser 57.5, veio %.2f", got)
+	}
+	if porParticipante[alice].Total != 4 || len(porParticipante[alice].Modalidades) != 2 {
+		t.Fatalf("detalhamento por urna inesperado: %+v", porParticipante[alice])
+	}
+	if urna := porParticipante[alice].Modalidades[0]; urna.Modalidade != domain.ModalidadeUnico || urna.Total != 3 || urna.Percentual != 75 {
+		t.Fatalf("urna de voto unico inesperada: %+v", urna)
+	}
+}
+
+func TestServiceAtualizarVotacaoSemPesosMantemOsAtuais(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		nil,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+	)
+
+	ctx := context.Background()
+	paredao, err := service.CriarParedao(ctx, domain.Paredao{
+		Nome:        "Paredão",
+		Inicio:      deps.baseTime.Add(-1 * time.Hour),
+		Fim:         deps.baseTime.Add(1 * time.Hour),
+		ModoVotacao: domain.ModoMisto,
+		Pesos:       domain.PesosModalidade{Unico: 70, Torcida: 30},
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	atualizado, err := service.AtualizarVotacao(ctx, paredao.ID, "", nil, domain.PolaridadeSalvar)
+	if err != nil {
+		t.Fatalf("erro atualizando polaridade: %v", err)
+	}
+	if atualizado.Pesos != (domain.PesosModalidade{Unico: 70, Torcida: 30}) || atualizado.ModoVotacao != domain.ModoMisto {
+		t.Fatalf("trocar so a polaridade nao deveria mexer em modo e pesos, veio %+v / %s", atualizado.Pesos, atualizado.ModoVotacao)
+	}
+	if atualizado.Polaridade != domain.PolaridadeSalvar {
+		t.Fatalf("polaridade deveria ser salvar, veio %s", atualizado.Polaridade)
+	}
+
+	novos := domain.PesosModalidade{Unico: 50, Torcida: 50}
+	atualizado, err = service.AtualizarVotacao(ctx, paredao.ID, "", &novos, "")
+	if err != nil {
+		t.Fatalf("erro atualizando pesos: %v", err)
+	}
+	if atualizado.Pesos != novos {
+		t.Fatalf("pesos informados deveriam substituir os atuais, veio %+v", atualizado.Pesos)
+	}
+}
+
+func TestServiceFinalizarEncerraParedao(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		nil,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+	)
+
+	paredao, err := service.CriarParedao(context.Background(), domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(1 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	ctx := context.Background()
+	voto := domain.Voto{ParedaoID: paredao.ID, ParticipanteID: paredao.Participantes[0].ID}
+	if _, err := service.RegistrarVoto(ctx, voto); err != nil {
+		t.Fatalf("erro registrando voto: %v", err)
+	}
+
+	resultado, err := service.Finalizar(ctx, paredao.ID)
+	if err != nil {
+		t.Fatalf("erro finalizando: %v", err)
+	}
+	if resultado.TotalVotos != 1 || !resultado.FinalizadoEm.Equal(deps.baseTime) {
+		t.Fatalf("resultado inesperado: %+v", resultado)
+	}
+
+	if _, err := service.RegistrarVoto(ctx, voto); !errors.Is(err, ErrPeriodoEncerrado) {
+		t.Fatalf("paredao finalizado deveria recusar votos, veio %v", err)
+	}
+}
+
+func TestServiceFinalizarApuraEliminadoPelaPolaridade(t *testing.T) {
+	for _, caso := range []struct {
+		polaridade domain.Polaridade
+		eliminado  int
+	}{
+		{domain.PolaridadeEliminar, 0},
+		{domain.PolaridadeSalvar, 1},
+	} {
+		deps := newServiceDeps()
+		service := NewService(
+			deps.paredaoRepo,
+			deps.participanteRepo,
+			deps.votoRepo,
+			deps.contador,
+			nil,
+			deps.antifraude,
+			deps.clock,
+			deps.idGen,
+		)
+
+		ctx := context.Background()
+		paredao, err := service.CriarParedao(ctx, domain.Paredao{
+			Nome:       "Paredão",
+			Polaridade: caso.polaridade,
+			Inicio:     deps.baseTime.Add(-1 * time.Hour),
+			Fim:        deps.baseTime.Add(1 * time.Hour),
+		}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+		if err != nil {
+			t.Fatalf("erro criando paredao: %v", err)
+		}
+
+		// Alice recebe dois votos e Bruno um: eliminando sai Alice, salvando sai Bruno.
+		for _, i := range []int{0, 0, 1} {
+			voto := domain.Voto{ParedaoID: paredao.ID, ParticipanteID: paredao.Participantes[i].ID}
+			if _, err := service.RegistrarVoto(ctx, voto); err != nil {
+				t.Fatalf("erro registrando voto: %v", err)
+			}
+		}
+
+		resultado, err := service.Finalizar(ctx, paredao.ID)
+		if err != nil {
+			t.Fatalf("erro finalizando: %v", err)
+		}
+		if resultado.Polaridade != caso.polaridade {
+			t.Fatalf("polaridade inesperada: %s", resultado.Polaridade)
+		}
+		if resultado.Eliminado != paredao.Participantes[caso.eliminado].ID || len(resultado.Empatados) != 0 {
+			t.Fatalf("%s: eliminado inesperado: %+v", caso.polaridade, resultado)
+		}
+	}
+}
+
+func TestApurarSinalizaEmpateNaPonta(t *testing.T) {
+	parciais := []domain.Parcial{
+		{ParticipanteID: "a", Total: 2, Percentual: 40},
+		{ParticipanteID: "b", Total: 2, Percentual: 40},
+		{ParticipanteID: "c", Total: 1, Percentual: 20},
+	}
+
+	eliminado, empatados := domain.Apurar(domain.PolaridadeEliminar, parciais)
+	if eliminado != "" || len(empatados) != 2 {
+		t.Fatalf("empate na ponta deveria ficar sem eliminado: %s %v", eliminado, empatados)
+	}
+
+	eliminado, empatados = domain.Apurar(domain.PolaridadeSalvar, parciais)
+	if eliminado != "c" || len(empatados) != 0 {
+		t.Fatalf("salvando, o menos votado deveria sair: %s %v", eliminado, empatados)
+	}
+
+	if eliminado, _ := domain.Apurar(domain.PolaridadeSalvar, []domain.Parcial{{ParticipanteID: "a"}, {ParticipanteID: "b"}}); eliminado != "" {
+		t.Fatalf("sem votos nao deveria haver eliminado: %s", eliminado)
+	}
+}
+
+func TestServiceRetirarParticipanteAplicaPoliticaAosVotos(t *testing.T) {
+	for _, caso := range []struct {
+		politica  domain.PoliticaRetirada
+		parciais  int
+		total     int64
+		eliminado int
+	}{
+		// Alice (retirada) tem 3 votos, Bruno 2 e Carla 1; entre os que ficam, Bruno lidera com 2/3.
+		{domain.RetiradaDescartar, 2, 3, 1},
+		{domain.RetiradaManter, 3, 6, 1},
+	} {
+		deps := newServiceDeps()
+		service := NewService(deps.paredaoRepo, deps.participanteRepo, deps.votoRepo, deps.contador, nil, deps.antifraude, deps.clock, deps.idGen)
+
+		ctx := context.Background()
+		paredao, err := service.CriarParedao(ctx, domain.Paredao{
+			Nome:   "Paredão",
+			Inicio: deps.baseTime.Add(-1 * time.Hour),
+			Fim:    deps.baseTime.Add(1 * time.Hour),
+		}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}, {Nome: "Carla"}})
+		if err != nil {
+			t.Fatalf("erro criando paredao: %v", err)
+		}
+		for _, i := range []int{0, 0, 0, 1, 1, 2} {
+			voto := domain.Voto{ParedaoID: paredao.ID, ParticipanteID: paredao.Participantes[i].ID}
+			if _, err := service.RegistrarVoto(ctx, voto); err != nil {
+				t.Fatalf("erro registrando voto: %v", err)
+			}
+		}
+
+		retirada := paredao.Participantes[0].ID
+		if _, err := service.RetirarParticipante(ctx, paredao.ID, retirada, caso.politica); err != nil {
+			t.Fatalf("%s: erro retirando participante: %v", caso.politica, err)
+		}
+
+		voto := domain.Voto{ParedaoID: paredao.ID, ParticipanteID: retirada}
+		if _, err := service.RegistrarVoto(ctx, voto); !errors.Is(err, ErrParticipanteRetirado) {
+			t.Fatalf("%s: voto em participante retirado deveria ser recusado, veio %v", caso.politica, err)
+		}
+		if _, err := service.RetirarParticipante(ctx, paredao.ID, retirada, caso.politica); !errors.Is(err, ErrParticipanteRetirado) {
+			t.Fatalf("%s: retirada repetida deveria falhar, veio %v", caso.politica, err)
+		}
+
+		parciais, err := service.Parciais(ctx, paredao.ID)
+		if err != nil {
+			t.Fatalf("erro obtendo parciais: %v", err)
+		}
+		if len(parciais) != caso.parciais {
+			t.Fatalf("%s: esperava %d parciais, veio %d", caso.politica, caso.parciais, len(parciais))
+		}
+		var total int64
+		var soma float64
+		for _, parcial := range parciais {
+			total += parcial.Total
+			soma += parcial.Percentual
+			if parcial.ParticipanteID == retirada && (!parcial.Retirado || parcial.Percentual != 0) {
+				t.Fatalf("%s: retirado deveria ficar fora dos percentuais: %+v", caso.politica, parcial)
+			}
+		}
+		if total != caso.total || math.Abs(soma-100) > 1e-9 {
+			t.Fatalf("%s: total %d e soma de percentuais %.2f inesperados", caso.politica, total, soma)
+		}
+
+		resultado, err := service.Finalizar(ctx, paredao.ID)
+		if err != nil {
+			t.Fatalf("erro finalizando: %v", err)
+		}
+		if resultado.Eliminado != paredao.Participantes[caso.eliminado].ID {
+			t.Fatalf("%s: eliminado inesperado: %+v", caso.politica, resultado)
+		}
+	}
+}
+
+func TestServiceRetirarParticipanteAnulaParedao(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(deps.paredaoRepo, deps.participanteRepo, deps.votoRepo, deps.contador, nil, deps.antifraude, deps.clock, deps.idGen)
+
+	ctx := context.Background()
+	paredao, err := service.CriarParedao(ctx, domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(1 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	// Com dois participantes, descartar deixaria um só na disputa.
+	if _, err := service.RetirarParticipante(ctx, paredao.ID, paredao.Participantes[0].ID, domain.RetiradaDescartar); !errors.Is(err, ErrParedaoInvalido) {
+		t.Fatalf("retirada que deixa um participante deveria exigir anulacao, veio %v", err)
+	}
+
+	voto := domain.Voto{ParedaoID: paredao.ID, ParticipanteID: paredao.Participantes[1].ID}
+	if _, err := service.RegistrarVoto(ctx, voto); err != nil {
+		t.Fatalf("erro registrando voto: %v", err)
+	}
+
+	anulado, err := service.RetirarParticipante(ctx, paredao.ID, paredao.Participantes[0].ID, domain.RetiradaAnular)
+	if err != nil {
+		t.Fatalf("erro anulando paredao: %v", err)
+	}
+	if !anulado.Anulado || anulado.Ativo || !anulado.Fim.Equal(deps.baseTime) {
+		t.Fatalf("paredao deveria estar anulado e encerrado: %+v", anulado)
+	}
+	if _, err := service.RegistrarVoto(ctx, voto); !errors.Is(err, ErrPeriodoEncerrado) {
+		t.Fatalf("paredao anulado deveria recusar votos, veio %v", err)
+	}
+
+	resultado, err := service.Finalizar(ctx, paredao.ID)
+	if err != nil {
+		t.Fatalf("erro finalizando: %v", err)
+	}
+	if !resultado.Anulado || resultado.Eliminado != "" || resultado.TotalVotos != 1 {
+		t.Fatalf("resultado de paredao anulado inesperado: %+v", resultado)
+	}
+}
+
+func TestServiceEtapasLevamRemanescentesParaOParedaoPrincipal(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		nil,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+	)
+	ctx := context.Background()
+
+	etapas, err := service.CriarEtapas(ctx, []domain.Paredao{
+		{Nome: "Paredão 10", EtapaNome: "Bate-volta", Inicio: deps.baseTime.Add(-time.Hour), Fim: deps.baseTime.Add(time.Hour)},
+		{Nome: "Paredão 10", EtapaNome: "Paredão", Fim: deps.baseTime.Add(48 * time.Hour)},
+	}, []domain.Participante{{Nome: "Ana"}, {Nome: "Bia"}, {Nome: "Caio"}})
+	if err != nil {
+		t.Fatalf("erro criando etapas: %v", err)
+	}
+	bateVolta, principal := etapas[0], etapas[1]
+	if bateVolta.GrupoID != bateVolta.ID || principal.GrupoID != bateVolta.ID || principal.Etapa != 2 {
+		t.Fatalf("etapas mal agrupadas: %+v", etapas)
+	}
+	if bateVolta.Polaridade != domain.PolaridadeSalvar || principal.Polaridade != domain.PolaridadeEliminar {
+		t.Fatalf("polaridades padrao inesperadas: %s %s", bateVolta.Polaridade, principal.Polaridade)
+	}
+	if !principal.Inicio.Equal(bateVolta.Fim) || principal.Ativo {
+		t.Fatalf("etapa principal deveria aguardar o fim do bate-volta: %+v", principal)
+	}
+
+	// A etapa seguinte não aceita votos enquanto a anterior não terminar.
+	if _, err := service.RegistrarVoto(ctx, domain.Voto{ParedaoID: principal.ID, ParticipanteID: bateVolta.Participantes[0].ID}); !errors.Is(err, ErrPeriodoEncerrado) {
+		t.Fatalf("etapa futura deveria recusar votos, veio %v", err)
+	}
+
+	ana := bateVolta.Participantes[0]
+	for _, i := range []int{0, 0, 1} {
+		voto := domain.Voto{ParedaoID: bateVolta.ID, ParticipanteID: bateVolta.Participantes[i].ID}
+		if _, err := service.RegistrarVoto(ctx, voto); err != nil {
+			t.Fatalf("erro registrando voto: %v", err)
+		}
+	}
+
+	resultado, err := service.Finalizar(ctx, bateVolta.ID)
+	if err != nil {
+		t.Fatalf("erro finalizando bate-volta: %v", err)
+	}
+	if resultado.Escapou != ana.ID || resultado.Eliminado != "" || resultado.ProximaEtapa != principal.ID {
+		t.Fatalf("resultado do bate-volta inesperado: %+v", resultado)
+	}
+
+	// Finalizar de novo não pode duplicar os participantes da etapa seguinte.
+	if _, err := service.Finalizar(ctx, bateVolta.ID); err != nil {
+		t.Fatalf("erro refinalizando: %v", err)
+	}
+
+	seguintes, err := deps.participanteRepo.ListByParedao(ctx, principal.ID)
+	if err != nil {
+		t.Fatalf("erro listando participantes: %v", err)
+	}
+	if len(seguintes) != 2 {
+		t.Fatalf("esperava 2 remanescentes, veio %d", len(seguintes))
+	}
+	for _, part := range seguintes {
+		if part.OrigemID == ana.ID || part.Nome == "Ana" {
+			t.Fatalf("quem escapou nao deveria seguir: %+v", part)
+		}
+		if part.OrigemID == "" {
+			t.Fatalf("participante sem origem: %+v", part)
+		}
+	}
+
+	atualizado, err := deps.paredaoRepo.FindByID(ctx, principal.ID)
+	if err != nil || !atualizado.Ativo {
+		t.Fatalf("etapa principal deveria ficar ativa: %+v %v", atualizado, err)
+	}
+
+	todas, err := service.Etapas(ctx, principal.ID)
+	if err != nil || len(todas) != 2 || todas[0].ID != bateVolta.ID {
+		t.Fatalf("etapas do grupo inesperadas: %+v %v", todas, err)
+	}
+}
+
+func TestServiceCriarEtapasValidaJanelasEParticipantes(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(deps.paredaoRepo, deps.participanteRepo, deps.votoRepo, deps.contador, nil, deps.antifraude, deps.clock, deps.idGen)
+	ctx := context.Background()
+	tres := []domain.Participante{{Nome: "Ana"}, {Nome: "Bia"}, {Nome: "Caio"}}
+
+	casos := map[string]struct {
+		etapas        []domain.Paredao
+		participantes []domain.Participante
+	}{
+		"etapas sobrepostas": {
+			etapas: []domain.Paredao{
+				{Nome: "P", Fim: deps.baseTime.Add(2 * time.Hour)},
+				{Nome: "P", Inicio: deps.baseTime.Add(time.Hour), Fim: deps.baseTime.Add(3 * time.Hour)},
+			},
+			participantes: tres,
+		},
+		"participantes insuficientes": {
+			etapas: []domain.Paredao{
+				{Nome: "P", Fim: deps.baseTime.Add(time.Hour)},
+				{Nome: "P", Fim: deps.baseTime.Add(2 * time.Hour)},
+			},
+			participantes: tres[:2],
+		},
+		"intermediaria para eliminar": {
+			etapas: []domain.Paredao{
+				{Nome: "P", Polaridade: domain.PolaridadeEliminar, Fim: deps.baseTime.Add(time.Hour)},
+				{Nome: "P", Fim: deps.baseTime.Add(2 * time.Hour)},
+			},
+			participantes: tres,
+		},
+	}
+	for nome, caso := range casos {
+		if _, err := service.CriarEtapas(ctx, caso.etapas, caso.participantes); !errors.Is(err, ErrParedaoInvalido) {
+			t.Fatalf("%s: esperava ErrParedaoInvalido, veio %v", nome, err)
+		}
+	}
+}
+
+func TestServiceCriarEtapasNaoDeixaGrupoPelaMetade(t *testing.T) {
+	deps := newServiceDeps()
+	deps.paredaoRepo.falharEtapa = 2
+	service := NewService(deps.paredaoRepo, deps.participanteRepo, deps.votoRepo, deps.contador, nil, deps.antifraude, deps.clock, deps.idGen)
+	ctx := context.Background()
+
+	_, err := service.CriarEtapas(ctx, []domain.Paredao{
+		{Nome: "P", Fim: deps.baseTime.Add(time.Hour)},
+		{Nome: "P", Fim: deps.baseTime.Add(2 * time.Hour)},
+	}, []domain.Participante{{Nome: "Ana"}, {Nome: "Bia"}, {Nome: "Caio"}})
+	if err == nil {
+		t.Fatalf("esperava erro ao gravar a segunda etapa")
+	}
+	if len(deps.paredaoRepo.data) != 0 || len(deps.participanteRepo.porParedo) != 0 {
+		t.Fatalf("falha deveria descartar o grupo inteiro: %+v %+v", deps.paredaoRepo.data, deps.participanteRepo.porParedo)
+	}
+}
+
+func TestServiceEtapaEmpatadaAguardaDesempate(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(deps.paredaoRepo, deps.participanteRepo, deps.votoRepo, deps.contador, nil, deps.antifraude, deps.clock, deps.idGen)
+	ctx := context.Background()
+
+	etapas, err := service.CriarEtapas(ctx, []domain.Paredao{
+		{Nome: "P", Inicio: deps.baseTime.Add(-time.Hour), Fim: deps.baseTime.Add(time.Hour)},
+		{Nome: "P", Fim: deps.baseTime.Add(48 * time.Hour)},
+	}, []domain.Participante{{Nome: "Ana"}, {Nome: "Bia"}, {Nome: "Caio"}})
+	if err != nil {
+		t.Fatalf("erro criando etapas: %v", err)
+	}
+	bateVolta, principal := etapas[0], etapas[1]
+	ana, bia, caio := bateVolta.Participantes[0], bateVolta.Participantes[1], bateVolta.Participantes[2]
+	for _, id := range []domain.ParticipanteID{ana.ID, bia.ID} {
+		if _, err := service.RegistrarVoto(ctx, domain.Voto{ParedaoID: bateVolta.ID, ParticipanteID: id}); err != nil {
+			t.Fatalf("erro registrando voto: %v", err)
+		}
+	}
+
+	resultado, err := service.Finalizar(ctx, bateVolta.ID)
+	if err != nil {
+		t.Fatalf("erro finalizando bate-volta: %v", err)
+	}
+	if !resultado.DesempatePendente || resultado.Escapou != "" || len(resultado.Empatados) != 2 {
+		t.Fatalf("empate deveria ficar pendente: %+v", resultado)
+	}
+
+	// Só um dos empatados pode escapar.
+	if _, err := service.Desempatar(ctx, bateVolta.ID, caio.ID); !errors.Is(err, ErrParedaoInvalido) {
+		t.Fatalf("desempate fora dos empatados deveria falhar, veio %v", err)
+	}
+	resultado, err = service.Desempatar(ctx, bateVolta.ID, bia.ID)
+	if err != nil {
+		t.Fatalf("erro desempatando: %v", err)
+	}
+	if resultado.Escapou != bia.ID || resultado.ProximaEtapa != principal.ID {
+		t.Fatalf("resultado do desempate inesperado: %+v", resultado)
+	}
+	if _, err := service.Desempatar(ctx, bateVolta.ID, ana.ID); !errors.Is(err, ErrParedaoInvalido) {
+		t.Fatalf("segundo desempate deveria falhar, veio %v", err)
+	}
+
+	seguintes, err := deps.participanteRepo.ListByParedao(ctx, principal.ID)
+	if err != nil || len(seguintes) != 2 {
+		t.Fatalf("esperava 2 remanescentes, veio %+v %v", seguintes, err)
+	}
+	atualizado, err := deps.paredaoRepo.FindByID(ctx, principal.ID)
+	if err != nil || !atualizado.Ativo {
+		t.Fatalf("etapa principal deveria ficar ativa: %+v %v", atualizado, err)
+	}
+	if refeito, err := service.Finalizar(ctx, bateVolta.ID); err != nil || refeito.DesempatePendente {
+		t.Fatalf("apos o desempate nada fica pendente: %+v %v", refeito, err)
+	}
+}
+
+func TestServiceParciaisPublicasRespeitamVisibilidade(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(deps.paredaoRepo, deps.participanteRepo, deps.votoRepo, deps.contador, nil, deps.antifraude, deps.clock, deps.idGen)
+
+	ctx := context.Background()
+	paredao, err := service.CriarParedao(ctx, domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(2 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	// Um voto agora e outro 20 minutos depois.
+	for i, minutos := range []int{0, 20} {
+		deps.clock.now = deps.baseTime.Add(time.Duration(minutos) * time.Minute)
+		voto := domain.Voto{ParedaoID: paredao.ID, ParticipanteID: paredao.Participantes[i].ID}
+		if _, err := service.RegistrarVoto(ctx, voto); err != nil {
+			t.Fatalf("erro registrando voto: %v", err)
+		}
+	}
+
+	if _, err := service.AtualizarVisibilidade(ctx, paredao.ID, domain.PoliticaVisibilidade{Modo: domain.VisibilidadeAtrasada}); !errors.Is(err, ErrParedaoInvalido) {
+		t.Fatalf("modo atrasado sem atraso deveria ser recusado, veio %v", err)
+	}
+	if _, err := service.AtualizarVisibilidade(ctx, paredao.ID, domain.PoliticaVisibilidade{Modo: domain.VisibilidadeAtrasada, AtrasoMinutos: 15}); err != nil {
+		t.Fatalf("erro atualizando visibilidade: %v", err)
+	}
+
+	parciais, publicacao, err := service.ParciaisPublicas(ctx, paredao.ID)
+	if err != nil {
+		t.Fatalf("erro obtendo parciais publicas: %v", err)
+	}
+	if !publicacao.Ate.Equal(deps.baseTime.Add(5*time.Minute)) || parciais[0].Total != 1 || parciais[1].Total != 0 {
+		t.Fatalf("modo atrasado deveria contar so o primeiro voto: %+v %+v", publicacao, parciais)
+	}
+	horas, _, err := service.TotaisPorHoraPublicos(ctx, paredao.ID)
+	if err != nil || len(horas) != 1 || horas[0].Total != 1 {
+		t.Fatalf("serie por hora atrasada inesperada: %+v %v", horas, err)
+	}
+	if ao, _ := service.Parciais(ctx, paredao.ID); ao[1].Total != 1 {
+		t.Fatalf("parciais ao vivo deveriam ignorar a visibilidade: %+v", ao)
+	}
+
+	if _, err := service.AtualizarVisibilidade(ctx, paredao.ID, domain.PoliticaVisibilidade{Modo: domain.VisibilidadeOculta}); err != nil {
+		t.Fatalf("erro atualizando visibilidade: %v", err)
+	}
+	parciais, publicacao, err = service.ParciaisPublicas(ctx, paredao.ID)
+	if err != nil || !publicacao.Oculta || parciais != nil || !publicacao.LiberaEm.Equal(paredao.Fim) {
+		t.Fatalf("modo oculto nao deveria devolver numeros: %+v %+v %v", publicacao, parciais, err)
+	}
+
+	if _, err := service.Finalizar(ctx, paredao.ID); err != nil {
+		t.Fatalf("erro finalizando: %v", err)
+	}
+	parciais, publicacao, err = service.ParciaisPublicas(ctx, paredao.ID)
+	if err != nil || publicacao.Oculta || len(parciais) != 2 {
+		t.Fatalf("encerrado, o paredao deveria ficar publico: %+v %+v %v", publicacao, parciais, err)
+	}
+}
+
+func TestServiceSeriePorParticipantePreencheIntervalosEAcumulaParticipacao(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(deps.paredaoRepo, deps.participanteRepo, deps.votoRepo, deps.contador, nil, deps.antifraude, deps.clock, deps.idGen)
+
+	ctx := context.Background()
+	paredao, err := service.CriarParedao(ctx, domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(2 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	// Alice aos 0 e 2 minutos, Bruno aos 11: o intervalo das 20:05 fica sem votos.
+	for _, v := range []struct {
+		minutos      int
+		participante int
+	}{{0, 0}, {2, 0}, {11, 1}} {
+		deps.clock.now = deps.baseTime.Add(time.Duration(v.minutos) * time.Minute)
+		voto := domain.Voto{ParedaoID: paredao.ID, ParticipanteID: paredao.Participantes[v.participante].ID}
+		if _, err := service.RegistrarVoto(ctx, voto); err != nil {
+			t.Fatalf("erro registrando voto: %v", err)
+		}
+	}
+
+	intervalo, err := ParseIntervaloSerie("")
+	if err != nil || intervalo != 5*time.Minute {
+		t.Fatalf("intervalo padrao inesperado: %v %v", intervalo, err)
+	}
+	if _, err := ParseIntervaloSerie("2m"); !errors.Is(err, ErrIntervaloInvalido) {
+		t.Fatalf("esperava ErrIntervaloInvalido, veio %v", err)
+	}
+
+	series, err := service.SeriePorParticipante(ctx, paredao.ID, intervalo)
+	if err != nil {
+		t.Fatalf("erro obtendo serie: %v", err)
+	}
+	if len(series) != 2 || len(series[0].Pontos) != 3 || len(series[1].Pontos) != 3 {
+		t.Fatalf("esperava tres intervalos por participante: %+v", series)
+	}
+	if !series[0].Pontos[1].Inicio.Equal(deps.baseTime.Add(5*time.Minute)) || series[0].Pontos[1].Votos != 0 {
+		t.Fatalf("intervalo vazio deveria ser preenchido com zero: %+v", series[0].Pontos[1])
+	}
+	alice, bruno := series[0].Pontos[2], series[1].Pontos[2]
+	if alice.Acumulado != 2 || bruno.Acumulado != 1 || bruno.Votos != 1 {
+		t.Fatalf("acumulados inesperados: %+v %+v", alice, bruno)
+	}
+	if math.Abs(alice.Participacao-200.0/3) > 1e-9 || math.Abs(bruno.Participacao-100.0/3) > 1e-9 {
+		t.Fatalf("participacao inesperada: %+v %+v", alice, bruno)
+	}
+	if series[0].Pontos[0].Participacao != 100 {
+		t.Fatalf("no primeiro intervalo so Alice tinha votos: %+v", series[0].Pontos[0])
+	}
+
+	if _, err := service.AtualizarVisibilidade(ctx, paredao.ID, domain.PoliticaVisibilidade{Modo: domain.VisibilidadeOculta}); err != nil {
+		t.Fatalf("erro atualizando visibilidade: %v", err)
+	}
+	publicas, publicacao, err := service.SeriePublica(ctx, paredao.ID, intervalo)
+	if err != nil || !publicacao.Oculta || publicas != nil {
+		t.Fatalf("modo oculto nao deveria devolver a serie: %+v %+v %v", publicacao, publicas, err)
+	}
+}
+
+func TestServiceProjecaoFinalExtrapolaTendenciaRecente(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(deps.paredaoRepo, deps.participanteRepo, deps.votoRepo, deps.contador, nil, deps.antifraude, deps.clock, deps.idGen)
+	ctx := context.Background()
+	paredao, err := service.CriarParedao(ctx, domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(1 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	// Arrange: 10 votos a cada 5 minutos na primeira hora; Alice leva 8 por intervalo na primeira meia hora
+	// e só 4 na segunda. Alice tem 60% agora, mas só 40% dos votos recentes.
+	alice, bruno := paredao.Participantes[0].ID, paredao.Participantes[1].ID
+	for intervalo := range 12 {
+		votosAlice := 8
+		if intervalo >= 6 {
+			votosAlice = 4
+		}
+		for n := range 10 {
+			participante := bruno
+			if n < votosAlice {
+				participante = alice
+			}
+			criadoEm := deps.baseTime.Add(-time.Hour + time.Duration(intervalo)*5*time.Minute + time.Duration(n)*time.Second)
+			if err := deps.votoRepo.Registrar(ctx, domain.Voto{ID: domain.VotoID(deps.idGen.New()), ParedaoID: paredao.ID, ParticipanteID: participante, CriadoEm: criadoEm}); err != nil {
+				t.Fatalf("erro registrando voto: %v", err)
+			}
+		}
+	}
+
+	// Act
+	projecao, err := service.ProjecaoFinal(ctx, paredao.ID, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("erro projetando: %v", err)
+	}
+
+	// Assert: mais uma hora no ritmo de 2 votos por minuto, divididos como na última meia hora
+	if !projecao.Estimativa || projecao.VotosAtuais != 120 || projecao.VotosProjetados != 240 {
+		t.Fatalf("projecao inesperada: %+v", projecao)
+	}
+	a, b := projecao.Participantes[0], projecao.Participantes[1]
+	if math.Abs(a.Atual-60) > 1e-9 || math.Abs(a.Projetada-50) > 1e-9 || math.Abs(b.Projetada-50) > 1e-9 {
+		t.Fatalf("percentuais inesperados: %+v %+v", a, b)
+	}
+	if a.Minima >= a.Projetada || a.Maxima <= a.Projetada || a.Minima < 40 || a.Maxima > 60 {
+		t.Fatalf("faixa inesperada: %+v", a)
+	}
+
+	// Depois do fim não há o que extrapolar.
+	deps.clock.now = paredao.Fim.Add(time.Minute)
+	encerrada, err := service.ProjecaoFinal(ctx, paredao.ID, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("erro projetando: %v", err)
+	}
+	if p := encerrada.Participantes[0]; p.Projetada != p.Atual || p.Minima != p.Atual || p.Maxima != p.Atual {
+		t.Fatalf("apos o fim a projecao deveria ser o atual: %+v", p)
+	}
+}
+
+func TestServiceProjecaoFinalPonderaUrnasComoAsParciais(t *testing.T) {
+	deps := newServiceDeps()
+	service := NewService(deps.paredaoRepo, deps.participanteRepo, deps.votoRepo, deps.contador, nil, deps.antifraude, deps.clock, deps.idGen)
+	ctx := context.Background()
+	paredao, err := service.CriarParedao(ctx, domain.Paredao{
+		Nome:        "Paredão",
+		Inicio:      deps.baseTime.Add(-1 * time.Hour),
+		Fim:         deps.baseTime.Add(1 * time.Hour),
+		ModoVotacao: domain.ModoMisto,
+		Pesos:       domain.PesosModalidade{Unico: 1, Torcida: 1},
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}, {Nome: "Caio"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	// Arrange: no único Alice tem 25% e Bruno 75%; na torcida, 90% e 10%. Caio sai com os votos descartados.
+	alice, bruno, caio := paredao.Participantes[0].ID, paredao.Participantes[1].ID, paredao.Participantes[2].ID
+	votos := []struct {
+		participante domain.ParticipanteID
+		modalidade   domain.Modalidade
+		quantidade   int
+	}{
+		{alice, domain.ModalidadeUnico, 1}, {bruno, domain.ModalidadeUnico, 3},
+		{alice, domain.ModalidadeTorcida, 9}, {bruno, domain.ModalidadeTorcida, 1},
+		{caio, domain.ModalidadeTorcida, 50},
+	}
+	for _, v := range votos {
+		for n := range v.quantidade {
+			criadoEm := deps.baseTime.Add(-30*time.Minute + time.Duration(n)*time.Second)
+			voto := domain.Voto{ID: domain.VotoID(deps.idGen.New()), ParedaoID: paredao.ID, ParticipanteID: v.participante, Modalidade: v.modalidade, CriadoEm: criadoEm}
+			if err := deps.votoRepo.Registrar(ctx, voto); err != nil {
+				t.Fatalf("erro registrando voto: %v", err)
+			}
+		}
+	}
+	if _, err := service.RetirarParticipante(ctx, paredao.ID, caio, domain.RetiradaDescartar); err != nil {
+		t.Fatalf("erro retirando participante: %v", err)
+	}
+	deps.clock.now = paredao.Fim.Add(time.Minute)
+
+	// Act
+	projecao, err := service.ProjecaoFinal(ctx, paredao.ID, 5*time.Minute)
+	if err != nil {
+		t.Fatalf("erro projetando: %v", err)
+	}
+	parciais, err := service.Parciais(ctx, paredao.ID)
+	if err != nil {
+		t.Fatalf("erro lendo parciais: %v", err)
+	}
+
+	// Assert: encerrada, a projeção é a parcial oficial (57,5% e 42,5%), não a participação bruta.
+	if len(projecao.Participantes) != 2 || projecao.VotosAtuais != 14 {
+		t.Fatalf("descartado deveria ficar fora da projecao: %+v", projecao)
+	}
+	for i, p := range projecao.Participantes {
+		if p.ParticipanteID != parciais[i].ParticipanteID || math.Abs(p.Atual-parciais[i].Percentual) > 1e-9 || math.Abs(p.Projetada-parciais[i].Percentual) > 1e-9 {
+			t.Fatalf("projecao %+v diverge da parcial %+v", p, parciais[i])
+		}
+	}
+	if math.Abs(projecao.Participantes[0].Projetada-57.5) > 1e-9 {
+		t.Fatalf("esperava 57,5%% para Alice, veio %+v", projecao.Participantes[0])
+	}
+}
+
+func TestServiceDetectarViradasRegistraTrocaDeLideranca(t *testing.T) {
+	deps := newServiceDeps()
+	viradas := &inMemoryViradas{}
+	service := NewService(deps.paredaoRepo, deps.participanteRepo, deps.votoRepo, deps.contador, nil, deps.antifraude, deps.clock, deps.idGen,
+		ComViradas(viradas, 2))
+
+	ctx := context.Background()
+	paredao, err := service.CriarParedao(ctx, domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(2 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+	alice, bruno := paredao.Participantes[0].ID, paredao.Participantes[1].ID
+	votar := func(id domain.ParticipanteID) {
+		t.Helper()
+		if _, err := service.RegistrarVoto(ctx, domain.Voto{ParedaoID: paredao.ID, ParticipanteID: id}); err != nil {
+			t.Fatalf("erro registrando voto: %v", err)
+		}
+	}
+	detectar := func() []domain.Virada {
+		t.Helper()
+		novas, err := service.DetectarViradas(ctx)
+		if err != nil {
+			t.Fatalf("erro detectando viradas: %v", err)
+		}
+		return novas
+	}
+
+	// Abaixo do mínimo de votos a ponta não é acompanhada.
+	votar(alice)
+	if novas := detectar(); len(novas) != 0 {
+		t.Fatalf("com um voto nao deveria haver virada: %+v", novas)
+	}
+
+	// Alice assume a ponta: a primeira virada não tem líder anterior.
+	votar(alice)
+	novas := detectar()
+	if len(novas) != 1 || novas[0].LiderAnterior != "" || novas[0].NovoLider != alice || novas[0].Ordem != 1 {
+		t.Fatalf("primeira lideranca inesperada: %+v", novas)
+	}
+	if novas[0].Margem != 100 {
+		t.Fatalf("margem sobre Bruno deveria ser 100 pontos: %+v", novas[0])
+	}
+	if novas := detectar(); len(novas) != 0 {
+		t.Fatalf("sem troca na ponta nao deveria registrar nada: %+v", novas)
+	}
+
+	// Empate não troca o líder; a virada vem quando Bruno passa à frente.
+	votar(bruno)
+	votar(bruno)
+	if novas := detectar(); len(novas) != 0 {
+		t.Fatalf("empate nao deveria virar: %+v", novas)
+	}
+	votar(bruno)
+	novas = detectar()
+	if len(novas) != 1 || novas[0].LiderAnterior != alice || novas[0].NovoLider != bruno || novas[0].Ordem != 2 || novas[0].TotalVotos != 5 {
+		t.Fatalf("virada inesperada: %+v", novas)
+	}
+	if math.Abs(novas[0].Margem-20) > 1e-9 {
+		t.Fatalf("margem deveria ser 20 pontos: %+v", novas[0])
+	}
+
+	if _, err := service.AtualizarVisibilidade(ctx, paredao.ID, domain.PoliticaVisibilidade{Modo: domain.VisibilidadeOculta}); err != nil {
+		t.Fatalf("erro atualizando visibilidade: %v", err)
+	}
+	publicas, publicacao, err := service.ViradasPublicas(ctx, paredao.ID)
+	if err != nil || !publicacao.Oculta || publicas != nil {
+		t.Fatalf("modo oculto nao deveria expor viradas: %+v %+v %v", publicacao, publicas, err)
+	}
+	if todas, err := service.Viradas(ctx, paredao.ID); err != nil || len(todas) != 2 {
+		t.Fatalf("a producao deveria ver as duas viradas: %+v %v", todas, err)
+	}
+}
+
+func TestServicePublicaEventosDoParedaoUmaVezSo(t *testing.T) {
+	deps := newServiceDeps()
+	eventos := &inMemoryEventos{}
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		nil,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+		ComWebhooks(eventos),
+	)
+	ctx := context.Background()
+
+	if _, err := service.CriarWebhook(ctx, domain.Webhook{URL: "ftp://x", Eventos: []domain.TipoEvento{domain.EventoVirada}}); !errors.Is(err, ErrWebhookInvalido) {
+		t.Fatalf("url fora de http deveria ser recusada, veio %v", err)
+	}
+	if _, err := service.CriarWebhook(ctx, domain.Webhook{URL: "https://x.example", Eventos: []domain.TipoEvento{"paredao.outro"}}); !errors.Is(err, ErrWebhookInvalido) {
+		t.Fatalf("evento desconhecido deveria ser recusado, veio %v", err)
+	}
+	criado, err := service.CriarWebhook(ctx, domain.Webhook{URL: "https://x.example/hook", Eventos: domain.TiposEvento})
+	if err != nil {
+		t.Fatalf("erro criando webhook: %v", err)
+	}
+	if len(criado.Segredo) != 64 || !criado.Ativo || criado.ID == "" {
+		t.Fatalf("webhook criado sem segredo gerado: %+v", criado)
+	}
+
+	paredao, err := service.CriarParedao(ctx, domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(1 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	// Act: o worker publica os marcos a cada ciclo e o admin finaliza duas vezes
+	for range 2 {
+		if err := service.PublicarMarcos(ctx); err != nil {
+			t.Fatalf("erro publicando marcos: %v", err)
+		}
+	}
+	for range 2 {
+		if _, err := service.Finalizar(ctx, paredao.ID); err != nil {
+			t.Fatalf("erro finalizando: %v", err)
+		}
+	}
+
+	var tipos []domain.TipoEvento
+	for _, e := range eventos.eventos {
+		tipos = append(tipos, e.Tipo)
+	}
+	if !slices.Equal(tipos, []domain.TipoEvento{domain.EventoParedaoAberto, domain.EventoParedaoFinalizado}) {
+		t.Fatalf("eventos inesperados: %v", tipos)
+	}
+	if eventos.eventos[1].ParedaoID != paredao.ID || !json.Valid(eventos.eventos[1].Dados) {
+		t.Fatalf("evento de finalizacao inesperado: %+v", eventos.eventos[1])
+	}
+}
+
+func TestServiceStatusVotoConsultaRedisEDepoisPostgres(t *testing.T) {
+	deps := newServiceDeps()
+	status := newInMemoryStatusVotos()
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		deps.queue,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+		ComStatusVotos(status),
+	)
+
+	paredao, err := service.CriarParedao(context.Background(), domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(1 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	resultado, err := service.RegistrarVoto(context.Background(), domain.Voto{
+		ParedaoID:      paredao.ID,
+		ParticipanteID: paredao.Participantes[0].ID,
+		OrigemIP:       "127.0.0.1",
+	})
+	if err != nil {
+		t.Fatalf("erro registrando voto: %v", err)
+	}
+
+	atual, err := service.StatusVoto(context.Background(), resultado.VotoID)
+	if err != nil || atual.Estado != domain.VotoEnfileirado {
+		t.Fatalf("voto recém-aceito deveria estar enfileirado, veio %+v (err %v)", atual, err)
+	}
+
+	// Sem o registro no Redis (TTL expirado), o voto gravado pelo worker ainda é encontrado no Postgres.
+	status.apagar(resultado.VotoID)
+	voto := deps.queue.votos[0]
+	voto.CriadoEm = deps.baseTime
+	if err := deps.votoRepo.Registrar(context.Background(), voto); err != nil {
+		t.Fatalf("erro gravando voto: %v", err)
+	}
+	atual, err = service.StatusVoto(context.Background(), resultado.VotoID)
+	if err != nil || atual.Estado != domain.VotoProcessado {
+		t.Fatalf("voto gravado deveria constar como processado, veio %+v (err %v)", atual, err)
+	}
+
+	if _, err := service.StatusVoto(context.Background(), "desconhecido"); !errors.Is(err, ErrVotoNaoEncontrado) {
+		t.Fatalf("voto desconhecido deveria retornar ErrVotoNaoEncontrado, veio %v", err)
+	}
+}
+
+type inMemoryStatusVotos struct {
+	mu     sync.Mutex
+	status map[domain.VotoID]domain.StatusVoto
+}
+
+func newInMemoryStatusVotos() *inMemoryStatusVotos {
+	return &inMemoryStatusVotos{status: make(map[domain.VotoID]domain.StatusVoto)}
+}
+
+func (s *inMemoryStatusVotos) Salvar(_ context.Context, status domain.StatusVoto) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status[status.VotoID] = status
+	return nil
+}
+
+func (s *inMemoryStatusVotos) Obter(_ context.Context, id domain.VotoID) (domain.StatusVoto, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status, ok := s.status[id]
+	if !ok {
+		return domain.StatusVoto{}, domain.ErrNotFound
+	}
+	return status, nil
+}
+
+func (s *inMemoryStatusVotos) apagar(id domain.VotoID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.status, id)
+}
+
+func TestServiceReciboConfereVotoGravadoEAnulacao(t *testing.T) {
+	deps := newServiceDeps()
+	assinador, err := recibos.NewAssinadorEfemero()
+	if err != nil {
+		t.Fatalf("erro gerando chave: %v", err)
+	}
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		deps.queue,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+		ComRecibos(assinador),
+	)
+
+	paredao, err := service.CriarParedao(context.Background(), domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(1 * time.Hour),
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}, {Nome: "Carla"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+
+	resultado, err := service.RegistrarVoto(context.Background(), domain.Voto{
+		ParedaoID:      paredao.ID,
+		ParticipanteID: paredao.Participantes[0].ID,
+		OrigemIP:       "127.0.0.1",
+	})
+	if err != nil {
+		t.Fatalf("erro registrando voto: %v", err)
+	}
+	if resultado.Recibo == nil || resultado.Recibo.VotoID != resultado.VotoID {
+		t.Fatalf("voto aceito deveria trazer recibo do proprio voto, veio %+v", resultado.Recibo)
+	}
+	recibo := *resultado.Recibo
+
+	verificacao, err := service.VerificarRecibo(context.Background(), recibo)
+	if err != nil || !verificacao.AssinaturaValida || verificacao.VotoEncontrado || verificacao.Valido {
+		t.Fatalf("voto ainda na fila deveria ter assinatura valida mas nao constar gravado, veio %+v (err %v)", verificacao, err)
+	}
+
+	if err := deps.votoRepo.Registrar(context.Background(), deps.queue.votos[0]); err != nil {
+		t.Fatalf("erro gravando voto: %v", err)
+	}
+	verificacao, err = service.VerificarRecibo(context.Background(), recibo)
+	if err != nil || !verificacao.Valido {
+		t.Fatalf("voto gravado deveria ter recibo valido, veio %+v (err %v)", verificacao, err)
+	}
+
+	adulterado := recibo
+	adulterado.VotoID = "outro-voto"
+	if verificacao, _ := service.VerificarRecibo(context.Background(), adulterado); verificacao.AssinaturaValida || verificacao.Valido {
+		t.Fatalf("recibo adulterado nao deveria conferir, veio %+v", verificacao)
+	}
+
+	if _, err := service.RetirarParticipante(context.Background(), paredao.ID, paredao.Participantes[0].ID, domain.RetiradaDescartar); err != nil {
+		t.Fatalf("erro retirando participante: %v", err)
+	}
+	verificacao, err = service.VerificarRecibo(context.Background(), recibo)
+	if err != nil || verificacao.Valido || !verificacao.Anulado {
+		t.Fatalf("voto descartado pela retirada deveria constar anulado, veio %+v (err %v)", verificacao, err)
+	}
+}
+
+// cadeiaVotosRepo expõe os votos gravados no repositório em memória como a cadeia de auditoria.
+type cadeiaVotosRepo struct {
+	votos *inMemoryVotoRepo
+}
+
+func (cadeiaVotosRepo) PendentesDeCheckpoint(context.Context) ([]domain.CadeiaVotos, error) {
+	return nil, nil
+}
+
+func (cadeiaVotosRepo) SalvarCheckpoint(context.Context, domain.Checkpoint) error {
+	return nil
+}
+
+func (cadeiaVotosRepo) Checkpoints(context.Context, domain.ParedaoID) ([]domain.Checkpoint, error) {
+	return nil, nil
+}
+
+func (c cadeiaVotosRepo) ExportarVotos(_ context.Context, paredaoID domain.ParedaoID, emitir func(domain.VotoAuditado) error) error {
+	c.votos.mu.Lock()
+	votos := append([]domain.Voto(nil), c.votos.lista...)
+	c.votos.mu.Unlock()
+	for i, v := range votos {
+		if v.ParedaoID != paredaoID {
+			continue
+		}
+		if err := emitir(domain.VotoAuditado{Seq: int64(i + 1), VotoID: v.ID, ParedaoID: v.ParedaoID, ParticipanteID: v.ParticipanteID, OrigemIP: v.OrigemIP}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type pseudonimosFixos struct{}
+
+func (pseudonimosFixos) Pseudonimo(paredaoID domain.ParedaoID, _ string) string {
+	return "pseudonimo-" + string(paredaoID)
+}
+
+func TestServicePacoteAuditoriaExigeParedaoFinalizado(t *testing.T) {
+	deps := newServiceDeps()
+	assinador, err := recibos.NewAssinadorEfemero()
+	if err != nil {
+		t.Fatalf("erro criando assinador: %v", err)
+	}
+	service := NewService(
+		deps.paredaoRepo,
+		deps.participanteRepo,
+		deps.votoRepo,
+		deps.contador,
+		deps.queue,
+		deps.antifraude,
+		deps.clock,
+		deps.idGen,
+		ComRecibos(assinador),
+		ComAuditoria(cadeiaVotosRepo{votos: deps.votoRepo}),
+		ComPseudonimos(pseudonimosFixos{}),
+	)
+
+	ctx := context.Background()
+	paredao, err := service.CriarParedao(ctx, domain.Paredao{
+		Nome:   "Paredão",
+		Inicio: deps.baseTime.Add(-1 * time.Hour),
+		Fim:    deps.baseTime.Add(1 * time.Hour),
+		Ativo:  true,
+	}, []domain.Participante{{Nome: "Alice"}, {Nome: "Bruno"}})
+	if err != nil {
+		t.Fatalf("erro criando paredao: %v", err)
+	}
+	for _, i := range []int{0, 0, 1} {
+		voto := domain.Voto{ParedaoID: paredao.ID, ParticipanteID: paredao.Participantes[i].ID, OrigemIP: "10.0.0.1"}
+		if err := deps.votoRepo.Registrar(ctx, voto); err != nil {
+			t.Fatalf("erro gravando voto: %v", err)
+		}
+	}
+
+	if _, err := service.PacoteAuditoria(ctx, paredao.ID); !errors.Is(err, ErrParedaoAberto) {
+		t.Fatalf("paredao aberto nao deveria gerar pacote, veio %v", err)
+	}
+
+	finalizado, err := service.Finalizar(ctx, paredao.ID)
+	if err != nil {
+		t.Fatalf("erro finalizando: %v", err)
+	}
+	pacote, err := service.PacoteAuditoria(ctx, paredao.ID)
+	if err != nil {
+		t.Fatalf("erro montando pacote: %v", err)
+	}
+	if pacote.Resultado.Eliminado != finalizado.Eliminado || pacote.Resultado.TotalVotos != 3 || len(pacote.Participantes) != 2 {
+		t.Fatalf("pacote deveria trazer o resultado oficial e os participantes, veio %+v", pacote)
+	}
+
+	var ips []string
+	err = service.ExportarVotos(ctx, paredao.ID, func(v domain.VotoAuditado) error {
+		ips = append(ips, v.OrigemIP)
+		return nil
+	})
+	if err != nil || len(ips) != 3 {
+		t.Fatalf("esperava exportar 3 votos, veio %d (err %v)", len(ips), err)
+	}
+	for _, ip := range ips {
+		if ip == "10.0.0.1" || ip != ips[0] {
+			t.Fatalf("IPs exportados deveriam sair com o mesmo pseudonimo, veio %v", ips)
+		}
+	}
+}
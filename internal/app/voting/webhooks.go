@@ -0,0 +1,184 @@
+package voting
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// janelaMarcos limita até quando um início ou fim de paredão ainda vira evento: um worker que volta
+// depois de horas parado não dispara aberturas e encerramentos antigos.
+const janelaMarcos = 24 * time.Hour
+
+// limiteEntregas é o tamanho padrão (e máximo) da página do registro de entregas.
+const limiteEntregas = 100
+
+var (
+	ErrWebhookInvalido       = errors.New("webhook invalido")
+	ErrWebhookNaoEncontrado  = errors.New("webhook nao encontrado")
+	ErrWebhooksDesabilitados = errors.New("webhooks indisponiveis")
+	ErrEntregaNaoEncontrada  = errors.New("entrega nao encontrada")
+)
+
+// ComWebhooks publica os eventos do paredão (abertura, encerramento, finalização e viradas) na fila de
+// entregas e habilita o cadastro de webhooks no admin.
+func ComWebhooks(webhooks domain.WebhookRepository) Option {
+	return func(s *Service) {
+		s.webhooks = webhooks
+	}
+}
+
+// marcoParedao é o dado dos eventos de abertura e encerramento.
+type marcoParedao struct {
+	Nome   string    `json:"nome"`
+	Inicio time.Time `json:"inicio"`
+	Fim    time.Time `json:"fim"`
+}
+
+// publicarEvento grava o evento para entrega; sem webhooks configurados, não faz nada. A chave deduplica
+// o mesmo fato publicado mais de uma vez.
+func (s *Service) publicarEvento(ctx context.Context, tipo domain.TipoEvento, paredaoID domain.ParedaoID, chave string, dados any) error {
+	if s.webhooks == nil {
+		return nil
+	}
+	corpo, err := json.Marshal(dados)
+	if err != nil {
+		return fmt.Errorf("evento %s: %w", tipo, err)
+	}
+	_, err = s.webhooks.Publicar(ctx, domain.EventoParedao{
+		ID:         domain.EventoID(s.ids.New()),
+		Chave:      fmt.Sprintf("%s:%s:%s", tipo, paredaoID, chave),
+		Tipo:       tipo,
+		ParedaoID:  paredaoID,
+		OcorridoEm: s.clock.Agora(),
+		Dados:      corpo,
+	})
+	if err != nil {
+		return fmt.Errorf("evento %s: %w", tipo, err)
+	}
+	return nil
+}
+
+// PublicarMarcos publica a abertura e o encerramento dos paredões que começaram ou terminaram nas últimas
+// horas. Os eventos já publicados são ignorados pela chave, então o worker pode chamar a cada ciclo.
+func (s *Service) PublicarMarcos(ctx context.Context) error {
+	if s.webhooks == nil {
+		return ErrWebhooksDesabilitados
+	}
+	agora := s.clock.Agora()
+	desde := agora.Add(-janelaMarcos)
+	paredoes, err := s.paredoes.ListComMarco(ctx, desde, agora)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, p := range paredoes {
+		marco := marcoParedao{Nome: p.Nome, Inicio: p.Inicio, Fim: p.Fim}
+		if p.Inicio.After(desde) && !p.Inicio.After(agora) {
+			errs = append(errs, s.publicarEvento(ctx, domain.EventoParedaoAberto, p.ID, "", marco))
+		}
+		if p.Fim.After(desde) && !p.Fim.After(agora) {
+			errs = append(errs, s.publicarEvento(ctx, domain.EventoParedaoEncerrado, p.ID, "", marco))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CriarWebhook cadastra a assinatura; sem segredo informado, gera um aleatório. O segredo só volta nesta
+// resposta.
+func (s *Service) CriarWebhook(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	if s.webhooks == nil {
+		return domain.Webhook{}, ErrWebhooksDesabilitados
+	}
+	destino, err := url.Parse(webhook.URL)
+	if err != nil || (destino.Scheme != "http" && destino.Scheme != "https") || destino.Host == "" {
+		return domain.Webhook{}, fmt.Errorf("%w: url deve ser http ou https absoluta", ErrWebhookInvalido)
+	}
+	if len(webhook.Eventos) == 0 {
+		return domain.Webhook{}, fmt.Errorf("%w: informe ao menos um evento", ErrWebhookInvalido)
+	}
+	for _, tipo := range webhook.Eventos {
+		if !slices.Contains(domain.TiposEvento, tipo) {
+			return domain.Webhook{}, fmt.Errorf("%w: evento %q desconhecido", ErrWebhookInvalido, tipo)
+		}
+	}
+	if webhook.Segredo == "" {
+		aleatorio := make([]byte, 32)
+		if _, err := rand.Read(aleatorio); err != nil {
+			return domain.Webhook{}, err
+		}
+		webhook.Segredo = hex.EncodeToString(aleatorio)
+	}
+
+	webhook.ID = domain.WebhookID(s.ids.New())
+	webhook.Ativo = true
+	webhook.CriadoEm = s.clock.Agora()
+	if err := s.webhooks.Criar(ctx, webhook); err != nil {
+		return domain.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// ListarWebhooks devolve as assinaturas sem os segredos.
+func (s *Service) ListarWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	if s.webhooks == nil {
+		return nil, ErrWebhooksDesabilitados
+	}
+	webhooks, err := s.webhooks.Listar(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].Segredo = ""
+	}
+	return webhooks, nil
+}
+
+// DesativarWebhook para de gerar entregas para a assinatura; as pendentes ainda são tentadas.
+func (s *Service) DesativarWebhook(ctx context.Context, id domain.WebhookID) error {
+	if s.webhooks == nil {
+		return ErrWebhooksDesabilitados
+	}
+	if err := s.webhooks.Desativar(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return ErrWebhookNaoEncontrado
+		}
+		return err
+	}
+	return nil
+}
+
+// EntregasWebhook devolve o registro de entregas da assinatura, das mais recentes para as mais antigas.
+func (s *Service) EntregasWebhook(ctx context.Context, id domain.WebhookID, limite int) ([]domain.EntregaWebhook, error) {
+	if s.webhooks == nil {
+		return nil, ErrWebhooksDesabilitados
+	}
+	if limite <= 0 || limite > limiteEntregas {
+		limite = limiteEntregas
+	}
+	return s.webhooks.Entregas(ctx, id, limite)
+}
+
+// ReenviarEntrega recoloca uma entrega na fila, com as tentativas zeradas, mesmo que já tenha sido entregue.
+func (s *Service) ReenviarEntrega(ctx context.Context, id domain.WebhookID, entrega domain.EntregaID) (domain.EntregaWebhook, error) {
+	if s.webhooks == nil {
+		return domain.EntregaWebhook{}, ErrWebhooksDesabilitados
+	}
+	reenviada, err := s.webhooks.Reenviar(ctx, id, entrega, s.clock.Agora())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.EntregaWebhook{}, ErrEntregaNaoEncontrada
+		}
+		return domain.EntregaWebhook{}, err
+	}
+	return reenviada, nil
+}
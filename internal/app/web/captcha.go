@@ -0,0 +1,62 @@
+package web
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// widgetCaptcha é o que a tela de voto precisa para desenhar o desafio do provedor.
+type widgetCaptcha struct {
+	Script  string
+	Classe  string
+	SiteKey string
+}
+
+// provedorCaptcha descreve um provedor do protocolo siteverify: o widget injeta no formulário um campo
+// com o token, de nome próprio de cada provedor.
+type provedorCaptcha struct {
+	host   string
+	script string
+	classe string
+	campo  string
+}
+
+var provedoresCaptcha = []provedorCaptcha{
+	{host: "hcaptcha.com", script: "https://js.hcaptcha.com/1/api.js", classe: "h-captcha", campo: "h-captcha-response"},
+	{host: "challenges.cloudflare.com", script: "https://challenges.cloudflare.com/turnstile/v0/api.js", classe: "cf-turnstile", campo: "cf-turnstile-response"},
+	{host: "google.com", script: "https://www.google.com/recaptcha/api.js", classe: "g-recaptcha", campo: "g-recaptcha-response"},
+	{host: "recaptcha.net", script: "https://www.recaptcha.net/recaptcha/api.js", classe: "g-recaptcha", campo: "g-recaptcha-response"},
+}
+
+// ComCaptcha reconhece o provedor pela URL de verificação do antifraude e desenha o widget dele nos
+// paredões que exigem CAPTCHA. Com provedor desconhecido ou sem site key, a tela pede o token num campo
+// de texto, o que basta para provedores de teste.
+func ComCaptcha(verifyURL, siteKey string) Option {
+	return func(f *Frontend) {
+		endereco, err := url.Parse(verifyURL)
+		if err != nil || siteKey == "" {
+			return
+		}
+		host := endereco.Hostname()
+		for _, p := range provedoresCaptcha {
+			if host == p.host || strings.HasSuffix(host, "."+p.host) {
+				f.captcha = &widgetCaptcha{Script: p.script, Classe: p.classe, SiteKey: siteKey}
+				return
+			}
+		}
+	}
+}
+
+// tokenCaptcha lê o token do campo próprio da tela ou, quando o widget o injetou, do campo do provedor.
+func tokenCaptcha(r *http.Request) string {
+	if token := r.FormValue("captcha_token"); token != "" {
+		return token
+	}
+	for _, p := range provedoresCaptcha {
+		if token := r.FormValue(p.campo); token != "" {
+			return token
+		}
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/app/voting"
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/antifraude"
+)
+
+func telaDeVoto(t *testing.T, exigeCaptcha bool, opts ...Option) string {
+	t.Helper()
+	agora := time.Now()
+	paredao := domain.Paredao{
+		ID:         "par-1",
+		Nome:       "Paredão",
+		Inicio:     agora.Add(-time.Hour),
+		Fim:        agora.Add(time.Hour),
+		Ativo:      true,
+		Antifraude: domain.PoliticaAntifraude{ExigeCaptcha: exigeCaptcha},
+	}
+	paredoes := paredoesFixos{paredoes: []domain.Paredao{paredao}}
+	politicas := antifraude.NewResolvedorPoliticas(paredoes, domain.PoliticaAntifraude{}, 0)
+	servico := voting.NewService(paredoes, semParticipantes{}, nil, nil, nil, nil, relogioFixo(agora), nil, voting.ComPoliticas(politicas))
+	frontend, err := New(servico, "", opts...)
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	frontend.Register(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/vote", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	return w.Body.String()
+}
+
+func TestVote_QuandoParedaoExigeCaptcha_DeveDesenharWidgetDoProvedor(t *testing.T) {
+	pagina := telaDeVoto(t, true, ComCaptcha("https://api.hcaptcha.com/siteverify", "chave-do-site"))
+
+	assert.Contains(t, pagina, `class="h-captcha" data-sitekey="chave-do-site"`)
+	assert.Contains(t, pagina, `src="https://js.hcaptcha.com/1/api.js"`)
+}
+
+func TestVote_QuandoProvedorDesconhecido_DevePedirTokenNoFormulario(t *testing.T) {
+	pagina := telaDeVoto(t, true, ComCaptcha("http://captcha.local/verificar", "chave"))
+
+	assert.Contains(t, pagina, `name="captcha_token"`)
+	assert.NotContains(t, pagina, "<script src=")
+}
+
+func TestVote_QuandoParedaoNaoExigeCaptcha_NaoDeveDesenharWidget(t *testing.T) {
+	pagina := telaDeVoto(t, false, ComCaptcha("https://api.hcaptcha.com/siteverify", "chave-do-site"))
+
+	assert.NotContains(t, pagina, "h-captcha")
+	assert.NotContains(t, pagina, `name="captcha_token"`)
+}
+
+func TestTokenCaptcha_DeveAceitarCampoDaTelaOuDoProvedor(t *testing.T) {
+	casos := []struct {
+		nome     string
+		form     url.Values
+		esperado string
+	}{
+		{nome: "campo da tela", form: url.Values{"captcha_token": {"t1"}}, esperado: "t1"},
+		{nome: "hcaptcha", form: url.Values{"h-captcha-response": {"t2"}}, esperado: "t2"},
+		{nome: "turnstile", form: url.Values{"cf-turnstile-response": {"t3"}}, esperado: "t3"},
+		{nome: "recaptcha", form: url.Values{"g-recaptcha-response": {"t4"}}, esperado: "t4"},
+		{nome: "sem token", form: url.Values{}, esperado: ""},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/vote", strings.NewReader(c.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			assert.Equal(t, c.esperado, tokenCaptcha(req))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/app/voting"
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// paredoesFixos atende só às leituras que a consulta faz antes de buscar os números.
+type paredoesFixos struct {
+	domain.ParedaoRepository
+	paredoes []domain.Paredao
+}
+
+func (r paredoesFixos) ListAtivos(context.Context) ([]domain.Paredao, error) {
+	return r.paredoes, nil
+}
+
+func (r paredoesFixos) FindByID(_ context.Context, id domain.ParedaoID) (domain.Paredao, error) {
+	for _, p := range r.paredoes {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return domain.Paredao{}, domain.ErrNotFound
+}
+
+type semParticipantes struct {
+	domain.ParticipanteRepository
+}
+
+func (semParticipantes) ListByParedao(context.Context, domain.ParedaoID) ([]domain.Participante, error) {
+	return nil, nil
+}
+
+type relogioFixo time.Time
+
+func (r relogioFixo) Agora() time.Time {
+	return time.Time(r)
+}
+
+func TestConsulta_QuandoSemTokenEParciaisNaoPublicas_NaoDeveMostrarNumeros(t *testing.T) {
+	agora := time.Date(2024, 11, 10, 22, 0, 0, 0, time.UTC)
+	paredao := domain.Paredao{
+		ID:           "par-1",
+		Nome:         "Paredão oculto",
+		Inicio:       agora.Add(-time.Hour),
+		Fim:          agora.Add(time.Hour),
+		Ativo:        true,
+		Visibilidade: domain.PoliticaVisibilidade{Modo: domain.VisibilidadeOculta},
+	}
+	servico := voting.NewService(paredoesFixos{paredoes: []domain.Paredao{paredao}}, semParticipantes{}, nil, nil, nil, nil, relogioFixo(agora), nil)
+	frontend, err := New(servico, "")
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	frontend.Register(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/consulta", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Paredão oculto")
+	assert.Contains(t, w.Body.String(), "defina CONSULTA_TOKEN")
+	assert.NotContains(t, w.Body.String(), "Total Geral de Votos")
+}
@@ -0,0 +1,83 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatacaoDeHorariosNoFusoDosRelatorios(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+	novaYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	casos := []struct {
+		nome     string
+		instante time.Time
+		fuso     *time.Location
+		dataHora string
+		hora     string
+		faixa    string
+	}{
+		{
+			nome:     "UTC sem conversão",
+			instante: time.Date(2024, 1, 15, 1, 30, 0, 0, time.UTC),
+			fuso:     time.UTC,
+			dataHora: "15/01/2024 01:30", hora: "01:30:00", faixa: "15/01 01h",
+		},
+		{
+			// 01:30 UTC ainda é o dia anterior em São Paulo (-03).
+			nome:     "virada de dia pelo deslocamento",
+			instante: time.Date(2024, 1, 15, 1, 30, 0, 0, time.UTC),
+			fuso:     saoPaulo,
+			dataHora: "14/01/2024 22:30", hora: "22:30:00", faixa: "14/01 22h",
+		},
+		{
+			// Último horário de verão de São Paulo: em 04/11/2018 o relógio saltou de 00:00 para 01:00 (-02).
+			nome:     "antes do horario de verao",
+			instante: time.Date(2018, 11, 4, 2, 59, 0, 0, time.UTC),
+			fuso:     saoPaulo,
+			dataHora: "03/11/2018 23:59", hora: "23:59:00", faixa: "03/11 23h",
+		},
+		{
+			nome:     "depois do horario de verao",
+			instante: time.Date(2018, 11, 4, 3, 0, 0, 0, time.UTC),
+			fuso:     saoPaulo,
+			dataHora: "04/11/2018 01:00", hora: "01:00:00", faixa: "04/11 01h",
+		},
+		{
+			// Em 03/11/2024 Nova York volta de -04 para -05 e a 01h local acontece duas vezes.
+			nome:     "hora repetida no fim do horario de verao",
+			instante: time.Date(2024, 11, 3, 6, 15, 0, 0, time.UTC),
+			fuso:     novaYork,
+			dataHora: "03/11/2024 01:15", hora: "01:15:00", faixa: "03/11 01h",
+		},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			assert.Equal(t, c.dataHora, formatDateTime(c.instante, c.fuso))
+			assert.Equal(t, c.hora, formatTime(c.instante, c.fuso))
+			assert.Equal(t, c.faixa, formatHour(c.instante, c.fuso))
+		})
+	}
+}
+
+func TestFormatacaoDeHorarios_QuandoInstanteZero_DeveFicarVazia(t *testing.T) {
+	assert.Empty(t, formatDateTime(time.Time{}, time.UTC))
+	assert.Empty(t, formatTime(time.Time{}, time.UTC))
+	assert.Empty(t, formatHour(time.Time{}, time.UTC))
+}
+
+func TestComFuso(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+
+	f := &Frontend{fuso: time.UTC}
+	ComFuso(nil)(f)
+	assert.Equal(t, time.UTC, f.fuso)
+	ComFuso(saoPaulo)(f)
+	assert.Equal(t, saoPaulo, f.fuso)
+}
@@ -3,6 +3,7 @@ package web
 // Pacote web centraliza a camada de apresentação HTML (SSR) usada pelo desafio.
 
 import (
+	"context"
 	"crypto/subtle"
 	"embed"
 	"errors"
@@ -27,10 +28,14 @@ type Frontend struct {
 	templates     *template.Template
 	service       *voting.Service
 	consultaToken string
+	login         ProvedorLogin
+	selo          selo
+	fuso          *time.Location
+	captcha       *widgetCaptcha
 }
 
 // New carrega os templates embutidos e registra as dependências necessárias.
-func New(service *voting.Service, consultaToken string) (*Frontend, error) {
+func New(service *voting.Service, consultaToken string, opts ...Option) (*Frontend, error) {
 	if service == nil {
 		return nil, fmt.Errorf("frontend: serviço de votação inexistente")
 	}
@@ -50,7 +55,23 @@ func New(service *voting.Service, consultaToken string) (*Frontend, error) {
 		}
 	}
 
-	return &Frontend{templates: tmpl, service: service, consultaToken: consultaToken}, nil
+	f := &Frontend{templates: tmpl, service: service, consultaToken: consultaToken, fuso: time.UTC}
+	for _, opt := range opts {
+		opt(f)
+	}
+	if f.login != nil && len(f.selo.segredo) == 0 {
+		return nil, fmt.Errorf("frontend: login habilitado sem segredo de sessão")
+	}
+	return f, nil
+}
+
+// ComFuso define o fuso em que as telas mostram horários; sem a opção, vale UTC.
+func ComFuso(fuso *time.Location) Option {
+	return func(f *Frontend) {
+		if fuso != nil {
+			f.fuso = fuso
+		}
+	}
 }
 
 // Register expõe as rotas HTML na mesma mux da API.
@@ -59,6 +80,11 @@ func (f *Frontend) Register(mux *http.ServeMux) {
 	mux.HandleFunc("/vote", f.handleVote)
 	mux.HandleFunc("/panorama", f.handlePanorama)
 	mux.HandleFunc("/consulta", f.handleConsulta)
+	if f.login != nil {
+		mux.HandleFunc("/login", f.handleLogin)
+		mux.HandleFunc("/auth/callback", f.handleCallback)
+		mux.HandleFunc("/logout", f.handleLogout)
+	}
 }
 
 func (f *Frontend) handleRoot(w http.ResponseWriter, r *http.Request) {
@@ -71,13 +97,22 @@ func (f *Frontend) handleRoot(w http.ResponseWriter, r *http.Request) {
 
 func (f *Frontend) handleVote(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	data := votePageData{}
+	sessao, logado := f.sessao(r)
+	data := votePageData{LoginHabilitado: f.login != nil, Logado: logado, Captcha: f.captcha}
 
 	paredoes, err := f.service.ListarAtivos(ctx)
 	if err != nil {
 		data.Error = "Não foi possível carregar os paredões ativos."
 	} else {
-		data.Paredoes = makeVoteParedoes(paredoes)
+		data.Paredoes = makeVoteParedoes(paredoes, f.fuso)
+		for i, p := range paredoes {
+			data.Paredoes[i].Etapa, _ = f.etapas(ctx, p)
+			// Sem a política, o widget não aparece; o voto segue para o antifraude, que decide do mesmo jeito.
+			if politica, err := f.service.PoliticaEfetiva(ctx, p.ID); err == nil && politica.ExigeCaptcha {
+				data.Paredoes[i].ExigeCaptcha = true
+				data.ExigeCaptcha = true
+			}
+		}
 	}
 
 	if r.Method == http.MethodPost && data.Error == "" {
@@ -87,22 +122,53 @@ func (f *Frontend) handleVote(w http.ResponseWriter, r *http.Request) {
 			vote := domain.Voto{
 				ParedaoID:      domain.ParedaoID(strings.TrimSpace(r.FormValue("paredao_id"))),
 				ParticipanteID: domain.ParticipanteID(strings.TrimSpace(r.FormValue("participante_id"))),
+				Modalidade:     domain.Modalidade(strings.TrimSpace(r.FormValue("modalidade"))),
+				CaptchaToken:   tokenCaptcha(r),
 				OrigemIP:       clientIP(r),
 				UserAgent:      r.UserAgent(),
 			}
+			if logado {
+				// Só o subject verificado no callback identifica o eleitor; nada vem do formulário.
+				vote.EleitorID = domain.EleitorID(sessao.Subject)
+			}
 
 			if vote.ParedaoID == "" || vote.ParticipanteID == "" {
 				data.Error = "Selecione um participante para votar."
-			} else if err := f.service.RegistrarVoto(ctx, vote); err != nil {
+			} else if resultado, err := f.service.RegistrarVoto(ctx, vote); err != nil {
 				data.Error = translateVoteError(err)
+				if errors.Is(err, antifraude.ErrRateLimitExceeded) && !resultado.Limite.Reset.IsZero() {
+					data.NextAttempt = formatTime(resultado.Limite.Reset, f.fuso)
+				}
 			} else {
-				http.Redirect(w, r, "/panorama?paredao_id="+url.QueryEscape(string(vote.ParedaoID))+"&status=success", http.StatusSeeOther)
+				http.Redirect(w, r, "/panorama?paredao_id="+url.QueryEscape(string(vote.ParedaoID))+"&status=success&voto_id="+url.QueryEscape(string(resultado.VotoID)), http.StatusSeeOther)
 				return
 			}
 		}
 	}
 
-	f.render(w, "vote_body", data)
+	f.render(w, r, "vote_body", data)
+}
+
+// confirmarVoto troca a mensagem genérica pelo estado real do voto; enquanto ele está na fila a página
+// continua recarregando. Se a consulta falhar, fica a mensagem genérica.
+func (f *Frontend) confirmarVoto(r *http.Request, votoID domain.VotoID, data *panoramaPageData) {
+	status, err := f.service.StatusVoto(r.Context(), votoID)
+	if err != nil {
+		return
+	}
+	switch status.Estado {
+	case domain.VotoProcessado:
+		data.Message = "Voto computado! Ele já está somado nas parciais abaixo."
+		data.VotoPendente = false
+	case domain.VotoRejeitado:
+		data.Message = ""
+		data.VotoPendente = false
+		data.VotoRecusado = "Seu voto não foi computado"
+		if status.Motivo != "" {
+			data.VotoRecusado += ": " + status.Motivo
+		}
+		data.VotoRecusado += "."
+	}
 }
 
 func (f *Frontend) handlePanorama(w http.ResponseWriter, r *http.Request) {
@@ -112,52 +178,63 @@ func (f *Frontend) handlePanorama(w http.ResponseWriter, r *http.Request) {
 
 	if status := r.URL.Query().Get("status"); status == "success" {
 		data.Message = "Voto registrado com sucesso! O resultado pode levar alguns segundos para ser computado."
+		data.VotoPendente = true
+		if votoID := domain.VotoID(r.URL.Query().Get("voto_id")); votoID != "" {
+			f.confirmarVoto(r, votoID, &data)
+		}
 	}
 
 	if paredaoID == "" {
 		data.Error = "Informe qual paredão deseja acompanhar."
-		f.render(w, "panorama_body", data)
+		f.render(w, r, "panorama_body", data)
 		return
 	}
 
-	parciais, err := f.service.Parciais(ctx, paredaoID)
+	// O panorama é público: respeita a visibilidade do paredão, ao contrário da /consulta da produção.
+	parciais, publicacao, err := f.service.ParciaisPublicas(ctx, paredaoID)
 	if err != nil {
 		data.Error = translateVoteError(err)
-		f.render(w, "panorama_body", data)
+		f.render(w, r, "panorama_body", data)
 		return
 	}
 
-	paredoes, err := f.service.ListarAtivos(ctx)
-	if err != nil {
-		// seguimos sem interromper; usaremos o ID caso não consiga o nome.
-		paredoes = nil
+	nomeParedao := string(paredaoID)
+	participantesNome := make(map[domain.ParticipanteID]string)
+	paredao, err := f.service.ObterParedao(ctx, paredaoID)
+	if err == nil {
+		nomeParedao = paredao.Nome
+		for _, part := range paredao.Participantes {
+			participantesNome[part.ID] = part.Nome
+		}
+	} else {
+		// Seguimos sem interromper; usaremos os IDs no lugar dos nomes e a polaridade padrão.
+		paredao = domain.Paredao{ID: paredaoID}
 	}
 
-	nomeParedao, participantesNome := identifyParedao(paredoes, paredaoID)
-	if nomeParedao == "" {
-		nomeParedao = string(paredaoID)
+	data.ParedaoNome = nomeParedao
+	if publicacao.Oculta {
+		data.Etapa, data.Etapas = f.etapas(ctx, paredao)
+		data.Ocultas = fmt.Sprintf("As parciais deste paredão ficam ocultas até o fim da votação, previsto para %s.", formatDateTime(publicacao.LiberaEm, f.fuso))
+		f.render(w, r, "panorama_body", data)
+		return
+	}
+	if !publicacao.Ate.IsZero() {
+		data.Atraso = fmt.Sprintf("Parciais com atraso: votos computados até %s.", publicacao.Ate.In(f.fuso).Format("15:04"))
 	}
 
-	data.ParedaoNome = nomeParedao
 	totalGeral := int64(0)
 	for _, parcial := range parciais {
 		totalGeral += parcial.Total
-		nome := participantesNome[parcial.ParticipanteID]
-		if nome == "" {
-			nome = string(parcial.ParticipanteID)
-		}
-		data.Participantes = append(data.Participantes, panoramaParticipanteView{
-			Nome:         nome,
-			TotalDisplay: displayInt(parcial.Total),
-			Percent:      formatPercent(parcial.Percentual),
-		})
+		data.Participantes = append(data.Participantes, makeParticipanteView(parcial, participantesNome))
 	}
 	data.TotalGeralDisplay = displayInt(totalGeral)
+	data.Urnas = makeUrnaHeaders(parciais)
+	data.apuracaoView = f.apuracao(ctx, paredao, parciais, participantesNome)
 
-	if totaisHora, err := f.service.TotaisPorHora(ctx, paredaoID); err == nil {
+	if totaisHora, _, err := f.service.TotaisPorHoraPublicos(ctx, paredaoID); err == nil {
 		for _, item := range totaisHora {
 			data.VotosHora = append(data.VotosHora, horaView{
-				Intervalo:    formatHour(item.Hora),
+				Intervalo:    formatHour(item.Hora, f.fuso),
 				TotalDisplay: displayInt(item.Total),
 			})
 		}
@@ -165,7 +242,7 @@ func (f *Frontend) handlePanorama(w http.ResponseWriter, r *http.Request) {
 		data.HoraError = "Não foi possível carregar o histórico por hora."
 	}
 
-	f.render(w, "panorama_body", data)
+	f.render(w, r, "panorama_body", data)
 }
 
 func (f *Frontend) handleConsulta(w http.ResponseWriter, r *http.Request) {
@@ -184,20 +261,34 @@ func (f *Frontend) handleConsulta(w http.ResponseWriter, r *http.Request) {
 			}
 			data.TokenError = true
 		}
-		f.render(w, "consulta_body", data)
+		f.render(w, r, "consulta_body", data)
 		return
 	}
 
 	paredoes, err := f.service.ListarAtivos(ctx)
-	data := consultaPageData{}
+	data := consultaPageData{Fuso: f.fuso.String()}
+	// Intervalo desconhecido no ?bucket= cai no padrão em vez de derrubar a página.
+	bucket := r.URL.Query().Get("bucket")
+	intervalo, errBucket := voting.ParseIntervaloSerie(bucket)
+	if errBucket != nil || bucket == "" {
+		bucket = voting.IntervaloSeriePadrao
+		intervalo, _ = voting.ParseIntervaloSerie(bucket)
+	}
 	if err != nil {
 		data.Error = "Não foi possível carregar as informações do paredão."
-		f.render(w, "consulta_body", data)
+		f.render(w, r, "consulta_body", data)
 		return
 	}
 
 	for _, p := range paredoes {
-		parciais, err := f.service.Parciais(ctx, p.ID)
+		parciais, err := f.parciaisConsulta(ctx, p)
+		if errors.Is(err, errConsultaRestrita) {
+			data.Paredoes = append(data.Paredoes, consultaParedaoView{
+				Nome:     p.Nome,
+				Restrita: "As parciais deste paredão não são públicas e a consulta está sem token; defina CONSULTA_TOKEN para acompanhá-las aqui.",
+			})
+			continue
+		}
 		if err != nil {
 			data.Error = "Falha ao consultar as parciais do paredão."
 			break
@@ -209,6 +300,24 @@ func (f *Frontend) handleConsulta(w http.ResponseWriter, r *http.Request) {
 			break
 		}
 
+		series, err := f.service.SeriePorParticipante(ctx, p.ID, intervalo)
+		if err != nil {
+			data.Error = "Falha ao consultar a série por participante."
+			break
+		}
+
+		viradas, err := f.service.Viradas(ctx, p.ID)
+		if err != nil && !errors.Is(err, voting.ErrViradasDesabilitadas) {
+			data.Error = "Falha ao consultar as viradas do paredão."
+			break
+		}
+
+		projecao, err := f.service.ProjecaoFinal(ctx, p.ID, intervalo)
+		if err != nil {
+			data.Error = "Falha ao calcular a projeção do paredão."
+			break
+		}
+
 		view := consultaParedaoView{Nome: p.Nome}
 		participantesNome := make(map[domain.ParticipanteID]string, len(p.Participantes))
 		for _, part := range p.Participantes {
@@ -218,32 +327,48 @@ func (f *Frontend) handleConsulta(w http.ResponseWriter, r *http.Request) {
 		totalGeral := int64(0)
 		for _, parcial := range parciais {
 			totalGeral += parcial.Total
-			nome := participantesNome[parcial.ParticipanteID]
-			if nome == "" {
-				nome = string(parcial.ParticipanteID)
-			}
-			view.Participantes = append(view.Participantes, panoramaParticipanteView{
-				Nome:         nome,
-				TotalDisplay: displayInt(parcial.Total),
-				Percent:      formatPercent(parcial.Percentual),
-			})
+			view.Participantes = append(view.Participantes, makeParticipanteView(parcial, participantesNome))
 		}
 		view.TotalDisplay = displayInt(totalGeral)
+		view.Urnas = makeUrnaHeaders(parciais)
+		view.apuracaoView = f.apuracao(ctx, p, parciais, participantesNome)
 
 		for _, item := range porHora {
 			view.VotosHora = append(view.VotosHora, horaView{
-				Intervalo:    formatHour(item.Hora),
+				Intervalo:    formatHour(item.Hora, f.fuso),
 				TotalDisplay: displayInt(item.Total),
 			})
 		}
+		view.Serie = makeSerieView(series, participantesNome, bucket, f.fuso)
+		view.Viradas = makeViradasView(viradas, participantesNome, f.fuso)
+		view.Projecao = makeProjecaoView(projecao, participantesNome, f.fuso)
 
 		data.Paredoes = append(data.Paredoes, view)
 	}
 
-	f.render(w, "consulta_body", data)
+	f.render(w, r, "consulta_body", data)
+}
+
+// errConsultaRestrita marca o paredão cujas parciais não são públicas quando a consulta não tem token.
+var errConsultaRestrita = errors.New("consulta sem token para parciais nao publicas")
+
+// parciaisConsulta devolve as parciais ao vivo. Sem CONSULTA_TOKEN a página é aberta a qualquer um, então
+// só os paredões de visibilidade pública aparecem; os ocultos ou atrasados ficam com o aviso.
+func (f *Frontend) parciaisConsulta(ctx context.Context, p domain.Paredao) ([]domain.Parcial, error) {
+	if f.consultaToken != "" {
+		return f.service.Parciais(ctx, p.ID)
+	}
+	parciais, publicacao, err := f.service.ParciaisPublicas(ctx, p.ID)
+	if err != nil {
+		return nil, err
+	}
+	if publicacao.Modo != domain.VisibilidadePublica {
+		return nil, errConsultaRestrita
+	}
+	return parciais, nil
 }
 
-func (f *Frontend) render(w http.ResponseWriter, tmpl string, data any) {
+func (f *Frontend) render(w http.ResponseWriter, r *http.Request, tmpl string, data any) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	var content strings.Builder
 	if err := f.templates.ExecuteTemplate(&content, tmpl, data); err != nil {
@@ -252,11 +377,22 @@ func (f *Frontend) render(w http.ResponseWriter, tmpl string, data any) {
 	}
 
 	page := struct {
-		Title   string
-		Content template.HTML
+		Title           string
+		Content         template.HTML
+		LoginHabilitado bool
+		Eleitor         string
+		Proximo         string
 	}{
-		Title:   pageTitle(tmpl),
-		Content: template.HTML(content.String()),
+		Title:           pageTitle(tmpl),
+		Content:         template.HTML(content.String()),
+		LoginHabilitado: f.login != nil,
+		Proximo:         r.URL.RequestURI(),
+	}
+	if sessao, ok := f.sessao(r); ok {
+		page.Eleitor = sessao.Nome
+		if page.Eleitor == "" {
+			page.Eleitor = sessao.Subject
+		}
 	}
 
 	if err := f.templates.ExecuteTemplate(w, "layout", page); err != nil {
@@ -278,8 +414,14 @@ func pageTitle(body string) string {
 }
 
 type votePageData struct {
-	Paredoes []voteParedaoView
-	Error    string
+	Paredoes        []voteParedaoView
+	Error           string
+	NextAttempt     string
+	LoginHabilitado bool
+	Logado          bool
+	// Captcha fica nil quando não há widget configurado; ExigeCaptcha diz se algum paredão pede o desafio.
+	Captcha      *widgetCaptcha
+	ExigeCaptcha bool
 }
 
 type voteParedaoView struct {
@@ -288,6 +430,12 @@ type voteParedaoView struct {
 	Descricao     string
 	Inicio        string
 	Fim           string
+	Etapa         string
+	Pergunta      string
+	Acao          string
+	AceitaUnico   bool
+	SomenteUnico  bool
+	ExigeCaptcha  bool
 	Participantes []voteParticipanteView
 }
 
@@ -297,19 +445,58 @@ type voteParticipanteView struct {
 }
 
 type panoramaPageData struct {
+	apuracaoView
 	ParedaoNome       string
+	Urnas             []urnaHeaderView
 	Participantes     []panoramaParticipanteView
 	TotalGeralDisplay string
 	VotosHora         []horaView
 	Message           string
 	Error             string
 	HoraError         string
+	// VotoPendente mantém a página recarregando enquanto o voto recém-enviado está na fila; VotoRecusado
+	// explica por que ele não entrou na contagem.
+	VotoPendente bool
+	VotoRecusado string
+	// Ocultas substitui os números quando o paredão esconde as parciais; Atraso avisa do corte no modo atrasado.
+	Ocultas string
+	Atraso  string
 }
 
 type panoramaParticipanteView struct {
 	Nome         string
 	TotalDisplay string
 	Percent      string
+	Urnas        []urnaValorView
+}
+
+// urnaHeaderView e urnaValorView só são preenchidos quando o paredão combina mais de uma urna.
+type urnaHeaderView struct {
+	Nome string
+	Peso string
+}
+
+type urnaValorView struct {
+	TotalDisplay string
+	Percent      string
+}
+
+// apuracaoView explica a regra do paredão (ou da etapa) e quem sairia com as parciais atuais.
+type apuracaoView struct {
+	Etapa     string
+	Etapas    []etapaView
+	Regra     string
+	Saindo    string
+	Escapando string
+	Empatados []string
+}
+
+// etapaView descreve uma etapa de paredão com várias votações (bate-volta, paredão principal).
+type etapaView struct {
+	ID     string
+	Nome   string
+	Status string
+	Atual  bool
 }
 
 type horaView struct {
@@ -321,27 +508,98 @@ type consultaPageData struct {
 	RequiresToken bool
 	TokenError    bool
 	Error         string
+	Fuso          string
 	Paredoes      []consultaParedaoView
 }
 
 type consultaParedaoView struct {
+	apuracaoView
 	Nome          string
+	Restrita      string
 	TotalDisplay  string
+	Urnas         []urnaHeaderView
 	Participantes []panoramaParticipanteView
 	VotosHora     []horaView
+	Serie         serieView
+	Viradas       []viradaView
+	Projecao      projecaoView
+}
+
+// projecaoView mostra a estimativa do resultado final; a página deixa claro que não é parcial.
+type projecaoView struct {
+	CalculadaEm string
+	Fim         string
+	Confianca   string
+	Votos       string
+	Linhas      []projecaoLinhaView
+}
+
+type projecaoLinhaView struct {
+	Nome      string
+	Atual     string
+	Projetada string
+	Faixa     string
+}
+
+// viradaView descreve uma troca de liderança; sem Anterior, é quem assumiu a ponta pela primeira vez.
+type viradaView struct {
+	Horario  string
+	Anterior string
+	Novo     string
+	Margem   string
+	Votos    string
+}
+
+// serieView desenha a participação acumulada de cada participante como polilinhas de um SVG de
+// larguraSerie x alturaSerie, com 0% embaixo e 100% em cima.
+type serieView struct {
+	Bucket  string
+	Opcoes  []string
+	Inicio  string
+	Fim     string
+	Largura int
+	Altura  int
+	Linhas  []linhaSerieView
 }
 
-func makeVoteParedoes(paredoes []domain.Paredao) []voteParedaoView {
+type linhaSerieView struct {
+	Nome   string
+	Cor    string
+	Pontos string
+	Final  string
+}
+
+const (
+	larguraSerie = 600
+	alturaSerie  = 200
+)
+
+// coresSerie alterna as cores do programa entre as linhas do gráfico.
+var coresSerie = []string{"#5001b3", "#d7008d", "#00a3e0", "#f5a300", "#2e9e44", "#7a7a7a"}
+
+func makeVoteParedoes(paredoes []domain.Paredao, fuso *time.Location) []voteParedaoView {
 	views := make([]voteParedaoView, 0, len(paredoes))
 	for _, p := range paredoes {
 		view := voteParedaoView{
 			ID:        string(p.ID),
 			Nome:      p.Nome,
 			Descricao: p.Descricao,
-			Inicio:    formatDateTime(p.Inicio),
-			Fim:       formatDateTime(p.Fim),
+			Inicio:    formatDateTime(p.Inicio, fuso),
+			Fim:       formatDateTime(p.Fim, fuso),
+			Pergunta:  "Quem você quer eliminar?",
+			Acao:      "Eliminar",
+
+			AceitaUnico:  p.ModoVotacao == domain.ModoUnico || p.ModoVotacao == domain.ModoMisto,
+			SomenteUnico: p.ModoVotacao == domain.ModoUnico,
+		}
+		if p.PolaridadeEfetiva() == domain.PolaridadeSalvar {
+			view.Pergunta = "Quem você quer salvar?"
+			view.Acao = "Salvar"
 		}
 		for _, part := range p.Participantes {
+			if part.Retirado() {
+				continue
+			}
 			view.Participantes = append(view.Participantes, voteParticipanteView{
 				ID:   string(part.ID),
 				Nome: part.Nome,
@@ -352,17 +610,241 @@ func makeVoteParedoes(paredoes []domain.Paredao) []voteParedaoView {
 	return views
 }
 
-func identifyParedao(paredoes []domain.Paredao, id domain.ParedaoID) (string, map[domain.ParticipanteID]string) {
-	nomes := make(map[domain.ParticipanteID]string)
-	for _, p := range paredoes {
-		if p.ID == id {
-			for _, part := range p.Participantes {
-				nomes[part.ID] = part.Nome
+// makeParticipanteView monta a linha de parciais; quem foi retirado com os votos mantidos aparece
+// com o total, mas sem percentual.
+func makeSerieView(series []domain.SerieParticipante, nomes map[domain.ParticipanteID]string, bucket string, fuso *time.Location) serieView {
+	view := serieView{
+		Bucket:  bucket,
+		Opcoes:  []string{"1m", "5m", "15m", "1h"},
+		Largura: larguraSerie,
+		Altura:  alturaSerie,
+	}
+	for i, serie := range series {
+		n := len(serie.Pontos)
+		if n == 0 {
+			continue
+		}
+		view.Inicio = formatTime(serie.Pontos[0].Inicio, fuso)
+		view.Fim = formatTime(serie.Pontos[n-1].Inicio, fuso)
+
+		coords := make([]string, 0, n+1)
+		for j, ponto := range serie.Pontos {
+			y := alturaSerie * (1 - ponto.Participacao/100)
+			if n == 1 {
+				// Um único intervalo vira um segmento horizontal, para a linha continuar visível.
+				coords = append(coords, fmt.Sprintf("0,%.1f", y), fmt.Sprintf("%d,%.1f", larguraSerie, y))
+				break
 			}
-			return p.Nome, nomes
+			coords = append(coords, fmt.Sprintf("%.1f,%.1f", float64(j)*larguraSerie/float64(n-1), y))
+		}
+
+		view.Linhas = append(view.Linhas, linhaSerieView{
+			Nome:   nomeOuID(serie.ParticipanteID, nomes),
+			Cor:    coresSerie[i%len(coresSerie)],
+			Pontos: strings.Join(coords, " "),
+			Final:  formatPercent(serie.Pontos[n-1].Participacao),
+		})
+	}
+	return view
+}
+
+// makeViradasView lista as viradas da mais recente para a mais antiga.
+func makeViradasView(viradas []domain.Virada, nomes map[domain.ParticipanteID]string, fuso *time.Location) []viradaView {
+	views := make([]viradaView, 0, len(viradas))
+	for i := len(viradas) - 1; i >= 0; i-- {
+		v := viradas[i]
+		view := viradaView{
+			Horario: formatTime(v.OcorridaEm, fuso),
+			Novo:    nomeOuID(v.NovoLider, nomes),
+			Margem:  fmt.Sprintf("%.2f p.p.", v.Margem),
+			Votos:   displayInt(v.TotalVotos),
+		}
+		if v.LiderAnterior != "" {
+			view.Anterior = nomeOuID(v.LiderAnterior, nomes)
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+func makeProjecaoView(projecao domain.Projecao, nomes map[domain.ParticipanteID]string, fuso *time.Location) projecaoView {
+	view := projecaoView{
+		CalculadaEm: formatTime(projecao.CalculadaEm, fuso),
+		Fim:         formatDateTime(projecao.Fim, fuso),
+		Confianca:   fmt.Sprintf("%.0f%%", projecao.Confianca*100),
+		Votos:       displayInt(projecao.VotosProjetados),
+	}
+	if projecao.VotosAtuais == 0 {
+		return view
+	}
+	for _, p := range projecao.Participantes {
+		view.Linhas = append(view.Linhas, projecaoLinhaView{
+			Nome:      nomeOuID(p.ParticipanteID, nomes),
+			Atual:     formatPercent(p.Atual),
+			Projetada: formatPercent(p.Projetada),
+			Faixa:     formatPercent(p.Minima) + " – " + formatPercent(p.Maxima),
+		})
+	}
+	return view
+}
+
+func nomeOuID(id domain.ParticipanteID, nomes map[domain.ParticipanteID]string) string {
+	if nome := nomes[id]; nome != "" {
+		return nome
+	}
+	return string(id)
+}
+
+func makeParticipanteView(parcial domain.Parcial, nomes map[domain.ParticipanteID]string) panoramaParticipanteView {
+	nome := nomes[parcial.ParticipanteID]
+	if nome == "" {
+		nome = string(parcial.ParticipanteID)
+	}
+	view := panoramaParticipanteView{
+		Nome:         nome,
+		TotalDisplay: displayInt(parcial.Total),
+		Percent:      formatPercent(parcial.Percentual),
+		Urnas:        makeUrnaValores(parcial),
+	}
+	if parcial.Retirado {
+		view.Nome += " (retirado)"
+		view.Percent = "—"
+	}
+	return view
+}
+
+func makeUrnaHeaders(parciais []domain.Parcial) []urnaHeaderView {
+	if len(parciais) == 0 || len(parciais[0].Modalidades) < 2 {
+		return nil
+	}
+	headers := make([]urnaHeaderView, len(parciais[0].Modalidades))
+	for i, urna := range parciais[0].Modalidades {
+		headers[i] = urnaHeaderView{
+			Nome: modalidadeLabel(urna.Modalidade),
+			Peso: formatPercent(urna.Peso * 100),
+		}
+	}
+	return headers
+}
+
+func makeUrnaValores(parcial domain.Parcial) []urnaValorView {
+	if len(parcial.Modalidades) < 2 {
+		return nil
+	}
+	valores := make([]urnaValorView, len(parcial.Modalidades))
+	for i, urna := range parcial.Modalidades {
+		valores[i] = urnaValorView{
+			TotalDisplay: displayInt(urna.Total),
+			Percent:      formatPercent(urna.Percentual),
+		}
+	}
+	return valores
+}
+
+// etapas monta o rótulo "Etapa X de N" do paredão e a lista de etapas do grupo; paredões de etapa única
+// (ou falhas ao consultar o grupo) não exibem nada.
+func (f *Frontend) etapas(ctx context.Context, p domain.Paredao) (string, []etapaView) {
+	if p.GrupoID == "" {
+		return "", nil
+	}
+	etapas, err := f.service.Etapas(ctx, p.ID)
+	if err != nil || len(etapas) < 2 {
+		return "", nil
+	}
+
+	agora := time.Now()
+	views := make([]etapaView, len(etapas))
+	for i, etapa := range etapas {
+		views[i] = etapaView{
+			ID:     string(etapa.ID),
+			Nome:   nomeEtapa(etapa),
+			Status: statusEtapa(etapa, agora),
+			Atual:  etapa.ID == p.ID,
+		}
+	}
+	return fmt.Sprintf("Etapa %d de %d · %s", p.Etapa, len(etapas), nomeEtapa(p)), views
+}
+
+func nomeEtapa(p domain.Paredao) string {
+	if p.EtapaNome != "" {
+		return p.EtapaNome
+	}
+	return fmt.Sprintf("Etapa %d", p.Etapa)
+}
+
+func statusEtapa(p domain.Paredao, agora time.Time) string {
+	switch {
+	case p.Ativo && !agora.Before(p.Inicio) && !agora.After(p.Fim):
+		return "Ao vivo"
+	case agora.Before(p.Inicio) || (!p.Ativo && agora.Before(p.Fim)):
+		return "Aguardando"
+	default:
+		return "Encerrada"
+	}
+}
+
+func (f *Frontend) apuracao(ctx context.Context, p domain.Paredao, parciais []domain.Parcial, nomes map[domain.ParticipanteID]string) apuracaoView {
+	var view apuracaoView
+	view.Etapa, view.Etapas = f.etapas(ctx, p)
+	if p.Anulado {
+		view.Regra = "Paredão anulado após a retirada de um participante: não há eliminação."
+		return view
+	}
+	if len(view.Etapas) > 0 && p.Etapa < len(view.Etapas) {
+		// No bate-volta ninguém deixa a casa: o mais votado escapa e os demais seguem para a próxima etapa.
+		view.Regra = "Etapa intermediária: quem tiver o maior percentual escapa do paredão; os demais seguem para a próxima etapa."
+		view.Escapando, view.Empatados = nomesDestaque(parciais, nomes)
+		return view
+	}
+	view.Regra = regraPolaridade(p.PolaridadeEfetiva())
+	view.Saindo, view.Empatados = nomesApuracao(p.PolaridadeEfetiva(), parciais, nomes)
+	return view
+}
+
+func regraPolaridade(p domain.Polaridade) string {
+	if p == domain.PolaridadeSalvar {
+		return "Votação para salvar: deixa a casa quem tiver o menor percentual."
+	}
+	return "Votação para eliminar: deixa a casa quem tiver o maior percentual."
+}
+
+// nomesApuracao traduz a apuração corrente em nomes para exibição; sem votos ambos vêm vazios.
+func nomesApuracao(p domain.Polaridade, parciais []domain.Parcial, nomes map[domain.ParticipanteID]string) (string, []string) {
+	eliminado, empatados := domain.Apurar(p, parciais)
+	return nomesParticipantes(eliminado, empatados, nomes)
+}
+
+func nomesDestaque(parciais []domain.Parcial, nomes map[domain.ParticipanteID]string) (string, []string) {
+	destaque, empatados := domain.Destaque(parciais)
+	return nomesParticipantes(destaque, empatados, nomes)
+}
+
+func nomesParticipantes(id domain.ParticipanteID, empatados []domain.ParticipanteID, nomes map[domain.ParticipanteID]string) (string, []string) {
+	nome := func(id domain.ParticipanteID) string {
+		if n := nomes[id]; n != "" {
+			return n
 		}
+		return string(id)
+	}
+	if id != "" {
+		return nome(id), nil
+	}
+	lista := make([]string, 0, len(empatados))
+	for _, e := range empatados {
+		lista = append(lista, nome(e))
+	}
+	return "", lista
+}
+
+func modalidadeLabel(m domain.Modalidade) string {
+	switch m {
+	case domain.ModalidadeUnico:
+		return "Voto único"
+	case domain.ModalidadeTorcida:
+		return "Voto da torcida"
+	default:
+		return string(m)
 	}
-	return "", nomes
 }
 
 func translateVoteError(err error) string {
@@ -371,8 +853,18 @@ func translateVoteError(err error) string {
 		return ""
 	case errors.Is(err, antifraude.ErrRateLimitExceeded):
 		return "Você atingiu o limite de votos por minuto. Aguarde um instante e tente novamente."
+	case errors.Is(err, antifraude.ErrCaptchaInvalido):
+		return "Não conseguimos confirmar que você não é um robô. Refaça a verificação e vote novamente."
+	case errors.Is(err, voting.ErrEleitorObrigatorio):
+		return "Este paredão aceita apenas o voto único: entre com sua conta para votar."
+	case errors.Is(err, voting.ErrModalidadeInvalida):
+		return "Esse tipo de voto não é aceito neste paredão."
+	case errors.Is(err, voting.ErrVotoJaRegistrado):
+		return "Você já registrou seu voto único neste paredão."
 	case errors.Is(err, voting.ErrPeriodoEncerrado):
 		return "Esse paredão já foi encerrado."
+	case errors.Is(err, voting.ErrParticipanteRetirado):
+		return "Esse participante deixou o paredão e não recebe mais votos."
 	case errors.Is(err, voting.ErrParticipanteDesconhecido):
 		return "Não encontrei o participante informado."
 	case errors.Is(err, voting.ErrParedaoNaoEncontrado):
@@ -404,18 +896,27 @@ func displayInt(v int64) string {
 	return fmt.Sprintf("%d", v)
 }
 
-func formatDateTime(t time.Time) string {
+// Os horários exibidos seguem o fuso dos relatórios, o mesmo em que o banco agrupa as horas, e não o fuso
+// do servidor.
+func formatDateTime(t time.Time, fuso *time.Location) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(fuso).Format("02/01/2006 15:04")
+}
+
+func formatTime(t time.Time, fuso *time.Location) string {
 	if t.IsZero() {
 		return ""
 	}
-	return t.Format("02/01/2006 15:04")
+	return t.In(fuso).Format("15:04:05")
 }
 
-func formatHour(t time.Time) string {
+func formatHour(t time.Time, fuso *time.Location) string {
 	if t.IsZero() {
 		return ""
 	}
-	return t.Format("02/01 15h")
+	return t.In(fuso).Format("02/01 15h")
 }
 
 func (f *Frontend) isConsultaAuthorized(r *http.Request) bool {
@@ -0,0 +1,238 @@
+package web
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/platform/oidc"
+)
+
+const (
+	cookieSessao = "bbb-sessao"
+	cookieLogin  = "bbb-login"
+
+	validadeSessao = 8 * time.Hour
+	validadeLogin  = 10 * time.Minute
+)
+
+var errCookieInvalido = errors.New("frontend: cookie invalido")
+
+// ProvedorLogin é o lado relying party do OIDC; *oidc.Cliente é a implementação usada em produção.
+type ProvedorLogin interface {
+	URLAutorizacao(ctx context.Context, a oidc.Autorizacao) (string, error)
+	Autenticar(ctx context.Context, codigo string, a oidc.Autorizacao) (oidc.Identidade, error)
+	URLLogout(ctx context.Context, idTokenHint, destino string) string
+}
+
+var _ ProvedorLogin = (*oidc.Cliente)(nil)
+
+// Option configura dependências opcionais do frontend.
+type Option func(*Frontend)
+
+// ComLogin habilita o login do eleitor via OIDC; a sessão fica num cookie assinado com segredoSessao.
+func ComLogin(provedor ProvedorLogin, segredoSessao string) Option {
+	return func(f *Frontend) {
+		f.login = provedor
+		f.selo = selo{segredo: []byte(segredoSessao)}
+	}
+}
+
+// sessaoEleitor guarda apenas claims já verificadas no callback; o id_token volta ao IdP como hint no logout.
+type sessaoEleitor struct {
+	Subject string `json:"sub"`
+	Nome    string `json:"nome,omitempty"`
+	IDToken string `json:"id_token,omitempty"`
+	Expira  int64  `json:"exp"`
+}
+
+// loginPendente atravessa o redirecionamento ao IdP e é consumido no callback.
+type loginPendente struct {
+	oidc.Autorizacao
+	Proximo string `json:"next,omitempty"`
+	Expira  int64  `json:"exp"`
+}
+
+func (f *Frontend) handleLogin(w http.ResponseWriter, r *http.Request) {
+	autorizacao, err := oidc.NovaAutorizacao()
+	if err != nil {
+		http.Error(w, "não foi possível iniciar o login", http.StatusInternalServerError)
+		return
+	}
+	destino, err := f.login.URLAutorizacao(r.Context(), autorizacao)
+	if err != nil {
+		http.Error(w, "provedor de identidade indisponível", http.StatusBadGateway)
+		return
+	}
+
+	pendente := loginPendente{
+		Autorizacao: autorizacao,
+		Proximo:     destinoLocal(r.URL.Query().Get("next")),
+		Expira:      time.Now().Add(validadeLogin).Unix(),
+	}
+	valor, err := f.selo.selar(pendente)
+	if err != nil {
+		http.Error(w, "não foi possível iniciar o login", http.StatusInternalServerError)
+		return
+	}
+	f.setCookie(w, r, cookieLogin, valor, "/auth", validadeLogin)
+	http.Redirect(w, r, destino, http.StatusFound)
+}
+
+func (f *Frontend) handleCallback(w http.ResponseWriter, r *http.Request) {
+	var pendente loginPendente
+	cookie, err := r.Cookie(cookieLogin)
+	if err == nil {
+		err = f.selo.abrir(cookie.Value, &pendente)
+	}
+	f.setCookie(w, r, cookieLogin, "", "/auth", -1)
+
+	q := r.URL.Query()
+	switch {
+	case err != nil || time.Now().Unix() > pendente.Expira:
+		http.Error(w, "login expirado; tente novamente", http.StatusBadRequest)
+		return
+	case subtle.ConstantTimeCompare([]byte(q.Get("state")), []byte(pendente.State)) != 1:
+		http.Error(w, "state inválido", http.StatusBadRequest)
+		return
+	case q.Get("error") != "":
+		http.Error(w, "login recusado pelo provedor de identidade", http.StatusUnauthorized)
+		return
+	}
+
+	identidade, err := f.login.Autenticar(r.Context(), q.Get("code"), pendente.Autorizacao)
+	if err != nil {
+		http.Error(w, "não foi possível confirmar sua identidade", http.StatusUnauthorized)
+		return
+	}
+
+	sessao := sessaoEleitor{
+		Subject: identidade.Claims.Subject,
+		Nome:    identidade.Claims.Nome,
+		IDToken: identidade.IDToken,
+		Expira:  time.Now().Add(validadeSessao).Unix(),
+	}
+	valor, err := f.selo.selar(sessao)
+	if err != nil {
+		http.Error(w, "não foi possível abrir a sessão", http.StatusInternalServerError)
+		return
+	}
+	f.setCookie(w, r, cookieSessao, valor, "/", validadeSessao)
+
+	proximo := pendente.Proximo
+	if proximo == "" {
+		proximo = "/vote"
+	}
+	http.Redirect(w, r, proximo, http.StatusSeeOther)
+}
+
+func (f *Frontend) handleLogout(w http.ResponseWriter, r *http.Request) {
+	sessao, ok := f.sessao(r)
+	f.setCookie(w, r, cookieSessao, "", "/", -1)
+
+	if ok {
+		if destino := f.login.URLLogout(r.Context(), sessao.IDToken, urlAbsoluta(r, "/vote")); destino != "" {
+			http.Redirect(w, r, destino, http.StatusSeeOther)
+			return
+		}
+	}
+	http.Redirect(w, r, "/vote", http.StatusSeeOther)
+}
+
+// sessao devolve o eleitor autenticado, se houver cookie válido e não expirado.
+func (f *Frontend) sessao(r *http.Request) (sessaoEleitor, bool) {
+	if f.login == nil {
+		return sessaoEleitor{}, false
+	}
+	cookie, err := r.Cookie(cookieSessao)
+	if err != nil {
+		return sessaoEleitor{}, false
+	}
+	var s sessaoEleitor
+	if err := f.selo.abrir(cookie.Value, &s); err != nil || s.Subject == "" || time.Now().Unix() > s.Expira {
+		return sessaoEleitor{}, false
+	}
+	return s, true
+}
+
+func (f *Frontend) setCookie(w http.ResponseWriter, r *http.Request, nome, valor, path string, validade time.Duration) {
+	maxAge := int(validade.Seconds())
+	if validade < 0 {
+		maxAge = -1
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     nome,
+		Value:    valor,
+		Path:     path,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   requisicaoSegura(r),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// selo assina payloads JSON com HMAC-SHA256: o conteúdo é legível pelo navegador, mas não pode ser alterado.
+type selo struct {
+	segredo []byte
+}
+
+func (s selo) selar(v any) (string, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	corpo := base64.RawURLEncoding.EncodeToString(payload)
+	return corpo + "." + base64.RawURLEncoding.EncodeToString(s.assinar(corpo)), nil
+}
+
+func (s selo) abrir(valor string, v any) error {
+	corpo, assinatura, ok := strings.Cut(valor, ".")
+	if !ok || len(s.segredo) == 0 {
+		return errCookieInvalido
+	}
+	recebida, err := base64.RawURLEncoding.DecodeString(assinatura)
+	if err != nil || !hmac.Equal(recebida, s.assinar(corpo)) {
+		return errCookieInvalido
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(corpo)
+	if err != nil {
+		return errCookieInvalido
+	}
+	if err := json.Unmarshal(payload, v); err != nil {
+		return errCookieInvalido
+	}
+	return nil
+}
+
+func (s selo) assinar(corpo string) []byte {
+	mac := hmac.New(sha256.New, s.segredo)
+	mac.Write([]byte(corpo))
+	return mac.Sum(nil)
+}
+
+// destinoLocal só aceita caminhos da própria aplicação, evitando open redirect pelo parâmetro next.
+func destinoLocal(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return ""
+	}
+	return next
+}
+
+func requisicaoSegura(r *http.Request) bool {
+	return r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https")
+}
+
+func urlAbsoluta(r *http.Request, path string) string {
+	esquema := "http"
+	if requisicaoSegura(r) {
+		esquema = "https"
+	}
+	return esquema + "://" + r.Host + path
+}
@@ -0,0 +1,136 @@
+package web
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/app/voting"
+	"github.com/marcelojr/desafio-globo/internal/platform/oidc"
+	"github.com/marcelojr/desafio-globo/internal/platform/oidc/mockidp"
+)
+
+type ambienteLogin struct {
+	frontend  *Frontend
+	app       *httptest.Server
+	navegador *http.Client
+}
+
+func novoAmbienteLogin(t *testing.T) ambienteLogin {
+	t.Helper()
+
+	idpSrv := httptest.NewUnstartedServer(nil)
+	issuer := "http://" + idpSrv.Listener.Addr().String()
+	idp, err := mockidp.New(issuer, "votacao-bbb", "segredo")
+	require.NoError(t, err)
+	idpSrv.Config.Handler = idp.Handler()
+	idpSrv.Start()
+	t.Cleanup(idpSrv.Close)
+
+	appSrv := httptest.NewUnstartedServer(nil)
+	appURL := "http://" + appSrv.Listener.Addr().String()
+	cliente := oidc.NewCliente(oidc.Config{
+		Issuer:       issuer,
+		ClientID:     "votacao-bbb",
+		ClientSecret: "segredo",
+		RedirectURL:  appURL + "/auth/callback",
+	})
+
+	servico := voting.NewService(nil, nil, nil, nil, nil, nil, nil, nil)
+	frontend, err := New(servico, "", ComLogin(cliente, "segredo-sessao"))
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	frontend.Register(mux)
+	appSrv.Config.Handler = mux
+	appSrv.Start()
+	t.Cleanup(appSrv.Close)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	navegador := &http.Client{
+		Jar:           jar,
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	return ambienteLogin{frontend: frontend, app: appSrv, navegador: navegador}
+}
+
+func (a ambienteLogin) get(t *testing.T, destino string) *http.Response {
+	t.Helper()
+	resp, err := a.navegador.Get(destino)
+	require.NoError(t, err)
+	resp.Body.Close()
+	return resp
+}
+
+func TestLoginOIDCAbreSessaoComSubjectVerificado(t *testing.T) {
+	amb := novoAmbienteLogin(t)
+
+	resp := amb.get(t, amb.app.URL+"/login?next=/panorama")
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	// O mock IdP aprova direto quando recebe login_hint, simulando o formulário preenchido.
+	resp = amb.get(t, resp.Header.Get("Location")+"&login_hint=Ana")
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	resp = amb.get(t, resp.Header.Get("Location"))
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	assert.Equal(t, "/panorama", resp.Header.Get("Location"))
+
+	req := httptest.NewRequest(http.MethodGet, "/vote", nil)
+	appURL, _ := url.Parse(amb.app.URL)
+	for _, c := range amb.navegador.Jar.Cookies(appURL) {
+		req.AddCookie(c)
+	}
+	sessao, ok := amb.frontend.sessao(req)
+	require.True(t, ok)
+	assert.Equal(t, "mock|ana", sessao.Subject)
+	assert.Equal(t, "Ana", sessao.Nome)
+
+	resp = amb.get(t, amb.app.URL+"/logout")
+	require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+	for _, c := range amb.navegador.Jar.Cookies(appURL) {
+		assert.NotEqual(t, cookieSessao, c.Name, "logout deveria remover a sessão")
+	}
+}
+
+func TestLoginOIDCRecusaStateDivergente(t *testing.T) {
+	amb := novoAmbienteLogin(t)
+
+	resp := amb.get(t, amb.app.URL+"/login")
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	resp = amb.get(t, resp.Header.Get("Location")+"&login_hint=ana")
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	q := callback.Query()
+	q.Set("state", "forjado")
+	callback.RawQuery = q.Encode()
+
+	resp = amb.get(t, callback.String())
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSessaoAdulteradaNaoIdentificaEleitor(t *testing.T) {
+	f := &Frontend{login: oidc.NewCliente(oidc.Config{}), selo: selo{segredo: []byte("segredo")}}
+
+	valor, err := selo{segredo: []byte("outro-segredo")}.selar(sessaoEleitor{Subject: "mock|admin", Expira: 1 << 40})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/vote", nil)
+	req.AddCookie(&http.Cookie{Name: cookieSessao, Value: valor})
+	_, ok := f.sessao(req)
+	assert.False(t, ok)
+}
+
+func TestDestinoLocalBloqueiaRedirecionamentoExterno(t *testing.T) {
+	assert.Equal(t, "/panorama?paredao_id=1", destinoLocal("/panorama?paredao_id=1"))
+	for _, next := range []string{"", "https://evil.local", "//evil.local", "/\\evil.local"} {
+		assert.Empty(t, destinoLocal(next), next)
+	}
+}
@@ -0,0 +1,238 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/metrics"
+)
+
+// maxJanelasRetroativas limita quantas janelas vazias avaliamos quando o paredão fica parado por muito tempo.
+const maxJanelasRetroativas = 60
+
+// maxAlertasPendentes limita os alertas à espera do webhook; acima disso o alerta fica só no log e na métrica.
+const maxAlertasPendentes = 64
+
+// ConfigAnomalia reúne os parâmetros globais da detecção; o paredão pode sobrescrever limiar e volume mínimo.
+type ConfigAnomalia struct {
+	Janela     time.Duration
+	Alpha      float64
+	LimiarZ    float64
+	MinVotos   int64
+	Aquecer    int64
+	WebhookURL string
+}
+
+// Notificador entrega alertas para canais externos (webhook, chat, etc.).
+type Notificador interface {
+	Enviar(ctx context.Context, url string, payload any) error
+}
+
+// DetectorAnomalias compara a velocidade de votos de cada participante (e do paredão) com uma linha de base EWMA.
+type DetectorAnomalias struct {
+	janelas     domain.JanelasVelocidade
+	paredoes    domain.ParedaoRepository
+	notificador Notificador
+	logger      *slog.Logger
+	cfg         ConfigAnomalia
+	alertas     chan domain.AlertaAnomalia
+}
+
+func NewDetectorAnomalias(janelas domain.JanelasVelocidade, paredoes domain.ParedaoRepository, notificador Notificador, logger *slog.Logger, cfg ConfigAnomalia) *DetectorAnomalias {
+	if cfg.Janela <= 0 {
+		cfg.Janela = time.Minute
+	}
+	if cfg.Alpha <= 0 || cfg.Alpha > 1 {
+		cfg.Alpha = 0.3
+	}
+	if cfg.Aquecer <= 0 {
+		cfg.Aquecer = 5
+	}
+	return &DetectorAnomalias{
+		janelas:     janelas,
+		paredoes:    paredoes,
+		notificador: notificador,
+		logger:      logger,
+		cfg:         cfg,
+		alertas:     make(chan domain.AlertaAnomalia, maxAlertasPendentes),
+	}
+}
+
+// Rodar entrega os alertas ao webhook até o contexto ser cancelado. A entrega fica fora do consumo dos
+// votos: um webhook lento ou fora do ar não pode segurar a fila atrás dele.
+func (d *DetectorAnomalias) Rodar(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case alerta := <-d.alertas:
+			if err := d.notificador.Enviar(ctx, d.cfg.WebhookURL, alerta); err != nil {
+				d.logger.Error("anomalia: falha ao notificar webhook", "paredao", alerta.ParedaoID, "err", err)
+			}
+		}
+	}
+}
+
+// VotoPersistido alimenta as séries do participante e do paredão; a avaliação só ocorre quando uma janela fecha.
+func (d *DetectorAnomalias) VotoPersistido(ctx context.Context, voto domain.Voto) error {
+	janela := voto.CriadoEm.Unix() / int64(d.cfg.Janela/time.Second)
+
+	var errs []error
+	for _, participante := range []domain.ParticipanteID{voto.ParticipanteID, ""} {
+		if err := d.observar(ctx, voto.ParedaoID, participante, janela); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *DetectorAnomalias) observar(ctx context.Context, paredaoID domain.ParedaoID, participanteID domain.ParticipanteID, janela int64) error {
+	serie := serieVelocidade(paredaoID, participanteID)
+	if _, err := d.janelas.Incrementar(ctx, serie, janela); err != nil {
+		return fmt.Errorf("anomalia: registrar janela: %w", err)
+	}
+
+	linha, err := d.janelas.ObterLinhaBase(ctx, serie)
+	if err != nil {
+		return fmt.Errorf("anomalia: carregar linha base: %w", err)
+	}
+
+	if linha.UltimaJanela == 0 {
+		// Primeira observação da série: apenas marcamos a janela corrente como ponto de partida.
+		linha.UltimaJanela = janela
+		return d.janelas.SalvarLinhaBase(ctx, serie, linha)
+	}
+	if janela <= linha.UltimaJanela {
+		return nil
+	}
+
+	ok, err := d.janelas.ReivindicarFechamento(ctx, serie, linha.UltimaJanela)
+	if err != nil {
+		return fmt.Errorf("anomalia: reivindicar janela: %w", err)
+	}
+	if !ok {
+		// Outro worker já está avaliando essa janela.
+		return nil
+	}
+
+	limiarZ, minVotos := d.limiares(ctx, paredaoID)
+
+	inicio := linha.UltimaJanela
+	if janela-inicio > maxJanelasRetroativas {
+		inicio = janela - maxJanelasRetroativas
+	}
+	for j := inicio; j < janela; j++ {
+		votos, err := d.janelas.Obter(ctx, serie, j)
+		if err != nil {
+			return fmt.Errorf("anomalia: ler janela: %w", err)
+		}
+
+		desvio := desvioReferencia(linha)
+		z := (float64(votos) - linha.Media) / desvio
+		if participanteID == "" {
+			metrics.SetVoteVelocityZScore(string(paredaoID), z)
+		}
+
+		if linha.Amostras >= d.cfg.Aquecer && votos >= minVotos && z >= limiarZ {
+			d.alertar(domain.AlertaAnomalia{
+				ParedaoID:      paredaoID,
+				ParticipanteID: participanteID,
+				InicioJanela:   time.Unix(j*int64(d.cfg.Janela/time.Second), 0).UTC(),
+				Votos:          votos,
+				Media:          linha.Media,
+				Desvio:         desvio,
+				ZScore:         z,
+			})
+		}
+
+		linha = atualizarLinhaBase(linha, float64(votos), d.cfg.Alpha)
+	}
+
+	linha.UltimaJanela = janela
+	return d.janelas.SalvarLinhaBase(ctx, serie, linha)
+}
+
+func (d *DetectorAnomalias) limiares(ctx context.Context, paredaoID domain.ParedaoID) (float64, int64) {
+	limiarZ, minVotos := d.cfg.LimiarZ, d.cfg.MinVotos
+	if d.paredoes == nil {
+		return limiarZ, minVotos
+	}
+
+	paredao, err := d.paredoes.FindByID(ctx, paredaoID)
+	if err != nil {
+		// Sem o paredão seguimos com os limiares globais; a detecção não deve travar o worker.
+		d.logger.Warn("anomalia: falha ao carregar limiares do paredao", "paredao", paredaoID, "err", err)
+		return limiarZ, minVotos
+	}
+	if paredao.Anomalia.LimiarZ > 0 {
+		limiarZ = paredao.Anomalia.LimiarZ
+	}
+	if paredao.Anomalia.MinVotos > 0 {
+		minVotos = paredao.Anomalia.MinVotos
+	}
+	return limiarZ, minVotos
+}
+
+func (d *DetectorAnomalias) alertar(alerta domain.AlertaAnomalia) {
+	escopo := "participante"
+	if alerta.ParticipanteID == "" {
+		escopo = "paredao"
+	}
+
+	metrics.IncVoteAnomaly(string(alerta.ParedaoID), escopo)
+	d.logger.Warn("anomalia na velocidade de votos",
+		"evento", "anomalia_velocidade",
+		"escopo", escopo,
+		"paredao", alerta.ParedaoID,
+		"participante", alerta.ParticipanteID,
+		"inicio_janela", alerta.InicioJanela,
+		"votos", alerta.Votos,
+		"media", alerta.Media,
+		"desvio", alerta.Desvio,
+		"z_score", alerta.ZScore,
+	)
+
+	if d.notificador == nil || d.cfg.WebhookURL == "" {
+		return
+	}
+	select {
+	case d.alertas <- alerta:
+	default:
+		d.logger.Error("anomalia: fila de alertas cheia, webhook descartado", "paredao", alerta.ParedaoID, "participante", alerta.ParticipanteID)
+	}
+}
+
+// atualizarLinhaBase aplica a atualização incremental de média e variância exponencialmente ponderadas.
+func atualizarLinhaBase(linha domain.LinhaBase, votos, alpha float64) domain.LinhaBase {
+	if linha.Amostras == 0 {
+		linha.Media = votos
+		linha.Variancia = 0
+		linha.Amostras = 1
+		return linha
+	}
+	diff := votos - linha.Media
+	incremento := alpha * diff
+	linha.Media += incremento
+	linha.Variancia = (1 - alpha) * (linha.Variancia + diff*incremento)
+	linha.Amostras++
+	return linha
+}
+
+// desvioReferencia usa o maior entre o desvio observado e o ruído Poisson esperado para não alarmar séries muito estáveis.
+func desvioReferencia(linha domain.LinhaBase) float64 {
+	return math.Max(1, math.Max(math.Sqrt(linha.Variancia), math.Sqrt(linha.Media)))
+}
+
+func serieVelocidade(paredaoID domain.ParedaoID, participanteID domain.ParticipanteID) string {
+	if participanteID == "" {
+		return fmt.Sprintf("paredao:%s", paredaoID)
+	}
+	return fmt.Sprintf("paredao:%s:participante:%s", paredaoID, participanteID)
+}
+
+var _ Observador = (*DetectorAnomalias)(nil)
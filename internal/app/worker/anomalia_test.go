@@ -0,0 +1,273 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+func TestDetectorAnomaliasDisparaAlertaQuandoJanelaFogeDaLinhaBase(t *testing.T) {
+	janelas := newMemJanelas()
+	notificador := &recordingNotificador{}
+	detector := NewDetectorAnomalias(janelas, nil, notificador, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), ConfigAnomalia{
+		Janela:     time.Minute,
+		Alpha:      0.3,
+		LimiarZ:    4,
+		MinVotos:   20,
+		Aquecer:    3,
+		WebhookURL: "http://alertas.local",
+	})
+
+	base := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	// Linha de base estável: 10 votos por minuto durante 6 minutos.
+	for minuto := 0; minuto < 6; minuto++ {
+		for i := 0; i < 10; i++ {
+			votar(t, detector, ctx, base.Add(time.Duration(minuto)*time.Minute))
+		}
+	}
+	// Pico: 80 votos no sétimo minuto.
+	for i := 0; i < 80; i++ {
+		votar(t, detector, ctx, base.Add(6*time.Minute))
+	}
+	if len(detector.alertas) != 0 {
+		t.Fatalf("nenhum alerta deveria sair antes da janela fechar, veio %d", len(detector.alertas))
+	}
+
+	// O primeiro voto do minuto seguinte fecha a janela do pico.
+	votar(t, detector, ctx, base.Add(7*time.Minute))
+
+	if len(detector.alertas) != 2 {
+		t.Fatalf("esperava alerta do participante e do paredão, veio %d", len(detector.alertas))
+	}
+	alerta := <-detector.alertas
+	if alerta.Votos != 80 {
+		t.Fatalf("alerta deveria reportar 80 votos, veio %d", alerta.Votos)
+	}
+	if alerta.ZScore < 4 {
+		t.Fatalf("z-score deveria superar o limiar, veio %.2f", alerta.ZScore)
+	}
+}
+
+func TestDetectorAnomaliasRespeitaLimiarDoParedao(t *testing.T) {
+	janelas := newMemJanelas()
+	notificador := &recordingNotificador{}
+	paredoes := &memParedaoRepo{paredao: domain.Paredao{
+		ID:       "paredao-1",
+		Anomalia: domain.LimiaresAnomalia{MinVotos: 1000},
+	}}
+	detector := NewDetectorAnomalias(janelas, paredoes, notificador, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), ConfigAnomalia{
+		Janela:     time.Minute,
+		LimiarZ:    4,
+		MinVotos:   20,
+		Aquecer:    3,
+		WebhookURL: "http://alertas.local",
+	})
+
+	base := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	for minuto := 0; minuto < 6; minuto++ {
+		for i := 0; i < 10; i++ {
+			votar(t, detector, ctx, base.Add(time.Duration(minuto)*time.Minute))
+		}
+	}
+	for i := 0; i < 80; i++ {
+		votar(t, detector, ctx, base.Add(6*time.Minute))
+	}
+	votar(t, detector, ctx, base.Add(7*time.Minute))
+
+	if len(detector.alertas) != 0 {
+		t.Fatalf("volume mínimo do paredão deveria suprimir o alerta, veio %d", len(detector.alertas))
+	}
+}
+
+func TestDetectorAnomaliasNaoEsperaWebhookLento(t *testing.T) {
+	notificador := &notificadorTravado{recebidos: make(chan any, maxAlertasPendentes), libera: make(chan struct{})}
+	defer close(notificador.libera)
+	detector := NewDetectorAnomalias(newMemJanelas(), nil, notificador, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)), ConfigAnomalia{
+		Janela:     time.Minute,
+		LimiarZ:    4,
+		MinVotos:   20,
+		Aquecer:    3,
+		WebhookURL: "http://alertas.local",
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go detector.Rodar(ctx)
+
+	base := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	for minuto := 0; minuto < 6; minuto++ {
+		for i := 0; i < 10; i++ {
+			votar(t, detector, ctx, base.Add(time.Duration(minuto)*time.Minute))
+		}
+	}
+	for i := 0; i < 80; i++ {
+		votar(t, detector, ctx, base.Add(6*time.Minute))
+	}
+
+	// Com o webhook travado no primeiro alerta, o voto que fecha a janela precisa voltar mesmo assim.
+	feito := make(chan struct{})
+	go func() {
+		votar(t, detector, ctx, base.Add(7*time.Minute))
+		close(feito)
+	}()
+	select {
+	case <-feito:
+	case <-time.After(time.Second):
+		t.Fatal("o processamento do voto ficou esperando o webhook")
+	}
+
+	select {
+	case payload := <-notificador.recebidos:
+		if payload.(domain.AlertaAnomalia).Votos != 80 {
+			t.Fatalf("alerta entregue inesperado: %+v", payload)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("o alerta deveria chegar ao webhook em segundo plano")
+	}
+}
+
+func votar(t *testing.T, detector *DetectorAnomalias, ctx context.Context, quando time.Time) {
+	t.Helper()
+	err := detector.VotoPersistido(ctx, domain.Voto{
+		ParedaoID:      "paredao-1",
+		ParticipanteID: "participante-1",
+		CriadoEm:       quando,
+	})
+	if err != nil {
+		t.Fatalf("VotoPersistido retornou erro: %v", err)
+	}
+}
+
+type memJanelas struct {
+	mu        sync.Mutex
+	contagens map[string]int64
+	fechadas  map[string]bool
+	linhas    map[string]domain.LinhaBase
+}
+
+func newMemJanelas() *memJanelas {
+	return &memJanelas{
+		contagens: make(map[string]int64),
+		fechadas:  make(map[string]bool),
+		linhas:    make(map[string]domain.LinhaBase),
+	}
+}
+
+func (m *memJanelas) Incrementar(_ context.Context, serie string, janela int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := fmt.Sprintf("%s:%d", serie, janela)
+	m.contagens[key]++
+	return m.contagens[key], nil
+}
+
+func (m *memJanelas) Obter(_ context.Context, serie string, janela int64) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.contagens[fmt.Sprintf("%s:%d", serie, janela)], nil
+}
+
+func (m *memJanelas) ReivindicarFechamento(_ context.Context, serie string, janela int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := fmt.Sprintf("%s:%d", serie, janela)
+	if m.fechadas[key] {
+		return false, nil
+	}
+	m.fechadas[key] = true
+	return true, nil
+}
+
+func (m *memJanelas) ObterLinhaBase(_ context.Context, serie string) (domain.LinhaBase, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.linhas[serie], nil
+}
+
+func (m *memJanelas) SalvarLinhaBase(_ context.Context, serie string, linha domain.LinhaBase) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.linhas[serie] = linha
+	return nil
+}
+
+type recordingNotificador struct {
+	alertas []any
+}
+
+func (r *recordingNotificador) Enviar(_ context.Context, _ string, payload any) error {
+	r.alertas = append(r.alertas, payload)
+	return nil
+}
+
+// notificadorTravado registra o alerta e só retorna quando o teste libera, como um webhook que não responde.
+type notificadorTravado struct {
+	recebidos chan any
+	libera    chan struct{}
+}
+
+func (n *notificadorTravado) Enviar(ctx context.Context, _ string, payload any) error {
+	n.recebidos <- payload
+	select {
+	case <-n.libera:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+type memParedaoRepo struct {
+	paredao domain.Paredao
+}
+
+func (m *memParedaoRepo) Create(context.Context, domain.Paredao) error { return nil }
+
+func (m *memParedaoRepo) Update(context.Context, domain.Paredao) error { return nil }
+
+func (m *memParedaoRepo) CreateEtapas(context.Context, []domain.Paredao) error { return nil }
+
+func (m *memParedaoRepo) AbrirEtapa(context.Context, domain.Paredao, []domain.Participante) error {
+	return nil
+}
+
+func (m *memParedaoRepo) AtualizarAntifraude(context.Context, domain.ParedaoID, domain.PoliticaAntifraude, time.Time) error {
+	return nil
+}
+
+func (m *memParedaoRepo) AtualizarVisibilidade(context.Context, domain.ParedaoID, domain.PoliticaVisibilidade, time.Time) error {
+	return nil
+}
+
+func (m *memParedaoRepo) AtualizarVotacao(context.Context, domain.ParedaoID, domain.ModoVotacao, domain.PesosModalidade, domain.Polaridade, time.Time) error {
+	return nil
+}
+
+func (m *memParedaoRepo) AtualizarLimiares(context.Context, domain.ParedaoID, domain.LimiaresAnomalia, time.Time) error {
+	return nil
+}
+
+func (m *memParedaoRepo) FindByID(_ context.Context, id domain.ParedaoID) (domain.Paredao, error) {
+	if id != m.paredao.ID {
+		return domain.Paredao{}, domain.ErrNotFound
+	}
+	return m.paredao, nil
+}
+
+func (m *memParedaoRepo) ListByGrupo(context.Context, domain.ParedaoID) ([]domain.Paredao, error) {
+	return nil, nil
+}
+
+func (m *memParedaoRepo) ListComMarco(context.Context, time.Time, time.Time) ([]domain.Paredao, error) {
+	return nil, nil
+}
+
+func (m *memParedaoRepo) ListAtivos(context.Context) ([]domain.Paredao, error) {
+	return []domain.Paredao{m.paredao}, nil
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// PublicadorAvisos avisa as réplicas da API que as parciais de um paredão mudaram, no máximo uma vez por
+// intervalo. Votos que chegam dentro do intervalo não se perdem: geram um único aviso ao fim dele.
+type PublicadorAvisos struct {
+	avisos    domain.AvisosParciais
+	intervalo time.Duration
+	logger    *slog.Logger
+
+	mu       sync.Mutex
+	paredoes map[domain.ParedaoID]*estadoAviso
+}
+
+type estadoAviso struct {
+	ultimo   time.Time
+	agendado bool
+}
+
+func NewPublicadorAvisos(avisos domain.AvisosParciais, intervalo time.Duration, logger *slog.Logger) *PublicadorAvisos {
+	if intervalo <= 0 {
+		intervalo = 500 * time.Millisecond
+	}
+	return &PublicadorAvisos{
+		avisos:    avisos,
+		intervalo: intervalo,
+		logger:    logger,
+		paredoes:  make(map[domain.ParedaoID]*estadoAviso),
+	}
+}
+
+var _ Observador = (*PublicadorAvisos)(nil)
+
+func (p *PublicadorAvisos) VotoPersistido(ctx context.Context, voto domain.Voto) error {
+	p.mu.Lock()
+	estado, ok := p.paredoes[voto.ParedaoID]
+	if !ok {
+		estado = &estadoAviso{}
+		p.paredoes[voto.ParedaoID] = estado
+	}
+	if estado.agendado {
+		p.mu.Unlock()
+		return nil
+	}
+	espera := p.intervalo - time.Since(estado.ultimo)
+	if espera > 0 {
+		estado.agendado = true
+		p.mu.Unlock()
+		time.AfterFunc(espera, func() { p.publicarAgendado(voto.ParedaoID) })
+		return nil
+	}
+	estado.ultimo = time.Now()
+	p.mu.Unlock()
+
+	if err := p.avisos.Publicar(ctx, voto.ParedaoID); err != nil {
+		return fmt.Errorf("avisos: %w", err)
+	}
+	return nil
+}
+
+func (p *PublicadorAvisos) publicarAgendado(paredaoID domain.ParedaoID) {
+	p.mu.Lock()
+	estado := p.paredoes[paredaoID]
+	estado.agendado = false
+	estado.ultimo = time.Now()
+	p.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.intervalo)
+	defer cancel()
+	if err := p.avisos.Publicar(ctx, paredaoID); err != nil {
+		p.logger.Warn("falha ao publicar aviso de parciais", "paredao", paredaoID, "err", err)
+	}
+}
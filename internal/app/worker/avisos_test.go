@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+type memAvisos struct {
+	mu         sync.Mutex
+	publicados []domain.ParedaoID
+}
+
+func (m *memAvisos) Publicar(_ context.Context, id domain.ParedaoID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.publicados = append(m.publicados, id)
+	return nil
+}
+
+func (m *memAvisos) Escutar(context.Context, func(), func(domain.ParedaoID)) error {
+	return nil
+}
+
+func (m *memAvisos) total(id domain.ParedaoID) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, p := range m.publicados {
+		if p == id {
+			n++
+		}
+	}
+	return n
+}
+
+func TestPublicadorAvisosLimitaAvisosPorParedao(t *testing.T) {
+	avisos := &memAvisos{}
+	publicador := NewPublicadorAvisos(avisos, 50*time.Millisecond, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+	ctx := context.Background()
+
+	for range 100 {
+		require.NoError(t, publicador.VotoPersistido(ctx, domain.Voto{ParedaoID: "par-1"}))
+	}
+	require.NoError(t, publicador.VotoPersistido(ctx, domain.Voto{ParedaoID: "par-2"}))
+
+	// O primeiro voto avisa na hora; os demais do intervalo viram um único aviso ao fim dele.
+	assert.Equal(t, 1, avisos.total("par-1"))
+	assert.Equal(t, 1, avisos.total("par-2"))
+	assert.Eventually(t, func() bool { return avisos.total("par-1") == 2 }, time.Second, 10*time.Millisecond)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, 2, avisos.total("par-1"))
+	assert.Equal(t, 1, avisos.total("par-2"))
+}
@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/auditoria"
+)
+
+// AssinadorMensagens assina os checkpoints; o assinador de recibos atende, de modo que as chaves públicas
+// já publicadas servem para conferir as duas coisas.
+type AssinadorMensagens interface {
+	AssinarMensagem(msg []byte) (chaveID, assinatura string)
+}
+
+// GeradorCheckpoints sela periodicamente a ponta da cadeia de cada paredão que recebeu votos desde o último
+// checkpoint. Um checkpoint publicado impede que votos anteriores a ele sejam alterados ou apagados sem que
+// o verificador perceba.
+type GeradorCheckpoints struct {
+	cadeia    domain.CadeiaAuditoria
+	assinador AssinadorMensagens
+	clock     domain.Clock
+	intervalo time.Duration
+	logger    *slog.Logger
+}
+
+func NewGeradorCheckpoints(cadeia domain.CadeiaAuditoria, assinador AssinadorMensagens, clock domain.Clock, intervalo time.Duration, logger *slog.Logger) *GeradorCheckpoints {
+	if intervalo <= 0 {
+		intervalo = time.Minute
+	}
+	return &GeradorCheckpoints{
+		cadeia:    cadeia,
+		assinador: assinador,
+		clock:     clock,
+		intervalo: intervalo,
+		logger:    logger,
+	}
+}
+
+// Rodar gera checkpoints a cada intervalo até o contexto ser cancelado, com um último ao sair para que
+// os votos do fim do paredão não fiquem sem selo.
+func (g *GeradorCheckpoints) Rodar(ctx context.Context) {
+	ticker := time.NewTicker(g.intervalo)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			final, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			if err := g.Gerar(final); err != nil {
+				g.logger.Warn("falha ao gerar checkpoints finais", "err", err)
+			}
+			cancel()
+			return
+		case <-ticker.C:
+			if err := g.Gerar(ctx); err != nil {
+				g.logger.Warn("falha ao gerar checkpoints", "err", err)
+			}
+		}
+	}
+}
+
+// Gerar assina e grava um checkpoint para cada cadeia com votos ainda não selados.
+func (g *GeradorCheckpoints) Gerar(ctx context.Context) error {
+	pontas, err := g.cadeia.PendentesDeCheckpoint(ctx)
+	if err != nil {
+		return fmt.Errorf("checkpoints: %w", err)
+	}
+	for _, ponta := range pontas {
+		cadeia, err := auditoria.Restaurar(ponta)
+		if err != nil {
+			return fmt.Errorf("checkpoints: paredao %s: %w", ponta.ParedaoID, err)
+		}
+		checkpoint := domain.Checkpoint{
+			ParedaoID:  ponta.ParedaoID,
+			Tamanho:    cadeia.Tamanho,
+			HashCadeia: cadeia.Elo.String(),
+			RaizMerkle: cadeia.Raiz().String(),
+			CriadoEm:   auditoria.Instante(g.clock.Agora()),
+		}
+		checkpoint.ChaveID, checkpoint.Assinatura = g.assinador.AssinarMensagem(auditoria.MensagemCheckpoint(checkpoint))
+		if err := g.cadeia.SalvarCheckpoint(ctx, checkpoint); err != nil {
+			return fmt.Errorf("checkpoints: %w", err)
+		}
+		g.logger.Info("checkpoint gerado", "paredao", checkpoint.ParedaoID, "tamanho", checkpoint.Tamanho, "raiz", checkpoint.RaizMerkle)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/auditoria"
+	"github.com/marcelojr/desafio-globo/internal/platform/recibos"
+)
+
+type memCadeia struct {
+	pontas      map[domain.ParedaoID]domain.CadeiaVotos
+	checkpoints []domain.Checkpoint
+}
+
+func (m *memCadeia) PendentesDeCheckpoint(context.Context) ([]domain.CadeiaVotos, error) {
+	var pendentes []domain.CadeiaVotos
+	for _, ponta := range m.pontas {
+		var selado int64
+		for _, c := range m.checkpoints {
+			if c.ParedaoID == ponta.ParedaoID && c.Tamanho > selado {
+				selado = c.Tamanho
+			}
+		}
+		if ponta.Tamanho > selado {
+			pendentes = append(pendentes, ponta)
+		}
+	}
+	return pendentes, nil
+}
+
+func (m *memCadeia) SalvarCheckpoint(_ context.Context, c domain.Checkpoint) error {
+	m.checkpoints = append(m.checkpoints, c)
+	return nil
+}
+
+func (m *memCadeia) Checkpoints(context.Context, domain.ParedaoID) ([]domain.Checkpoint, error) {
+	return m.checkpoints, nil
+}
+
+func (m *memCadeia) ExportarVotos(context.Context, domain.ParedaoID, func(domain.VotoAuditado) error) error {
+	return nil
+}
+
+func TestGeradorCheckpointsSelaSoCadeiasComVotosNovos(t *testing.T) {
+	var cadeia auditoria.Cadeia
+	for i := range 5 {
+		cadeia.Anexar(auditoria.Folha(domain.VotoAuditado{VotoID: domain.VotoID(rune('a' + i)), ParedaoID: "par-1"}))
+	}
+	repo := &memCadeia{pontas: map[domain.ParedaoID]domain.CadeiaVotos{"par-1": cadeia.Ponta("par-1")}}
+	assinador, err := recibos.NewAssinadorEfemero()
+	require.NoError(t, err)
+	clock := &fixedClock{now: time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC)}
+	gerador := NewGeradorCheckpoints(repo, assinador, clock, time.Minute, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)))
+	ctx := context.Background()
+
+	require.NoError(t, gerador.Gerar(ctx))
+	require.NoError(t, gerador.Gerar(ctx))
+
+	// A segunda rodada não repete o checkpoint: a cadeia não cresceu.
+	require.Len(t, repo.checkpoints, 1)
+	checkpoint := repo.checkpoints[0]
+	assert.Equal(t, int64(5), checkpoint.Tamanho)
+	assert.Equal(t, cadeia.Elo.String(), checkpoint.HashCadeia)
+	assert.Equal(t, cadeia.Raiz().String(), checkpoint.RaizMerkle)
+	assert.NoError(t, assinador.VerificarMensagem(checkpoint.ChaveID, auditoria.MensagemCheckpoint(checkpoint), checkpoint.Assinatura))
+}
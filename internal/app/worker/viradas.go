@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// DetectorViradas grava as trocas de liderança dos paredões abertos; o voting.Service atende.
+type DetectorViradas interface {
+	DetectarViradas(ctx context.Context) ([]domain.Virada, error)
+}
+
+// MonitorViradas confere a ponta dos paredões a cada intervalo e registra no log cada virada gravada. O
+// aviso a sistemas externos sai como evento paredao.virada, publicado pelo próprio serviço e entregue
+// pelos webhooks cadastrados. A primeira liderança de um paredão fica só no registro: não é virada.
+type MonitorViradas struct {
+	detector  DetectorViradas
+	intervalo time.Duration
+	logger    *slog.Logger
+}
+
+func NewMonitorViradas(detector DetectorViradas, intervalo time.Duration, logger *slog.Logger) *MonitorViradas {
+	if intervalo <= 0 {
+		intervalo = 10 * time.Second
+	}
+	return &MonitorViradas{
+		detector:  detector,
+		intervalo: intervalo,
+		logger:    logger,
+	}
+}
+
+// Rodar verifica as viradas a cada intervalo até o contexto ser cancelado.
+func (m *MonitorViradas) Rodar(ctx context.Context) {
+	ticker := time.NewTicker(m.intervalo)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.Verificar(ctx)
+		}
+	}
+}
+
+// Verificar grava as viradas do momento e registra as que trocaram um líder por outro. Falhas num
+// paredão não impedem os demais.
+func (m *MonitorViradas) Verificar(ctx context.Context) {
+	viradas, err := m.detector.DetectarViradas(ctx)
+	if err != nil {
+		m.logger.Warn("falha ao detectar viradas", "err", err)
+	}
+	for _, v := range viradas {
+		if v.LiderAnterior == "" {
+			m.logger.Info("lideranca assumida", "paredao", v.ParedaoID, "lider", v.NovoLider, "margem", v.Margem)
+			continue
+		}
+		m.logger.Info("virada na lideranca",
+			"evento", "virada",
+			"paredao", v.ParedaoID,
+			"lider_anterior", v.LiderAnterior,
+			"novo_lider", v.NovoLider,
+			"margem", v.Margem,
+			"total_votos", v.TotalVotos,
+		)
+	}
+}
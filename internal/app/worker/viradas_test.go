@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+type detectorFixo struct {
+	viradas []domain.Virada
+	err     error
+}
+
+func (d detectorFixo) DetectarViradas(context.Context) ([]domain.Virada, error) {
+	return d.viradas, d.err
+}
+
+func TestMonitorViradas_QuandoLiderTroca_DeveRegistrarSoAVirada(t *testing.T) {
+	var logs bytes.Buffer
+	detector := detectorFixo{
+		viradas: []domain.Virada{
+			{ParedaoID: "par-1", Ordem: 1, NovoLider: "a"},
+			{ParedaoID: "par-2", Ordem: 3, LiderAnterior: "b", NovoLider: "c", Margem: 0.4},
+		},
+		// Falha num paredão não impede o registro das viradas dos outros.
+		err: errors.New("paredao par-3: indisponivel"),
+	}
+	monitor := NewMonitorViradas(detector, 0, slog.New(slog.NewTextHandler(&logs, nil)))
+
+	monitor.Verificar(context.Background())
+
+	saida := logs.String()
+	assert.Equal(t, 1, strings.Count(saida, "evento=virada"))
+	assert.Contains(t, saida, "novo_lider=c")
+	assert.Contains(t, saida, "falha ao detectar viradas")
+}
@@ -3,6 +3,7 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,18 +12,28 @@ import (
 	"github.com/marcelojr/desafio-globo/internal/platform/metrics"
 )
 
+// Observador recebe cada voto já persistido para análises complementares (ex.: detecção de anomalias).
+type Observador interface {
+	VotoPersistido(ctx context.Context, voto domain.Voto) error
+}
+
 // VoteProcessor grava votos no repositório e mantém contadores/ métricas.
 type VoteProcessor struct {
-	repo     domain.VotoRepository
-	contador domain.Contador
-	clock    domain.Clock
+	repo         domain.VotoRepository
+	contador     domain.Contador
+	status       domain.StatusVotos
+	clock        domain.Clock
+	observadores []Observador
 }
 
-func NewVoteProcessor(repo domain.VotoRepository, contador domain.Contador, clock domain.Clock) *VoteProcessor {
+// NewVoteProcessor aceita status nil quando ninguém consulta o andamento dos votos.
+func NewVoteProcessor(repo domain.VotoRepository, contador domain.Contador, status domain.StatusVotos, clock domain.Clock, observadores ...Observador) *VoteProcessor {
 	return &VoteProcessor{
-		repo:     repo,
-		contador: contador,
-		clock:    clock,
+		repo:         repo,
+		contador:     contador,
+		status:       status,
+		clock:        clock,
+		observadores: observadores,
 	}
 }
 
@@ -35,26 +46,60 @@ func (p *VoteProcessor) Process(ctx context.Context, voto domain.Voto) error {
 	}
 
 	if err := p.repo.Registrar(ctx, voto); err != nil {
-		return fmt.Errorf("worker: registrar voto %s: %w", voto.ID, err)
+		if errors.Is(err, domain.ErrVotoDuplicado) {
+			// Voto único repetido que escapou da reserva no Redis: descartamos sem tocar nos contadores.
+			metrics.IncVoteDuplicated()
+			return p.marcar(ctx, voto, domain.VotoRejeitado, "voto unico ja registrado")
+		}
+		err = fmt.Errorf("worker: registrar voto %s: %w", voto.ID, err)
+		return errors.Join(err, p.marcar(ctx, voto, domain.VotoRejeitado, "falha ao gravar o voto"))
 	}
 
-	if p.contador == nil {
-		// Quando o contador não está configurado, mantemos as métricas para monitorar o throughput.
-		metrics.IncVoteProcessed()
-		metrics.ObserveProcessingDuration(time.Since(start).Seconds())
-		return nil
+	// O voto já está gravado: o status sai primeiro, para a consulta não ficar presa em "recebido", e as falhas
+	// do status, dos contadores e dos observadores são reportadas juntas sem desfazer o processamento.
+	var errs []error
+	if err := p.marcar(ctx, voto, domain.VotoProcessado, ""); err != nil {
+		errs = append(errs, err)
 	}
 
-	if _, err := p.contador.Incrementar(ctx, voting.CounterKeyTotalParedao(voto.ParedaoID), 1); err != nil {
-		return fmt.Errorf("worker: incrementar contador total %s: %w", voto.ParedaoID, err)
-	}
-
-	if _, err := p.contador.Incrementar(ctx, voting.CounterKeyParticipante(voto.ParedaoID, voto.ParticipanteID), 1); err != nil {
-		return fmt.Errorf("worker: incrementar contador participante %s/%s: %w", voto.ParedaoID, voto.ParticipanteID, err)
+	// Quando o contador não está configurado, mantemos as métricas para monitorar o throughput.
+	if p.contador != nil {
+		if _, err := p.contador.Incrementar(ctx, voting.CounterKeyTotalParedao(voto.ParedaoID), 1); err != nil {
+			errs = append(errs, fmt.Errorf("incrementar contador total %s: %w", voto.ParedaoID, err))
+		}
+		if _, err := p.contador.Incrementar(ctx, voting.CounterKeyParticipante(voto.ParedaoID, voto.ParticipanteID), 1); err != nil {
+			errs = append(errs, fmt.Errorf("incrementar contador participante %s/%s: %w", voto.ParedaoID, voto.ParticipanteID, err))
+		}
 	}
 
 	metrics.IncVoteProcessed()
 	metrics.ObserveProcessingDuration(time.Since(start).Seconds())
 
+	for _, obs := range p.observadores {
+		if err := obs.VotoPersistido(ctx, voto); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("worker: pos-processamento do voto %s: %w", voto.ID, err)
+	}
+
+	return nil
+}
+
+func (p *VoteProcessor) marcar(ctx context.Context, voto domain.Voto, estado domain.EstadoVoto, motivo string) error {
+	if p.status == nil {
+		return nil
+	}
+	err := p.status.Salvar(ctx, domain.StatusVoto{
+		VotoID:       voto.ID,
+		ParedaoID:    voto.ParedaoID,
+		Estado:       estado,
+		Motivo:       motivo,
+		AtualizadoEm: p.clock.Agora(),
+	})
+	if err != nil {
+		return fmt.Errorf("worker: status do voto %s: %w", voto.ID, err)
+	}
 	return nil
 }
@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -14,7 +15,8 @@ func TestVoteProcessorProcess(t *testing.T) {
 	contador := &memContador{valores: make(map[string]int64)}
 	clock := &fixedClock{now: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
 
-	processor := NewVoteProcessor(repo, contador, clock)
+	status := memStatusVotos{}
+	processor := NewVoteProcessor(repo, contador, status, clock)
 
 	voto := domain.Voto{
 		ID:             "voto-1",
@@ -42,17 +44,99 @@ func TestVoteProcessorProcess(t *testing.T) {
 	if contador.valores[partKey] != 1 {
 		t.Fatalf("contador por participante deveria ser 1, veio %d", contador.valores[partKey])
 	}
+	if status["voto-1"].Estado != domain.VotoProcessado {
+		t.Fatalf("status do voto deveria ser processado, veio %q", status["voto-1"].Estado)
+	}
+}
+
+func TestVoteProcessorMarcaProcessadoMesmoComFalhaNoContador(t *testing.T) {
+	repo := &memVotoRepo{}
+	falha := errors.New("redis fora do ar")
+	contador := &memContador{valores: make(map[string]int64), erro: falha}
+	clock := &fixedClock{now: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	status := memStatusVotos{}
+	processor := NewVoteProcessor(repo, contador, status, clock)
+
+	err := processor.Process(context.Background(), domain.Voto{
+		ID:             "voto-1",
+		ParedaoID:      "paredao-1",
+		ParticipanteID: "participante-1",
+	})
+	if !errors.Is(err, falha) {
+		t.Fatalf("falha do contador deveria ser reportada, veio %v", err)
+	}
+	if len(repo.votos) != 1 {
+		t.Fatalf("voto deveria continuar gravado, obteve %d", len(repo.votos))
+	}
+	if status["voto-1"].Estado != domain.VotoProcessado {
+		t.Fatalf("voto gravado deveria ficar processado, veio %q", status["voto-1"].Estado)
+	}
+}
+
+func TestVoteProcessorDescartaVotoUnicoDuplicado(t *testing.T) {
+	repo := &memVotoRepo{erro: domain.ErrVotoDuplicado}
+	contador := &memContador{valores: make(map[string]int64)}
+	clock := &fixedClock{now: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)}
+
+	status := memStatusVotos{}
+	processor := NewVoteProcessor(repo, contador, status, clock)
+
+	err := processor.Process(context.Background(), domain.Voto{
+		ID:             "voto-1",
+		ParedaoID:      "paredao-1",
+		ParticipanteID: "participante-1",
+		EleitorID:      "conta-1",
+		Modalidade:     domain.ModalidadeUnico,
+	})
+	if err != nil {
+		t.Fatalf("duplicidade deveria ser descartada sem erro, veio %v", err)
+	}
+	if len(contador.valores) != 0 {
+		t.Fatalf("voto duplicado nao deveria mexer nos contadores: %v", contador.valores)
+	}
+	if status["voto-1"].Estado != domain.VotoRejeitado || status["voto-1"].Motivo == "" {
+		t.Fatalf("voto duplicado deveria ficar rejeitado com motivo, veio %+v", status["voto-1"])
+	}
+}
+
+type memStatusVotos map[domain.VotoID]domain.StatusVoto
+
+func (m memStatusVotos) Salvar(_ context.Context, status domain.StatusVoto) error {
+	m[status.VotoID] = status
+	return nil
+}
+
+func (m memStatusVotos) Obter(_ context.Context, id domain.VotoID) (domain.StatusVoto, error) {
+	status, ok := m[id]
+	if !ok {
+		return domain.StatusVoto{}, domain.ErrNotFound
+	}
+	return status, nil
 }
 
 type memVotoRepo struct {
 	votos []domain.Voto
+	erro  error
 }
 
 func (m *memVotoRepo) Registrar(_ context.Context, voto domain.Voto) error {
+	if m.erro != nil {
+		return m.erro
+	}
 	m.votos = append(m.votos, voto)
 	return nil
 }
 
+func (m *memVotoRepo) FindByID(_ context.Context, id domain.VotoID) (domain.Voto, error) {
+	for _, v := range m.votos {
+		if v.ID == id {
+			return v, nil
+		}
+	}
+	return domain.Voto{}, domain.ErrNotFound
+}
+
 func (m *memVotoRepo) TotalPorParedao(context.Context, domain.ParedaoID) (int64, error) {
 	return 0, nil
 }
@@ -61,15 +145,39 @@ func (m *memVotoRepo) TotalPorParticipante(context.Context, domain.ParedaoID) (m
 	return nil, nil
 }
 
+func (m *memVotoRepo) TotalPorModalidade(context.Context, domain.ParedaoID) (map[domain.Modalidade]map[domain.ParticipanteID]int64, error) {
+	return nil, nil
+}
+
 func (m *memVotoRepo) TotalPorHora(context.Context, domain.ParedaoID) ([]domain.ParcialHora, error) {
 	return nil, nil
 }
 
+func (m *memVotoRepo) TotalPorModalidadeAte(context.Context, domain.ParedaoID, time.Time) (map[domain.Modalidade]map[domain.ParticipanteID]int64, error) {
+	return nil, nil
+}
+
+func (m *memVotoRepo) TotalPorHoraAte(context.Context, domain.ParedaoID, time.Time) ([]domain.ParcialHora, error) {
+	return nil, nil
+}
+
+func (m *memVotoRepo) TotalPorIntervalo(context.Context, domain.ParedaoID, time.Duration) ([]domain.ParcialIntervalo, error) {
+	return nil, nil
+}
+
+func (m *memVotoRepo) TotalPorIntervaloAte(context.Context, domain.ParedaoID, time.Duration, time.Time) ([]domain.ParcialIntervalo, error) {
+	return nil, nil
+}
+
 type memContador struct {
 	valores map[string]int64
+	erro    error
 }
 
 func (m *memContador) Incrementar(_ context.Context, chave string, delta int64) (int64, error) {
+	if m.erro != nil {
+		return 0, m.erro
+	}
 	m.valores[chave] += delta
 	return m.valores[chave], nil
 }
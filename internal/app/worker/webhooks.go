@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/webhook"
+)
+
+// ConfigEntregas reúne os parâmetros da entrega de webhooks.
+type ConfigEntregas struct {
+	Intervalo     time.Duration
+	MaxTentativas int
+	// Backoff é a espera depois da primeira falha; dobra a cada tentativa, até BackoffMax.
+	Backoff    time.Duration
+	BackoffMax time.Duration
+	// Lote limita quantas entregas saem por ciclo. Os envios do lote são sequenciais, então a Reserva
+	// nunca fica abaixo de Lote × Timeout (o prazo do cliente HTTP) mais margemReserva.
+	Lote    int
+	Timeout time.Duration
+	Reserva time.Duration
+}
+
+// margemReserva cobre, além dos envios, a gravação de cada tentativa no banco.
+const margemReserva = time.Minute
+
+// PublicadorMarcos publica abertura e encerramento dos paredões; o voting.Service atende.
+type PublicadorMarcos interface {
+	PublicarMarcos(ctx context.Context) error
+}
+
+// EnviadorAssinado faz o POST assinado de uma entrega; o webhook.Cliente atende.
+type EnviadorAssinado interface {
+	EnviarAssinado(ctx context.Context, url, segredo string, cabecalhos map[string]string, corpo []byte) (int, error)
+}
+
+// EntregadorWebhooks publica os marcos dos paredões e envia as entregas pendentes a cada intervalo. Cada
+// falha adia a próxima tentativa em backoff exponencial; esgotadas as tentativas, a entrega fica como
+// falhou até alguém pedir o reenvio pelo admin.
+type EntregadorWebhooks struct {
+	repo     domain.WebhookRepository
+	marcos   PublicadorMarcos
+	enviador EnviadorAssinado
+	clock    domain.Clock
+	logger   *slog.Logger
+	cfg      ConfigEntregas
+}
+
+func NewEntregadorWebhooks(repo domain.WebhookRepository, marcos PublicadorMarcos, enviador EnviadorAssinado, clock domain.Clock, logger *slog.Logger, cfg ConfigEntregas) *EntregadorWebhooks {
+	if cfg.Intervalo <= 0 {
+		cfg.Intervalo = 2 * time.Second
+	}
+	if cfg.MaxTentativas <= 0 {
+		cfg.MaxTentativas = 8
+	}
+	if cfg.Backoff <= 0 {
+		cfg.Backoff = 10 * time.Second
+	}
+	if cfg.BackoffMax <= 0 {
+		cfg.BackoffMax = time.Hour
+	}
+	if cfg.Lote <= 0 {
+		cfg.Lote = 50
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	cfg.Reserva = max(cfg.Reserva, time.Duration(cfg.Lote)*cfg.Timeout+margemReserva)
+	return &EntregadorWebhooks{
+		repo:     repo,
+		marcos:   marcos,
+		enviador: enviador,
+		clock:    clock,
+		logger:   logger,
+		cfg:      cfg,
+	}
+}
+
+// Rodar executa um ciclo a cada intervalo até o contexto ser cancelado.
+func (e *EntregadorWebhooks) Rodar(ctx context.Context) {
+	ticker := time.NewTicker(e.cfg.Intervalo)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Ciclo(ctx)
+		}
+	}
+}
+
+// Ciclo publica os marcos e envia um lote de entregas vencidas.
+func (e *EntregadorWebhooks) Ciclo(ctx context.Context) {
+	if e.marcos != nil {
+		if err := e.marcos.PublicarMarcos(ctx); err != nil {
+			e.logger.Warn("webhooks: falha ao publicar marcos", "err", err)
+		}
+	}
+
+	// Se o worker cair no meio do lote, as entregas reservadas voltam para a fila quando a reserva vence.
+	agendadas, err := e.repo.ReservarEntregas(ctx, e.clock.Agora(), e.cfg.Reserva, e.cfg.Lote)
+	if err != nil {
+		e.logger.Warn("webhooks: falha ao reservar entregas", "err", err)
+		return
+	}
+	for _, a := range agendadas {
+		e.entregar(ctx, a)
+	}
+}
+
+func (e *EntregadorWebhooks) entregar(ctx context.Context, a domain.EntregaAgendada) {
+	entrega := a.Entrega
+	reservadaAte := entrega.ProximaTentativa
+	corpo, err := json.Marshal(a.Evento)
+	status := 0
+	if err == nil {
+		status, err = e.enviador.EnviarAssinado(ctx, a.URL, a.Segredo, map[string]string{
+			webhook.CabecalhoEntrega: string(entrega.ID),
+			webhook.CabecalhoEvento:  string(entrega.Tipo),
+		}, corpo)
+	}
+
+	agora := e.clock.Agora()
+	entrega.Tentativas++
+	entrega.UltimoStatusHTTP = status
+	switch {
+	case err == nil:
+		entrega.Status = domain.EntregaEntregue
+		entrega.UltimoErro = ""
+		entrega.EntregueEm = &agora
+	case entrega.Tentativas >= e.cfg.MaxTentativas:
+		entrega.Status = domain.EntregaFalhou
+		entrega.UltimoErro = err.Error()
+		e.logger.Error("webhooks: entrega desistida", "entrega", entrega.ID, "webhook", entrega.WebhookID, "tentativas", entrega.Tentativas, "err", err)
+	default:
+		entrega.Status = domain.EntregaPendente
+		entrega.UltimoErro = err.Error()
+		entrega.ProximaTentativa = agora.Add(e.espera(entrega.Tentativas))
+		e.logger.Warn("webhooks: falha na entrega", "entrega", entrega.ID, "webhook", entrega.WebhookID, "tentativa", entrega.Tentativas, "err", err)
+	}
+
+	if err := e.repo.RegistrarTentativa(ctx, entrega, reservadaAte); err != nil {
+		if errors.Is(err, domain.ErrReservaVencida) {
+			e.logger.Warn("webhooks: reserva vencida, tentativa descartada", "entrega", entrega.ID, "webhook", entrega.WebhookID)
+			return
+		}
+		e.logger.Error("webhooks: falha ao registrar tentativa", "entrega", entrega.ID, "err", err)
+	}
+}
+
+// espera devolve o backoff depois da tentativa n: Backoff, 2×Backoff, 4×Backoff..., limitado a BackoffMax.
+func (e *EntregadorWebhooks) espera(n int) time.Duration {
+	d := e.cfg.Backoff
+	for i := 1; i < n && d < e.cfg.BackoffMax; i++ {
+		d *= 2
+	}
+	return min(d, e.cfg.BackoffMax)
+}
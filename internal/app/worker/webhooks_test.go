@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/webhook"
+)
+
+// filaEntregas atende só a parte da fila que o entregador usa.
+type filaEntregas struct {
+	domain.WebhookRepository
+	agendadas   []domain.EntregaAgendada
+	registradas []domain.EntregaWebhook
+	reserva     time.Duration
+	// reservadas guarda o fim de reserva informado em cada RegistrarTentativa.
+	reservadas []time.Time
+}
+
+func (f *filaEntregas) ReservarEntregas(_ context.Context, _ time.Time, reserva time.Duration, _ int) ([]domain.EntregaAgendada, error) {
+	agendadas := f.agendadas
+	f.agendadas = nil
+	f.reserva = reserva
+	return agendadas, nil
+}
+
+func (f *filaEntregas) RegistrarTentativa(_ context.Context, entrega domain.EntregaWebhook, reservadaAte time.Time) error {
+	f.registradas = append(f.registradas, entrega)
+	f.reservadas = append(f.reservadas, reservadaAte)
+	return nil
+}
+
+type enviadorFixo struct {
+	status     int
+	err        error
+	cabecalhos []map[string]string
+	corpos     [][]byte
+}
+
+func (e *enviadorFixo) EnviarAssinado(_ context.Context, _, _ string, cabecalhos map[string]string, corpo []byte) (int, error) {
+	e.cabecalhos = append(e.cabecalhos, cabecalhos)
+	e.corpos = append(e.corpos, corpo)
+	return e.status, e.err
+}
+
+func agendada(tentativas int) domain.EntregaAgendada {
+	return domain.EntregaAgendada{
+		Entrega: domain.EntregaWebhook{ID: "ent-1", WebhookID: "wh-1", EventoID: "ev-1", Tipo: domain.EventoVirada, Status: domain.EntregaPendente, Tentativas: tentativas},
+		URL:     "http://destino",
+		Segredo: "segredo",
+		Evento:  domain.EventoParedao{ID: "ev-1", Tipo: domain.EventoVirada, ParedaoID: "par-1", Dados: json.RawMessage(`{"novo_lider":"b"}`)},
+	}
+}
+
+func TestEntregadorWebhooks_QuandoDestinoFalha_DeveReagendarComBackoffExponencial(t *testing.T) {
+	agora := time.Date(2024, 11, 25, 22, 0, 0, 0, time.UTC)
+	fila := &filaEntregas{agendadas: []domain.EntregaAgendada{agendada(2)}}
+	enviador := &enviadorFixo{status: 503, err: errors.New("webhook: status inesperado 503")}
+	entregador := NewEntregadorWebhooks(fila, nil, enviador, &fixedClock{now: agora}, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)),
+		ConfigEntregas{MaxTentativas: 5, Backoff: 10 * time.Second})
+
+	entregador.Ciclo(context.Background())
+
+	require.Len(t, fila.registradas, 1)
+	registrada := fila.registradas[0]
+	assert.Equal(t, domain.EntregaPendente, registrada.Status)
+	assert.Equal(t, 3, registrada.Tentativas)
+	assert.Equal(t, agora.Add(40*time.Second), registrada.ProximaTentativa, "terceira falha espera 4×backoff")
+	assert.Equal(t, 503, registrada.UltimoStatusHTTP)
+	assert.Equal(t, "ent-1", enviador.cabecalhos[0][webhook.CabecalhoEntrega])
+	assert.JSONEq(t, `{"id":"ev-1","tipo":"paredao.virada","paredao_id":"par-1","ocorrido_em":"0001-01-01T00:00:00Z","dados":{"novo_lider":"b"}}`, string(enviador.corpos[0]))
+}
+
+func TestEntregadorWebhooks_QuandoTentativasAcabam_DeveMarcarFalha(t *testing.T) {
+	fila := &filaEntregas{agendadas: []domain.EntregaAgendada{agendada(4)}}
+	enviador := &enviadorFixo{err: errors.New("webhook: enviar: connection refused")}
+	entregador := NewEntregadorWebhooks(fila, nil, enviador, &fixedClock{now: time.Now()}, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)),
+		ConfigEntregas{MaxTentativas: 5})
+
+	entregador.Ciclo(context.Background())
+
+	require.Len(t, fila.registradas, 1)
+	assert.Equal(t, domain.EntregaFalhou, fila.registradas[0].Status)
+	assert.Contains(t, fila.registradas[0].UltimoErro, "connection refused")
+}
+
+func TestEntregadorWebhooks_QuandoDestinoAceita_DeveMarcarEntregue(t *testing.T) {
+	agora := time.Date(2024, 11, 25, 22, 0, 0, 0, time.UTC)
+	fila := &filaEntregas{agendadas: []domain.EntregaAgendada{agendada(0)}}
+	entregador := NewEntregadorWebhooks(fila, nil, &enviadorFixo{status: 204}, &fixedClock{now: agora}, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)),
+		ConfigEntregas{})
+
+	entregador.Ciclo(context.Background())
+
+	require.Len(t, fila.registradas, 1)
+	assert.Equal(t, domain.EntregaEntregue, fila.registradas[0].Status)
+	require.NotNil(t, fila.registradas[0].EntregueEm)
+	assert.Equal(t, agora, *fila.registradas[0].EntregueEm)
+}
+
+func TestEntregadorWebhooks_QuandoReservaMenorQueOLote_DeveAmpliarParaCobrirOsEnvios(t *testing.T) {
+	agora := time.Date(2024, 11, 25, 22, 0, 0, 0, time.UTC)
+	reservada := agendada(0)
+	reservada.Entrega.ProximaTentativa = agora.Add(10 * time.Minute)
+	fila := &filaEntregas{agendadas: []domain.EntregaAgendada{reservada}}
+	entregador := NewEntregadorWebhooks(fila, nil, &enviadorFixo{status: 204}, &fixedClock{now: agora}, slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil)),
+		ConfigEntregas{Lote: 50, Timeout: 10 * time.Second, Reserva: 5 * time.Minute})
+
+	entregador.Ciclo(context.Background())
+
+	assert.Equal(t, 50*10*time.Second+margemReserva, fila.reserva)
+	require.Len(t, fila.reservadas, 1)
+	assert.Equal(t, agora.Add(10*time.Minute), fila.reservadas[0], "a tentativa confere a reserva devolvida, não a próxima tentativa nova")
+}
@@ -3,3 +3,10 @@ package domain
 import "errors"
 
 var ErrNotFound = errors.New("registro nao encontrado")
+
+// ErrReservaVencida indica que a entrega de webhook mudou depois da reserva: a reserva venceu e outro
+// worker a assumiu, ou o admin pediu o reenvio.
+var ErrReservaVencida = errors.New("reserva da entrega vencida")
+
+// ErrVotoDuplicado indica que a restrição de voto único rejeitou o registro no banco.
+var ErrVotoDuplicado = errors.New("voto unico ja registrado")
@@ -1,6 +1,8 @@
 package domain
 
 import (
+	"encoding/json"
+	"math"
 	"time"
 )
 
@@ -8,6 +10,48 @@ type (
 	ParedaoID      string
 	ParticipanteID string
 	VotoID         string
+	// EleitorID identifica a conta autenticada do eleitor; vazio nos votos anônimos de torcida.
+	EleitorID string
+	WebhookID string
+	EventoID  string
+	EntregaID string
+)
+
+// ModoVotacao define quais modalidades de voto o paredão aceita.
+type ModoVotacao string
+
+const (
+	ModoTorcida ModoVotacao = "torcida"
+	ModoUnico   ModoVotacao = "unico"
+	ModoMisto   ModoVotacao = "misto"
+)
+
+// Modalidade classifica cada voto: torcida é ilimitado (sujeito ao antifraude), único vale uma vez por conta.
+type Modalidade string
+
+const (
+	ModalidadeTorcida Modalidade = "torcida"
+	ModalidadeUnico   Modalidade = "unico"
+)
+
+// Polaridade diz o que o voto significa: eliminar (sai o mais votado) ou salvar (sai o menos votado).
+type Polaridade string
+
+const (
+	PolaridadeEliminar Polaridade = "eliminar"
+	PolaridadeSalvar   Polaridade = "salvar"
+)
+
+// PoliticaRetirada define o que acontece com os votos de um participante que deixa o paredão com a votação aberta.
+type PoliticaRetirada string
+
+const (
+	// RetiradaDescartar ignora os votos do participante, como se ele nunca tivesse estado no paredão.
+	RetiradaDescartar PoliticaRetirada = "descartar"
+	// RetiradaManter preserva os votos no total, mas tira o participante dos percentuais.
+	RetiradaManter PoliticaRetirada = "manter"
+	// RetiradaAnular encerra o paredão sem resultado.
+	RetiradaAnular PoliticaRetirada = "anular"
 )
 
 type Paredao struct {
@@ -18,33 +62,257 @@ type Paredao struct {
 	Fim           time.Time      `gorm:"column:fim;not null"`
 	Participantes []Participante `gorm:"foreignKey:ParedaoID;constraint:OnDelete:CASCADE"`
 	Ativo         bool           `gorm:"column:ativo;not null;default:true"`
-	CriadoEm      time.Time      `gorm:"column:criado_em;autoCreateTime"`
-	AtualizadoEm  time.Time      `gorm:"column:atualizado_em;autoUpdateTime"`
+	ModoVotacao   ModoVotacao    `gorm:"column:modo_votacao;type:text;not null;default:'torcida'"`
+	Polaridade    Polaridade     `gorm:"column:polaridade;type:text;not null;default:'eliminar'"`
+	// Etapas de um mesmo paredão (bate-volta seguido do paredão principal) compartilham GrupoID, que é o
+	// ID da primeira etapa. Paredões de etapa única ficam com GrupoID vazio e Etapa 1.
+	GrupoID   ParedaoID `gorm:"column:grupo_id;type:char(26);index:idx_paredoes_grupo_etapa,priority:1"`
+	Etapa     int       `gorm:"column:etapa;not null;default:1;index:idx_paredoes_grupo_etapa,priority:2"`
+	EtapaNome string    `gorm:"column:etapa_nome;type:text"`
+	// Anulado marca o paredão encerrado sem resultado pela retirada de um participante.
+	Anulado bool `gorm:"column:anulado;not null;default:false"`
+	// Os ajustes administrativos ficam fora do JSON: as rotas legadas servem o Paredao direto ao público e
+	// esses valores só saem pelos getters do admin.
+	Pesos        PesosModalidade      `gorm:"embedded;embeddedPrefix:peso_" json:"-"`
+	Anomalia     LimiaresAnomalia     `gorm:"embedded;embeddedPrefix:anomalia_" json:"-"`
+	Antifraude   PoliticaAntifraude   `gorm:"embedded;embeddedPrefix:antifraude_" json:"-"`
+	Visibilidade PoliticaVisibilidade `gorm:"embedded;embeddedPrefix:visibilidade_" json:"-"`
+	CriadoEm     time.Time            `gorm:"column:criado_em;autoCreateTime"`
+	AtualizadoEm time.Time            `gorm:"column:atualizado_em;autoUpdateTime"`
+}
+
+// LimiaresAnomalia calibra a detecção de picos de votos de um paredão; valores zerados usam o padrão global.
+type LimiaresAnomalia struct {
+	LimiarZ  float64 `gorm:"column:limiar_z;not null;default:0" json:"limiar_z"`
+	MinVotos int64   `gorm:"column:min_votos;not null;default:0" json:"min_votos"`
+}
+
+// PesosModalidade define quanto cada modalidade vale no percentual oficial de um paredão misto.
+// Os pesos são proporcionais (50/50 equivale a 1/1); ambos zerados significam divisão igual.
+type PesosModalidade struct {
+	Unico   float64 `gorm:"column:unico;not null;default:0" json:"unico"`
+	Torcida float64 `gorm:"column:torcida;not null;default:0" json:"torcida"`
+}
+
+// PesosEfetivos devolve o peso normalizado de cada modalidade que conta para o resultado do paredão.
+func (p Paredao) PesosEfetivos() map[Modalidade]float64 {
+	switch p.ModoVotacao {
+	case ModoUnico:
+		return map[Modalidade]float64{ModalidadeUnico: 1}
+	case ModoMisto:
+		unico, torcida := p.Pesos.Unico, p.Pesos.Torcida
+		if unico+torcida <= 0 {
+			unico, torcida = 1, 1
+		}
+		soma := unico + torcida
+		return map[Modalidade]float64{ModalidadeUnico: unico / soma, ModalidadeTorcida: torcida / soma}
+	default:
+		return map[Modalidade]float64{ModalidadeTorcida: 1}
+	}
+}
+
+// PolaridadeEfetiva trata paredões antigos, sem polaridade gravada, como votação para eliminar.
+func (p Paredao) PolaridadeEfetiva() Polaridade {
+	if p.Polaridade == PolaridadeSalvar {
+		return PolaridadeSalvar
+	}
+	return PolaridadeEliminar
+}
+
+// Apurar aponta quem deixa a casa segundo a polaridade: o maior percentual oficial quando se vota para
+// eliminar, o menor quando se vota para salvar. Em caso de empate na ponta, eliminado fica vazio e
+// empatados lista os participantes que precisam de desempate; sem votos não há eliminado.
+// Participantes retirados nunca são apontados.
+func Apurar(polaridade Polaridade, parciais []Parcial) (eliminado ParticipanteID, empatados []ParticipanteID) {
+	return extremo(parciais, polaridade == PolaridadeSalvar)
+}
+
+// Destaque aponta o maior percentual oficial independentemente da polaridade; numa etapa intermediária
+// (bate-volta) é quem escapa do paredão. Empates e ausência de votos seguem a mesma regra de Apurar.
+func Destaque(parciais []Parcial) (ParticipanteID, []ParticipanteID) {
+	return extremo(parciais, false)
+}
+
+func extremo(parciais []Parcial, menor bool) (ParticipanteID, []ParticipanteID) {
+	const epsilon = 1e-9
+
+	var total int64
+	disputa := make([]Parcial, 0, len(parciais))
+	for _, parcial := range parciais {
+		if parcial.Retirado {
+			continue
+		}
+		total += parcial.Total
+		disputa = append(disputa, parcial)
+	}
+	if total == 0 || len(disputa) == 0 {
+		return "", nil
+	}
+
+	alvo := disputa[0].Percentual
+	for _, parcial := range disputa[1:] {
+		if (menor && parcial.Percentual < alvo) || (!menor && parcial.Percentual > alvo) {
+			alvo = parcial.Percentual
+		}
+	}
+	var empatados []ParticipanteID
+	for _, parcial := range disputa {
+		if math.Abs(parcial.Percentual-alvo) < epsilon {
+			empatados = append(empatados, parcial.ParticipanteID)
+		}
+	}
+	if len(empatados) == 1 {
+		return empatados[0], nil
+	}
+	return "", empatados
+}
+
+// AlgoritmoLimite escolhe como o rate limit conta os votos dentro da janela.
+type AlgoritmoLimite string
+
+const (
+	AlgoritmoJanelaFixa       AlgoritmoLimite = "janela_fixa"
+	AlgoritmoJanelaDeslizante AlgoritmoLimite = "janela_deslizante"
+)
+
+// EscopoBloqueio define se a contagem (e o bloqueio) de um eleitor vale só para o paredão ou para todos.
+type EscopoBloqueio string
+
+const (
+	EscopoParedao EscopoBloqueio = "paredao"
+	EscopoGlobal  EscopoBloqueio = "global"
+)
+
+// PoliticaAntifraude sobrescreve os padrões globais de antifraude para um paredão.
+// Campos zerados herdam o padrão; o CAPTCHA vale se o padrão global ou o paredão o exigirem.
+type PoliticaAntifraude struct {
+	Limite         int             `gorm:"column:limite;not null;default:0" json:"limite"`
+	JanelaSegundos int             `gorm:"column:janela_segundos;not null;default:0" json:"janela_segundos"`
+	Algoritmo      AlgoritmoLimite `gorm:"column:algoritmo;type:text;not null;default:''" json:"algoritmo,omitempty"`
+	ExigeCaptcha   bool            `gorm:"column:exige_captcha;not null;default:false" json:"exige_captcha"`
+	EscopoBloqueio EscopoBloqueio  `gorm:"column:escopo_bloqueio;type:text;not null;default:''" json:"escopo_bloqueio,omitempty"`
+}
+
+// Janela converte JanelaSegundos para time.Duration.
+func (p PoliticaAntifraude) Janela() time.Duration {
+	return time.Duration(p.JanelaSegundos) * time.Second
+}
+
+// ModoVisibilidade define quando o público vê as parciais de um paredão aberto.
+type ModoVisibilidade string
+
+const (
+	VisibilidadePublica  ModoVisibilidade = "publica"
+	VisibilidadeOculta   ModoVisibilidade = "oculta"
+	VisibilidadeAtrasada ModoVisibilidade = "atrasada"
+)
+
+// PoliticaVisibilidade controla as parciais exibidas nos endpoints públicos; a produção sempre vê ao vivo.
+// AtrasoMinutos só vale no modo atrasado.
+type PoliticaVisibilidade struct {
+	Modo          ModoVisibilidade `gorm:"column:modo;type:text;not null;default:'publica'" json:"modo"`
+	AtrasoMinutos int              `gorm:"column:atraso_minutos;not null;default:0" json:"atraso_minutos"`
+}
+
+// Publicacao diz o que o público pode ver de um paredão num dado instante.
+// Oculta dispensa qualquer número; Ate diferente de zero limita as parciais aos votos até aquele instante.
+type Publicacao struct {
+	Modo     ModoVisibilidade `json:"visibilidade"`
+	Oculta   bool             `json:"oculta"`
+	Ate      time.Time        `json:"ate,omitempty"`
+	LiberaEm time.Time        `json:"libera_em,omitempty"`
+}
+
+// Publicacao aplica a política de visibilidade: encerrada a votação, tudo fica público e ao vivo.
+func (p Paredao) Publicacao(agora time.Time) Publicacao {
+	if !p.Ativo || agora.After(p.Fim) {
+		return Publicacao{Modo: VisibilidadePublica}
+	}
+	switch p.Visibilidade.Modo {
+	case VisibilidadeOculta:
+		return Publicacao{Modo: VisibilidadeOculta, Oculta: true, LiberaEm: p.Fim}
+	case VisibilidadeAtrasada:
+		atraso := time.Duration(p.Visibilidade.AtrasoMinutos) * time.Minute
+		return Publicacao{Modo: VisibilidadeAtrasada, Ate: agora.Add(-atraso)}
+	default:
+		return Publicacao{Modo: VisibilidadePublica}
+	}
 }
 
 type Participante struct {
-	ID           ParticipanteID `gorm:"column:id;type:char(26);primaryKey"`
-	ParedaoID    ParedaoID      `gorm:"column:paredao_id;type:char(26);not null;index"`
-	Nome         string         `gorm:"column:nome;type:text;not null"`
-	FotoURL      string         `gorm:"column:foto_url;type:text"`
-	CriadoEm     time.Time      `gorm:"column:criado_em;autoCreateTime"`
-	AtualizadoEm time.Time      `gorm:"column:atualizado_em;autoUpdateTime"`
+	ID        ParticipanteID `gorm:"column:id;type:char(26);primaryKey"`
+	ParedaoID ParedaoID      `gorm:"column:paredao_id;type:char(26);not null;index"`
+	Nome      string         `gorm:"column:nome;type:text;not null"`
+	FotoURL   string         `gorm:"column:foto_url;type:text"`
+	// OrigemID liga o participante à sua primeira aparição no grupo de etapas; igual ao próprio ID na etapa 1.
+	OrigemID ParticipanteID `gorm:"column:origem_id;type:char(26)"`
+	// Retirada vazia indica participante na disputa; preenchida, guarda a política aplicada aos seus votos.
+	Retirada     PoliticaRetirada `gorm:"column:retirada;type:text;not null;default:''"`
+	RetiradoEm   *time.Time       `gorm:"column:retirado_em"`
+	CriadoEm     time.Time        `gorm:"column:criado_em;autoCreateTime"`
+	AtualizadoEm time.Time        `gorm:"column:atualizado_em;autoUpdateTime"`
+}
+
+// Retirado indica que o participante deixou o paredão e não recebe mais votos.
+func (p Participante) Retirado() bool {
+	return p.Retirada != ""
 }
 
 type Voto struct {
 	ID             VotoID         `gorm:"column:id;type:char(26);primaryKey"`
-	ParedaoID      ParedaoID      `gorm:"column:paredao_id;type:char(26);not null;index:idx_votos_paredao;index:idx_votos_paredao_criado_em,priority:1"`
+	ParedaoID      ParedaoID      `gorm:"column:paredao_id;type:char(26);not null;index:idx_votos_paredao;index:idx_votos_paredao_criado_em,priority:1;index:idx_votos_paredao_seq,priority:1;uniqueIndex:idx_votos_voto_unico,priority:1,where:modalidade = 'unico'"`
 	ParticipanteID ParticipanteID `gorm:"column:participante_id;type:char(26);not null;index:idx_votos_participante"`
 	OrigemIP       string         `gorm:"column:origem_ip;type:inet"`
 	UserAgent      string         `gorm:"column:user_agent;type:text"`
-	CriadoEm       time.Time      `gorm:"column:criado_em;autoCreateTime;index:idx_votos_paredao_criado_em,priority:2"`
+	// O índice único parcial é a garantia final do voto único, mesmo que a reserva no Redis se perca.
+	EleitorID  EleitorID  `gorm:"column:eleitor_id;type:text;uniqueIndex:idx_votos_voto_unico,priority:2,where:modalidade = 'unico'"`
+	Modalidade Modalidade `gorm:"column:modalidade;type:text;not null;default:'torcida'"`
+	CriadoEm   time.Time  `gorm:"column:criado_em;autoCreateTime;index:idx_votos_paredao_criado_em,priority:2"`
+	// Seq e Hash posicionam o voto na cadeia de auditoria do paredão; são preenchidos na gravação.
+	Seq  int64  `gorm:"column:seq;not null;default:0;index:idx_votos_paredao_seq,priority:2" json:"-"`
+	Hash string `gorm:"column:hash;type:char(64);not null;default:''" json:"-"`
+	// CaptchaToken só vive na requisição: é validado pelo antifraude e nunca segue para fila ou banco.
+	CaptchaToken string `gorm:"-" json:"-"`
 }
 
+// Parcial resume os votos de um participante. Total soma todas as modalidades e Percentual é o oficial,
+// já ponderado pelos pesos do paredão; Modalidades traz o detalhamento de cada urna.
+// Retirado marca quem saiu do paredão mas teve os votos mantidos: entra no total, não nos percentuais.
 type Parcial struct {
 	ParedaoID      ParedaoID
 	ParticipanteID ParticipanteID
 	Total          int64
 	Percentual     float64
+	Modalidades    []ParcialModalidade
+	Retirado       bool `json:",omitempty"`
+}
+
+// ParcialModalidade é o recorte de uma única urna: total, percentual dentro dela e o peso aplicado.
+type ParcialModalidade struct {
+	Modalidade Modalidade
+	Total      int64
+	Percentual float64
+	Peso       float64
+}
+
+// Resultado consolida as parciais no momento em que o paredão é finalizado.
+// Eliminado vem vazio quando há empate na ponta (ver Empatados), quando não houve votos ou quando o
+// paredão foi anulado.
+type Resultado struct {
+	ParedaoID  ParedaoID
+	Polaridade Polaridade
+	Anulado    bool `json:",omitempty"`
+	TotalVotos int64
+	Parciais   []Parcial
+	Eliminado  ParticipanteID   `json:",omitempty"`
+	Empatados  []ParticipanteID `json:",omitempty"`
+	// Numa etapa intermediária ninguém deixa a casa: Escapou sai do paredão e os demais seguem para ProximaEtapa.
+	Escapou      ParticipanteID `json:",omitempty"`
+	ProximaEtapa ParedaoID      `json:",omitempty"`
+	// DesempatePendente indica etapa intermediária sem destaque único: a seguinte só abre depois que a
+	// produção escolher quem escapa.
+	DesempatePendente bool `json:",omitempty"`
+	FinalizadoEm      time.Time
 }
 
 type ParcialHora struct {
@@ -53,8 +321,292 @@ type ParcialHora struct {
 	Total     int64
 }
 
+// ParcialIntervalo conta os votos de um participante num intervalo da série temporal.
+type ParcialIntervalo struct {
+	ParedaoID      ParedaoID
+	ParticipanteID ParticipanteID
+	Modalidade     Modalidade
+	Inicio         time.Time
+	Total          int64
+}
+
+// SerieParticipante acompanha um participante intervalo a intervalo, do primeiro ao último com votos.
+type SerieParticipante struct {
+	ParedaoID      ParedaoID
+	ParticipanteID ParticipanteID
+	Pontos         []PontoSerie
+}
+
+// PontoSerie traz os votos recebidos no intervalo, o acumulado até o fim dele e a participação: o
+// percentual do acumulado do participante sobre o acumulado de todos naquele momento, sem ponderar urnas.
+type PontoSerie struct {
+	Inicio       time.Time
+	Votos        int64
+	Acumulado    int64
+	Participacao float64
+}
+
+// Projecao é uma estimativa do resultado ao fim da votação, não um resultado: extrapola o ritmo e a
+// divisão dos votos dos últimos intervalos de cada urna até Fim. Os percentuais seguem a regra das
+// parciais oficiais: urnas ponderadas pelos pesos efetivos e só quem segue na disputa na base.
+type Projecao struct {
+	ParedaoID ParedaoID `json:"paredao_id"`
+	// Estimativa é sempre true; vai no JSON para que nenhum consumidor confunda a projeção com parcial.
+	Estimativa  bool      `json:"estimativa"`
+	CalculadaEm time.Time `json:"calculada_em"`
+	Fim         time.Time `json:"fim"`
+	// Confianca é o nível nominal da faixa de cada participante (ex.: 0.9).
+	Confianca       float64                `json:"confianca"`
+	VotosAtuais     int64                  `json:"votos_atuais"`
+	VotosProjetados int64                  `json:"votos_projetados"`
+	Participantes   []ProjecaoParticipante `json:"participantes"`
+}
+
+// ProjecaoParticipante traz a participação atual, a projetada para o fim e a faixa de confiança dela.
+type ProjecaoParticipante struct {
+	ParticipanteID ParticipanteID `json:"participante_id"`
+	Atual          float64        `json:"atual"`
+	Projetada      float64        `json:"projetada"`
+	Minima         float64        `json:"minima"`
+	Maxima         float64        `json:"maxima"`
+}
+
+// DecisaoLimite resume a cota de rate limit consumida pelo voto; Limite zero indica que não há limite ativo.
+type DecisaoLimite struct {
+	Limite   int
+	Restante int
+	Reset    time.Time
+}
+
+// EstadoVoto acompanha o voto desde o aceite na API até a gravação pelo worker.
+type EstadoVoto string
+
+const (
+	VotoEnfileirado EstadoVoto = "enfileirado"
+	VotoProcessado  EstadoVoto = "processado"
+	VotoRejeitado   EstadoVoto = "rejeitado"
+)
+
+// StatusVoto é o andamento de um voto consultado pelo eleitor depois do 202; Motivo explica a rejeição.
+type StatusVoto struct {
+	VotoID       VotoID     `json:"voto_id"`
+	ParedaoID    ParedaoID  `json:"paredao_id,omitempty"`
+	Estado       EstadoVoto `json:"estado"`
+	Motivo       string     `json:"motivo,omitempty"`
+	AtualizadoEm time.Time  `json:"atualizado_em"`
+}
+
+// ResultadoVoto devolve à camada de entrega o que foi decidido ao registrar um voto.
+type ResultadoVoto struct {
+	// VotoID é o identificador atribuído ao voto aceito; fica vazio quando o voto é recusado.
+	VotoID VotoID
+	Limite DecisaoLimite
+	// Recibo vem nil quando o voto é recusado ou a emissão de recibos está desligada.
+	Recibo *Recibo
+}
+
+// Recibo comprova que o servidor aceitou o voto: a assinatura Ed25519 da chave ChaveID cobre voto,
+// paredão e instante de emissão. Não identifica o participante escolhido.
+type Recibo struct {
+	VotoID     VotoID    `json:"voto_id"`
+	ParedaoID  ParedaoID `json:"paredao_id"`
+	EmitidoEm  time.Time `json:"emitido_em"`
+	ChaveID    string    `json:"chave_id"`
+	Assinatura string    `json:"assinatura"`
+}
+
+// ChavePublicaRecibo é publicada para que terceiros verifiquem recibos sem consultar a API; Ativa marca a
+// chave que assina os recibos novos, as demais seguem valendo para recibos antigos.
+type ChavePublicaRecibo struct {
+	ChaveID   string `json:"chave_id"`
+	Algoritmo string `json:"algoritmo"`
+	Chave     string `json:"chave_publica"`
+	Ativa     bool   `json:"ativa"`
+}
+
+// VerificacaoRecibo detalha a conferência de um recibo; Valido só é verdadeiro quando a assinatura confere
+// e o voto está gravado e não foi anulado.
+type VerificacaoRecibo struct {
+	Valido           bool       `json:"valido"`
+	AssinaturaValida bool       `json:"assinatura_valida"`
+	VotoEncontrado   bool       `json:"voto_encontrado"`
+	Anulado          bool       `json:"anulado"`
+	Estado           EstadoVoto `json:"estado,omitempty"`
+	Motivo           string     `json:"motivo,omitempty"`
+}
+
+// LinhaBase guarda a média/variância móvel (EWMA) de votos por janela usada como referência de normalidade.
+type LinhaBase struct {
+	Media        float64
+	Variancia    float64
+	Amostras     int64
+	UltimaJanela int64
+}
+
+// AlertaAnomalia descreve uma janela cujo volume de votos fugiu da linha de base.
+// ParticipanteID vazio indica que o alerta se refere ao paredão inteiro.
+type AlertaAnomalia struct {
+	ParedaoID      ParedaoID      `json:"paredao_id"`
+	ParticipanteID ParticipanteID `json:"participante_id,omitempty"`
+	InicioJanela   time.Time      `json:"inicio_janela"`
+	Votos          int64          `json:"votos"`
+	Media          float64        `json:"media"`
+	Desvio         float64        `json:"desvio"`
+	ZScore         float64        `json:"z_score"`
+}
+
+// VotoAuditado é o voto como aparece na exportação de auditoria: só os campos cobertos pelo hash, mais a
+// posição e o elo gravados na cadeia.
+type VotoAuditado struct {
+	Seq            int64          `json:"seq"`
+	VotoID         VotoID         `json:"voto_id"`
+	ParedaoID      ParedaoID      `json:"paredao_id"`
+	ParticipanteID ParticipanteID `json:"participante_id"`
+	Modalidade     Modalidade     `json:"modalidade"`
+	CriadoEm       time.Time      `json:"criado_em"`
+	Hash           string         `json:"hash"`
+	// OrigemIP sai sempre pseudonimizado: agrupa votos da mesma origem sem revelar o endereço.
+	OrigemIP string `json:"origem_ip,omitempty"`
+}
+
+// CadeiaVotos é a ponta da cadeia de hashes de um paredão: quantos votos ela tem, o último elo e a
+// fronteira da árvore de Merkle (hashes em hexadecimal separados por vírgula).
+type CadeiaVotos struct {
+	ParedaoID    ParedaoID `gorm:"column:paredao_id;type:char(26);primaryKey"`
+	Tamanho      int64     `gorm:"column:tamanho;not null;default:0"`
+	Hash         string    `gorm:"column:hash;type:char(64);not null;default:''"`
+	Fronteira    string    `gorm:"column:fronteira;type:text;not null;default:''"`
+	AtualizadoEm time.Time `gorm:"column:atualizado_em"`
+}
+
+// Checkpoint é a raiz assinada da cadeia de um paredão num dado tamanho. Quem guardou um checkpoint
+// consegue provar depois que os Tamanho primeiros votos não foram inseridos, alterados nem apagados.
+type Checkpoint struct {
+	ParedaoID  ParedaoID `gorm:"column:paredao_id;type:char(26);primaryKey" json:"paredao_id"`
+	Tamanho    int64     `gorm:"column:tamanho;primaryKey;autoIncrement:false" json:"tamanho"`
+	HashCadeia string    `gorm:"column:hash_cadeia;type:char(64);not null" json:"hash_cadeia"`
+	RaizMerkle string    `gorm:"column:raiz_merkle;type:char(64);not null" json:"raiz_merkle"`
+	CriadoEm   time.Time `gorm:"column:criado_em;not null" json:"criado_em"`
+	ChaveID    string    `gorm:"column:chave_id;type:text;not null" json:"chave_id"`
+	Assinatura string    `gorm:"column:assinatura;type:text;not null" json:"assinatura"`
+}
+
+// PacoteAuditoria reúne o que acompanha os votos no pacote de auditoria de um paredão encerrado.
+type PacoteAuditoria struct {
+	Paredao       Paredao
+	Participantes []Participante
+	Resultado     Resultado
+	Checkpoints   []Checkpoint
+}
+
+// Virada registra uma troca na ponta da apuração: quem estava saindo (ou escapando, numa etapa
+// intermediária) deixou de estar. A primeira de cada paredão não tem LiderAnterior e só marca quem assumiu
+// a ponta. Margem é a vantagem, em pontos percentuais, do novo líder sobre o segundo colocado.
+type Virada struct {
+	ParedaoID     ParedaoID      `gorm:"column:paredao_id;type:char(26);primaryKey" json:"paredao_id"`
+	Ordem         int            `gorm:"column:ordem;primaryKey;autoIncrement:false" json:"ordem"`
+	OcorridaEm    time.Time      `gorm:"column:ocorrida_em;not null" json:"ocorrida_em"`
+	LiderAnterior ParticipanteID `gorm:"column:lider_anterior;type:char(26);not null;default:''" json:"lider_anterior,omitempty"`
+	NovoLider     ParticipanteID `gorm:"column:novo_lider;type:char(26);not null" json:"novo_lider"`
+	Margem        float64        `gorm:"column:margem;not null" json:"margem"`
+	TotalVotos    int64          `gorm:"column:total_votos;not null" json:"total_votos"`
+}
+
+// TipoEvento identifica os eventos do paredão entregues aos webhooks.
+type TipoEvento string
+
+const (
+	EventoParedaoAberto     TipoEvento = "paredao.aberto"
+	EventoParedaoEncerrado  TipoEvento = "paredao.encerrado"
+	EventoParedaoFinalizado TipoEvento = "paredao.finalizado"
+	EventoVirada            TipoEvento = "paredao.virada"
+)
+
+// TiposEvento lista os eventos que um webhook pode assinar.
+var TiposEvento = []TipoEvento{EventoParedaoAberto, EventoParedaoEncerrado, EventoParedaoFinalizado, EventoVirada}
+
+// Webhook é a assinatura de um sistema externo: cada evento dos tipos em Eventos gera uma entrega para
+// URL, assinada com HMAC-SHA256 de Segredo. O segredo só aparece na resposta da criação.
+type Webhook struct {
+	ID       WebhookID    `gorm:"column:id;type:char(26);primaryKey" json:"id"`
+	URL      string       `gorm:"column:url;type:text;not null" json:"url"`
+	Segredo  string       `gorm:"column:segredo;type:text;not null" json:"segredo,omitempty"`
+	Eventos  []TipoEvento `gorm:"column:eventos;type:text;not null;serializer:json" json:"eventos"`
+	Ativo    bool         `gorm:"column:ativo;not null;default:true" json:"ativo"`
+	CriadoEm time.Time    `gorm:"column:criado_em;not null" json:"criado_em"`
+}
+
+// Assina diz se o webhook está ativo e quer receber eventos do tipo informado.
+func (w Webhook) Assina(tipo TipoEvento) bool {
+	if !w.Ativo {
+		return false
+	}
+	for _, t := range w.Eventos {
+		if t == tipo {
+			return true
+		}
+	}
+	return false
+}
+
+// EventoParedao é o corpo enviado aos webhooks. Chave identifica o fato (ex.: a virada de ordem 3 de um
+// paredão), para que duas instâncias publicando o mesmo fato gerem um evento só.
+type EventoParedao struct {
+	ID         EventoID        `gorm:"column:id;type:char(26);primaryKey" json:"id"`
+	Chave      string          `gorm:"column:chave;type:text;not null;uniqueIndex" json:"-"`
+	Tipo       TipoEvento      `gorm:"column:tipo;type:text;not null" json:"tipo"`
+	ParedaoID  ParedaoID       `gorm:"column:paredao_id;type:char(26);not null;index" json:"paredao_id"`
+	OcorridoEm time.Time       `gorm:"column:ocorrido_em;not null" json:"ocorrido_em"`
+	Dados      json.RawMessage `gorm:"column:dados;type:jsonb;not null" json:"dados"`
+}
+
+// StatusEntrega acompanha uma entrega de evento para um webhook.
+type StatusEntrega string
+
+const (
+	EntregaPendente StatusEntrega = "pendente"
+	EntregaEntregue StatusEntrega = "entregue"
+	// EntregaFalhou é definitivo: as tentativas acabaram e só o reenvio pelo admin recoloca a entrega na fila.
+	EntregaFalhou StatusEntrega = "falhou"
+)
+
+// EntregaWebhook é o registro de entrega de um evento a um webhook, atualizado a cada tentativa.
+type EntregaWebhook struct {
+	ID               EntregaID     `gorm:"column:id;type:char(26);primaryKey" json:"id"`
+	WebhookID        WebhookID     `gorm:"column:webhook_id;type:char(26);not null;index" json:"webhook_id"`
+	EventoID         EventoID      `gorm:"column:evento_id;type:char(26);not null" json:"evento_id"`
+	Tipo             TipoEvento    `gorm:"column:tipo;type:text;not null" json:"tipo"`
+	Status           StatusEntrega `gorm:"column:status;type:text;not null;index:idx_entregas_pendentes,priority:1" json:"status"`
+	Tentativas       int           `gorm:"column:tentativas;not null;default:0" json:"tentativas"`
+	ProximaTentativa time.Time     `gorm:"column:proxima_tentativa;not null;index:idx_entregas_pendentes,priority:2" json:"proxima_tentativa"`
+	UltimoStatusHTTP int           `gorm:"column:ultimo_status_http;not null;default:0" json:"ultimo_status_http,omitempty"`
+	UltimoErro       string        `gorm:"column:ultimo_erro;type:text;not null;default:''" json:"ultimo_erro,omitempty"`
+	CriadaEm         time.Time     `gorm:"column:criada_em;not null" json:"criada_em"`
+	EntregueEm       *time.Time    `gorm:"column:entregue_em" json:"entregue_em,omitempty"`
+}
+
+// EntregaAgendada junta a entrega ao destino e ao evento que o worker precisa enviar.
+type EntregaAgendada struct {
+	Entrega EntregaWebhook
+	URL     string
+	Segredo string
+	Evento  EventoParedao
+}
+
 func (Paredao) TableName() string { return "paredoes" }
 
 func (Participante) TableName() string { return "participantes" }
 
 func (Voto) TableName() string { return "votos" }
+
+func (CadeiaVotos) TableName() string { return "cadeias_votos" }
+
+func (Checkpoint) TableName() string { return "checkpoints_votos" }
+
+func (Virada) TableName() string { return "viradas" }
+
+func (Webhook) TableName() string { return "webhooks" }
+
+func (EventoParedao) TableName() string { return "eventos_paredao" }
+
+func (EntregaWebhook) TableName() string { return "entregas_webhook" }
@@ -7,21 +7,50 @@ import (
 
 type ParedaoRepository interface {
 	Create(ctx context.Context, p Paredao) error
+	// Update grava só o que o ciclo de vida controla: nome, descrição, período, ativo e anulado. Os ajustes
+	// administrativos têm cada um seu método, para não sobrescrever uma finalização feita em paralelo.
 	Update(ctx context.Context, p Paredao) error
 	FindByID(ctx context.Context, id ParedaoID) (Paredao, error)
 	ListAtivos(ctx context.Context) ([]Paredao, error)
+	// ListByGrupo devolve as etapas de um paredão em ordem; vazio quando o ID não agrupa etapas.
+	ListByGrupo(ctx context.Context, grupo ParedaoID) ([]Paredao, error)
+	// ListComMarco devolve os paredões cujo início ou fim caiu no período (desde, ate].
+	ListComMarco(ctx context.Context, desde, ate time.Time) ([]Paredao, error)
+	// CreateEtapas grava todas as etapas de um grupo, com os participantes que cada uma já traz, numa única
+	// transação: ou o grupo inteiro existe, ou nenhuma etapa existe.
+	CreateEtapas(ctx context.Context, etapas []Paredao) error
+	// AbrirEtapa preenche a etapa com os participantes e a ativa, com a linha da etapa travada durante a
+	// transação. Não faz nada quando a etapa já tem participantes, então finalizações concorrentes abrem uma vez só.
+	AbrirEtapa(ctx context.Context, etapa Paredao, participantes []Participante) error
+	// AtualizarAntifraude grava só a política antifraude do paredão, sem tocar nas colunas do ciclo de vida.
+	AtualizarAntifraude(ctx context.Context, id ParedaoID, politica PoliticaAntifraude, em time.Time) error
+	// AtualizarLimiares grava só os limiares de alerta de anomalias.
+	AtualizarLimiares(ctx context.Context, id ParedaoID, limiares LimiaresAnomalia, em time.Time) error
+	// AtualizarVotacao grava só o modo de votação, os pesos das urnas e a polaridade.
+	AtualizarVotacao(ctx context.Context, id ParedaoID, modo ModoVotacao, pesos PesosModalidade, polaridade Polaridade, em time.Time) error
+	// AtualizarVisibilidade grava só a política de visibilidade das parciais públicas.
+	AtualizarVisibilidade(ctx context.Context, id ParedaoID, politica PoliticaVisibilidade, em time.Time) error
 }
 
 type ParticipanteRepository interface {
 	BulkCreate(ctx context.Context, paredaoID ParedaoID, participantes []Participante) error
 	ListByParedao(ctx context.Context, paredaoID ParedaoID) ([]Participante, error)
+	Update(ctx context.Context, p Participante) error
 }
 
 type VotoRepository interface {
 	Registrar(ctx context.Context, voto Voto) error
+	FindByID(ctx context.Context, id VotoID) (Voto, error)
 	TotalPorParedao(ctx context.Context, id ParedaoID) (int64, error)
 	TotalPorParticipante(ctx context.Context, paredaoID ParedaoID) (map[ParticipanteID]int64, error)
+	TotalPorModalidade(ctx context.Context, paredaoID ParedaoID) (map[Modalidade]map[ParticipanteID]int64, error)
 	TotalPorHora(ctx context.Context, paredaoID ParedaoID) ([]ParcialHora, error)
+	// TotalPorIntervalo agrega por participante em intervalos de duração fixa alinhados à época Unix.
+	TotalPorIntervalo(ctx context.Context, paredaoID ParedaoID, intervalo time.Duration) ([]ParcialIntervalo, error)
+	// As variantes "Ate" consideram apenas votos registrados até o instante informado.
+	TotalPorModalidadeAte(ctx context.Context, paredaoID ParedaoID, ate time.Time) (map[Modalidade]map[ParticipanteID]int64, error)
+	TotalPorHoraAte(ctx context.Context, paredaoID ParedaoID, ate time.Time) ([]ParcialHora, error)
+	TotalPorIntervaloAte(ctx context.Context, paredaoID ParedaoID, intervalo time.Duration, ate time.Time) ([]ParcialIntervalo, error)
 }
 
 type Contador interface {
@@ -35,8 +64,109 @@ type Fila interface {
 	ConsumirVotos(ctx context.Context, handler func(context.Context, Voto) error) error
 }
 
+// JanelasVelocidade mantém contagens de votos por janela de tempo e a linha de base de cada série monitorada.
+type JanelasVelocidade interface {
+	Incrementar(ctx context.Context, serie string, janela int64) (int64, error)
+	Obter(ctx context.Context, serie string, janela int64) (int64, error)
+	// ReivindicarFechamento garante que apenas um worker avalie cada janela encerrada.
+	ReivindicarFechamento(ctx context.Context, serie string, janela int64) (bool, error)
+	ObterLinhaBase(ctx context.Context, serie string) (LinhaBase, error)
+	SalvarLinhaBase(ctx context.Context, serie string, linha LinhaBase) error
+}
+
+// StatusVotos guarda por pouco tempo o andamento dos votos enfileirados; Obter devolve ErrNotFound quando o
+// registro não existe ou já expirou.
+type StatusVotos interface {
+	Salvar(ctx context.Context, status StatusVoto) error
+	Obter(ctx context.Context, id VotoID) (StatusVoto, error)
+}
+
+// AssinadorRecibos assina e confere recibos de voto; Verificar devolve erro quando a assinatura não confere
+// ou a chave é desconhecida.
+type AssinadorRecibos interface {
+	Assinar(recibo Recibo) Recibo
+	Verificar(recibo Recibo) error
+	// AssinarMensagem assina com a chave ativa outros documentos publicados, como checkpoints e manifestos.
+	AssinarMensagem(msg []byte) (chaveID, assinatura string)
+	ChavesPublicas() []ChavePublicaRecibo
+}
+
+// Pseudonimizador troca o IP de origem por um identificador estável dentro do paredão, para que auditores
+// agrupem votos da mesma origem sem conhecer o endereço.
+type Pseudonimizador interface {
+	Pseudonimo(paredaoID ParedaoID, ip string) string
+}
+
+// CadeiaAuditoria lê a cadeia de hashes dos votos e guarda os checkpoints assinados sobre ela.
+type CadeiaAuditoria interface {
+	// PendentesDeCheckpoint devolve as pontas de cadeia que cresceram desde o último checkpoint.
+	PendentesDeCheckpoint(ctx context.Context) ([]CadeiaVotos, error)
+	// SalvarCheckpoint ignora checkpoints repetidos (mesmo paredão e tamanho), gravados por outro worker.
+	SalvarCheckpoint(ctx context.Context, checkpoint Checkpoint) error
+	Checkpoints(ctx context.Context, paredaoID ParedaoID) ([]Checkpoint, error)
+	// ExportarVotos percorre os votos do paredão em ordem de Seq sem carregá-los todos em memória; votos
+	// fora da cadeia (Seq zero) vêm primeiro.
+	ExportarVotos(ctx context.Context, paredaoID ParedaoID, emitir func(VotoAuditado) error) error
+}
+
+// ViradaRepository guarda as trocas de liderança de cada paredão, numeradas a partir de 1.
+type ViradaRepository interface {
+	// Registrar devolve false quando outra virada com a mesma ordem já foi gravada, por outro worker.
+	Registrar(ctx context.Context, virada Virada) (bool, error)
+	// Ultima devolve ErrNotFound enquanto o paredão não tiver viradas.
+	Ultima(ctx context.Context, paredaoID ParedaoID) (Virada, error)
+	Listar(ctx context.Context, paredaoID ParedaoID) ([]Virada, error)
+}
+
+// WebhookRepository guarda as assinaturas de webhook, os eventos publicados e a fila de entregas, que
+// também serve de registro das tentativas de cada assinatura.
+type WebhookRepository interface {
+	Criar(ctx context.Context, webhook Webhook) error
+	Listar(ctx context.Context) ([]Webhook, error)
+	// Desativar devolve ErrNotFound para webhook inexistente; as entregas já registradas continuam.
+	Desativar(ctx context.Context, id WebhookID) error
+	// Publicar grava o evento e uma entrega pendente para cada webhook ativo que assina o tipo. Devolve
+	// false, sem gravar nada, quando a chave do evento já foi publicada.
+	Publicar(ctx context.Context, evento EventoParedao) (bool, error)
+	// ReservarEntregas devolve até limite entregas pendentes vencidas em agora e adia a próxima tentativa
+	// delas por reserva, para que outro worker não as envie ao mesmo tempo. A ProximaTentativa devolvida é o
+	// fim da reserva, que RegistrarTentativa confere.
+	ReservarEntregas(ctx context.Context, agora time.Time, reserva time.Duration, limite int) ([]EntregaAgendada, error)
+	// RegistrarTentativa grava o resultado de uma tentativa: status, contagem, próxima tentativa e erro. Só
+	// grava se a entrega ainda estiver reservada até reservadaAte; senão devolve ErrReservaVencida.
+	RegistrarTentativa(ctx context.Context, entrega EntregaWebhook, reservadaAte time.Time) error
+	// Entregas lista as entregas do webhook, das mais recentes para as mais antigas.
+	Entregas(ctx context.Context, id WebhookID, limite int) ([]EntregaWebhook, error)
+	// Reenviar recoloca a entrega na fila com as tentativas zeradas; ErrNotFound se ela não for do webhook.
+	Reenviar(ctx context.Context, id WebhookID, entrega EntregaID, agora time.Time) (EntregaWebhook, error)
+}
+
+// AvisosParciais transporta entre processos o aviso de que os contadores de um paredão mudaram: o worker
+// publica, as instâncias da API escutam para atualizar as parciais ao vivo.
+type AvisosParciais interface {
+	Publicar(ctx context.Context, paredaoID ParedaoID) error
+	// Escutar bloqueia entregando cada aviso a receber; conectado é chamado quando a inscrição é confirmada.
+	// Retorna quando ctx termina ou a conexão cai.
+	Escutar(ctx context.Context, conectado func(), receber func(ParedaoID)) error
+}
+
+// ReservaVotoUnico marca no caminho quente que a conta já votou no paredão, antes de o voto chegar à fila.
+type ReservaVotoUnico interface {
+	// Reservar devolve false quando a conta já tinha reserva para o paredão.
+	Reservar(ctx context.Context, paredaoID ParedaoID, eleitorID EleitorID, validade time.Duration) (bool, error)
+	Liberar(ctx context.Context, paredaoID ParedaoID, eleitorID EleitorID) error
+}
+
+// Antifraude decide se o voto pode seguir; a decisão acompanha inclusive o erro de limite excedido.
 type Antifraude interface {
-	Validar(ctx context.Context, voto Voto) error
+	Validar(ctx context.Context, voto Voto) (DecisaoLimite, error)
+}
+
+// PoliticasAntifraude resolve a política efetiva (padrão global + ajustes do paredão) aplicada a cada voto.
+type PoliticasAntifraude interface {
+	Resolver(ctx context.Context, paredaoID ParedaoID) (PoliticaAntifraude, error)
+	// Invalidar descarta o que estiver em cache para o paredão, para que uma edição valha já na próxima leitura.
+	Invalidar(paredaoID ParedaoID)
 }
 
 type Clock interface {
@@ -44,9 +174,51 @@ type Clock interface {
 }
 
 type VotingService interface {
-	RegistrarVoto(ctx context.Context, voto Voto) error
+	RegistrarVoto(ctx context.Context, voto Voto) (ResultadoVoto, error)
+	StatusVoto(ctx context.Context, id VotoID) (StatusVoto, error)
+	VerificarRecibo(ctx context.Context, recibo Recibo) (VerificacaoRecibo, error)
+	ChavesRecibo() []ChavePublicaRecibo
+	Checkpoints(ctx context.Context, id ParedaoID) ([]Checkpoint, error)
 	ListarAtivos(ctx context.Context) ([]Paredao, error)
+	ObterParedao(ctx context.Context, id ParedaoID) (Paredao, error)
 	Parciais(ctx context.Context, id ParedaoID) ([]Parcial, error)
 	TotaisPorHora(ctx context.Context, id ParedaoID) ([]ParcialHora, error)
+	// As variantes públicas respeitam a política de visibilidade do paredão; ocultas devolvem só a Publicacao.
+	ParciaisPublicas(ctx context.Context, id ParedaoID) ([]Parcial, Publicacao, error)
+	TotaisPorHoraPublicos(ctx context.Context, id ParedaoID) ([]ParcialHora, Publicacao, error)
+	SeriePublica(ctx context.Context, id ParedaoID, intervalo time.Duration) ([]SerieParticipante, Publicacao, error)
+	ViradasPublicas(ctx context.Context, id ParedaoID) ([]Virada, Publicacao, error)
 	CriarParedao(ctx context.Context, paredao Paredao, participantes []Participante) (Paredao, error)
 }
+
+// AdminService reúne operações administrativas que não fazem parte do fluxo público de votação.
+type AdminService interface {
+	ObterPoliticaAntifraude(ctx context.Context, id ParedaoID) (PoliticaAntifraude, error)
+	AtualizarPoliticaAntifraude(ctx context.Context, id ParedaoID, politica PoliticaAntifraude) (PoliticaAntifraude, error)
+	ObterVisibilidade(ctx context.Context, id ParedaoID) (PoliticaVisibilidade, error)
+	AtualizarVisibilidade(ctx context.Context, id ParedaoID, politica PoliticaVisibilidade) (PoliticaVisibilidade, error)
+	// Limiares da detecção de anomalias; zero herda o padrão global do worker.
+	ObterLimiaresAnomalia(ctx context.Context, id ParedaoID) (LimiaresAnomalia, error)
+	AtualizarLimiaresAnomalia(ctx context.Context, id ParedaoID, limiares LimiaresAnomalia) (LimiaresAnomalia, error)
+	CriarEtapas(ctx context.Context, etapas []Paredao, participantes []Participante) ([]Paredao, error)
+	AtualizarVotacao(ctx context.Context, id ParedaoID, modo ModoVotacao, pesos *PesosModalidade, polaridade Polaridade) (Paredao, error)
+	// RetirarParticipante tira o participante de um paredão aberto aplicando a política aos votos dele.
+	RetirarParticipante(ctx context.Context, id ParedaoID, participanteID ParticipanteID, politica PoliticaRetirada) (Paredao, error)
+	Finalizar(ctx context.Context, id ParedaoID) (Resultado, error)
+	// Desempatar abre a etapa seguinte de uma etapa finalizada com DesempatePendente, com quem escapa escolhido pela produção.
+	Desempatar(ctx context.Context, id ParedaoID, escapou ParticipanteID) (Resultado, error)
+	// Checkpoints e ExportarVotos alimentam a exportação de auditoria, restrita ao admin por expor os votos.
+	Checkpoints(ctx context.Context, id ParedaoID) ([]Checkpoint, error)
+	ExportarVotos(ctx context.Context, id ParedaoID, emitir func(VotoAuditado) error) error
+	// PacoteAuditoria e AssinarMensagem montam o pacote assinado de um paredão encerrado.
+	PacoteAuditoria(ctx context.Context, id ParedaoID) (PacoteAuditoria, error)
+	AssinarMensagem(msg []byte) (chaveID, assinatura string)
+	// ProjecaoFinal estima o resultado ao fim da votação a partir da série no intervalo informado.
+	ProjecaoFinal(ctx context.Context, id ParedaoID, intervalo time.Duration) (Projecao, error)
+	// Webhooks de eventos do paredão: cadastro, registro de entregas e reenvio.
+	CriarWebhook(ctx context.Context, webhook Webhook) (Webhook, error)
+	ListarWebhooks(ctx context.Context) ([]Webhook, error)
+	DesativarWebhook(ctx context.Context, id WebhookID) error
+	EntregasWebhook(ctx context.Context, id WebhookID, limite int) ([]EntregaWebhook, error)
+	ReenviarEntrega(ctx context.Context, id WebhookID, entrega EntregaID) (EntregaWebhook, error)
+}
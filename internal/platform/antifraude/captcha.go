@@ -0,0 +1,82 @@
+package antifraude
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrCaptchaInvalido = fmt.Errorf("captcha invalido")
+
+// VerificadorCaptcha confirma com o provedor que o token enviado pelo eleitor é válido.
+type VerificadorCaptcha interface {
+	Verificar(ctx context.Context, token, ip string) error
+}
+
+// SiteVerify fala o protocolo "siteverify" comum a reCAPTCHA, hCaptcha e Turnstile.
+type SiteVerify struct {
+	url    string
+	secret string
+	http   *http.Client
+}
+
+func NewSiteVerify(endpoint, secret string, timeout time.Duration) *SiteVerify {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	return &SiteVerify{
+		url:    endpoint,
+		secret: secret,
+		http:   &http.Client{Timeout: timeout},
+	}
+}
+
+type siteVerifyResposta struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+func (s *SiteVerify) Verificar(ctx context.Context, token, ip string) error {
+	if strings.TrimSpace(token) == "" {
+		return ErrCaptchaInvalido
+	}
+
+	form := url.Values{
+		"secret":   {s.secret},
+		"response": {token},
+	}
+	if ip != "" {
+		form.Set("remoteip", ip)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("antifraude: montar verificacao de captcha: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("antifraude: verificar captcha: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("antifraude: verificar captcha: status inesperado %d", resp.StatusCode)
+	}
+
+	var corpo siteVerifyResposta
+	if err := json.NewDecoder(resp.Body).Decode(&corpo); err != nil {
+		return fmt.Errorf("antifraude: resposta de captcha invalida: %w", err)
+	}
+	if !corpo.Success {
+		return fmt.Errorf("%w: %s", ErrCaptchaInvalido, strings.Join(corpo.ErrorCodes, ","))
+	}
+	return nil
+}
+
+var _ VerificadorCaptcha = (*SiteVerify)(nil)
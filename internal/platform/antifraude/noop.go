@@ -13,7 +13,7 @@ func NewNoop() Noop {
 	return Noop{}
 }
 
-func (Noop) Validar(ctx context.Context, voto domain.Voto) error {
+func (Noop) Validar(ctx context.Context, voto domain.Voto) (domain.DecisaoLimite, error) {
 	// Implementação vazia usada quando o rate limit é desligado via config.
-	return nil
+	return domain.DecisaoLimite{}, nil
 }
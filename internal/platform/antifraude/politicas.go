@@ -0,0 +1,97 @@
+package antifraude
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// ResolvedorPoliticas combina o padrão global com a política gravada no paredão e mantém o resultado em cache local.
+// Alterações feitas pelo admin valem na hora na instância que as recebeu e nas demais após no máximo um TTL.
+type ResolvedorPoliticas struct {
+	paredoes domain.ParedaoRepository
+	padrao   domain.PoliticaAntifraude
+	ttl      time.Duration
+	agora    func() time.Time
+
+	mu    sync.Mutex
+	cache map[domain.ParedaoID]politicaEmCache
+}
+
+type politicaEmCache struct {
+	politica domain.PoliticaAntifraude
+	expira   time.Time
+}
+
+func NewResolvedorPoliticas(paredoes domain.ParedaoRepository, padrao domain.PoliticaAntifraude, ttl time.Duration) *ResolvedorPoliticas {
+	if padrao.Algoritmo == "" {
+		padrao.Algoritmo = domain.AlgoritmoJanelaFixa
+	}
+	if padrao.EscopoBloqueio == "" {
+		padrao.EscopoBloqueio = domain.EscopoParedao
+	}
+	return &ResolvedorPoliticas{
+		paredoes: paredoes,
+		padrao:   padrao,
+		ttl:      ttl,
+		agora:    time.Now,
+		cache:    make(map[domain.ParedaoID]politicaEmCache),
+	}
+}
+
+func (r *ResolvedorPoliticas) Resolver(ctx context.Context, paredaoID domain.ParedaoID) (domain.PoliticaAntifraude, error) {
+	agora := r.agora()
+
+	r.mu.Lock()
+	entrada, ok := r.cache[paredaoID]
+	r.mu.Unlock()
+	if ok && agora.Before(entrada.expira) {
+		return entrada.politica, nil
+	}
+
+	paredao, err := r.paredoes.FindByID(ctx, paredaoID)
+	if err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return domain.PoliticaAntifraude{}, fmt.Errorf("antifraude: carregar politica do paredao: %w", err)
+	}
+	// Paredão inexistente segue com o padrão; o serviço de votação é quem rejeita o voto nesse caso.
+	politica := MesclarPolitica(r.padrao, paredao.Antifraude)
+
+	if r.ttl > 0 {
+		r.mu.Lock()
+		r.cache[paredaoID] = politicaEmCache{politica: politica, expira: agora.Add(r.ttl)}
+		r.mu.Unlock()
+	}
+	return politica, nil
+}
+
+// Invalidar descarta a política em cache de um paredão; o serviço chama logo após a edição pelo admin.
+func (r *ResolvedorPoliticas) Invalidar(paredaoID domain.ParedaoID) {
+	r.mu.Lock()
+	delete(r.cache, paredaoID)
+	r.mu.Unlock()
+}
+
+// MesclarPolitica aplica os campos preenchidos do paredão sobre o padrão global.
+func MesclarPolitica(padrao, paredao domain.PoliticaAntifraude) domain.PoliticaAntifraude {
+	efetiva := padrao
+	if paredao.Limite > 0 {
+		efetiva.Limite = paredao.Limite
+	}
+	if paredao.JanelaSegundos > 0 {
+		efetiva.JanelaSegundos = paredao.JanelaSegundos
+	}
+	if paredao.Algoritmo != "" {
+		efetiva.Algoritmo = paredao.Algoritmo
+	}
+	if paredao.EscopoBloqueio != "" {
+		efetiva.EscopoBloqueio = paredao.EscopoBloqueio
+	}
+	efetiva.ExigeCaptcha = padrao.ExigeCaptcha || paredao.ExigeCaptcha
+	return efetiva
+}
+
+var _ domain.PoliticasAntifraude = (*ResolvedorPoliticas)(nil)
@@ -0,0 +1,108 @@
+package antifraude
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+func TestResolvedorPoliticasMesclaPadraoEUsaCache(t *testing.T) {
+	repo := &paredaoRepoContador{paredao: domain.Paredao{
+		ID:         "paredao-1",
+		Antifraude: domain.PoliticaAntifraude{Limite: 5, Algoritmo: domain.AlgoritmoJanelaDeslizante},
+	}}
+	padrao := domain.PoliticaAntifraude{Limite: 30, JanelaSegundos: 60}
+	resolvedor := NewResolvedorPoliticas(repo, padrao, time.Minute)
+	agora := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	resolvedor.agora = func() time.Time { return agora }
+
+	ctx := context.Background()
+	politica, err := resolvedor.Resolver(ctx, "paredao-1")
+	if err != nil {
+		t.Fatalf("resolver retornou erro: %v", err)
+	}
+	esperada := domain.PoliticaAntifraude{
+		Limite:         5,
+		JanelaSegundos: 60,
+		Algoritmo:      domain.AlgoritmoJanelaDeslizante,
+		EscopoBloqueio: domain.EscopoParedao,
+	}
+	if politica != esperada {
+		t.Fatalf("politica efetiva inesperada: %+v", politica)
+	}
+
+	_, _ = resolvedor.Resolver(ctx, "paredao-1")
+	if repo.buscas != 1 {
+		t.Fatalf("segunda resolução deveria vir do cache, buscas=%d", repo.buscas)
+	}
+
+	agora = agora.Add(2 * time.Minute)
+	_, _ = resolvedor.Resolver(ctx, "paredao-1")
+	if repo.buscas != 2 {
+		t.Fatalf("cache expirado deveria recarregar o paredão, buscas=%d", repo.buscas)
+	}
+}
+
+func TestMesclarPoliticaCaptchaValeSeQualquerLadoExigir(t *testing.T) {
+	efetiva := MesclarPolitica(domain.PoliticaAntifraude{ExigeCaptcha: true}, domain.PoliticaAntifraude{})
+	if !efetiva.ExigeCaptcha {
+		t.Fatal("captcha global deveria continuar valendo sem ajuste no paredão")
+	}
+	efetiva = MesclarPolitica(domain.PoliticaAntifraude{}, domain.PoliticaAntifraude{ExigeCaptcha: true})
+	if !efetiva.ExigeCaptcha {
+		t.Fatal("captcha do paredão deveria valer mesmo com padrão desligado")
+	}
+}
+
+type paredaoRepoContador struct {
+	paredao domain.Paredao
+	buscas  int
+}
+
+func (r *paredaoRepoContador) Create(context.Context, domain.Paredao) error { return nil }
+
+func (r *paredaoRepoContador) Update(context.Context, domain.Paredao) error { return nil }
+
+func (r *paredaoRepoContador) CreateEtapas(context.Context, []domain.Paredao) error { return nil }
+
+func (r *paredaoRepoContador) AbrirEtapa(context.Context, domain.Paredao, []domain.Participante) error {
+	return nil
+}
+
+func (r *paredaoRepoContador) AtualizarAntifraude(context.Context, domain.ParedaoID, domain.PoliticaAntifraude, time.Time) error {
+	return nil
+}
+
+func (r *paredaoRepoContador) AtualizarVisibilidade(context.Context, domain.ParedaoID, domain.PoliticaVisibilidade, time.Time) error {
+	return nil
+}
+
+func (r *paredaoRepoContador) AtualizarVotacao(context.Context, domain.ParedaoID, domain.ModoVotacao, domain.PesosModalidade, domain.Polaridade, time.Time) error {
+	return nil
+}
+
+func (r *paredaoRepoContador) AtualizarLimiares(context.Context, domain.ParedaoID, domain.LimiaresAnomalia, time.Time) error {
+	return nil
+}
+
+func (r *paredaoRepoContador) FindByID(_ context.Context, id domain.ParedaoID) (domain.Paredao, error) {
+	r.buscas++
+	if id != r.paredao.ID {
+		return domain.Paredao{}, domain.ErrNotFound
+	}
+	return r.paredao, nil
+}
+
+func (r *paredaoRepoContador) ListByGrupo(context.Context, domain.ParedaoID) ([]domain.Paredao, error) {
+	return nil, nil
+}
+
+func (r *paredaoRepoContador) ListComMarco(context.Context, time.Time, time.Time) ([]domain.Paredao, error) {
+	return nil, nil
+}
+
+func (r *paredaoRepoContador) ListAtivos(context.Context) ([]domain.Paredao, error) {
+	return []domain.Paredao{r.paredao}, nil
+}
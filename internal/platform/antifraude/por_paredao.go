@@ -0,0 +1,53 @@
+package antifraude
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// PorParedao resolve a política efetiva do paredão a cada voto e aplica rate limit e CAPTCHA conforme ela.
+type PorParedao struct {
+	politicas domain.PoliticasAntifraude
+	limiter   *RedisRateLimiter
+	captcha   VerificadorCaptcha
+}
+
+// NewPorParedao aceita captcha nil; nesse caso paredões que exigem CAPTCHA recusam os votos.
+func NewPorParedao(politicas domain.PoliticasAntifraude, limiter *RedisRateLimiter, captcha VerificadorCaptcha) *PorParedao {
+	return &PorParedao{
+		politicas: politicas,
+		limiter:   limiter,
+		captcha:   captcha,
+	}
+}
+
+func (p *PorParedao) Validar(ctx context.Context, voto domain.Voto) (domain.DecisaoLimite, error) {
+	politica, err := p.politicas.Resolver(ctx, voto.ParedaoID)
+	if err != nil {
+		return domain.DecisaoLimite{}, err
+	}
+
+	// O rate limit vem antes do CAPTCHA para que rajadas não virem chamadas ao provedor externo.
+	var decisao domain.DecisaoLimite
+	if p.limiter != nil {
+		decisao, err = p.limiter.Aplicar(ctx, voto, politica)
+		if err != nil {
+			return decisao, err
+		}
+	}
+
+	if politica.ExigeCaptcha {
+		if p.captcha == nil {
+			return decisao, fmt.Errorf("antifraude: paredao %s exige captcha mas nenhum verificador foi configurado", voto.ParedaoID)
+		}
+		if err := p.captcha.Verificar(ctx, voto.CaptchaToken, voto.OrigemIP); err != nil {
+			return decisao, err
+		}
+	}
+
+	return decisao, nil
+}
+
+var _ domain.Antifraude = (*PorParedao)(nil)
@@ -0,0 +1,70 @@
+package antifraude
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	miniredis "github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+func TestPorParedaoExigeCaptchaQuandoPoliticaPede(t *testing.T) {
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	provedor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostFormValue("response") == "token-bom" && r.PostFormValue("secret") == "segredo" {
+			_, _ = w.Write([]byte(`{"success":true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+	}))
+	defer provedor.Close()
+
+	repo := &paredaoRepoContador{paredao: domain.Paredao{
+		ID:         "paredao-1",
+		Antifraude: domain.PoliticaAntifraude{ExigeCaptcha: true},
+	}}
+	politicas := NewResolvedorPoliticas(repo, domain.PoliticaAntifraude{Limite: 10, JanelaSegundos: 60}, time.Minute)
+	limiter := NewRedisRateLimiter(redis.NewClient(&redis.Options{Addr: mr.Addr()}), 10, time.Minute, "rl")
+	af := NewPorParedao(politicas, limiter, NewSiteVerify(provedor.URL, "segredo", time.Second))
+
+	ctx := context.Background()
+	voto := domain.Voto{ParedaoID: "paredao-1", OrigemIP: "200.6.6.6", UserAgent: "ua"}
+
+	if _, err := af.Validar(ctx, voto); !errors.Is(err, ErrCaptchaInvalido) {
+		t.Fatalf("voto sem token deveria falhar no captcha, recebeu: %v", err)
+	}
+	voto.CaptchaToken = "token-ruim"
+	if _, err := af.Validar(ctx, voto); !errors.Is(err, ErrCaptchaInvalido) {
+		t.Fatalf("token recusado pelo provedor deveria falhar, recebeu: %v", err)
+	}
+	voto.CaptchaToken = "token-bom"
+	decisao, err := af.Validar(ctx, voto)
+	if err != nil {
+		t.Fatalf("token válido deveria passar: %v", err)
+	}
+	if decisao.Limite != 10 {
+		t.Fatalf("decisão deveria refletir o limite da política, veio %+v", decisao)
+	}
+}
+
+func TestPorParedaoSemVerificadorRecusaParedaoComCaptcha(t *testing.T) {
+	repo := &paredaoRepoContador{paredao: domain.Paredao{
+		ID:         "paredao-1",
+		Antifraude: domain.PoliticaAntifraude{ExigeCaptcha: true},
+	}}
+	af := NewPorParedao(NewResolvedorPoliticas(repo, domain.PoliticaAntifraude{}, time.Minute), nil, nil)
+
+	if _, err := af.Validar(context.Background(), domain.Voto{ParedaoID: "paredao-1", CaptchaToken: "x"}); err == nil {
+		t.Fatal("sem verificador configurado o voto deveria ser recusado")
+	}
+}
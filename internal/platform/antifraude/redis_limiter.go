@@ -6,6 +6,8 @@ import (
 	"crypto/sha1"
 	"encoding/hex"
 	"fmt"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -15,12 +17,14 @@ import (
 
 var ErrRateLimitExceeded = fmt.Errorf("limite de votos atingido")
 
-// RedisRateLimiter limita votos por IP/UA em janelas fixas usando Redis.
+// RedisRateLimiter limita votos por IP/UA usando Redis, em janela fixa (INCR) ou deslizante (ZSET).
 type RedisRateLimiter struct {
 	client    *redis.Client
 	limit     int
 	window    time.Duration
 	keyPrefix string
+	agora     func() time.Time
+	seq       atomic.Uint64
 }
 
 func NewRedisRateLimiter(client *redis.Client, limit int, window time.Duration, prefix string) *RedisRateLimiter {
@@ -32,37 +36,118 @@ func NewRedisRateLimiter(client *redis.Client, limit int, window time.Duration,
 		limit:     limit,
 		window:    window,
 		keyPrefix: prefix,
+		agora:     time.Now,
 	}
 }
 
-func (r *RedisRateLimiter) Validar(ctx context.Context, voto domain.Voto) error {
-	if r.client == nil || r.limit <= 0 || r.window <= 0 {
+// Validar aplica os parâmetros globais do limiter; políticas por paredão passam por Aplicar.
+func (r *RedisRateLimiter) Validar(ctx context.Context, voto domain.Voto) (domain.DecisaoLimite, error) {
+	return r.Aplicar(ctx, voto, domain.PoliticaAntifraude{
+		Limite:         r.limit,
+		JanelaSegundos: int(r.window / time.Second),
+		Algoritmo:      domain.AlgoritmoJanelaFixa,
+		EscopoBloqueio: domain.EscopoParedao,
+	})
+}
+
+// Aplicar conta o voto segundo a política informada e devolve a cota restante.
+func (r *RedisRateLimiter) Aplicar(ctx context.Context, voto domain.Voto, politica domain.PoliticaAntifraude) (domain.DecisaoLimite, error) {
+	if r.client == nil || politica.Limite <= 0 || politica.Janela() <= 0 {
 		// Configurações inválidas caem automaticamente no modo permissivo.
-		return nil
+		return domain.DecisaoLimite{}, nil
+	}
+
+	if politica.Algoritmo == domain.AlgoritmoJanelaDeslizante {
+		return r.janelaDeslizante(ctx, voto, politica)
 	}
+	return r.janelaFixa(ctx, voto, politica)
+}
+
+func (r *RedisRateLimiter) janelaFixa(ctx context.Context, voto domain.Voto, politica domain.PoliticaAntifraude) (domain.DecisaoLimite, error) {
+	key := r.buildKey(voto, politica.EscopoBloqueio)
+	window := politica.Janela()
 
-	key := r.buildKey(voto)
-	count, err := r.client.Incr(ctx, key).Result()
-	if err != nil {
-		return fmt.Errorf("antifraude: falha ao incrementar chave: %w", err)
+	// INCR e PTTL no mesmo round-trip: o TTL restante da chave é o instante em que a janela reinicia.
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pttl := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return domain.DecisaoLimite{}, fmt.Errorf("antifraude: falha ao incrementar chave: %w", err)
 	}
+	count := incr.Val()
 
-	if count == 1 {
-		if err := r.client.Expire(ctx, key, r.window).Err(); err != nil {
-			return fmt.Errorf("antifraude: falha ao definir expiracao: %w", err)
+	ttl := pttl.Val()
+	if count == 1 || ttl < 0 {
+		if err := r.client.Expire(ctx, key, window).Err(); err != nil {
+			return domain.DecisaoLimite{}, fmt.Errorf("antifraude: falha ao definir expiracao: %w", err)
 		}
+		ttl = window
 	}
 
-	if int(count) > r.limit {
-		return ErrRateLimitExceeded
+	decisao := domain.DecisaoLimite{
+		Limite:   politica.Limite,
+		Restante: max(politica.Limite-int(count), 0),
+		Reset:    r.agora().Add(ttl),
 	}
 
-	return nil
+	if int(count) > politica.Limite {
+		return decisao, ErrRateLimitExceeded
+	}
+
+	return decisao, nil
 }
 
-func (r *RedisRateLimiter) buildKey(voto domain.Voto) string {
+// janelaDeslizante guarda o instante de cada voto aceito num ZSET e conta apenas os que ainda estão dentro da janela.
+func (r *RedisRateLimiter) janelaDeslizante(ctx context.Context, voto domain.Voto, politica domain.PoliticaAntifraude) (domain.DecisaoLimite, error) {
+	key := r.buildKey(voto, politica.EscopoBloqueio) + ":deslizante"
+	window := politica.Janela()
+	agora := r.agora()
+	membro := strconv.FormatInt(agora.UnixNano(), 10) + "-" + strconv.FormatUint(r.seq.Add(1), 10)
+
+	pipe := r.client.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(agora.Add(-window).UnixMilli(), 10))
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(agora.UnixMilli()), Member: membro})
+	card := pipe.ZCard(ctx, key)
+	maisAntigo := pipe.ZRangeWithScores(ctx, key, 0, 0)
+	pipe.PExpire(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return domain.DecisaoLimite{}, fmt.Errorf("antifraude: falha ao registrar na janela deslizante: %w", err)
+	}
+	count := card.Val()
+
+	excedeu := int(count) > politica.Limite
+	if excedeu {
+		// Votos barrados não ocupam a janela; do contrário um cliente insistente nunca sairia do bloqueio.
+		if err := r.client.ZRem(ctx, key, membro).Err(); err != nil {
+			return domain.DecisaoLimite{}, fmt.Errorf("antifraude: falha ao descartar voto barrado: %w", err)
+		}
+		count--
+	}
+
+	// A próxima vaga abre quando o voto mais antigo da janela expira.
+	reset := agora.Add(window)
+	if z := maisAntigo.Val(); len(z) > 0 {
+		reset = time.UnixMilli(int64(z[0].Score)).Add(window)
+	}
+
+	decisao := domain.DecisaoLimite{
+		Limite:   politica.Limite,
+		Restante: max(politica.Limite-int(count), 0),
+		Reset:    reset,
+	}
+	if excedeu {
+		return decisao, ErrRateLimitExceeded
+	}
+	return decisao, nil
+}
+
+func (r *RedisRateLimiter) buildKey(voto domain.Voto, escopo domain.EscopoBloqueio) string {
 	// Hash SHA-1 evita expor IP/UA diretamente no Redis e mantém o prefixo limpo.
 	base := fmt.Sprintf("%s|%s|%s", voto.ParedaoID, voto.OrigemIP, voto.UserAgent)
+	if escopo == domain.EscopoGlobal {
+		// No escopo global o mesmo eleitor divide a cota entre todos os paredões.
+		base = fmt.Sprintf("global|%s|%s", voto.OrigemIP, voto.UserAgent)
+	}
 	hash := sha1.Sum([]byte(base))
 	return fmt.Sprintf("%s:%s", r.keyPrefix, hex.EncodeToString(hash[:]))
 }
@@ -27,18 +27,18 @@ func TestRedisRateLimiterRespectsLimit(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	if err := limiter.Validar(ctx, voto); err != nil {
+	if _, err := limiter.Validar(ctx, voto); err != nil {
 		t.Fatalf("primeiro voto deveria ser aceito, erro: %v", err)
 	}
-	if err := limiter.Validar(ctx, voto); err != nil {
+	if _, err := limiter.Validar(ctx, voto); err != nil {
 		t.Fatalf("segundo voto deveria ser aceito, erro: %v", err)
 	}
 
-	if err := limiter.Validar(ctx, voto); !errors.Is(err, ErrRateLimitExceeded) {
+	if _, err := limiter.Validar(ctx, voto); !errors.Is(err, ErrRateLimitExceeded) {
 		t.Fatalf("terceiro voto deveria ser bloqueado, recebeu: %v", err)
 	}
 
-	key := limiter.buildKey(voto)
+	key := limiter.buildKey(voto, domain.EscopoParedao)
 	if ttl := mr.TTL(key); ttl <= 0 {
 		t.Fatalf("esperava TTL positivo para %s, veio %v", key, ttl)
 	}
@@ -60,16 +60,104 @@ func TestRedisRateLimiterResetsAfterWindow(t *testing.T) {
 	}
 
 	ctx := context.Background()
-	if err := limiter.Validar(ctx, voto); err != nil {
+	if _, err := limiter.Validar(ctx, voto); err != nil {
 		t.Fatalf("voto inicial deveria ser aceito: %v", err)
 	}
-	if err := limiter.Validar(ctx, voto); !errors.Is(err, ErrRateLimitExceeded) {
+	if _, err := limiter.Validar(ctx, voto); !errors.Is(err, ErrRateLimitExceeded) {
 		t.Fatalf("segundo voto antes da janela deveria falhar: %v", err)
 	}
 
 	mr.FastForward(window + time.Second)
 
-	if err := limiter.Validar(ctx, voto); err != nil {
+	if _, err := limiter.Validar(ctx, voto); err != nil {
 		t.Fatalf("apos expirar janela, voto deveria ser aceito: %v", err)
 	}
 }
+
+func TestRedisRateLimiterReportaCotaRestante(t *testing.T) {
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	limiter := NewRedisRateLimiter(client, 2, time.Minute, "rl")
+	agora := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	limiter.agora = func() time.Time { return agora }
+
+	voto := domain.Voto{ParedaoID: "paredao-3", OrigemIP: "200.3.3.3", UserAgent: "ua"}
+
+	ctx := context.Background()
+	decisao, err := limiter.Validar(ctx, voto)
+	if err != nil {
+		t.Fatalf("primeiro voto deveria ser aceito: %v", err)
+	}
+	if decisao.Limite != 2 || decisao.Restante != 1 {
+		t.Fatalf("esperava limite 2 e restante 1, veio %+v", decisao)
+	}
+	if !decisao.Reset.Equal(agora.Add(time.Minute)) {
+		t.Fatalf("reset deveria coincidir com o fim da janela, veio %v", decisao.Reset)
+	}
+
+	_, _ = limiter.Validar(ctx, voto)
+	decisao, err = limiter.Validar(ctx, voto)
+	if !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("terceiro voto deveria ser bloqueado, recebeu: %v", err)
+	}
+	if decisao.Restante != 0 || decisao.Reset.IsZero() {
+		t.Fatalf("bloqueio deveria informar cota zerada e reset, veio %+v", decisao)
+	}
+}
+
+func TestRedisRateLimiterJanelaDeslizanteLiberaConformeVotosAntigosSaem(t *testing.T) {
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	limiter := NewRedisRateLimiter(client, 0, 0, "rl")
+	agora := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	limiter.agora = func() time.Time { return agora }
+
+	politica := domain.PoliticaAntifraude{Limite: 2, JanelaSegundos: 60, Algoritmo: domain.AlgoritmoJanelaDeslizante}
+	voto := domain.Voto{ParedaoID: "paredao-4", OrigemIP: "200.4.4.4", UserAgent: "ua"}
+	ctx := context.Background()
+
+	if _, err := limiter.Aplicar(ctx, voto, politica); err != nil {
+		t.Fatalf("primeiro voto deveria ser aceito: %v", err)
+	}
+	agora = agora.Add(40 * time.Second)
+	if _, err := limiter.Aplicar(ctx, voto, politica); err != nil {
+		t.Fatalf("segundo voto deveria ser aceito: %v", err)
+	}
+	decisao, err := limiter.Aplicar(ctx, voto, politica)
+	if !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("terceiro voto deveria ser bloqueado, recebeu: %v", err)
+	}
+	// A vaga abre quando o primeiro voto (t=0) deixa a janela, em t=60s.
+	if esperado := agora.Add(20 * time.Second); !decisao.Reset.Equal(esperado) {
+		t.Fatalf("reset deveria ser %v, veio %v", esperado, decisao.Reset)
+	}
+
+	// Numa janela fixa iniciada em t=0 ainda estaríamos bloqueados; na deslizante o primeiro voto já saiu.
+	agora = agora.Add(21 * time.Second)
+	if _, err := limiter.Aplicar(ctx, voto, politica); err != nil {
+		t.Fatalf("voto após o primeiro sair da janela deveria ser aceito: %v", err)
+	}
+}
+
+func TestRedisRateLimiterEscopoGlobalCompartilhaCotaEntreParedoes(t *testing.T) {
+	mr := miniredis.RunT(t)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	limiter := NewRedisRateLimiter(client, 0, 0, "rl")
+	politica := domain.PoliticaAntifraude{Limite: 1, JanelaSegundos: 60, EscopoBloqueio: domain.EscopoGlobal}
+
+	ctx := context.Background()
+	voto := domain.Voto{ParedaoID: "paredao-a", OrigemIP: "200.5.5.5", UserAgent: "ua"}
+	if _, err := limiter.Aplicar(ctx, voto, politica); err != nil {
+		t.Fatalf("primeiro voto deveria ser aceito: %v", err)
+	}
+	voto.ParedaoID = "paredao-b"
+	if _, err := limiter.Aplicar(ctx, voto, politica); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("escopo global deveria bloquear em outro paredão, recebeu: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+// Pacote auditoria define como cada voto gravado entra na cadeia de hashes do paredão e como as raízes de
+// Merkle dos checkpoints são calculadas, de modo que a gravação, a exportação e o verificador externo
+// usem exatamente as mesmas regras.
+package auditoria
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// Hash é um SHA-256; a cadeia e a árvore guardam e publicam hashes em hexadecimal.
+type Hash [sha256.Size]byte
+
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// LerHash interpreta um hash em hexadecimal; vazio vale o hash zero que abre toda cadeia.
+func LerHash(s string) (Hash, error) {
+	var h Hash
+	if s == "" {
+		return h, nil
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != len(h) {
+		return h, fmt.Errorf("auditoria: hash %q invalido", s)
+	}
+	copy(h[:], b)
+	return h, nil
+}
+
+// Prefixos de domínio do RFC 6962: impedem que uma folha se passe por nó interno e vice-versa.
+const (
+	prefixoFolha = 0x00
+	prefixoNo    = 0x01
+)
+
+// versaoVoto abre a serialização canônica; mudar os campos cobertos exige uma nova versão.
+const versaoVoto = "bbb-voto-v1"
+
+// Instante normaliza o horário do voto como ele é gravado e hasheado: UTC com precisão de microssegundos,
+// que é o que o Postgres preserva.
+func Instante(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// Folha é o hash de um voto. Cobre só o que entra na apuração (identificador, paredão, participante,
+// modalidade e horário); IP, user agent e eleitor ficam de fora para que a exportação não os exija.
+func Folha(v domain.VotoAuditado) Hash {
+	canonico := strings.Join([]string{
+		versaoVoto,
+		string(v.VotoID),
+		string(v.ParedaoID),
+		string(v.ParticipanteID),
+		string(v.Modalidade),
+		Instante(v.CriadoEm).Format(time.RFC3339Nano),
+	}, "\n")
+	return sha256.Sum256(append([]byte{prefixoFolha}, canonico...))
+}
+
+// Encadear liga a folha ao elo anterior: elo = SHA-256(anterior || folha).
+func Encadear(anterior, folha Hash) Hash {
+	return sha256.Sum256(append(anterior[:], folha[:]...))
+}
+
+func no(esquerda, direita Hash) Hash {
+	buf := make([]byte, 0, 1+2*len(esquerda))
+	buf = append(buf, prefixoNo)
+	buf = append(buf, esquerda[:]...)
+	buf = append(buf, direita[:]...)
+	return sha256.Sum256(buf)
+}
+
+// RaizMerkle calcula a raiz RFC 6962 de uma lista de folhas; serve de referência para a Cadeia incremental.
+func RaizMerkle(folhas []Hash) Hash {
+	switch len(folhas) {
+	case 0:
+		return sha256.Sum256(nil)
+	case 1:
+		return folhas[0]
+	}
+	k := 1
+	for k*2 < len(folhas) {
+		k *= 2
+	}
+	return no(RaizMerkle(folhas[:k]), RaizMerkle(folhas[k:]))
+}
+
+// Cadeia é o estado da cadeia de um paredão depois de Tamanho votos: o último elo e a fronteira da árvore
+// de Merkle, isto é, as raízes das subárvores completas da maior para a menor. A fronteira tem no máximo
+// log2(Tamanho) hashes e basta para anexar folhas e calcular a raiz sem reler os votos.
+type Cadeia struct {
+	Tamanho   int64
+	Elo       Hash
+	Fronteira []Hash
+}
+
+// Anexar acrescenta a folha e devolve a posição (a partir de 1) e o novo elo.
+func (c *Cadeia) Anexar(folha Hash) (int64, Hash) {
+	c.Elo = Encadear(c.Elo, folha)
+	atual := folha
+	// Cada bit 1 no fim do tamanho é uma subárvore do mesmo tamanho esperando par.
+	for n := c.Tamanho; n&1 == 1; n >>= 1 {
+		ultimo := len(c.Fronteira) - 1
+		atual = no(c.Fronteira[ultimo], atual)
+		c.Fronteira = c.Fronteira[:ultimo]
+	}
+	c.Fronteira = append(c.Fronteira, atual)
+	c.Tamanho++
+	return c.Tamanho, c.Elo
+}
+
+// Raiz é a raiz de Merkle das folhas anexadas até agora.
+func (c *Cadeia) Raiz() Hash {
+	if len(c.Fronteira) == 0 {
+		return sha256.Sum256(nil)
+	}
+	raiz := c.Fronteira[len(c.Fronteira)-1]
+	for i := len(c.Fronteira) - 2; i >= 0; i-- {
+		raiz = no(c.Fronteira[i], raiz)
+	}
+	return raiz
+}
+
+// Restaurar reconstrói a Cadeia a partir da ponta gravada no banco.
+func Restaurar(ponta domain.CadeiaVotos) (Cadeia, error) {
+	elo, err := LerHash(ponta.Hash)
+	if err != nil {
+		return Cadeia{}, err
+	}
+	c := Cadeia{Tamanho: ponta.Tamanho, Elo: elo}
+	if ponta.Fronteira != "" {
+		for _, s := range strings.Split(ponta.Fronteira, ",") {
+			h, err := LerHash(s)
+			if err != nil {
+				return Cadeia{}, err
+			}
+			c.Fronteira = append(c.Fronteira, h)
+		}
+	}
+	if len(c.Fronteira) != bitsLigados(c.Tamanho) {
+		return Cadeia{}, fmt.Errorf("auditoria: fronteira com %d hashes nao corresponde a %d votos", len(c.Fronteira), c.Tamanho)
+	}
+	return c, nil
+}
+
+// Ponta serializa a Cadeia no formato guardado em cadeias_votos.
+func (c Cadeia) Ponta(paredaoID domain.ParedaoID) domain.CadeiaVotos {
+	fronteira := make([]string, len(c.Fronteira))
+	for i, h := range c.Fronteira {
+		fronteira[i] = h.String()
+	}
+	return domain.CadeiaVotos{
+		ParedaoID: paredaoID,
+		Tamanho:   c.Tamanho,
+		Hash:      c.Elo.String(),
+		Fronteira: strings.Join(fronteira, ","),
+	}
+}
+
+func bitsLigados(n int64) int {
+	total := 0
+	for ; n > 0; n >>= 1 {
+		total += int(n & 1)
+	}
+	return total
+}
+
+// versaoCheckpoint abre a mensagem assinada de cada checkpoint.
+const versaoCheckpoint = "bbb-checkpoint-v1"
+
+// MensagemCheckpoint monta os bytes assinados do checkpoint: paredão, tamanho, último elo, raiz de Merkle
+// e instante, um por linha.
+func MensagemCheckpoint(c domain.Checkpoint) []byte {
+	return []byte(strings.Join([]string{
+		versaoCheckpoint,
+		string(c.ParedaoID),
+		strconv.FormatInt(c.Tamanho, 10),
+		c.HashCadeia,
+		c.RaizMerkle,
+		Instante(c.CriadoEm).Format(time.RFC3339Nano),
+	}, "\n"))
+}
@@ -0,0 +1,73 @@
+package auditoria
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+func votoTeste(i int) domain.VotoAuditado {
+	return domain.VotoAuditado{
+		VotoID:         domain.VotoID(fmt.Sprintf("voto-%03d", i)),
+		ParedaoID:      "par-1",
+		ParticipanteID: domain.ParticipanteID(fmt.Sprintf("p%d", i%3)),
+		Modalidade:     domain.ModalidadeTorcida,
+		CriadoEm:       time.Date(2024, 1, 1, 20, 0, i, 0, time.UTC),
+	}
+}
+
+func TestCadeia_QuandoAnexaFolhas_DeveCalcularMesmaRaizQueArvoreCompleta(t *testing.T) {
+	var cadeia Cadeia
+	var folhas []Hash
+	assert.Equal(t, RaizMerkle(nil), cadeia.Raiz())
+
+	for i := 1; i <= 33; i++ {
+		folha := Folha(votoTeste(i))
+		folhas = append(folhas, folha)
+		seq, _ := cadeia.Anexar(folha)
+
+		require.Equal(t, int64(i), seq)
+		require.Equal(t, RaizMerkle(folhas), cadeia.Raiz(), "raiz divergente com %d folhas", i)
+	}
+}
+
+func TestCadeia_QuandoRestauradaDaPonta_DeveContinuarDeOndeParou(t *testing.T) {
+	// Arrange
+	var continua Cadeia
+	for i := 1; i <= 11; i++ {
+		continua.Anexar(Folha(votoTeste(i)))
+	}
+
+	// Act
+	restaurada, err := Restaurar(continua.Ponta("par-1"))
+	require.NoError(t, err)
+	for i := 12; i <= 20; i++ {
+		continua.Anexar(Folha(votoTeste(i)))
+		restaurada.Anexar(Folha(votoTeste(i)))
+	}
+
+	// Assert
+	assert.Equal(t, continua.Elo, restaurada.Elo)
+	assert.Equal(t, continua.Raiz(), restaurada.Raiz())
+}
+
+func TestRestaurar_QuandoFronteiraIncoerente_DeveFalhar(t *testing.T) {
+	_, err := Restaurar(domain.CadeiaVotos{ParedaoID: "par-1", Tamanho: 3, Fronteira: Hash{}.String()})
+	assert.Error(t, err)
+}
+
+func TestFolha_QuandoHorarioTemNanossegundosOuOutroFuso_DeveUsarMicrossegundosEmUTC(t *testing.T) {
+	v := votoTeste(1)
+	outro := v
+	outro.CriadoEm = v.CriadoEm.Add(999 * time.Nanosecond).In(time.FixedZone("BRT", -3*3600))
+
+	assert.Equal(t, Folha(v), Folha(outro))
+
+	outro.ParticipanteID = "p9"
+	assert.NotEqual(t, Folha(v), Folha(outro))
+}
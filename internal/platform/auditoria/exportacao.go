@@ -0,0 +1,215 @@
+package auditoria
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/recibos"
+)
+
+// VersaoExportacao identifica o formato do NDJSON de auditoria.
+const VersaoExportacao = "bbb-auditoria-v1"
+
+// Tipos de linha da exportação: um cabeçalho, depois os checkpoints e por fim os votos em ordem de Seq.
+const (
+	LinhaCabecalho  = "cabecalho"
+	LinhaCheckpoint = "checkpoint"
+	LinhaVoto       = "voto"
+)
+
+// Cabecalho abre a exportação.
+type Cabecalho struct {
+	Versao    string           `json:"versao"`
+	ParedaoID domain.ParedaoID `json:"paredao_id"`
+	GeradoEm  time.Time        `json:"gerado_em"`
+}
+
+// Linha é uma linha do NDJSON; só o campo correspondente a Tipo vem preenchido.
+type Linha struct {
+	Tipo       string               `json:"tipo"`
+	Cabecalho  *Cabecalho           `json:"cabecalho,omitempty"`
+	Checkpoint *domain.Checkpoint   `json:"checkpoint,omitempty"`
+	Voto       *domain.VotoAuditado `json:"voto,omitempty"`
+}
+
+// Exportador escreve a exportação linha a linha, sem acumular votos em memória.
+type Exportador struct {
+	enc *json.Encoder
+}
+
+func NewExportador(w io.Writer) *Exportador {
+	return &Exportador{enc: json.NewEncoder(w)}
+}
+
+func (e *Exportador) Cabecalho(paredaoID domain.ParedaoID, geradoEm time.Time) error {
+	return e.enc.Encode(Linha{Tipo: LinhaCabecalho, Cabecalho: &Cabecalho{Versao: VersaoExportacao, ParedaoID: paredaoID, GeradoEm: geradoEm}})
+}
+
+func (e *Exportador) Checkpoint(c domain.Checkpoint) error {
+	return e.enc.Encode(Linha{Tipo: LinhaCheckpoint, Checkpoint: &c})
+}
+
+func (e *Exportador) Voto(v domain.VotoAuditado) error {
+	return e.enc.Encode(Linha{Tipo: LinhaVoto, Voto: &v})
+}
+
+// maxProblemasListados limita o relatório: uma adulteração no começo da cadeia quebra todos os elos seguintes.
+const maxProblemasListados = 50
+
+// Relatorio resume a verificação; a exportação está íntegra quando TotalProblemas é zero.
+type Relatorio struct {
+	ParedaoID             domain.ParedaoID
+	Votos                 int64
+	ForaDaCadeia          int64
+	HashCadeia            string
+	RaizMerkle            string
+	Checkpoints           int
+	CheckpointsConferidos int
+	AssinaturasConferidas bool
+	TotalProblemas        int
+	Problemas             []string
+}
+
+func (r *Relatorio) problema(formato string, args ...any) {
+	r.TotalProblemas++
+	if len(r.Problemas) < maxProblemasListados {
+		r.Problemas = append(r.Problemas, fmt.Sprintf(formato, args...))
+	}
+}
+
+// Integra indica que nenhum voto foi inserido, alterado ou apagado em relação à cadeia e aos checkpoints.
+func (r Relatorio) Integra() bool {
+	return r.TotalProblemas == 0
+}
+
+// Verificada indica que a integridade se apoia em ao menos um checkpoint de assinatura conferida. Sem as
+// chaves ou sem checkpoints, quem tivesse acesso ao banco poderia recalcular a cadeia inteira e a
+// exportação continuaria coerente consigo mesma, então Integra sozinho não prova nada.
+func (r Relatorio) Verificada() bool {
+	return r.AssinaturasConferidas && r.CheckpointsConferidos > 0
+}
+
+// Verificar recalcula a cadeia e as raízes de Merkle a partir dos votos exportados e compara com os elos
+// gravados e com cada checkpoint. Com chaves, confere também as assinaturas dos checkpoints; sem elas, só
+// a consistência interna. O erro indica arquivo ilegível; adulterações aparecem no relatório.
+func Verificar(r io.Reader, chaves map[string]ed25519.PublicKey) (Relatorio, error) {
+	leitor := bufio.NewReaderSize(r, 64<<10)
+	dec := json.NewDecoder(leitor)
+
+	var linha Linha
+	if err := dec.Decode(&linha); err != nil || linha.Tipo != LinhaCabecalho || linha.Cabecalho == nil {
+		return Relatorio{}, errors.New("auditoria: exportacao sem cabecalho")
+	}
+	if linha.Cabecalho.Versao != VersaoExportacao {
+		return Relatorio{}, fmt.Errorf("auditoria: versao %q nao suportada", linha.Cabecalho.Versao)
+	}
+
+	verificador := NewVerificador(linha.Cabecalho.ParedaoID, chaves)
+	for numero := 2; ; numero++ {
+		linha = Linha{}
+		if err := dec.Decode(&linha); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return verificador.rel, fmt.Errorf("auditoria: linha %d: %w", numero, err)
+		}
+
+		switch {
+		case linha.Tipo == LinhaCheckpoint && linha.Checkpoint != nil:
+			verificador.Checkpoint(*linha.Checkpoint)
+		case linha.Tipo == LinhaVoto && linha.Voto != nil:
+			verificador.Voto(*linha.Voto)
+		default:
+			return verificador.rel, fmt.Errorf("auditoria: linha %d de tipo %q inesperada", numero, linha.Tipo)
+		}
+	}
+	return verificador.Concluir(), nil
+}
+
+// Verificador confere a cadeia de um paredão aos poucos: primeiro os checkpoints, depois os votos em ordem
+// de Seq. Serve tanto à exportação NDJSON quanto ao pacote de auditoria.
+type Verificador struct {
+	rel         Relatorio
+	chaves      map[string]ed25519.PublicKey
+	checkpoints map[int64]domain.Checkpoint
+	cadeia      Cadeia
+}
+
+// NewVerificador prepara a conferência; chaves nil dispensa a verificação das assinaturas.
+func NewVerificador(paredaoID domain.ParedaoID, chaves map[string]ed25519.PublicKey) *Verificador {
+	return &Verificador{
+		rel:         Relatorio{ParedaoID: paredaoID, AssinaturasConferidas: chaves != nil},
+		chaves:      chaves,
+		checkpoints: make(map[int64]domain.Checkpoint),
+	}
+}
+
+func (v *Verificador) Checkpoint(c domain.Checkpoint) {
+	v.rel.Checkpoints++
+	if c.ParedaoID != v.rel.ParedaoID {
+		v.rel.problema("checkpoint de %d votos pertence ao paredao %s", c.Tamanho, c.ParedaoID)
+		return
+	}
+	if v.chaves != nil {
+		publica, ok := v.chaves[c.ChaveID]
+		if !ok {
+			v.rel.problema("checkpoint de %d votos assinado com chave desconhecida %q", c.Tamanho, c.ChaveID)
+		} else if err := recibos.VerificarAssinatura(publica, MensagemCheckpoint(c), c.Assinatura); err != nil {
+			v.rel.problema("checkpoint de %d votos com assinatura invalida", c.Tamanho)
+		}
+	}
+	v.checkpoints[c.Tamanho] = c
+}
+
+func (v *Verificador) Voto(voto domain.VotoAuditado) {
+	v.rel.Votos++
+	if voto.ParedaoID != v.rel.ParedaoID {
+		v.rel.problema("voto %s pertence ao paredao %s", voto.VotoID, voto.ParedaoID)
+		return
+	}
+	if voto.Seq == 0 {
+		v.rel.ForaDaCadeia++
+		v.rel.problema("voto %s fora da cadeia: inserido sem passar pelo registro", voto.VotoID)
+		return
+	}
+	if esperado := v.cadeia.Tamanho + 1; voto.Seq != esperado {
+		v.rel.problema("sequencia quebrada no voto %s: esperado seq %d, veio %d", voto.VotoID, esperado, voto.Seq)
+	}
+	_, elo := v.cadeia.Anexar(Folha(voto))
+	if elo.String() != voto.Hash {
+		v.rel.problema("elo do voto %s (seq %d) nao confere: voto alterado ou cadeia adulterada", voto.VotoID, voto.Seq)
+	}
+	if c, ok := v.checkpoints[v.cadeia.Tamanho]; ok {
+		conferirCheckpoint(&v.rel, c, v.cadeia)
+	}
+}
+
+// Concluir aponta checkpoints além do fim da cadeia e fecha o relatório com o último elo e a raiz.
+func (v *Verificador) Concluir() Relatorio {
+	for _, tamanho := range slices.Sorted(maps.Keys(v.checkpoints)) {
+		if tamanho > v.cadeia.Tamanho {
+			v.rel.problema("checkpoint de %d votos, mas a exportacao so tem %d encadeados: votos apagados", tamanho, v.cadeia.Tamanho)
+		}
+	}
+	v.rel.HashCadeia = v.cadeia.Elo.String()
+	v.rel.RaizMerkle = v.cadeia.Raiz().String()
+	return v.rel
+}
+
+func conferirCheckpoint(rel *Relatorio, c domain.Checkpoint, cadeia Cadeia) {
+	rel.CheckpointsConferidos++
+	if c.HashCadeia != cadeia.Elo.String() {
+		rel.problema("checkpoint de %d votos: ultimo elo recalculado difere do publicado", c.Tamanho)
+	}
+	if c.RaizMerkle != cadeia.Raiz().String() {
+		rel.problema("checkpoint de %d votos: raiz de Merkle recalculada difere da publicada", c.Tamanho)
+	}
+}
@@ -0,0 +1,153 @@
+package auditoria
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/recibos"
+)
+
+// exportacaoTeste grava votos encadeados como o repositório faria e assina um checkpoint a cada cinco votos.
+func exportacaoTeste(t *testing.T, total int, adulterar func([]domain.VotoAuditado) []domain.VotoAuditado) (*bytes.Buffer, map[string]ed25519.PublicKey) {
+	t.Helper()
+	assinador, err := recibos.NewAssinadorEfemero()
+	require.NoError(t, err)
+
+	var cadeia Cadeia
+	var votos []domain.VotoAuditado
+	var checkpoints []domain.Checkpoint
+	for i := 1; i <= total; i++ {
+		v := votoTeste(i)
+		seq, elo := cadeia.Anexar(Folha(v))
+		v.Seq, v.Hash = seq, elo.String()
+		votos = append(votos, v)
+		if i%5 == 0 {
+			c := domain.Checkpoint{ParedaoID: "par-1", Tamanho: seq, HashCadeia: elo.String(), RaizMerkle: cadeia.Raiz().String(), CriadoEm: time.Now()}
+			c.ChaveID, c.Assinatura = assinador.AssinarMensagem(MensagemCheckpoint(c))
+			checkpoints = append(checkpoints, c)
+		}
+	}
+	if adulterar != nil {
+		votos = adulterar(votos)
+	}
+
+	var buf bytes.Buffer
+	exp := NewExportador(&buf)
+	require.NoError(t, exp.Cabecalho("par-1", time.Now()))
+	for _, c := range checkpoints {
+		require.NoError(t, exp.Checkpoint(c))
+	}
+	for _, v := range votos {
+		require.NoError(t, exp.Voto(v))
+	}
+
+	chaves := make(map[string]ed25519.PublicKey)
+	for _, c := range assinador.ChavesPublicas() {
+		publica, err := base64.StdEncoding.DecodeString(c.Chave)
+		require.NoError(t, err)
+		chaves[c.ChaveID] = publica
+	}
+	return &buf, chaves
+}
+
+func TestVerificar_QuandoExportacaoIntegra_DeveConferirTodosOsCheckpoints(t *testing.T) {
+	buf, chaves := exportacaoTeste(t, 12, nil)
+
+	rel, err := Verificar(buf, chaves)
+
+	require.NoError(t, err)
+	assert.True(t, rel.Integra(), rel.Problemas)
+	assert.Equal(t, int64(12), rel.Votos)
+	assert.Equal(t, 2, rel.CheckpointsConferidos)
+}
+
+func TestVerificar_QuandoSemChavesOuSemCheckpoints_NaoDeveDarComoVerificada(t *testing.T) {
+	casos := map[string]struct {
+		votos      int
+		usarChaves bool
+		verificada bool
+	}{
+		"sem chaves":          {votos: 12, usarChaves: false, verificada: false},
+		"sem checkpoints":     {votos: 4, usarChaves: true, verificada: false},
+		"chaves e checkpoint": {votos: 12, usarChaves: true, verificada: true},
+	}
+	for nome, caso := range casos {
+		t.Run(nome, func(t *testing.T) {
+			buf, chaves := exportacaoTeste(t, caso.votos, nil)
+			if !caso.usarChaves {
+				chaves = nil
+			}
+
+			rel, err := Verificar(buf, chaves)
+
+			require.NoError(t, err)
+			assert.True(t, rel.Integra(), rel.Problemas)
+			assert.Equal(t, caso.verificada, rel.Verificada())
+		})
+	}
+}
+
+func TestVerificar_QuandoVotoAlteradoNoBanco_DeveApontar(t *testing.T) {
+	buf, chaves := exportacaoTeste(t, 10, func(v []domain.VotoAuditado) []domain.VotoAuditado {
+		v[2].ParticipanteID = "p-favorito"
+		return v
+	})
+
+	rel, err := Verificar(buf, chaves)
+
+	require.NoError(t, err)
+	assert.False(t, rel.Integra())
+	assert.Contains(t, rel.Problemas[0], "voto-003")
+}
+
+func TestVerificar_QuandoCadeiaInteiraRecalculada_DeveFalharNoCheckpoint(t *testing.T) {
+	// Quem altera um voto e refaz todos os elos seguintes ainda esbarra na raiz assinada.
+	buf, chaves := exportacaoTeste(t, 10, func(v []domain.VotoAuditado) []domain.VotoAuditado {
+		v[7].ParticipanteID = "p-favorito"
+		var cadeia Cadeia
+		for i := range v {
+			_, elo := cadeia.Anexar(Folha(v[i]))
+			v[i].Hash = elo.String()
+		}
+		return v
+	})
+
+	rel, err := Verificar(buf, chaves)
+
+	require.NoError(t, err)
+	assert.False(t, rel.Integra())
+	assert.Contains(t, rel.Problemas[0], "checkpoint de 10 votos")
+}
+
+func TestVerificar_QuandoVotosApagadosOuInseridos_DeveApontar(t *testing.T) {
+	buf, chaves := exportacaoTeste(t, 10, func(v []domain.VotoAuditado) []domain.VotoAuditado {
+		intruso := votoTeste(99)
+		return append([]domain.VotoAuditado{intruso}, v[:9]...)
+	})
+
+	rel, err := Verificar(buf, chaves)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rel.ForaDaCadeia)
+	assert.Contains(t, rel.Problemas, "checkpoint de 10 votos, mas a exportacao so tem 9 encadeados: votos apagados")
+}
+
+func TestVerificar_QuandoChaveNaoConfere_DeveApontarAssinatura(t *testing.T) {
+	buf, _ := exportacaoTeste(t, 5, nil)
+	outra, err := recibos.NewAssinadorEfemero()
+	require.NoError(t, err)
+	publica, err := base64.StdEncoding.DecodeString(outra.ChavesPublicas()[0].Chave)
+	require.NoError(t, err)
+
+	rel, err := Verificar(buf, map[string]ed25519.PublicKey{"efemera": publica})
+
+	require.NoError(t, err)
+	assert.Contains(t, rel.Problemas, "checkpoint de 5 votos com assinatura invalida")
+}
@@ -0,0 +1,284 @@
+package auditoria
+
+import (
+	"archive/zip"
+	"bufio"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// VersaoPacote identifica o formato do pacote de auditoria.
+const VersaoPacote = "bbb-pacote-v1"
+
+// Arquivos do pacote. O manifesto é o último e lista o SHA-256 de todos os outros.
+const (
+	ArquivoManifesto     = "manifesto.json"
+	ArquivoParedao       = "paredao.json"
+	ArquivoParticipantes = "participantes.json"
+	ArquivoResultado     = "resultado.json"
+	ArquivoCheckpoints   = "checkpoints.json"
+)
+
+// FormatoVotos escolhe como os votos vão no pacote.
+type FormatoVotos string
+
+const (
+	FormatoCSV    FormatoVotos = "csv"
+	FormatoNDJSON FormatoVotos = "ndjson"
+)
+
+// LerFormato interpreta o formato pedido; vazio vale CSV.
+func LerFormato(s string) (FormatoVotos, error) {
+	switch FormatoVotos(s) {
+	case "", FormatoCSV:
+		return FormatoCSV, nil
+	case FormatoNDJSON:
+		return FormatoNDJSON, nil
+	}
+	return "", fmt.Errorf("auditoria: formato %q desconhecido (use csv ou ndjson)", s)
+}
+
+// Arquivo é o nome do arquivo de votos dentro do pacote.
+func (f FormatoVotos) Arquivo() string {
+	return "votos." + string(f)
+}
+
+// ItemManifesto descreve um arquivo do pacote.
+type ItemManifesto struct {
+	Nome   string `json:"nome"`
+	SHA256 string `json:"sha256"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// Manifesto fecha o pacote: a assinatura da chave ChaveID cobre a mensagem de MensagemManifesto.
+type Manifesto struct {
+	Versao     string           `json:"versao"`
+	ParedaoID  domain.ParedaoID `json:"paredao_id"`
+	GeradoEm   time.Time        `json:"gerado_em"`
+	Arquivos   []ItemManifesto  `json:"arquivos"`
+	ChaveID    string           `json:"chave_id"`
+	Assinatura string           `json:"assinatura"`
+}
+
+// MensagemManifesto monta os bytes assinados: versão, paredão e instante, seguidos de uma linha
+// "nome sha256 bytes" por arquivo, na ordem do pacote.
+func MensagemManifesto(m Manifesto) []byte {
+	linhas := []string{m.Versao, string(m.ParedaoID), Instante(m.GeradoEm).Format(time.RFC3339Nano)}
+	for _, a := range m.Arquivos {
+		linhas = append(linhas, fmt.Sprintf("%s %s %d", a.Nome, a.SHA256, a.Bytes))
+	}
+	return []byte(strings.Join(linhas, "\n"))
+}
+
+// Pacote escreve o zip do pacote de auditoria direto no destino, sem guardar os arquivos em memória: cada
+// arquivo tem o SHA-256 calculado enquanto é escrito e o manifesto sai por último.
+type Pacote struct {
+	zip       *zip.Writer
+	manifesto Manifesto
+	atual     *arquivoPacote
+}
+
+func NewPacote(w io.Writer, paredaoID domain.ParedaoID, geradoEm time.Time) *Pacote {
+	return &Pacote{
+		zip:       zip.NewWriter(w),
+		manifesto: Manifesto{Versao: VersaoPacote, ParedaoID: paredaoID, GeradoEm: Instante(geradoEm)},
+	}
+}
+
+type arquivoPacote struct {
+	nome  string
+	w     io.Writer
+	hash  hash.Hash
+	bytes int64
+}
+
+func (a *arquivoPacote) Write(p []byte) (int, error) {
+	n, err := a.w.Write(p)
+	a.hash.Write(p[:n])
+	a.bytes += int64(n)
+	return n, err
+}
+
+// Criar abre o próximo arquivo do pacote; o anterior é encerrado e entra no manifesto.
+func (p *Pacote) Criar(nome string) (io.Writer, error) {
+	p.encerrarAtual()
+	// A data fixa no instante de geração deixa o zip reproduzível a partir dos mesmos dados.
+	w, err := p.zip.CreateHeader(&zip.FileHeader{Name: nome, Method: zip.Deflate, Modified: p.manifesto.GeradoEm})
+	if err != nil {
+		return nil, err
+	}
+	p.atual = &arquivoPacote{nome: nome, w: w, hash: sha256.New()}
+	return p.atual, nil
+}
+
+// JSON grava v indentado num arquivo próprio.
+func (p *Pacote) JSON(nome string, v any) error {
+	w, err := p.Criar(nome)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (p *Pacote) encerrarAtual() {
+	if p.atual == nil {
+		return
+	}
+	p.manifesto.Arquivos = append(p.manifesto.Arquivos, ItemManifesto{
+		Nome:   p.atual.nome,
+		SHA256: hex.EncodeToString(p.atual.hash.Sum(nil)),
+		Bytes:  p.atual.bytes,
+	})
+	p.atual = nil
+}
+
+// Fechar assina e grava o manifesto e conclui o zip.
+func (p *Pacote) Fechar(assinar func(msg []byte) (chaveID, assinatura string)) error {
+	p.encerrarAtual()
+	p.manifesto.ChaveID, p.manifesto.Assinatura = assinar(MensagemManifesto(p.manifesto))
+	w, err := p.zip.CreateHeader(&zip.FileHeader{Name: ArquivoManifesto, Method: zip.Deflate, Modified: p.manifesto.GeradoEm})
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p.manifesto); err != nil {
+		return err
+	}
+	return p.zip.Close()
+}
+
+// EscritorVotos grava os votos do pacote no formato escolhido; Concluir descarrega o que estiver em buffer.
+type EscritorVotos interface {
+	Escrever(v domain.VotoAuditado) error
+	Concluir() error
+}
+
+func NewEscritorVotos(formato FormatoVotos, w io.Writer) EscritorVotos {
+	if formato == FormatoNDJSON {
+		return &escritorNDJSON{enc: json.NewEncoder(w)}
+	}
+	return &escritorCSV{w: csv.NewWriter(w)}
+}
+
+// colunasCSV segue a ordem dos campos de domain.VotoAuditado.
+var colunasCSV = []string{"seq", "voto_id", "paredao_id", "participante_id", "modalidade", "criado_em", "hash", "origem_ip"}
+
+type escritorCSV struct {
+	w         *csv.Writer
+	cabecalho bool
+}
+
+func (e *escritorCSV) Escrever(v domain.VotoAuditado) error {
+	if !e.cabecalho {
+		e.cabecalho = true
+		if err := e.w.Write(colunasCSV); err != nil {
+			return err
+		}
+	}
+	return e.w.Write([]string{
+		strconv.FormatInt(v.Seq, 10),
+		string(v.VotoID),
+		string(v.ParedaoID),
+		string(v.ParticipanteID),
+		string(v.Modalidade),
+		Instante(v.CriadoEm).Format(time.RFC3339Nano),
+		v.Hash,
+		v.OrigemIP,
+	})
+}
+
+func (e *escritorCSV) Concluir() error {
+	if !e.cabecalho {
+		e.cabecalho = true
+		if err := e.w.Write(colunasCSV); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type escritorNDJSON struct {
+	enc *json.Encoder
+}
+
+func (e *escritorNDJSON) Escrever(v domain.VotoAuditado) error {
+	return e.enc.Encode(v)
+}
+
+func (e *escritorNDJSON) Concluir() error {
+	return nil
+}
+
+// lerVotos percorre o arquivo de votos no formato indicado, um voto por vez.
+func lerVotos(formato FormatoVotos, r io.Reader, emitir func(domain.VotoAuditado) error) error {
+	if formato == FormatoNDJSON {
+		dec := json.NewDecoder(bufio.NewReaderSize(r, 64<<10))
+		for linha := 1; ; linha++ {
+			var v domain.VotoAuditado
+			if err := dec.Decode(&v); err != nil {
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				return fmt.Errorf("auditoria: %s linha %d: %w", formato.Arquivo(), linha, err)
+			}
+			if err := emitir(v); err != nil {
+				return err
+			}
+		}
+	}
+
+	leitor := csv.NewReader(bufio.NewReaderSize(r, 64<<10))
+	leitor.FieldsPerRecord = len(colunasCSV)
+	leitor.ReuseRecord = true
+	cabecalho, err := leitor.Read()
+	if err != nil {
+		return fmt.Errorf("auditoria: %s sem cabecalho: %w", formato.Arquivo(), err)
+	}
+	if strings.Join(cabecalho, ",") != strings.Join(colunasCSV, ",") {
+		return fmt.Errorf("auditoria: %s com colunas inesperadas", formato.Arquivo())
+	}
+	for linha := 2; ; linha++ {
+		registro, err := leitor.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("auditoria: %s linha %d: %w", formato.Arquivo(), linha, err)
+		}
+		seq, err := strconv.ParseInt(registro[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("auditoria: %s linha %d: seq invalido", formato.Arquivo(), linha)
+		}
+		criadoEm, err := time.Parse(time.RFC3339Nano, registro[5])
+		if err != nil {
+			return fmt.Errorf("auditoria: %s linha %d: criado_em invalido", formato.Arquivo(), linha)
+		}
+		if err := emitir(domain.VotoAuditado{
+			Seq:            seq,
+			VotoID:         domain.VotoID(registro[1]),
+			ParedaoID:      domain.ParedaoID(registro[2]),
+			ParticipanteID: domain.ParticipanteID(registro[3]),
+			Modalidade:     domain.Modalidade(registro[4]),
+			CriadoEm:       criadoEm,
+			Hash:           registro[6],
+			OrigemIP:       registro[7],
+		}); err != nil {
+			return err
+		}
+	}
+}
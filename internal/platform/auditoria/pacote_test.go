@@ -0,0 +1,154 @@
+package auditoria
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/recibos"
+)
+
+// pacoteTeste monta o pacote de um paredão com três participantes: a recebe 3 votos, b 2 e c 1, e o
+// resultado oficial aponta a como eliminado. ajustar permite adulterar votos e resultado antes da escrita.
+func pacoteTeste(t *testing.T, formato FormatoVotos, ajustar func(*domain.Resultado, []domain.VotoAuditado) []domain.VotoAuditado) (*bytes.Reader, map[string]ed25519.PublicKey) {
+	t.Helper()
+	assinador, err := recibos.NewAssinadorEfemero()
+	require.NoError(t, err)
+
+	paredao := domain.Paredao{ID: "par-1", Nome: "Paredão", ModoVotacao: domain.ModoTorcida}
+	participantes := []domain.Participante{{ID: "a", ParedaoID: "par-1"}, {ID: "b", ParedaoID: "par-1"}, {ID: "c", ParedaoID: "par-1"}}
+	var cadeia Cadeia
+	var votos []domain.VotoAuditado
+	for i, p := range []domain.ParticipanteID{"a", "b", "a", "c", "b", "a"} {
+		v := votoTeste(i + 1)
+		v.ParticipanteID = p
+		v.OrigemIP = "pseudonimo"
+		seq, elo := cadeia.Anexar(Folha(v))
+		v.Seq, v.Hash = seq, elo.String()
+		votos = append(votos, v)
+	}
+	checkpoint := domain.Checkpoint{ParedaoID: "par-1", Tamanho: cadeia.Tamanho, HashCadeia: cadeia.Elo.String(), RaizMerkle: cadeia.Raiz().String(), CriadoEm: time.Now()}
+	checkpoint.ChaveID, checkpoint.Assinatura = assinador.AssinarMensagem(MensagemCheckpoint(checkpoint))
+	resultado := domain.Resultado{
+		ParedaoID:  "par-1",
+		Polaridade: domain.PolaridadeEliminar,
+		TotalVotos: 6,
+		Parciais: []domain.Parcial{
+			{ParedaoID: "par-1", ParticipanteID: "a", Total: 3, Percentual: 50},
+			{ParedaoID: "par-1", ParticipanteID: "b", Total: 2, Percentual: 100.0 * 2 / 6},
+			{ParedaoID: "par-1", ParticipanteID: "c", Total: 1, Percentual: 100.0 / 6},
+		},
+		Eliminado: "a",
+	}
+	if ajustar != nil {
+		votos = ajustar(&resultado, votos)
+	}
+
+	var buf bytes.Buffer
+	pacote := NewPacote(&buf, "par-1", time.Now())
+	require.NoError(t, pacote.JSON(ArquivoParedao, paredao))
+	require.NoError(t, pacote.JSON(ArquivoParticipantes, participantes))
+	require.NoError(t, pacote.JSON(ArquivoResultado, resultado))
+	require.NoError(t, pacote.JSON(ArquivoCheckpoints, []domain.Checkpoint{checkpoint}))
+	w, err := pacote.Criar(formato.Arquivo())
+	require.NoError(t, err)
+	escritor := NewEscritorVotos(formato, w)
+	for _, v := range votos {
+		require.NoError(t, escritor.Escrever(v))
+	}
+	require.NoError(t, escritor.Concluir())
+	require.NoError(t, pacote.Fechar(assinador.AssinarMensagem))
+
+	chaves, err := recibos.ChavesPorID(assinador.ChavesPublicas())
+	require.NoError(t, err)
+	return bytes.NewReader(buf.Bytes()), chaves
+}
+
+func TestVerificarPacote_QuandoIntegro_DeveRecontarOMesmoResultado(t *testing.T) {
+	for _, formato := range []FormatoVotos{FormatoCSV, FormatoNDJSON} {
+		t.Run(string(formato), func(t *testing.T) {
+			pacote, chaves := pacoteTeste(t, formato, nil)
+
+			rel, err := VerificarPacote(pacote, pacote.Size(), chaves)
+
+			require.NoError(t, err)
+			assert.True(t, rel.Integra(), rel.Problemas)
+			assert.Equal(t, formato, rel.Formato)
+			assert.Equal(t, int64(6), rel.Recontado.TotalVotos)
+			assert.Equal(t, domain.ParticipanteID("a"), rel.Recontado.Eliminado)
+			assert.Equal(t, 1, rel.CheckpointsConferidos)
+		})
+	}
+}
+
+func TestVerificarPacote_QuandoResultadoPublicadoDivergeDosVotos_DeveApontar(t *testing.T) {
+	pacote, chaves := pacoteTeste(t, FormatoCSV, func(r *domain.Resultado, v []domain.VotoAuditado) []domain.VotoAuditado {
+		r.Eliminado = "b"
+		return v
+	})
+
+	rel, err := VerificarPacote(pacote, pacote.Size(), chaves)
+
+	require.NoError(t, err)
+	assert.False(t, rel.Integra())
+	assert.Contains(t, rel.Problemas[0], "desfecho publicado")
+}
+
+func TestVerificarPacote_QuandoVotoApagado_DeveApontarCadeiaERecontagem(t *testing.T) {
+	pacote, chaves := pacoteTeste(t, FormatoNDJSON, func(_ *domain.Resultado, v []domain.VotoAuditado) []domain.VotoAuditado {
+		return v[:5]
+	})
+
+	rel, err := VerificarPacote(pacote, pacote.Size(), chaves)
+
+	require.NoError(t, err)
+	assert.Contains(t, rel.Problemas, "total de votos publicado 6, recontado 5")
+	assert.Contains(t, rel.Problemas, "checkpoint de 6 votos, mas a exportacao so tem 5 encadeados: votos apagados")
+}
+
+func TestVerificarPacote_QuandoArquivoTrocadoDepoisDeAssinado_DeveApontarManifesto(t *testing.T) {
+	original, chaves := pacoteTeste(t, FormatoCSV, nil)
+	leitor, err := zip.NewReader(original, original.Size())
+	require.NoError(t, err)
+
+	// Regrava o zip trocando o conteúdo do resultado e mantendo o manifesto original.
+	var buf bytes.Buffer
+	escritor := zip.NewWriter(&buf)
+	for _, f := range leitor.File {
+		w, err := escritor.Create(f.Name)
+		require.NoError(t, err)
+		conteudo, err := f.Open()
+		require.NoError(t, err)
+		dados, err := io.ReadAll(conteudo)
+		require.NoError(t, err)
+		if f.Name == ArquivoResultado {
+			dados = bytes.Replace(dados, []byte(`"Eliminado": "a"`), []byte(`"Eliminado": "c"`), 1)
+		}
+		_, err = w.Write(dados)
+		require.NoError(t, err)
+	}
+	require.NoError(t, escritor.Close())
+	adulterado := bytes.NewReader(buf.Bytes())
+
+	rel, err := VerificarPacote(adulterado, adulterado.Size(), chaves)
+
+	require.NoError(t, err)
+	assert.Contains(t, rel.Problemas, "arquivo resultado.json difere do manifesto")
+}
+
+func TestPseudonimizador_QuandoMesmoIP_DeveRepetirSoDentroDoParedao(t *testing.T) {
+	p, err := NewPseudonimizador("segredo")
+	require.NoError(t, err)
+
+	assert.Equal(t, p.Pseudonimo("par-1", "10.0.0.1"), p.Pseudonimo("par-1", "10.0.0.1"))
+	assert.NotEqual(t, p.Pseudonimo("par-1", "10.0.0.1"), p.Pseudonimo("par-2", "10.0.0.1"))
+	assert.NotContains(t, p.Pseudonimo("par-1", "10.0.0.1"), "10.0.0.1")
+	assert.Empty(t, p.Pseudonimo("par-1", ""))
+}
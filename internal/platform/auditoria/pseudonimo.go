@@ -0,0 +1,44 @@
+package auditoria
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// Pseudonimizador troca IPs por HMAC-SHA256 do segredo sobre paredão e IP. O mesmo IP vira o mesmo
+// pseudônimo dentro de um paredão, mas pacotes de paredões diferentes não podem ser cruzados, e sem o
+// segredo não dá para testar os quatro bilhões de IPv4 contra o pseudônimo.
+type Pseudonimizador struct {
+	segredo []byte
+}
+
+var _ domain.Pseudonimizador = (*Pseudonimizador)(nil)
+
+// NewPseudonimizador usa o segredo informado; vazio gera um aleatório, e os pseudônimos mudam a cada
+// reinício do processo.
+func NewPseudonimizador(segredo string) (*Pseudonimizador, error) {
+	if segredo != "" {
+		return &Pseudonimizador{segredo: []byte(segredo)}, nil
+	}
+	aleatorio := make([]byte, 32)
+	if _, err := rand.Read(aleatorio); err != nil {
+		return nil, err
+	}
+	return &Pseudonimizador{segredo: aleatorio}, nil
+}
+
+// Pseudonimo devolve 16 bytes do HMAC em hexadecimal; IP vazio continua vazio.
+func (p *Pseudonimizador) Pseudonimo(paredaoID domain.ParedaoID, ip string) string {
+	if ip == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, p.segredo)
+	mac.Write([]byte(paredaoID))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
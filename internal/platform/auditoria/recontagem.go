@@ -0,0 +1,285 @@
+package auditoria
+
+import (
+	"archive/zip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/recibos"
+)
+
+// toleranciaPercentual absorve diferenças de arredondamento entre a recontagem e o resultado publicado.
+const toleranciaPercentual = 1e-6
+
+// RelatorioPacote soma à verificação da cadeia a do manifesto e a recontagem dos votos. Os problemas de
+// todas as etapas ficam na mesma lista; o pacote está íntegro quando ela vem vazia.
+type RelatorioPacote struct {
+	Relatorio
+	Formato           FormatoVotos
+	ManifestoAssinado bool
+	Publicado         domain.Resultado
+	Recontado         domain.Resultado
+}
+
+// VerificarPacote abre o pacote de auditoria, confere o SHA-256 de cada arquivo contra o manifesto e, com
+// chaves, a assinatura do manifesto e dos checkpoints. Depois refaz a cadeia de hashes e reapura o
+// resultado só com os votos do pacote, comparando com o resultado publicado. O erro indica pacote
+// ilegível; adulterações e divergências aparecem no relatório.
+func VerificarPacote(r io.ReaderAt, tamanho int64, chaves map[string]ed25519.PublicKey) (RelatorioPacote, error) {
+	arquivo, err := zip.NewReader(r, tamanho)
+	if err != nil {
+		return RelatorioPacote{}, fmt.Errorf("auditoria: pacote invalido: %w", err)
+	}
+	arquivos := make(map[string]*zip.File, len(arquivo.File))
+	for _, f := range arquivo.File {
+		arquivos[f.Name] = f
+	}
+
+	var manifesto Manifesto
+	if err := lerJSON(arquivos, ArquivoManifesto, &manifesto); err != nil {
+		return RelatorioPacote{}, err
+	}
+	if manifesto.Versao != VersaoPacote {
+		return RelatorioPacote{}, fmt.Errorf("auditoria: versao de pacote %q nao suportada", manifesto.Versao)
+	}
+
+	verificador := NewVerificador(manifesto.ParedaoID, chaves)
+	rel := &verificador.rel
+	if chaves != nil {
+		publica, ok := chaves[manifesto.ChaveID]
+		switch {
+		case !ok:
+			rel.problema("manifesto assinado com chave desconhecida %q", manifesto.ChaveID)
+		case recibos.VerificarAssinatura(publica, MensagemManifesto(manifesto), manifesto.Assinatura) != nil:
+			rel.problema("manifesto com assinatura invalida")
+		}
+	}
+	listados := map[string]bool{ArquivoManifesto: true}
+	for _, item := range manifesto.Arquivos {
+		listados[item.Nome] = true
+		conferirItem(rel, arquivos[item.Nome], item)
+	}
+	for _, f := range arquivo.File {
+		if !listados[f.Name] {
+			rel.problema("arquivo %s fora do manifesto", f.Name)
+		}
+	}
+
+	var (
+		paredao       domain.Paredao
+		participantes []domain.Participante
+		publicado     domain.Resultado
+		checkpoints   []domain.Checkpoint
+	)
+	for nome, destino := range map[string]any{
+		ArquivoParedao:       &paredao,
+		ArquivoParticipantes: &participantes,
+		ArquivoResultado:     &publicado,
+		ArquivoCheckpoints:   &checkpoints,
+	} {
+		if err := lerJSON(arquivos, nome, destino); err != nil {
+			return RelatorioPacote{}, err
+		}
+	}
+	if paredao.ID != manifesto.ParedaoID {
+		rel.problema("%s descreve o paredao %s", ArquivoParedao, paredao.ID)
+	}
+
+	formato := FormatoCSV
+	if _, ok := arquivos[FormatoNDJSON.Arquivo()]; ok {
+		formato = FormatoNDJSON
+	}
+	f, ok := arquivos[formato.Arquivo()]
+	if !ok {
+		return RelatorioPacote{}, errors.New("auditoria: pacote sem arquivo de votos")
+	}
+
+	for _, c := range checkpoints {
+		verificador.Checkpoint(c)
+	}
+	conhecidos := make(map[domain.ParticipanteID]bool, len(participantes))
+	for _, p := range participantes {
+		conhecidos[p.ID] = true
+	}
+	totais := make(map[domain.Modalidade]map[domain.ParticipanteID]int64)
+	conteudo, err := f.Open()
+	if err != nil {
+		return RelatorioPacote{}, fmt.Errorf("auditoria: %s: %w", f.Name, err)
+	}
+	defer conteudo.Close()
+	err = lerVotos(formato, conteudo, func(v domain.VotoAuditado) error {
+		verificador.Voto(v)
+		if !conhecidos[v.ParticipanteID] {
+			rel.problema("voto %s para participante %s fora da lista", v.VotoID, v.ParticipanteID)
+			return nil
+		}
+		if totais[v.Modalidade] == nil {
+			totais[v.Modalidade] = make(map[domain.ParticipanteID]int64)
+		}
+		totais[v.Modalidade][v.ParticipanteID]++
+		return nil
+	})
+	if err != nil {
+		return RelatorioPacote{}, err
+	}
+
+	recontado := Recontar(paredao, participantes, totais, publicado.ProximaEtapa != "")
+	recontado.ProximaEtapa = publicado.ProximaEtapa
+	recontado.FinalizadoEm = publicado.FinalizadoEm
+	conferirResultado(rel, publicado, recontado)
+
+	return RelatorioPacote{
+		Relatorio:         verificador.Concluir(),
+		Formato:           formato,
+		ManifestoAssinado: chaves != nil,
+		Publicado:         publicado,
+		Recontado:         recontado,
+	}, nil
+}
+
+func lerJSON(arquivos map[string]*zip.File, nome string, destino any) error {
+	f, ok := arquivos[nome]
+	if !ok {
+		return fmt.Errorf("auditoria: pacote sem %s", nome)
+	}
+	conteudo, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("auditoria: %s: %w", nome, err)
+	}
+	defer conteudo.Close()
+	if err := json.NewDecoder(conteudo).Decode(destino); err != nil {
+		return fmt.Errorf("auditoria: %s: %w", nome, err)
+	}
+	return nil
+}
+
+func conferirItem(rel *Relatorio, f *zip.File, item ItemManifesto) {
+	if f == nil {
+		rel.problema("arquivo %s do manifesto ausente no pacote", item.Nome)
+		return
+	}
+	conteudo, err := f.Open()
+	if err != nil {
+		rel.problema("arquivo %s ilegivel: %v", item.Nome, err)
+		return
+	}
+	defer conteudo.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, conteudo)
+	if err != nil {
+		rel.problema("arquivo %s ilegivel: %v", item.Nome, err)
+		return
+	}
+	if n != item.Bytes || hex.EncodeToString(h.Sum(nil)) != item.SHA256 {
+		rel.problema("arquivo %s difere do manifesto", item.Nome)
+	}
+}
+
+// Recontar apura o resultado a partir da contagem dos votos por urna, sem passar pelos contadores nem pelo
+// cálculo de parciais do serviço: cada urna vale o percentual do participante entre os que seguem na
+// disputa, ponderado pelo peso da urna entre as urnas que receberam votos. Votos de retirados com descarte
+// não contam; os demais retirados entram no total, fora dos percentuais. Com intermediaria, o destaque
+// escapa em vez de alguém ser eliminado.
+func Recontar(paredao domain.Paredao, participantes []domain.Participante, totais map[domain.Modalidade]map[domain.ParticipanteID]int64, intermediaria bool) domain.Resultado {
+	contagem := make(map[domain.Modalidade]map[domain.ParticipanteID]int64, len(totais))
+	for m, porParticipante := range totais {
+		// Votos anteriores às modalidades contam como torcida.
+		if m == "" {
+			m = domain.ModalidadeTorcida
+		}
+		if contagem[m] == nil {
+			contagem[m] = make(map[domain.ParticipanteID]int64)
+		}
+		for id, n := range porParticipante {
+			contagem[m][id] += n
+		}
+	}
+
+	pesos := paredao.PesosEfetivos()
+	base := make(map[domain.Modalidade]int64)
+	for m, porParticipante := range contagem {
+		for _, p := range participantes {
+			if !p.Retirado() {
+				base[m] += porParticipante[p.ID]
+			}
+		}
+	}
+	somaPesos := 0.0
+	for m, peso := range pesos {
+		if base[m] > 0 {
+			somaPesos += peso
+		}
+	}
+
+	resultado := domain.Resultado{
+		ParedaoID:  paredao.ID,
+		Polaridade: paredao.PolaridadeEfetiva(),
+		Anulado:    paredao.Anulado,
+	}
+	for _, p := range participantes {
+		if p.Retirada == domain.RetiradaDescartar {
+			continue
+		}
+		parcial := domain.Parcial{ParedaoID: paredao.ID, ParticipanteID: p.ID, Retirado: p.Retirado()}
+		for m, porParticipante := range contagem {
+			parcial.Total += porParticipante[p.ID]
+			if !parcial.Retirado && base[m] > 0 && somaPesos > 0 {
+				parcial.Percentual += 100 * float64(porParticipante[p.ID]) / float64(base[m]) * pesos[m] / somaPesos
+			}
+		}
+		resultado.TotalVotos += parcial.Total
+		resultado.Parciais = append(resultado.Parciais, parcial)
+	}
+
+	switch {
+	case paredao.Anulado:
+	case intermediaria:
+		resultado.Escapou, resultado.Empatados = domain.Destaque(resultado.Parciais)
+	default:
+		resultado.Eliminado, resultado.Empatados = domain.Apurar(resultado.Polaridade, resultado.Parciais)
+	}
+	return resultado
+}
+
+func conferirResultado(rel *Relatorio, publicado, recontado domain.Resultado) {
+	if publicado.TotalVotos != recontado.TotalVotos {
+		rel.problema("total de votos publicado %d, recontado %d", publicado.TotalVotos, recontado.TotalVotos)
+	}
+	porID := make(map[domain.ParticipanteID]domain.Parcial, len(publicado.Parciais))
+	for _, p := range publicado.Parciais {
+		porID[p.ParticipanteID] = p
+	}
+	for _, r := range recontado.Parciais {
+		p, ok := porID[r.ParticipanteID]
+		if !ok {
+			rel.problema("participante %s ausente do resultado publicado", r.ParticipanteID)
+			continue
+		}
+		delete(porID, r.ParticipanteID)
+		if p.Total != r.Total {
+			rel.problema("participante %s: %d votos publicados, %d recontados", r.ParticipanteID, p.Total, r.Total)
+		}
+		if math.Abs(p.Percentual-r.Percentual) > toleranciaPercentual {
+			rel.problema("participante %s: %.6f%% publicado, %.6f%% recontado", r.ParticipanteID, p.Percentual, r.Percentual)
+		}
+	}
+	for id := range porID {
+		rel.problema("participante %s no resultado publicado sem constar da recontagem", id)
+	}
+	if publicado.Anulado != recontado.Anulado {
+		rel.problema("resultado publicado com anulado=%t, recontagem com anulado=%t", publicado.Anulado, recontado.Anulado)
+	}
+	if publicado.Eliminado != recontado.Eliminado || publicado.Escapou != recontado.Escapou ||
+		!slices.Equal(publicado.Empatados, recontado.Empatados) {
+		rel.problema("desfecho publicado (eliminado %q, escapou %q, empatados %v) difere do recontado (eliminado %q, escapou %q, empatados %v)",
+			publicado.Eliminado, publicado.Escapou, publicado.Empatados, recontado.Eliminado, recontado.Escapou, recontado.Empatados)
+	}
+}
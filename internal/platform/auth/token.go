@@ -0,0 +1,75 @@
+// Pacote auth valida a identidade do eleitor a partir de tokens assinados emitidos pelo serviço de contas.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+var ErrTokenInvalido = errors.New("token de eleitor invalido")
+
+// Tokens assina e verifica tokens no formato base64url(payload).base64url(HMAC-SHA256(payload)).
+// O segredo é compartilhado com o serviço de contas que autentica o eleitor.
+type Tokens struct {
+	segredo []byte
+	agora   func() time.Time
+}
+
+type claims struct {
+	Sub string `json:"sub"`
+	Exp int64  `json:"exp"`
+}
+
+func NewTokens(segredo string) *Tokens {
+	return &Tokens{segredo: []byte(segredo), agora: time.Now}
+}
+
+// Emitir gera um token para a conta; usado em testes e ambientes locais sem serviço de contas.
+func (t *Tokens) Emitir(eleitor domain.EleitorID, validade time.Duration) (string, error) {
+	payload, err := json.Marshal(claims{Sub: string(eleitor), Exp: t.agora().Add(validade).Unix()})
+	if err != nil {
+		return "", fmt.Errorf("auth: serializar token: %w", err)
+	}
+	corpo := base64.RawURLEncoding.EncodeToString(payload)
+	return corpo + "." + base64.RawURLEncoding.EncodeToString(t.assinar(corpo)), nil
+}
+
+// Verificar confere assinatura e validade e devolve a conta do eleitor.
+func (t *Tokens) Verificar(token string) (domain.EleitorID, error) {
+	corpo, assinatura, ok := strings.Cut(token, ".")
+	if !ok || len(t.segredo) == 0 {
+		return "", ErrTokenInvalido
+	}
+
+	recebida, err := base64.RawURLEncoding.DecodeString(assinatura)
+	if err != nil || !hmac.Equal(recebida, t.assinar(corpo)) {
+		return "", ErrTokenInvalido
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(corpo)
+	if err != nil {
+		return "", ErrTokenInvalido
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil || c.Sub == "" {
+		return "", ErrTokenInvalido
+	}
+	if t.agora().Unix() >= c.Exp {
+		return "", fmt.Errorf("%w: expirado", ErrTokenInvalido)
+	}
+	return domain.EleitorID(c.Sub), nil
+}
+
+func (t *Tokens) assinar(corpo string) []byte {
+	mac := hmac.New(sha256.New, t.segredo)
+	mac.Write([]byte(corpo))
+	return mac.Sum(nil)
+}
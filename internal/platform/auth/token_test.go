@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokensEmitirEVerificar(t *testing.T) {
+	tokens := NewTokens("segredo")
+	agora := time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)
+	tokens.agora = func() time.Time { return agora }
+
+	token, err := tokens.Emitir("conta-1", time.Hour)
+	if err != nil {
+		t.Fatalf("emitir retornou erro: %v", err)
+	}
+
+	eleitor, err := tokens.Verificar(token)
+	if err != nil {
+		t.Fatalf("token recem emitido deveria ser valido: %v", err)
+	}
+	if eleitor != "conta-1" {
+		t.Fatalf("eleitor inesperado: %s", eleitor)
+	}
+
+	if _, err := NewTokens("outro-segredo").Verificar(token); !errors.Is(err, ErrTokenInvalido) {
+		t.Fatalf("assinatura com outro segredo deveria falhar, veio %v", err)
+	}
+
+	agora = agora.Add(2 * time.Hour)
+	if _, err := tokens.Verificar(token); !errors.Is(err, ErrTokenInvalido) {
+		t.Fatalf("token expirado deveria falhar, veio %v", err)
+	}
+}
+
+func TestTokensVerificarRejeitaFormatoInvalido(t *testing.T) {
+	tokens := NewTokens("segredo")
+	for _, token := range []string{"", "sem-ponto", "abc.def", "e30.xyz"} {
+		if _, err := tokens.Verificar(token); !errors.Is(err, ErrTokenInvalido) {
+			t.Fatalf("token %q deveria ser recusado, veio %v", token, err)
+		}
+	}
+}
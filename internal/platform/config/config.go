@@ -5,11 +5,17 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
 // Config agrega todos os parâmetros necessários para API e worker.
 type Config struct {
 	HTTPAddress string
+	// Prazos do servidor HTTP em segundos; SSE, websocket e exportações de auditoria ficam livres do de escrita.
+	HTTPReadTimeoutSeconds  int
+	HTTPWriteTimeoutSeconds int
+	HTTPIdleTimeoutSeconds  int
+	HTTPMaxBodyBytes        int64
 
 	PostgresHost     string
 	PostgresPort     string
@@ -29,34 +35,139 @@ type Config struct {
 	RateLimitMaxActions    int
 	RateLimitWindowSeconds int
 	RateLimitKeyPrefix     string
+	RateLimitAlgoritmo     string
+	RateLimitEscopo        string
+
+	CaptchaObrigatorio bool
+	CaptchaVerifyURL   string
+	CaptchaSecret      string
+	CaptchaSiteKey     string
+
+	PoliticaCacheSeconds int
+
+	EleitorTokenSecret string
+	VotoUnicoKeyPrefix string
+
+	VotoStatusKeyPrefix  string
+	VotoStatusTTLSeconds int
+
+	ReciboChaves string
+
+	AuditoriaCheckpointSeconds int
+	AuditoriaPseudonimoSegredo string
+
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	SessionSecret    string
 
 	AutoMigrate bool
 
+	LiveCadenciaMS       int
+	LiveKeepAliveSeconds int
+	LiveMaxConexoes      int
+	LiveRevalidacaoSecs  int
+	AvisosEnabled        bool
+	AvisosCanal          string
+	AvisosIntervaloMS    int
+	WSMensagensPorSeg    float64
+	WSRajada             int
+	WSOrigens            []string
+
+	AnomaliaEnabled       bool
+	AnomaliaJanelaSeconds int
+	AnomaliaAlpha         float64
+	AnomaliaLimiarZ       float64
+	AnomaliaMinVotos      int
+	AnomaliaKeyPrefix     string
+	AnomaliaWebhookURL    string
+
+	ViradasIntervaloSeconds int
+	ViradasMinVotos         int
+	// ViradasWebhookURL é descontinuado: o worker só avisa que o ignora. As viradas saem pelos webhooks cadastrados.
+	ViradasWebhookURL string
+
+	WebhooksIntervaloMS    int
+	WebhooksMaxTentativas  int
+	WebhooksBackoffSeconds int
+
+	FusoRelatorios string
+
 	WorkerMetricsAddress string
 	ConsultaToken        string
+	AdminToken           string
 }
 
 func Load() (Config, error) {
 	// Defaults priorizam execução local; variáveis permitem sobrescrever em Docker/K8s.
 	cfg := Config{
-		HTTPAddress:            getEnv("HTTP_ADDRESS", ":8080"),
-		PostgresHost:           getEnv("POSTGRES_HOST", "localhost"),
-		PostgresPort:           getEnv("POSTGRES_PORT", "5432"),
-		PostgresUser:           getEnv("POSTGRES_USER", "bbb"),
-		PostgresPassword:       getEnv("POSTGRES_PASSWORD", "bbb"),
-		PostgresDB:             getEnv("POSTGRES_DB", "bbb_votes"),
-		PostgresSSLMode:        getEnv("POSTGRES_SSLMODE", "disable"),
-		RedisAddr:              getEnv("REDIS_ADDR", "localhost:6379"),
-		RedisPassword:          os.Getenv("REDIS_PASSWORD"),
-		FilaKeyPrefix:          getEnv("REDIS_QUEUE_PREFIX", "fila:votos"),
-		ContadorKeyPrefix:      getEnv("REDIS_COUNTER_PREFIX", "contador"),
-		RateLimitEnabled:       getEnv("ANTIFRAUDE_RATE_LIMIT_ENABLED", "true") == "true",
-		RateLimitMaxActions:    getEnvAsInt("ANTIFRAUDE_RATE_LIMIT_MAX", 30),
-		RateLimitWindowSeconds: getEnvAsInt("ANTIFRAUDE_RATE_LIMIT_WINDOW", 60),
-		RateLimitKeyPrefix:     getEnv("ANTIFRAUDE_RATE_LIMIT_PREFIX", "ratelimit"),
-		AutoMigrate:            getEnvAsBool("DB_AUTO_MIGRATE", true),
-		WorkerMetricsAddress:   getEnv("WORKER_METRICS_ADDRESS", ":9090"),
-		ConsultaToken:          os.Getenv("CONSULTA_TOKEN"),
+		HTTPAddress:                getEnv("HTTP_ADDRESS", ":8080"),
+		HTTPReadTimeoutSeconds:     getEnvAsInt("HTTP_READ_TIMEOUT", 10),
+		HTTPWriteTimeoutSeconds:    getEnvAsInt("HTTP_WRITE_TIMEOUT", 30),
+		HTTPIdleTimeoutSeconds:     getEnvAsInt("HTTP_IDLE_TIMEOUT", 120),
+		HTTPMaxBodyBytes:           int64(getEnvAsInt("HTTP_MAX_BODY_BYTES", 1<<20)),
+		PostgresHost:               getEnv("POSTGRES_HOST", "localhost"),
+		PostgresPort:               getEnv("POSTGRES_PORT", "5432"),
+		PostgresUser:               getEnv("POSTGRES_USER", "bbb"),
+		PostgresPassword:           getEnv("POSTGRES_PASSWORD", "bbb"),
+		PostgresDB:                 getEnv("POSTGRES_DB", "bbb_votes"),
+		PostgresSSLMode:            getEnv("POSTGRES_SSLMODE", "disable"),
+		RedisAddr:                  getEnv("REDIS_ADDR", "localhost:6379"),
+		RedisPassword:              os.Getenv("REDIS_PASSWORD"),
+		FilaKeyPrefix:              getEnv("REDIS_QUEUE_PREFIX", "fila:votos"),
+		ContadorKeyPrefix:          getEnv("REDIS_COUNTER_PREFIX", "contador"),
+		RateLimitEnabled:           getEnv("ANTIFRAUDE_RATE_LIMIT_ENABLED", "true") == "true",
+		RateLimitMaxActions:        getEnvAsInt("ANTIFRAUDE_RATE_LIMIT_MAX", 30),
+		RateLimitWindowSeconds:     getEnvAsInt("ANTIFRAUDE_RATE_LIMIT_WINDOW", 60),
+		RateLimitKeyPrefix:         getEnv("ANTIFRAUDE_RATE_LIMIT_PREFIX", "ratelimit"),
+		RateLimitAlgoritmo:         getEnv("ANTIFRAUDE_RATE_LIMIT_ALGORITMO", "janela_fixa"),
+		RateLimitEscopo:            getEnv("ANTIFRAUDE_ESCOPO_BLOQUEIO", "paredao"),
+		CaptchaObrigatorio:         getEnvAsBool("ANTIFRAUDE_CAPTCHA_OBRIGATORIO", false),
+		CaptchaVerifyURL:           os.Getenv("ANTIFRAUDE_CAPTCHA_VERIFY_URL"),
+		CaptchaSecret:              os.Getenv("ANTIFRAUDE_CAPTCHA_SECRET"),
+		CaptchaSiteKey:             os.Getenv("ANTIFRAUDE_CAPTCHA_SITE_KEY"),
+		PoliticaCacheSeconds:       getEnvAsInt("ANTIFRAUDE_POLITICA_CACHE_TTL", 30),
+		EleitorTokenSecret:         os.Getenv("ELEITOR_TOKEN_SECRET"),
+		VotoUnicoKeyPrefix:         getEnv("VOTO_UNICO_PREFIX", "voto-unico"),
+		VotoStatusKeyPrefix:        getEnv("VOTO_STATUS_PREFIX", "voto-status"),
+		VotoStatusTTLSeconds:       getEnvAsInt("VOTO_STATUS_TTL", 3600),
+		ReciboChaves:               os.Getenv("RECIBO_CHAVES"),
+		AuditoriaCheckpointSeconds: getEnvAsInt("AUDITORIA_CHECKPOINT_INTERVALO", 60),
+		AuditoriaPseudonimoSegredo: os.Getenv("AUDITORIA_PSEUDONIMO_SEGREDO"),
+		OIDCIssuer:                 os.Getenv("OIDC_ISSUER"),
+		OIDCClientID:               getEnv("OIDC_CLIENT_ID", "votacao-bbb"),
+		OIDCClientSecret:           os.Getenv("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:            getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/auth/callback"),
+		SessionSecret:              os.Getenv("SESSION_SECRET"),
+		AutoMigrate:                getEnvAsBool("DB_AUTO_MIGRATE", true),
+		LiveCadenciaMS:             getEnvAsInt("LIVE_CADENCIA_MS", 1000),
+		LiveKeepAliveSeconds:       getEnvAsInt("LIVE_KEEPALIVE", 15),
+		LiveMaxConexoes:            getEnvAsInt("LIVE_MAX_CONEXOES", 5000),
+		LiveRevalidacaoSecs:        getEnvAsInt("LIVE_REVALIDACAO", 30),
+		AvisosEnabled:              getEnvAsBool("LIVE_AVISOS_ENABLED", true),
+		AvisosCanal:                getEnv("LIVE_AVISOS_CANAL", "parciais:atualizadas"),
+		AvisosIntervaloMS:          getEnvAsInt("LIVE_AVISOS_INTERVALO_MS", 500),
+		WSMensagensPorSeg:          getEnvAsFloat("WS_MENSAGENS_POR_SEGUNDO", 5),
+		WSRajada:                   getEnvAsInt("WS_RAJADA", 10),
+		WSOrigens:                  getEnvAsList("WS_ORIGENS"),
+		AnomaliaEnabled:            getEnvAsBool("ANOMALIA_ENABLED", true),
+		AnomaliaJanelaSeconds:      getEnvAsInt("ANOMALIA_JANELA", 60),
+		AnomaliaAlpha:              getEnvAsFloat("ANOMALIA_EWMA_ALPHA", 0.3),
+		AnomaliaLimiarZ:            getEnvAsFloat("ANOMALIA_LIMIAR_Z", 4),
+		AnomaliaMinVotos:           getEnvAsInt("ANOMALIA_MIN_VOTOS", 100),
+		AnomaliaKeyPrefix:          getEnv("ANOMALIA_PREFIX", "velocidade"),
+		AnomaliaWebhookURL:         os.Getenv("ANOMALIA_WEBHOOK_URL"),
+		ViradasIntervaloSeconds:    getEnvAsInt("VIRADAS_INTERVALO", 10),
+		ViradasMinVotos:            getEnvAsInt("VIRADAS_MIN_VOTOS", 100),
+		ViradasWebhookURL:          os.Getenv("VIRADAS_WEBHOOK_URL"),
+		WebhooksIntervaloMS:        getEnvAsInt("WEBHOOKS_INTERVALO_MS", 2000),
+		WebhooksMaxTentativas:      getEnvAsInt("WEBHOOKS_MAX_TENTATIVAS", 8),
+		WebhooksBackoffSeconds:     getEnvAsInt("WEBHOOKS_BACKOFF", 10),
+		FusoRelatorios:             getEnv("FUSO_RELATORIOS", "America/Sao_Paulo"),
+		WorkerMetricsAddress:       getEnv("WORKER_METRICS_ADDRESS", ":9090"),
+		ConsultaToken:              os.Getenv("CONSULTA_TOKEN"),
+		AdminToken:                 os.Getenv("ADMIN_TOKEN"),
 	}
 
 	dbStr := getEnv("REDIS_DB", "0")
@@ -66,6 +177,11 @@ func Load() (Config, error) {
 	}
 	cfg.RedisDB = dbInt
 
+	// Com janela zero o worker gravaria os contadores de velocidade sem expiração (ou os apagaria na hora).
+	if cfg.AnomaliaEnabled && cfg.AnomaliaJanelaSeconds <= 0 {
+		return Config{}, fmt.Errorf("config: ANOMALIA_JANELA deve ser maior que zero, veio %d (use ANOMALIA_ENABLED=false para desligar)", cfg.AnomaliaJanelaSeconds)
+	}
+
 	return cfg, nil
 }
 
@@ -101,6 +217,29 @@ func getEnvAsInt(key string, fallback int) int {
 	return i
 }
 
+func getEnvAsFloat(key string, fallback float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fallback
+	}
+	return f
+}
+
+// getEnvAsList separa a variável por vírgulas, descartando itens vazios.
+func getEnvAsList(key string) []string {
+	var itens []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			itens = append(itens, item)
+		}
+	}
+	return itens
+}
+
 func getEnvAsBool(key string, fallback bool) bool {
 	value := os.Getenv(key)
 	if value == "" {
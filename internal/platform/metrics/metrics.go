@@ -16,11 +16,48 @@ var (
 		Help: "Total de votos processados pelo worker",
 	})
 
+	voteDuplicatedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "bbb_vote_duplicated_total",
+		Help: "Total de votos unicos descartados pelo worker por duplicidade",
+	})
+
 	voteProcessingDuration = promauto.NewHistogram(prometheus.HistogramOpts{
 		Name:    "bbb_vote_processing_duration_seconds",
 		Help:    "Tempo para processar um voto no worker",
 		Buckets: prometheus.DefBuckets,
 	})
+
+	voteAnomaliesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bbb_vote_anomalies_total",
+		Help: "Total de janelas com velocidade de votos acima da linha de base",
+	}, []string{"paredao", "escopo"})
+
+	voteVelocityZScore = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "bbb_vote_velocity_zscore",
+		Help: "Ultimo z-score calculado para a velocidade de votos do paredao",
+	}, []string{"paredao"})
+
+	liveConnections = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "bbb_live_connections",
+		Help: "Conexoes de streaming de parciais abertas na instancia",
+	})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bbb_http_request_duration_seconds",
+		Help:    "Latencia das requisicoes HTTP por padrao de rota, metodo e status",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"rota", "metodo", "status"})
+
+	httpStreamDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bbb_http_stream_duration_seconds",
+		Help:    "Duracao das conexoes de streaming (SSE, websocket) e das exportacoes por rota e status",
+		Buckets: []float64{1, 10, 60, 300, 900, 1800, 3600, 7200, 14400},
+	}, []string{"rota", "status"})
+
+	wsMessagesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "bbb_ws_messages_total",
+		Help: "Total de mensagens recebidas pelo websocket por tipo ou motivo de recusa",
+	}, []string{"tipo"})
 )
 
 func ObserveVoteRequest(status string) {
@@ -31,6 +68,36 @@ func IncVoteProcessed() {
 	voteProcessedTotal.Inc()
 }
 
+func IncVoteDuplicated() {
+	voteDuplicatedTotal.Inc()
+}
+
 func ObserveProcessingDuration(seconds float64) {
 	voteProcessingDuration.Observe(seconds)
 }
+
+func IncVoteAnomaly(paredao, escopo string) {
+	voteAnomaliesTotal.WithLabelValues(paredao, escopo).Inc()
+}
+
+func SetVoteVelocityZScore(paredao string, z float64) {
+	voteVelocityZScore.WithLabelValues(paredao).Set(z)
+}
+
+func SetLiveConnections(n int) {
+	liveConnections.Set(float64(n))
+}
+
+func ObserveWebSocketMessage(tipo string) {
+	wsMessagesTotal.WithLabelValues(tipo).Inc()
+}
+
+// ObserveHTTPRequest recebe o padrão de rota do mux, nunca o caminho bruto, para manter a cardinalidade baixa.
+func ObserveHTTPRequest(rota, metodo, status string, seconds float64) {
+	httpRequestDuration.WithLabelValues(rota, metodo, status).Observe(seconds)
+}
+
+// ObserveHTTPStream registra a duração das rotas que mantêm a resposta aberta, fora do histograma de latência.
+func ObserveHTTPStream(rota, status string, seconds float64) {
+	httpStreamDuration.WithLabelValues(rota, status).Observe(seconds)
+}
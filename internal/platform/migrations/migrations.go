@@ -11,6 +11,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/auditoria"
 	"github.com/marcelojr/desafio-globo/internal/platform/ids"
 )
 
@@ -79,6 +80,166 @@ func Run(db *gorm.DB) error {
 				return nil
 			},
 		},
+		{
+			ID: "202411050001_paredao_limiares_anomalia",
+			Migrate: func(tx *gorm.DB) error {
+				// AutoMigrate só adiciona as colunas novas; bancos criados do zero já recebem o schema completo.
+				return tx.AutoMigrate(&domain.Paredao{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropColumn(&domain.Paredao{}, "anomalia_limiar_z"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&domain.Paredao{}, "anomalia_min_votos")
+			},
+		},
+		{
+			ID: "202411060001_paredao_politica_antifraude",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&domain.Paredao{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				for _, coluna := range []string{
+					"antifraude_limite",
+					"antifraude_janela_segundos",
+					"antifraude_algoritmo",
+					"antifraude_exige_captcha",
+					"antifraude_escopo_bloqueio",
+				} {
+					if err := tx.Migrator().DropColumn(&domain.Paredao{}, coluna); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			ID: "202411070001_voto_unico_eleitor",
+			Migrate: func(tx *gorm.DB) error {
+				// Cria eleitor_id/modalidade em votos, modo_votacao em paredoes e o índice único parcial do voto único.
+				return tx.AutoMigrate(&domain.Paredao{}, &domain.Voto{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropIndex(&domain.Voto{}, "idx_votos_voto_unico"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().DropColumn(&domain.Voto{}, "eleitor_id"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().DropColumn(&domain.Voto{}, "modalidade"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&domain.Paredao{}, "modo_votacao")
+			},
+		},
+		{
+			ID: "202411080001_paredao_pesos_modalidade",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&domain.Paredao{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropColumn(&domain.Paredao{}, "peso_unico"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&domain.Paredao{}, "peso_torcida")
+			},
+		},
+		{
+			ID: "202411090001_paredao_polaridade",
+			Migrate: func(tx *gorm.DB) error {
+				// Paredões existentes assumem o default 'eliminar', que era o comportamento implícito.
+				return tx.AutoMigrate(&domain.Paredao{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropColumn(&domain.Paredao{}, "polaridade")
+			},
+		},
+		{
+			ID: "202411100001_paredao_etapas",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&domain.Paredao{}, &domain.Participante{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropIndex(&domain.Paredao{}, "idx_paredoes_grupo_etapa"); err != nil {
+					return err
+				}
+				for _, coluna := range []string{"grupo_id", "etapa", "etapa_nome"} {
+					if err := tx.Migrator().DropColumn(&domain.Paredao{}, coluna); err != nil {
+						return err
+					}
+				}
+				return tx.Migrator().DropColumn(&domain.Participante{}, "origem_id")
+			},
+		},
+		{
+			ID: "202411110001_participante_retirada",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&domain.Paredao{}, &domain.Participante{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				for _, coluna := range []string{"retirada", "retirado_em"} {
+					if err := tx.Migrator().DropColumn(&domain.Participante{}, coluna); err != nil {
+						return err
+					}
+				}
+				return tx.Migrator().DropColumn(&domain.Paredao{}, "anulado")
+			},
+		},
+		{
+			ID: "202411120001_paredao_visibilidade",
+			Migrate: func(tx *gorm.DB) error {
+				// Paredões existentes seguem públicos, como sempre foram.
+				return tx.AutoMigrate(&domain.Paredao{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropColumn(&domain.Paredao{}, "visibilidade_modo"); err != nil {
+					return err
+				}
+				return tx.Migrator().DropColumn(&domain.Paredao{}, "visibilidade_atraso_minutos")
+			},
+		},
+		{
+			ID: "202411130001_votos_cadeia_auditoria",
+			Migrate: func(tx *gorm.DB) error {
+				if err := tx.AutoMigrate(&domain.Voto{}, &domain.CadeiaVotos{}, &domain.Checkpoint{}); err != nil {
+					return err
+				}
+				// Votos gravados antes da cadeia entram nela em ordem de ID (ULID, portanto de chegada).
+				return encadearVotosExistentes(tx)
+			},
+			Rollback: func(tx *gorm.DB) error {
+				if err := tx.Migrator().DropTable("checkpoints_votos", "cadeias_votos"); err != nil {
+					return err
+				}
+				if err := tx.Migrator().DropIndex(&domain.Voto{}, "idx_votos_paredao_seq"); err != nil {
+					return err
+				}
+				for _, coluna := range []string{"seq", "hash"} {
+					if err := tx.Migrator().DropColumn(&domain.Voto{}, coluna); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			ID: "202411200001_viradas",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&domain.Virada{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("viradas")
+			},
+		},
+		{
+			ID: "202411250001_webhooks",
+			Migrate: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&domain.Webhook{}, &domain.EventoParedao{}, &domain.EntregaWebhook{})
+			},
+			Rollback: func(tx *gorm.DB) error {
+				return tx.Migrator().DropTable("entregas_webhook", "eventos_paredao", "webhooks")
+			},
+		},
 	})
 
 	if err := m.Migrate(); err != nil {
@@ -88,6 +249,44 @@ func Run(db *gorm.DB) error {
 	return nil
 }
 
+// encadearVotosExistentes monta a cadeia de auditoria dos paredões que já tinham votos, em lotes.
+func encadearVotosExistentes(tx *gorm.DB) error {
+	var paredoes []domain.ParedaoID
+	if err := tx.Model(&domain.Voto{}).Distinct("paredao_id").Where("seq = 0").Pluck("paredao_id", &paredoes).Error; err != nil {
+		return err
+	}
+	// As atualizações usam uma sessão limpa para não herdar filtro, ordem e limite dos lotes.
+	db := tx.Session(&gorm.Session{NewDB: true})
+	for _, paredaoID := range paredoes {
+		var cadeia auditoria.Cadeia
+		var lote []domain.Voto
+		err := tx.Where("paredao_id = ?", paredaoID).FindInBatches(&lote, 1000, func(_ *gorm.DB, _ int) error {
+			for _, v := range lote {
+				seq, elo := cadeia.Anexar(auditoria.Folha(domain.VotoAuditado{
+					VotoID:         v.ID,
+					ParedaoID:      v.ParedaoID,
+					ParticipanteID: v.ParticipanteID,
+					Modalidade:     v.Modalidade,
+					CriadoEm:       v.CriadoEm,
+				}))
+				if err := db.Model(&domain.Voto{}).Where("id = ?", v.ID).Updates(map[string]any{"seq": seq, "hash": elo.String()}).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+		if err != nil {
+			return err
+		}
+		ponta := cadeia.Ponta(paredaoID)
+		ponta.AtualizadoEm = time.Now()
+		if err := db.Create(&ponta).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // getEnv retorna variável de ambiente ou valor default
 func getEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
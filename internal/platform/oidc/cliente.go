@@ -0,0 +1,259 @@
+// Pacote oidc implementa o lado relying party do OpenID Connect (authorization code + PKCE) usado no login do eleitor.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrIDTokenInvalido = errors.New("oidc: id_token invalido")
+	ErrTrocaRecusada   = errors.New("oidc: troca do codigo recusada pelo provedor")
+)
+
+// Config descreve o client registrado no provedor de identidade.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	Timeout      time.Duration
+}
+
+// Descoberta guarda os endpoints publicados em /.well-known/openid-configuration.
+type Descoberta struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// Autorizacao reúne os valores gerados no início do login que precisam voltar intactos no callback.
+type Autorizacao struct {
+	State       string `json:"state"`
+	Nonce       string `json:"nonce"`
+	Verificador string `json:"verifier"`
+}
+
+// Identidade é o resultado do login: claims já verificadas e o id_token bruto (usado como hint no logout).
+type Identidade struct {
+	Claims  Claims
+	IDToken string
+}
+
+// Cliente conversa com o provedor; a descoberta é preguiçosa para a API subir mesmo com o IdP fora do ar.
+type Cliente struct {
+	cfg   Config
+	http  *http.Client
+	agora func() time.Time
+
+	mu         sync.Mutex
+	descoberta *Descoberta
+	chaves     *conjuntoChaves
+}
+
+func NewCliente(cfg Config) *Cliente {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "profile", "email"}
+	}
+	cfg.Issuer = strings.TrimRight(cfg.Issuer, "/")
+	return &Cliente{
+		cfg:   cfg,
+		http:  &http.Client{Timeout: cfg.Timeout},
+		agora: time.Now,
+	}
+}
+
+// NovaAutorizacao sorteia state, nonce e o code_verifier do PKCE.
+func NovaAutorizacao() (Autorizacao, error) {
+	var a Autorizacao
+	for _, campo := range []*string{&a.State, &a.Nonce, &a.Verificador} {
+		valor, err := aleatorio(32)
+		if err != nil {
+			return Autorizacao{}, fmt.Errorf("oidc: gerar autorizacao: %w", err)
+		}
+		*campo = valor
+	}
+	return a, nil
+}
+
+// DesafioPKCE calcula o code_challenge S256 a partir do verificador.
+func DesafioPKCE(verificador string) string {
+	soma := sha256.Sum256([]byte(verificador))
+	return base64.RawURLEncoding.EncodeToString(soma[:])
+}
+
+// URLAutorizacao monta o redirecionamento para a tela de login do provedor.
+func (c *Cliente) URLAutorizacao(ctx context.Context, a Autorizacao) (string, error) {
+	d, err := c.descobrir(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {c.cfg.ClientID},
+		"redirect_uri":          {c.cfg.RedirectURL},
+		"scope":                 {strings.Join(c.cfg.Scopes, " ")},
+		"state":                 {a.State},
+		"nonce":                 {a.Nonce},
+		"code_challenge":        {DesafioPKCE(a.Verificador)},
+		"code_challenge_method": {"S256"},
+	}
+	return juntarQuery(d.AuthorizationEndpoint, q), nil
+}
+
+// Autenticar troca o código pelo id_token e valida assinatura, emissor, audiência, validade e nonce.
+func (c *Cliente) Autenticar(ctx context.Context, codigo string, a Autorizacao) (Identidade, error) {
+	d, err := c.descobrir(ctx)
+	if err != nil {
+		return Identidade{}, err
+	}
+
+	idToken, err := c.trocar(ctx, d, codigo, a.Verificador)
+	if err != nil {
+		return Identidade{}, err
+	}
+
+	claims, err := c.verificar(ctx, d, idToken, a.Nonce)
+	if err != nil {
+		return Identidade{}, err
+	}
+	return Identidade{Claims: claims, IDToken: idToken}, nil
+}
+
+// URLLogout devolve o endpoint de encerramento de sessão do provedor, ou vazio quando ele não oferece um.
+func (c *Cliente) URLLogout(ctx context.Context, idTokenHint, destino string) string {
+	d, err := c.descobrir(ctx)
+	if err != nil || d.EndSessionEndpoint == "" {
+		return ""
+	}
+	q := url.Values{"client_id": {c.cfg.ClientID}}
+	if idTokenHint != "" {
+		q.Set("id_token_hint", idTokenHint)
+	}
+	if destino != "" {
+		q.Set("post_logout_redirect_uri", destino)
+	}
+	return juntarQuery(d.EndSessionEndpoint, q)
+}
+
+func (c *Cliente) descobrir(ctx context.Context) (*Descoberta, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.descoberta != nil {
+		return c.descoberta, nil
+	}
+
+	var d Descoberta
+	if err := c.getJSON(ctx, c.cfg.Issuer+"/.well-known/openid-configuration", &d); err != nil {
+		return nil, fmt.Errorf("oidc: descoberta: %w", err)
+	}
+	// O emissor anunciado precisa bater com o configurado; caso contrário tokens de outro IdP seriam aceitos.
+	if strings.TrimRight(d.Issuer, "/") != c.cfg.Issuer {
+		return nil, fmt.Errorf("oidc: descoberta: emissor %q diferente do configurado %q", d.Issuer, c.cfg.Issuer)
+	}
+	if d.AuthorizationEndpoint == "" || d.TokenEndpoint == "" || d.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc: descoberta: endpoints obrigatorios ausentes")
+	}
+
+	c.descoberta = &d
+	c.chaves = &conjuntoChaves{url: d.JWKSURI}
+	return c.descoberta, nil
+}
+
+type respostaToken struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	TokenType        string `json:"token_type"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (c *Cliente) trocar(ctx context.Context, d *Descoberta, codigo, verificador string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {codigo},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"client_id":     {c.cfg.ClientID},
+		"code_verifier": {verificador},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("oidc: montar troca do codigo: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(c.cfg.ClientID), url.QueryEscape(c.cfg.ClientSecret))
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("oidc: trocar codigo: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var corpo respostaToken
+	if err := json.NewDecoder(resp.Body).Decode(&corpo); err != nil {
+		return "", fmt.Errorf("oidc: resposta do token endpoint invalida: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || corpo.Error != "" {
+		return "", fmt.Errorf("%w: status %d %s %s", ErrTrocaRecusada, resp.StatusCode, corpo.Error, corpo.ErrorDescription)
+	}
+	if corpo.IDToken == "" {
+		return "", fmt.Errorf("%w: resposta sem id_token", ErrIDTokenInvalido)
+	}
+	return corpo.IDToken, nil
+}
+
+func (c *Cliente) getJSON(ctx context.Context, endpoint string, destino any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status inesperado %d em %s", resp.StatusCode, endpoint)
+	}
+	return json.NewDecoder(resp.Body).Decode(destino)
+}
+
+func juntarQuery(endpoint string, q url.Values) string {
+	sep := "?"
+	if strings.Contains(endpoint, "?") {
+		sep = "&"
+	}
+	return endpoint + sep + q.Encode()
+}
+
+func aleatorio(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
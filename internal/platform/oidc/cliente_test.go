@@ -0,0 +1,158 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/platform/oidc/mockidp"
+)
+
+const (
+	clientID     = "votacao-bbb"
+	clientSecret = "segredo"
+	redirectURL  = "http://app.local/auth/callback"
+)
+
+func novoIdP(t *testing.T) (*mockidp.Servidor, string) {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(nil)
+	issuer := "http://" + srv.Listener.Addr().String()
+	idp, err := mockidp.New(issuer, clientID, clientSecret)
+	require.NoError(t, err)
+	srv.Config.Handler = idp.Handler()
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return idp, issuer
+}
+
+// autorizar segue o redirecionamento do IdP como o navegador faria e devolve o code recebido no callback.
+func autorizar(t *testing.T, c *Cliente, a Autorizacao, usuario string) url.Values {
+	t.Helper()
+	destino, err := c.URLAutorizacao(context.Background(), a)
+	require.NoError(t, err)
+
+	navegador := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := navegador.Get(destino + "&login_hint=" + url.QueryEscape(usuario))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return callback.Query()
+}
+
+func TestCliente_QuandoFluxoCompleto_DeveDevolverClaimsVerificadas(t *testing.T) {
+	_, issuer := novoIdP(t)
+	c := NewCliente(Config{Issuer: issuer, ClientID: clientID, ClientSecret: clientSecret, RedirectURL: redirectURL})
+
+	a, err := NovaAutorizacao()
+	require.NoError(t, err)
+	callback := autorizar(t, c, a, "Ana")
+	assert.Equal(t, a.State, callback.Get("state"))
+
+	identidade, err := c.Autenticar(context.Background(), callback.Get("code"), a)
+	require.NoError(t, err)
+	assert.Equal(t, "mock|ana", identidade.Claims.Subject)
+	assert.Equal(t, "Ana", identidade.Claims.Nome)
+	assert.Equal(t, a.Nonce, identidade.Claims.Nonce)
+	assert.NotEmpty(t, identidade.IDToken)
+
+	// O código é de uso único.
+	_, err = c.Autenticar(context.Background(), callback.Get("code"), a)
+	assert.ErrorIs(t, err, ErrTrocaRecusada)
+}
+
+func TestCliente_QuandoVerificadorPKCEDiferente_DeveRecusarTroca(t *testing.T) {
+	_, issuer := novoIdP(t)
+	c := NewCliente(Config{Issuer: issuer, ClientID: clientID, ClientSecret: clientSecret, RedirectURL: redirectURL})
+
+	a, err := NovaAutorizacao()
+	require.NoError(t, err)
+	callback := autorizar(t, c, a, "ana")
+
+	outra := a
+	outra.Verificador = "verificador-de-outra-sessao"
+	_, err = c.Autenticar(context.Background(), callback.Get("code"), outra)
+	assert.ErrorIs(t, err, ErrTrocaRecusada)
+}
+
+func TestCliente_QuandoNonceNaoConfere_DeveRecusarIDToken(t *testing.T) {
+	_, issuer := novoIdP(t)
+	c := NewCliente(Config{Issuer: issuer, ClientID: clientID, ClientSecret: clientSecret, RedirectURL: redirectURL})
+
+	a, err := NovaAutorizacao()
+	require.NoError(t, err)
+	callback := autorizar(t, c, a, "ana")
+
+	outra := a
+	outra.Nonce = "nonce-de-outra-sessao"
+	_, err = c.Autenticar(context.Background(), callback.Get("code"), outra)
+	assert.ErrorIs(t, err, ErrIDTokenInvalido)
+}
+
+func TestCliente_QuandoIDTokenAdulterado_DeveRecusar(t *testing.T) {
+	idp, issuer := novoIdP(t)
+	c := NewCliente(Config{Issuer: issuer, ClientID: clientID, ClientSecret: clientSecret, RedirectURL: redirectURL})
+	d, err := c.descobrir(context.Background())
+	require.NoError(t, err)
+
+	agora := time.Now()
+	valido := map[string]any{"iss": issuer, "sub": "mock|ana", "aud": clientID, "iat": agora.Unix(), "exp": agora.Add(time.Minute).Unix()}
+	token, err := idp.Assinar(valido)
+	require.NoError(t, err)
+	_, err = c.verificar(context.Background(), d, token, "")
+	require.NoError(t, err)
+
+	casos := map[string]map[string]any{
+		"outra audiencia": {"iss": issuer, "sub": "mock|ana", "aud": "outro-client", "exp": agora.Add(time.Minute).Unix()},
+		"outro emissor":   {"iss": "http://evil.local", "sub": "mock|ana", "aud": clientID, "exp": agora.Add(time.Minute).Unix()},
+		"expirado":        {"iss": issuer, "sub": "mock|ana", "aud": clientID, "exp": agora.Add(-time.Hour).Unix()},
+	}
+	for nome, claims := range casos {
+		token, err := idp.Assinar(claims)
+		require.NoError(t, err)
+		_, err = c.verificar(context.Background(), d, token, "")
+		assert.True(t, errors.Is(err, ErrIDTokenInvalido), "%s: esperava ErrIDTokenInvalido, veio %v", nome, err)
+	}
+
+	// Troca do payload mantendo a assinatura original.
+	outro, err := idp.Assinar(map[string]any{"iss": issuer, "sub": "mock|admin", "aud": clientID, "exp": agora.Add(time.Minute).Unix()})
+	require.NoError(t, err)
+	partesValido, partesOutro := strings.Split(token, "."), strings.Split(outro, ".")
+	adulterado := partesValido[0] + "." + partesOutro[1] + "." + partesValido[2]
+	_, err = c.verificar(context.Background(), d, adulterado, "")
+	assert.ErrorIs(t, err, ErrIDTokenInvalido)
+
+	_, err = c.verificar(context.Background(), d, "eyJhbGciOiJub25lIn0.e30.", "")
+	assert.ErrorIs(t, err, ErrIDTokenInvalido)
+}
+
+func TestCliente_QuandoEmissorDivergente_DeveFalharDescoberta(t *testing.T) {
+	_, issuer := novoIdP(t)
+	c := NewCliente(Config{Issuer: issuer + "/outro", ClientID: clientID, RedirectURL: redirectURL})
+
+	_, err := c.URLAutorizacao(context.Background(), Autorizacao{})
+	assert.Error(t, err)
+}
+
+func TestCliente_QuandoLogout_DeveIncluirDestino(t *testing.T) {
+	_, issuer := novoIdP(t)
+	c := NewCliente(Config{Issuer: issuer, ClientID: clientID, RedirectURL: redirectURL})
+
+	destino := c.URLLogout(context.Background(), "token", "http://app.local/vote")
+	u, err := url.Parse(destino)
+	require.NoError(t, err)
+	assert.Equal(t, "/logout", u.Path)
+	assert.Equal(t, "http://app.local/vote", u.Query().Get("post_logout_redirect_uri"))
+	assert.Equal(t, "token", u.Query().Get("id_token_hint"))
+}
@@ -0,0 +1,202 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// toleranciaRelogio absorve pequenas diferenças de horário entre a API e o provedor.
+const toleranciaRelogio = time.Minute
+
+// Claims são os campos do id_token que a aplicação consome.
+type Claims struct {
+	Issuer    string    `json:"iss"`
+	Subject   string    `json:"sub"`
+	Audience  Audiencia `json:"aud"`
+	Expira    int64     `json:"exp"`
+	EmitidoEm int64     `json:"iat"`
+	Nonce     string    `json:"nonce,omitempty"`
+	Nome      string    `json:"name,omitempty"`
+	Email     string    `json:"email,omitempty"`
+}
+
+// Audiencia aceita tanto a forma string quanto a lista prevista na especificação.
+type Audiencia []string
+
+func (a *Audiencia) UnmarshalJSON(b []byte) error {
+	var unica string
+	if err := json.Unmarshal(b, &unica); err == nil {
+		*a = Audiencia{unica}
+		return nil
+	}
+	var lista []string
+	if err := json.Unmarshal(b, &lista); err != nil {
+		return err
+	}
+	*a = lista
+	return nil
+}
+
+func (a Audiencia) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+	return json.Marshal([]string(a))
+}
+
+func (a Audiencia) contem(valor string) bool {
+	for _, item := range a {
+		if item == valor {
+			return true
+		}
+	}
+	return false
+}
+
+type cabecalhoJWT struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ,omitempty"`
+}
+
+func (c *Cliente) verificar(ctx context.Context, d *Descoberta, token, nonce string) (Claims, error) {
+	partes := strings.Split(token, ".")
+	if len(partes) != 3 {
+		return Claims{}, fmt.Errorf("%w: formato", ErrIDTokenInvalido)
+	}
+
+	var cab cabecalhoJWT
+	if err := decodificarSegmento(partes[0], &cab); err != nil {
+		return Claims{}, fmt.Errorf("%w: cabecalho: %v", ErrIDTokenInvalido, err)
+	}
+	// Só aceitamos RS256: evita "alg: none" e confusão de algoritmo com chaves simétricas.
+	if cab.Alg != "RS256" {
+		return Claims{}, fmt.Errorf("%w: algoritmo %q nao suportado", ErrIDTokenInvalido, cab.Alg)
+	}
+
+	chave, err := c.chaves.buscar(ctx, c, cab.Kid)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	assinatura, err := base64.RawURLEncoding.DecodeString(partes[2])
+	if err != nil {
+		return Claims{}, fmt.Errorf("%w: assinatura: %v", ErrIDTokenInvalido, err)
+	}
+	soma := sha256.Sum256([]byte(partes[0] + "." + partes[1]))
+	if err := rsa.VerifyPKCS1v15(chave, crypto.SHA256, soma[:], assinatura); err != nil {
+		return Claims{}, fmt.Errorf("%w: assinatura nao confere", ErrIDTokenInvalido)
+	}
+
+	var claims Claims
+	if err := decodificarSegmento(partes[1], &claims); err != nil {
+		return Claims{}, fmt.Errorf("%w: payload: %v", ErrIDTokenInvalido, err)
+	}
+
+	agora := c.agora()
+	switch {
+	case strings.TrimRight(claims.Issuer, "/") != strings.TrimRight(d.Issuer, "/"):
+		return Claims{}, fmt.Errorf("%w: emissor %q", ErrIDTokenInvalido, claims.Issuer)
+	case !claims.Audience.contem(c.cfg.ClientID):
+		return Claims{}, fmt.Errorf("%w: audiencia nao inclui o client", ErrIDTokenInvalido)
+	case claims.Subject == "":
+		return Claims{}, fmt.Errorf("%w: sub vazio", ErrIDTokenInvalido)
+	case agora.After(time.Unix(claims.Expira, 0).Add(toleranciaRelogio)):
+		return Claims{}, fmt.Errorf("%w: expirado", ErrIDTokenInvalido)
+	case claims.EmitidoEm != 0 && time.Unix(claims.EmitidoEm, 0).After(agora.Add(toleranciaRelogio)):
+		return Claims{}, fmt.Errorf("%w: emitido no futuro", ErrIDTokenInvalido)
+	case nonce != "" && claims.Nonce != nonce:
+		return Claims{}, fmt.Errorf("%w: nonce nao confere", ErrIDTokenInvalido)
+	}
+	return claims, nil
+}
+
+func decodificarSegmento(segmento string, destino any) error {
+	bruto, err := base64.RawURLEncoding.DecodeString(segmento)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bruto, destino)
+}
+
+// jwk é a representação pública de uma chave RSA publicada no jwks_uri.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// jwks é o documento servido pelo jwks_uri.
+type jwks struct {
+	Keys []jwk `json:"keys"`
+}
+
+func (j jwk) chavePublica() (*rsa.PublicKey, error) {
+	if j.Kty != "RSA" {
+		return nil, fmt.Errorf("tipo de chave %q nao suportado", j.Kty)
+	}
+	n, err := base64.RawURLEncoding.DecodeString(j.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(j.E)
+	if err != nil {
+		return nil, err
+	}
+	expoente := new(big.Int).SetBytes(e)
+	if !expoente.IsInt64() || expoente.Int64() < 3 {
+		return nil, fmt.Errorf("expoente invalido")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(expoente.Int64())}, nil
+}
+
+// conjuntoChaves mantém o JWKS em memória e o recarrega quando surge um kid desconhecido (rotação de chaves).
+type conjuntoChaves struct {
+	url string
+
+	mu     sync.Mutex
+	chaves map[string]*rsa.PublicKey
+}
+
+func (s *conjuntoChaves) buscar(ctx context.Context, c *Cliente, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if chave, ok := s.chaves[kid]; ok {
+		return chave, nil
+	}
+
+	var doc jwks
+	if err := c.getJSON(ctx, s.url, &doc); err != nil {
+		return nil, fmt.Errorf("oidc: carregar jwks: %w", err)
+	}
+	s.chaves = make(map[string]*rsa.PublicKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		chave, err := k.chavePublica()
+		if err != nil {
+			continue
+		}
+		s.chaves[k.Kid] = chave
+	}
+
+	chave, ok := s.chaves[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: chave %q nao publicada", ErrIDTokenInvalido, kid)
+	}
+	return chave, nil
+}
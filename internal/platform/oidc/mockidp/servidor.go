@@ -0,0 +1,319 @@
+// Pacote mockidp é um provedor OpenID Connect mínimo para desenvolvimento local e testes.
+// Aceita qualquer nome de usuário, sem senha; nunca deve ser exposto em produção.
+package mockidp
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	validadeCodigo  = time.Minute
+	validadeIDToken = 10 * time.Minute
+)
+
+// Servidor emite códigos e id_tokens RS256 para um único client registrado.
+type Servidor struct {
+	issuer       string
+	clientID     string
+	clientSecret string
+	chave        *rsa.PrivateKey
+	kid          string
+	agora        func() time.Time
+
+	mu      sync.Mutex
+	codigos map[string]codigoPendente
+}
+
+type codigoPendente struct {
+	usuario     string
+	redirectURI string
+	nonce       string
+	desafio     string
+	expira      time.Time
+}
+
+func New(issuer, clientID, clientSecret string) (*Servidor, error) {
+	chave, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("mockidp: gerar chave: %w", err)
+	}
+	kid, err := aleatorio(8)
+	if err != nil {
+		return nil, fmt.Errorf("mockidp: gerar kid: %w", err)
+	}
+	return &Servidor{
+		issuer:       strings.TrimRight(issuer, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		chave:        chave,
+		kid:          kid,
+		agora:        time.Now,
+		codigos:      make(map[string]codigoPendente),
+	}, nil
+}
+
+// Handler expõe descoberta, autorização, token, JWKS e logout.
+func (s *Servidor) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.handleDescoberta)
+	mux.HandleFunc("/authorize", s.handleAutorizar)
+	mux.HandleFunc("/token", s.handleToken)
+	mux.HandleFunc("/jwks", s.handleJWKS)
+	mux.HandleFunc("/logout", s.handleLogout)
+	return mux
+}
+
+func (s *Servidor) handleDescoberta(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                s.issuer,
+		"authorization_endpoint":                s.issuer + "/authorize",
+		"token_endpoint":                        s.issuer + "/token",
+		"jwks_uri":                              s.issuer + "/jwks",
+		"end_session_endpoint":                  s.issuer + "/logout",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post"},
+	})
+}
+
+func (s *Servidor) handleJWKS(w http.ResponseWriter, _ *http.Request) {
+	pub := s.chave.PublicKey
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": s.kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}},
+	})
+}
+
+var telaLogin = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="pt-BR">
+<head><meta charset="UTF-8"><title>Mock IdP</title></head>
+<body style="font-family: sans-serif; max-width: 420px; margin: 4rem auto;">
+<h1>Mock IdP</h1>
+<p>Provedor de identidade de desenvolvimento: informe qualquer nome para entrar.</p>
+<form method="post" action="/authorize">
+    {{range $k, $v := .}}<input type="hidden" name="{{$k}}" value="{{index $v 0}}">{{end}}
+    <label>Usuário <input name="login_hint" autofocus required></label>
+    <button type="submit">Entrar</button>
+</form>
+</body>
+</html>`))
+
+// handleAutorizar mostra a tela de login no GET; com login_hint (query ou formulário) aprova direto.
+func (s *Servidor) handleAutorizar(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "requisicao invalida", http.StatusBadRequest)
+		return
+	}
+
+	redirectURI := r.Form.Get("redirect_uri")
+	destino, err := url.Parse(redirectURI)
+	if err != nil || redirectURI == "" || !destino.IsAbs() {
+		http.Error(w, "redirect_uri invalido", http.StatusBadRequest)
+		return
+	}
+	switch {
+	case r.Form.Get("client_id") != s.clientID:
+		http.Error(w, "client_id desconhecido", http.StatusBadRequest)
+		return
+	case r.Form.Get("response_type") != "code":
+		redirecionarErro(w, r, destino, "unsupported_response_type")
+		return
+	case r.Form.Get("code_challenge") == "" || r.Form.Get("code_challenge_method") != "S256":
+		redirecionarErro(w, r, destino, "invalid_request")
+		return
+	}
+
+	usuario := strings.TrimSpace(r.Form.Get("login_hint"))
+	if usuario == "" {
+		params := url.Values{}
+		for _, k := range []string{"client_id", "redirect_uri", "response_type", "scope", "state", "nonce", "code_challenge", "code_challenge_method"} {
+			params.Set(k, r.Form.Get(k))
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = telaLogin.Execute(w, params)
+		return
+	}
+
+	codigo, err := aleatorio(24)
+	if err != nil {
+		http.Error(w, "falha ao gerar codigo", http.StatusInternalServerError)
+		return
+	}
+	s.mu.Lock()
+	s.codigos[codigo] = codigoPendente{
+		usuario:     usuario,
+		redirectURI: redirectURI,
+		nonce:       r.Form.Get("nonce"),
+		desafio:     r.Form.Get("code_challenge"),
+		expira:      s.agora().Add(validadeCodigo),
+	}
+	s.mu.Unlock()
+
+	q := destino.Query()
+	q.Set("code", codigo)
+	if state := r.Form.Get("state"); state != "" {
+		q.Set("state", state)
+	}
+	destino.RawQuery = q.Encode()
+	http.Redirect(w, r, destino.String(), http.StatusFound)
+}
+
+func (s *Servidor) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		erroToken(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	if !s.clientAutenticado(r) {
+		erroToken(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "authorization_code" {
+		erroToken(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	// Código é de uso único: removemos antes de validar para impedir replay mesmo em caso de erro.
+	s.mu.Lock()
+	pendente, ok := s.codigos[r.PostForm.Get("code")]
+	delete(s.codigos, r.PostForm.Get("code"))
+	s.mu.Unlock()
+
+	agora := s.agora()
+	if !ok || agora.After(pendente.expira) || pendente.redirectURI != r.PostForm.Get("redirect_uri") {
+		erroToken(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+	soma := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(soma[:]) != pendente.desafio {
+		erroToken(w, http.StatusBadRequest, "invalid_grant")
+		return
+	}
+
+	claims := map[string]any{
+		"iss":   s.issuer,
+		"sub":   "mock|" + strings.ToLower(pendente.usuario),
+		"aud":   s.clientID,
+		"iat":   agora.Unix(),
+		"exp":   agora.Add(validadeIDToken).Unix(),
+		"name":  pendente.usuario,
+		"email": strings.ToLower(pendente.usuario) + "@mockidp.local",
+	}
+	if pendente.nonce != "" {
+		claims["nonce"] = pendente.nonce
+	}
+	idToken, err := s.assinar(claims)
+	if err != nil {
+		erroToken(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	accessToken, err := aleatorio(24)
+	if err != nil {
+		erroToken(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int(validadeIDToken.Seconds()),
+		"id_token":     idToken,
+	})
+}
+
+func (s *Servidor) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if destino := r.URL.Query().Get("post_logout_redirect_uri"); destino != "" {
+		http.Redirect(w, r, destino, http.StatusFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("sessao encerrada"))
+}
+
+func (s *Servidor) clientAutenticado(r *http.Request) bool {
+	id, segredo, ok := r.BasicAuth()
+	if ok {
+		id, _ = url.QueryUnescape(id)
+		segredo, _ = url.QueryUnescape(segredo)
+	} else {
+		id, segredo = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	return id == s.clientID && subtle.ConstantTimeCompare([]byte(segredo), []byte(s.clientSecret)) == 1
+}
+
+// Assinar emite um JWT RS256 com a chave do servidor; exposto para testes montarem tokens arbitrários.
+func (s *Servidor) Assinar(claims map[string]any) (string, error) {
+	return s.assinar(claims)
+}
+
+func (s *Servidor) assinar(claims map[string]any) (string, error) {
+	cab, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": s.kid})
+	if err != nil {
+		return "", err
+	}
+	corpo, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	entrada := base64.RawURLEncoding.EncodeToString(cab) + "." + base64.RawURLEncoding.EncodeToString(corpo)
+	soma := sha256.Sum256([]byte(entrada))
+	assinatura, err := rsa.SignPKCS1v15(rand.Reader, s.chave, crypto.SHA256, soma[:])
+	if err != nil {
+		return "", err
+	}
+	return entrada + "." + base64.RawURLEncoding.EncodeToString(assinatura), nil
+}
+
+func redirecionarErro(w http.ResponseWriter, r *http.Request, destino *url.URL, codigo string) {
+	q := destino.Query()
+	q.Set("error", codigo)
+	if state := r.Form.Get("state"); state != "" {
+		q.Set("state", state)
+	}
+	destino.RawQuery = q.Encode()
+	http.Redirect(w, r, destino.String(), http.StatusFound)
+}
+
+func erroToken(w http.ResponseWriter, status int, codigo string) {
+	writeJSON(w, status, map[string]string{"error": codigo})
+}
+
+func writeJSON(w http.ResponseWriter, status int, payload any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+func aleatorio(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
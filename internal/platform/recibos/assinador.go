@@ -0,0 +1,169 @@
+// Pacote recibos assina com Ed25519 os recibos entregues a cada voto aceito e confere recibos apresentados
+// por eleitores e auditores.
+package recibos
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+var (
+	ErrAssinaturaInvalida = errors.New("assinatura invalida")
+	ErrChaveDesconhecida  = errors.New("chave de assinatura desconhecida")
+)
+
+// Algoritmo é o único esquema de assinatura suportado e aparece nas chaves publicadas.
+const Algoritmo = "Ed25519"
+
+// versaoMensagem prefixa os bytes assinados; mudar o formato da mensagem exige uma nova versão.
+const versaoMensagem = "bbb-recibo-v1"
+
+type chave struct {
+	id      string
+	privada ed25519.PrivateKey
+	publica ed25519.PublicKey
+}
+
+// Assinador guarda as chaves em ordem: a primeira assina os recibos novos e as seguintes só conferem
+// recibos emitidos antes da rotação.
+type Assinador struct {
+	chaves []chave
+}
+
+var _ domain.AssinadorRecibos = (*Assinador)(nil)
+
+// NewAssinador lê chaves no formato "id:seed,id:seed", com a seed Ed25519 de 32 bytes em base64. Para
+// rotacionar, coloque a chave nova na frente e mantenha a antiga na lista enquanto houver recibos dela.
+func NewAssinador(config string) (*Assinador, error) {
+	a := &Assinador{}
+	vistos := make(map[string]bool)
+	for _, item := range strings.Split(config, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		id, seed, ok := strings.Cut(item, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("recibos: chave %q fora do formato id:seed", item)
+		}
+		if vistos[id] {
+			return nil, fmt.Errorf("recibos: chave %q repetida", id)
+		}
+		bruta, err := base64.StdEncoding.DecodeString(seed)
+		if err != nil || len(bruta) != ed25519.SeedSize {
+			return nil, fmt.Errorf("recibos: seed da chave %q deve ter %d bytes em base64", id, ed25519.SeedSize)
+		}
+		vistos[id] = true
+		a.adicionar(id, ed25519.NewKeyFromSeed(bruta))
+	}
+	if len(a.chaves) == 0 {
+		return nil, errors.New("recibos: nenhuma chave configurada")
+	}
+	return a, nil
+}
+
+// NewAssinadorEfemero gera uma chave aleatória que some quando o processo termina; serve para ambientes
+// locais, já que recibos emitidos por outra réplica ou antes de um restart deixam de conferir.
+func NewAssinadorEfemero() (*Assinador, error) {
+	_, privada, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("recibos: gerar chave: %w", err)
+	}
+	a := &Assinador{}
+	a.adicionar("efemera", privada)
+	return a, nil
+}
+
+func (a *Assinador) adicionar(id string, privada ed25519.PrivateKey) {
+	a.chaves = append(a.chaves, chave{id: id, privada: privada, publica: privada.Public().(ed25519.PublicKey)})
+}
+
+// Assinar preenche ChaveID e Assinatura com a chave ativa; o instante é normalizado para UTC para que o
+// recibo serializado em JSON reproduza exatamente os bytes assinados.
+func (a *Assinador) Assinar(recibo domain.Recibo) domain.Recibo {
+	recibo.EmitidoEm = recibo.EmitidoEm.UTC()
+	recibo.ChaveID, recibo.Assinatura = a.AssinarMensagem(mensagem(recibo))
+	return recibo
+}
+
+// Verificar confere a assinatura com a chave indicada no recibo, inclusive chaves já rotacionadas.
+func (a *Assinador) Verificar(recibo domain.Recibo) error {
+	return a.VerificarMensagem(recibo.ChaveID, mensagem(recibo), recibo.Assinatura)
+}
+
+// AssinarMensagem assina bytes arbitrários com a chave ativa; os checkpoints da auditoria usam as mesmas
+// chaves dos recibos, já publicadas para verificação.
+func (a *Assinador) AssinarMensagem(msg []byte) (chaveID, assinatura string) {
+	ativa := a.chaves[0]
+	return ativa.id, base64.RawURLEncoding.EncodeToString(ed25519.Sign(ativa.privada, msg))
+}
+
+// VerificarMensagem confere uma assinatura feita por AssinarMensagem com qualquer chave da lista.
+func (a *Assinador) VerificarMensagem(chaveID string, msg []byte, assinatura string) error {
+	for _, c := range a.chaves {
+		if c.id != chaveID {
+			continue
+		}
+		return VerificarAssinatura(c.publica, msg, assinatura)
+	}
+	return fmt.Errorf("%w: %q", ErrChaveDesconhecida, chaveID)
+}
+
+// VerificarAssinatura confere uma assinatura em base64url com uma chave pública avulsa, como as publicadas
+// em /.well-known/recibos-chaves.json.
+func VerificarAssinatura(publica ed25519.PublicKey, msg []byte, assinatura string) error {
+	bruta, err := base64.RawURLEncoding.DecodeString(assinatura)
+	if err != nil || len(publica) != ed25519.PublicKeySize || !ed25519.Verify(publica, msg, bruta) {
+		return ErrAssinaturaInvalida
+	}
+	return nil
+}
+
+// ChavesPublicas lista as chaves em uso, a ativa primeiro.
+func (a *Assinador) ChavesPublicas() []domain.ChavePublicaRecibo {
+	chaves := make([]domain.ChavePublicaRecibo, len(a.chaves))
+	for i, c := range a.chaves {
+		chaves[i] = domain.ChavePublicaRecibo{
+			ChaveID:   c.id,
+			Algoritmo: Algoritmo,
+			Chave:     base64.StdEncoding.EncodeToString(c.publica),
+			Ativa:     i == 0,
+		}
+	}
+	return chaves
+}
+
+// ChavesPorID decodifica chaves publicadas por ChavesPublicas, indexadas pelo identificador, para quem
+// confere assinaturas fora do serviço.
+func ChavesPorID(publicadas []domain.ChavePublicaRecibo) (map[string]ed25519.PublicKey, error) {
+	chaves := make(map[string]ed25519.PublicKey, len(publicadas))
+	for _, p := range publicadas {
+		if p.Algoritmo != "" && p.Algoritmo != Algoritmo {
+			return nil, fmt.Errorf("recibos: chave %q com algoritmo %q nao suportado", p.ChaveID, p.Algoritmo)
+		}
+		bruta, err := base64.StdEncoding.DecodeString(p.Chave)
+		if err != nil || len(bruta) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("recibos: chave publica %q invalida", p.ChaveID)
+		}
+		chaves[p.ChaveID] = ed25519.PublicKey(bruta)
+	}
+	return chaves, nil
+}
+
+// mensagem monta os bytes assinados: versão, voto, paredão e instante em RFC 3339 com nanossegundos,
+// separados por quebra de linha, para que qualquer cliente consiga reproduzi-los.
+func mensagem(r domain.Recibo) []byte {
+	return []byte(strings.Join([]string{
+		versaoMensagem,
+		string(r.VotoID),
+		string(r.ParedaoID),
+		r.EmitidoEm.UTC().Format(time.RFC3339Nano),
+	}, "\n"))
+}
@@ -0,0 +1,97 @@
+package recibos
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+func seed(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune(b)), 32)))
+}
+
+func TestAssinador_QuandoReciboAssinado_DeveConferirAposIdaEVoltaEmJSON(t *testing.T) {
+	// Arrange
+	assinador, err := NewAssinador("k1:" + seed('a'))
+	require.NoError(t, err)
+	emitido := time.Date(2024, 1, 1, 20, 0, 0, 123456789, time.FixedZone("BRT", -3*3600))
+
+	// Act
+	recibo := assinador.Assinar(domain.Recibo{VotoID: "voto-1", ParedaoID: "par-1", EmitidoEm: emitido})
+	payload, err := json.Marshal(recibo)
+	require.NoError(t, err)
+	var recebido domain.Recibo
+	require.NoError(t, json.Unmarshal(payload, &recebido))
+
+	// Assert
+	assert.Equal(t, "k1", recibo.ChaveID)
+	assert.NoError(t, assinador.Verificar(recebido))
+}
+
+func TestAssinador_QuandoReciboAdulterado_DeveRecusar(t *testing.T) {
+	assinador, err := NewAssinador("k1:" + seed('a'))
+	require.NoError(t, err)
+	recibo := assinador.Assinar(domain.Recibo{VotoID: "voto-1", ParedaoID: "par-1", EmitidoEm: time.Now()})
+
+	adulterado := recibo
+	adulterado.ParedaoID = "par-2"
+	assert.ErrorIs(t, assinador.Verificar(adulterado), ErrAssinaturaInvalida)
+
+	adulterado = recibo
+	adulterado.Assinatura = "nao-e-base64!"
+	assert.ErrorIs(t, assinador.Verificar(adulterado), ErrAssinaturaInvalida)
+
+	adulterado = recibo
+	adulterado.ChaveID = "k9"
+	assert.ErrorIs(t, assinador.Verificar(adulterado), ErrChaveDesconhecida)
+}
+
+func TestAssinador_QuandoChaveRotacionada_DeveConferirRecibosAntigos(t *testing.T) {
+	// Arrange
+	antigo, err := NewAssinador("k1:" + seed('a'))
+	require.NoError(t, err)
+	recibo := antigo.Assinar(domain.Recibo{VotoID: "voto-1", ParedaoID: "par-1", EmitidoEm: time.Now()})
+
+	// Act
+	rotacionado, err := NewAssinador("k2:" + seed('b') + ", k1:" + seed('a'))
+	require.NoError(t, err)
+	novo := rotacionado.Assinar(domain.Recibo{VotoID: "voto-2", ParedaoID: "par-1", EmitidoEm: time.Now()})
+
+	// Assert
+	assert.NoError(t, rotacionado.Verificar(recibo))
+	assert.Equal(t, "k2", novo.ChaveID)
+	chaves := rotacionado.ChavesPublicas()
+	require.Len(t, chaves, 2)
+	assert.True(t, chaves[0].Ativa)
+	assert.False(t, chaves[1].Ativa)
+	assert.Equal(t, antigo.ChavesPublicas()[0].Chave, chaves[1].Chave)
+}
+
+func TestNewAssinador_QuandoConfiguracaoInvalida_DeveFalhar(t *testing.T) {
+	for _, config := range []string{"", "sem-seed", "k1:curta", "k1:" + seed('a') + ",k1:" + seed('b')} {
+		_, err := NewAssinador(config)
+		assert.Error(t, err, config)
+	}
+}
+
+func TestChavesPorID_QuandoChavesPublicadas_DeveConferirMensagemAssinada(t *testing.T) {
+	assinador, err := NewAssinador("k1:" + seed('a'))
+	require.NoError(t, err)
+	msg := []byte("bbb-checkpoint-v1\npar-1\n10")
+	chaveID, assinatura := assinador.AssinarMensagem(msg)
+
+	chaves, err := ChavesPorID(assinador.ChavesPublicas())
+
+	require.NoError(t, err)
+	assert.NoError(t, VerificarAssinatura(chaves[chaveID], msg, assinatura))
+	assert.ErrorIs(t, VerificarAssinatura(chaves[chaveID], []byte("outra"), assinatura), ErrAssinaturaInvalida)
+	_, err = ChavesPorID([]domain.ChavePublicaRecibo{{ChaveID: "k2", Chave: "curta"}})
+	assert.Error(t, err)
+}
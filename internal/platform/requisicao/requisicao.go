@@ -0,0 +1,20 @@
+// Package requisicao identifica cada requisição HTTP para correlacionar a resposta de erro com os logs.
+package requisicao
+
+import "context"
+
+// Cabecalho leva o ID da requisição na resposta.
+const Cabecalho = "X-Request-Id"
+
+type chaveID struct{}
+
+// ComID guarda o ID da requisição no contexto.
+func ComID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, chaveID{}, id)
+}
+
+// ID devolve o ID guardado por ComID, ou vazio.
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(chaveID{}).(string)
+	return id
+}
@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// loteExportacao é quantos votos cada consulta da exportação traz por vez.
+const loteExportacao = 1000
+
+// AuditoriaRepository lê a cadeia de hashes gravada junto com os votos e guarda os checkpoints assinados.
+type AuditoriaRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditoriaRepository(db *gorm.DB) *AuditoriaRepository {
+	return &AuditoriaRepository{db: db}
+}
+
+var _ domain.CadeiaAuditoria = (*AuditoriaRepository)(nil)
+
+func (r *AuditoriaRepository) PendentesDeCheckpoint(ctx context.Context) ([]domain.CadeiaVotos, error) {
+	var pontas []domain.CadeiaVotos
+	if err := r.db.WithContext(ctx).
+		Where("tamanho > COALESCE((SELECT MAX(c.tamanho) FROM checkpoints_votos c WHERE c.paredao_id = cadeias_votos.paredao_id), 0)").
+		Order("paredao_id").
+		Find(&pontas).Error; err != nil {
+		return nil, fmt.Errorf("gorm auditoria: cadeias pendentes: %w", err)
+	}
+	return pontas, nil
+}
+
+func (r *AuditoriaRepository) SalvarCheckpoint(ctx context.Context, checkpoint domain.Checkpoint) error {
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&checkpoint).Error; err != nil {
+		return fmt.Errorf("gorm auditoria: salvar checkpoint: %w", err)
+	}
+	return nil
+}
+
+func (r *AuditoriaRepository) Checkpoints(ctx context.Context, paredaoID domain.ParedaoID) ([]domain.Checkpoint, error) {
+	var checkpoints []domain.Checkpoint
+	if err := r.db.WithContext(ctx).
+		Where("paredao_id = ?", paredaoID).
+		Order("tamanho").
+		Find(&checkpoints).Error; err != nil {
+		return nil, fmt.Errorf("gorm auditoria: checkpoints: %w", err)
+	}
+	return checkpoints, nil
+}
+
+// ExportarVotos pagina por (seq, id) em vez de OFFSET, para que cada lote use o índice do paredão e a
+// exportação de milhões de votos não fique quadrática.
+func (r *AuditoriaRepository) ExportarVotos(ctx context.Context, paredaoID domain.ParedaoID, emitir func(domain.VotoAuditado) error) error {
+	var (
+		ultimoSeq int64 = -1
+		ultimoID  string
+	)
+	for {
+		var lote []votoModel
+		if err := r.db.WithContext(ctx).
+			Select("id", "paredao_id", "participante_id", "modalidade", "criado_em", "seq", "hash", "origem_ip").
+			Where("paredao_id = ? AND (seq > ? OR (seq = ? AND id > ?))", paredaoID, ultimoSeq, ultimoSeq, ultimoID).
+			Order("seq").Order("id").
+			Limit(loteExportacao).
+			Find(&lote).Error; err != nil {
+			return fmt.Errorf("gorm auditoria: exportar votos: %w", err)
+		}
+		for _, m := range lote {
+			if err := emitir(m.auditado()); err != nil {
+				return err
+			}
+		}
+		if len(lote) < loteExportacao {
+			return nil
+		}
+		ultimoSeq, ultimoID = lote[len(lote)-1].Seq, lote[len(lote)-1].ID
+	}
+}
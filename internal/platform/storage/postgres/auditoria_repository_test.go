@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/auditoria"
+	"github.com/marcelojr/desafio-globo/internal/platform/ids"
+	"github.com/marcelojr/desafio-globo/internal/platform/recibos"
+)
+
+func TestAuditoriaRepository_QuandoVotosRegistrados_DeveExportarCadeiaVerificavel(t *testing.T) {
+	db := setupPostgres(t)
+	votos := NewVotoRepository(db)
+	repo := NewAuditoriaRepository(db)
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	paredaoID := domain.ParedaoID(gen.New())
+	assinador, err := recibos.NewAssinadorEfemero()
+	require.NoError(t, err)
+
+	// Arrange: três votos, checkpoint, mais dois votos
+	registrar := func() {
+		require.NoError(t, votos.Registrar(ctx, domain.Voto{
+			ID:             domain.VotoID(gen.New()),
+			ParedaoID:      paredaoID,
+			ParticipanteID: "participante-a",
+			CriadoEm:       time.Now(),
+		}))
+	}
+	for range 3 {
+		registrar()
+	}
+	pendentes, err := repo.PendentesDeCheckpoint(ctx)
+	require.NoError(t, err)
+	require.Len(t, pendentes, 1)
+	cadeia, err := auditoria.Restaurar(pendentes[0])
+	require.NoError(t, err)
+	checkpoint := domain.Checkpoint{
+		ParedaoID:  paredaoID,
+		Tamanho:    cadeia.Tamanho,
+		HashCadeia: cadeia.Elo.String(),
+		RaizMerkle: cadeia.Raiz().String(),
+		CriadoEm:   auditoria.Instante(time.Now()),
+	}
+	checkpoint.ChaveID, checkpoint.Assinatura = assinador.AssinarMensagem(auditoria.MensagemCheckpoint(checkpoint))
+	require.NoError(t, repo.SalvarCheckpoint(ctx, checkpoint))
+	for range 2 {
+		registrar()
+	}
+
+	// Act
+	var buf bytes.Buffer
+	exportador := auditoria.NewExportador(&buf)
+	require.NoError(t, exportador.Cabecalho(paredaoID, time.Now()))
+	checkpoints, err := repo.Checkpoints(ctx, paredaoID)
+	require.NoError(t, err)
+	for _, c := range checkpoints {
+		require.NoError(t, exportador.Checkpoint(c))
+	}
+	require.NoError(t, repo.ExportarVotos(ctx, paredaoID, exportador.Voto))
+	chaves, err := recibos.ChavesPorID(assinador.ChavesPublicas())
+	require.NoError(t, err)
+	rel, err := auditoria.Verificar(&buf, chaves)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, rel.Integra(), rel.Problemas)
+	assert.Equal(t, int64(5), rel.Votos)
+	assert.Equal(t, 1, rel.CheckpointsConferidos)
+	pendentes, err = repo.PendentesDeCheckpoint(ctx)
+	require.NoError(t, err)
+	require.Len(t, pendentes, 1)
+	assert.Equal(t, int64(5), pendentes[0].Tamanho)
+}
+
+func TestAuditoriaRepository_QuandoVotoAlteradoNoBanco_DeveAcusarNaVerificacao(t *testing.T) {
+	db := setupPostgres(t)
+	votos := NewVotoRepository(db)
+	repo := NewAuditoriaRepository(db)
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	paredaoID := domain.ParedaoID(gen.New())
+
+	var alvo domain.VotoID
+	for i := range 4 {
+		id := domain.VotoID(gen.New())
+		if i == 1 {
+			alvo = id
+		}
+		require.NoError(t, votos.Registrar(ctx, domain.Voto{ID: id, ParedaoID: paredaoID, ParticipanteID: "participante-a", CriadoEm: time.Now()}))
+	}
+	require.NoError(t, db.Table("votos").Where("id = ?", alvo).Update("participante_id", "participante-b").Error)
+
+	var buf bytes.Buffer
+	exportador := auditoria.NewExportador(&buf)
+	require.NoError(t, exportador.Cabecalho(paredaoID, time.Now()))
+	require.NoError(t, repo.ExportarVotos(ctx, paredaoID, exportador.Voto))
+	rel, err := auditoria.Verificar(&buf, nil)
+
+	require.NoError(t, err)
+	assert.False(t, rel.Integra())
+	assert.Contains(t, rel.Problemas[0], string(alvo))
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/marcelojr/desafio-globo/internal/domain"
 )
@@ -21,15 +22,32 @@ func NewParedaoRepository(db *gorm.DB) *ParedaoRepository {
 }
 
 type paredaoModel struct {
-	ID            string              `gorm:"column:id;primaryKey"`
-	Nome          string              `gorm:"column:nome"`
-	Descricao     string              `gorm:"column:descricao"`
-	Inicio        time.Time           `gorm:"column:inicio"`
-	Fim           time.Time           `gorm:"column:fim"`
-	Ativo         bool                `gorm:"column:ativo"`
-	CriadoEm      time.Time           `gorm:"column:criado_em"`
-	AtualizadoEm  time.Time           `gorm:"column:atualizado_em"`
-	Participantes []participanteModel `gorm:"foreignKey:ParedaoID;references:ID"`
+	ID                       string              `gorm:"column:id;primaryKey"`
+	Nome                     string              `gorm:"column:nome"`
+	Descricao                string              `gorm:"column:descricao"`
+	Inicio                   time.Time           `gorm:"column:inicio"`
+	Fim                      time.Time           `gorm:"column:fim"`
+	Ativo                    bool                `gorm:"column:ativo"`
+	ModoVotacao              string              `gorm:"column:modo_votacao"`
+	Polaridade               string              `gorm:"column:polaridade"`
+	GrupoID                  *string             `gorm:"column:grupo_id"`
+	Etapa                    int                 `gorm:"column:etapa"`
+	EtapaNome                string              `gorm:"column:etapa_nome"`
+	Anulado                  bool                `gorm:"column:anulado"`
+	PesoUnico                float64             `gorm:"column:peso_unico"`
+	PesoTorcida              float64             `gorm:"column:peso_torcida"`
+	AnomaliaLimiarZ          float64             `gorm:"column:anomalia_limiar_z"`
+	AnomaliaMinVotos         int64               `gorm:"column:anomalia_min_votos"`
+	AntifraudeLimite         int                 `gorm:"column:antifraude_limite"`
+	AntifraudeJanelaSegundos int                 `gorm:"column:antifraude_janela_segundos"`
+	AntifraudeAlgoritmo      string              `gorm:"column:antifraude_algoritmo"`
+	AntifraudeExigeCaptcha   bool                `gorm:"column:antifraude_exige_captcha"`
+	AntifraudeEscopoBloqueio string              `gorm:"column:antifraude_escopo_bloqueio"`
+	VisibilidadeModo         string              `gorm:"column:visibilidade_modo"`
+	VisibilidadeAtraso       int                 `gorm:"column:visibilidade_atraso_minutos"`
+	CriadoEm                 time.Time           `gorm:"column:criado_em"`
+	AtualizadoEm             time.Time           `gorm:"column:atualizado_em"`
+	Participantes            []participanteModel `gorm:"foreignKey:ParedaoID;references:ID"`
 }
 
 func (paredaoModel) TableName() string {
@@ -38,16 +56,47 @@ func (paredaoModel) TableName() string {
 
 func (m paredaoModel) toDomain(includeParticipants bool) domain.Paredao {
 	p := domain.Paredao{
-		ID:           domain.ParedaoID(m.ID),
-		Nome:         m.Nome,
-		Descricao:    m.Descricao,
-		Inicio:       m.Inicio,
-		Fim:          m.Fim,
-		Ativo:        m.Ativo,
+		ID:          domain.ParedaoID(m.ID),
+		Nome:        m.Nome,
+		Descricao:   m.Descricao,
+		Inicio:      m.Inicio,
+		Fim:         m.Fim,
+		Ativo:       m.Ativo,
+		ModoVotacao: domain.ModoVotacao(m.ModoVotacao),
+		Polaridade:  domain.Polaridade(m.Polaridade),
+		Etapa:       m.Etapa,
+		EtapaNome:   m.EtapaNome,
+		Anulado:     m.Anulado,
+		Pesos: domain.PesosModalidade{
+			Unico:   m.PesoUnico,
+			Torcida: m.PesoTorcida,
+		},
+		Anomalia: domain.LimiaresAnomalia{
+			LimiarZ:  m.AnomaliaLimiarZ,
+			MinVotos: m.AnomaliaMinVotos,
+		},
+		Antifraude: domain.PoliticaAntifraude{
+			Limite:         m.AntifraudeLimite,
+			JanelaSegundos: m.AntifraudeJanelaSegundos,
+			Algoritmo:      domain.AlgoritmoLimite(m.AntifraudeAlgoritmo),
+			ExigeCaptcha:   m.AntifraudeExigeCaptcha,
+			EscopoBloqueio: domain.EscopoBloqueio(m.AntifraudeEscopoBloqueio),
+		},
+		Visibilidade: domain.PoliticaVisibilidade{
+			Modo:          domain.ModoVisibilidade(m.VisibilidadeModo),
+			AtrasoMinutos: m.VisibilidadeAtraso,
+		},
 		CriadoEm:     m.CriadoEm,
 		AtualizadoEm: m.AtualizadoEm,
 	}
 
+	if m.GrupoID != nil {
+		p.GrupoID = domain.ParedaoID(*m.GrupoID)
+	}
+	if p.Etapa == 0 {
+		p.Etapa = 1
+	}
+
 	if includeParticipants {
 		participantes := make([]domain.Participante, len(m.Participantes))
 		for i, part := range m.Participantes {
@@ -61,14 +110,42 @@ func (m paredaoModel) toDomain(includeParticipants bool) domain.Paredao {
 
 func fromDomainParedao(p domain.Paredao) paredaoModel {
 	model := paredaoModel{
-		ID:           string(p.ID),
-		Nome:         p.Nome,
-		Descricao:    p.Descricao,
-		Inicio:       p.Inicio,
-		Fim:          p.Fim,
-		Ativo:        p.Ativo,
-		CriadoEm:     p.CriadoEm,
-		AtualizadoEm: p.AtualizadoEm,
+		ID:                       string(p.ID),
+		Nome:                     p.Nome,
+		Descricao:                p.Descricao,
+		Inicio:                   p.Inicio,
+		Fim:                      p.Fim,
+		Ativo:                    p.Ativo,
+		ModoVotacao:              string(p.ModoVotacao),
+		Polaridade:               string(p.PolaridadeEfetiva()),
+		Etapa:                    p.Etapa,
+		EtapaNome:                p.EtapaNome,
+		Anulado:                  p.Anulado,
+		PesoUnico:                p.Pesos.Unico,
+		PesoTorcida:              p.Pesos.Torcida,
+		AnomaliaLimiarZ:          p.Anomalia.LimiarZ,
+		AnomaliaMinVotos:         p.Anomalia.MinVotos,
+		AntifraudeLimite:         p.Antifraude.Limite,
+		AntifraudeJanelaSegundos: p.Antifraude.JanelaSegundos,
+		AntifraudeAlgoritmo:      string(p.Antifraude.Algoritmo),
+		AntifraudeExigeCaptcha:   p.Antifraude.ExigeCaptcha,
+		AntifraudeEscopoBloqueio: string(p.Antifraude.EscopoBloqueio),
+		VisibilidadeModo:         string(p.Visibilidade.Modo),
+		VisibilidadeAtraso:       p.Visibilidade.AtrasoMinutos,
+		CriadoEm:                 p.CriadoEm,
+		AtualizadoEm:             p.AtualizadoEm,
+	}
+
+	// grupo_id é char(26): gravamos NULL em vez de string vazia nos paredões de etapa única.
+	if p.GrupoID != "" {
+		grupo := string(p.GrupoID)
+		model.GrupoID = &grupo
+	}
+	if model.Etapa == 0 {
+		model.Etapa = 1
+	}
+	if model.VisibilidadeModo == "" {
+		model.VisibilidadeModo = string(domain.VisibilidadePublica)
 	}
 
 	if len(p.Participantes) > 0 {
@@ -89,6 +166,65 @@ func (r *ParedaoRepository) Create(ctx context.Context, p domain.Paredao) error
 	return nil
 }
 
+func (r *ParedaoRepository) CreateEtapas(ctx context.Context, etapas []domain.Paredao) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, etapa := range etapas {
+			model := fromDomainParedao(etapa)
+			participantes := model.Participantes
+			model.Participantes = nil
+			if err := tx.Create(&model).Error; err != nil {
+				return fmt.Errorf("gorm paredao: inserir etapa %d: %w", etapa.Etapa, err)
+			}
+			if len(participantes) == 0 {
+				continue
+			}
+			for i := range participantes {
+				participantes[i].ParedaoID = model.ID
+			}
+			if err := tx.Create(&participantes).Error; err != nil {
+				return fmt.Errorf("gorm paredao: inserir participantes da etapa %d: %w", etapa.Etapa, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *ParedaoRepository) AbrirEtapa(ctx context.Context, etapa domain.Paredao, participantes []domain.Participante) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// A trava serializa finalizações concorrentes da etapa anterior: a segunda já encontra os participantes.
+		var model paredaoModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&model, "id = ?", etapa.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrNotFound
+			}
+			return fmt.Errorf("gorm paredao: travar etapa: %w", err)
+		}
+		var existentes int64
+		if err := tx.Model(&participanteModel{}).Where("paredao_id = ?", etapa.ID).Count(&existentes).Error; err != nil {
+			return fmt.Errorf("gorm paredao: contar participantes da etapa: %w", err)
+		}
+		if existentes > 0 {
+			return nil
+		}
+
+		if len(participantes) > 0 {
+			models := make([]participanteModel, len(participantes))
+			for i, part := range participantes {
+				part.ParedaoID = etapa.ID
+				models[i] = fromDomainParticipante(part)
+			}
+			if err := tx.Create(&models).Error; err != nil {
+				return fmt.Errorf("gorm paredao: inserir participantes da etapa: %w", err)
+			}
+		}
+		if err := tx.Model(&paredaoModel{}).Where("id = ?", etapa.ID).
+			Updates(map[string]any{"ativo": true, "atualizado_em": etapa.AtualizadoEm}).Error; err != nil {
+			return fmt.Errorf("gorm paredao: ativar etapa: %w", err)
+		}
+		return nil
+	})
+}
+
 func (r *ParedaoRepository) Update(ctx context.Context, p domain.Paredao) error {
 	model := fromDomainParedao(p)
 	if err := r.db.WithContext(ctx).Model(&paredaoModel{}).
@@ -99,6 +235,7 @@ func (r *ParedaoRepository) Update(ctx context.Context, p domain.Paredao) error
 			"inicio":        model.Inicio,
 			"fim":           model.Fim,
 			"ativo":         model.Ativo,
+			"anulado":       model.Anulado,
 			"atualizado_em": model.AtualizadoEm,
 		}).Error; err != nil {
 		return fmt.Errorf("gorm paredao: atualizar: %w", err)
@@ -106,6 +243,60 @@ func (r *ParedaoRepository) Update(ctx context.Context, p domain.Paredao) error
 	return nil
 }
 
+func (r *ParedaoRepository) AtualizarAntifraude(ctx context.Context, id domain.ParedaoID, politica domain.PoliticaAntifraude, em time.Time) error {
+	return r.atualizarColunas(ctx, id, "politica antifraude", map[string]any{
+		"antifraude_limite":          politica.Limite,
+		"antifraude_janela_segundos": politica.JanelaSegundos,
+		"antifraude_algoritmo":       string(politica.Algoritmo),
+		"antifraude_exige_captcha":   politica.ExigeCaptcha,
+		"antifraude_escopo_bloqueio": string(politica.EscopoBloqueio),
+		"atualizado_em":              em,
+	})
+}
+
+func (r *ParedaoRepository) AtualizarLimiares(ctx context.Context, id domain.ParedaoID, limiares domain.LimiaresAnomalia, em time.Time) error {
+	return r.atualizarColunas(ctx, id, "limiares de anomalia", map[string]any{
+		"anomalia_limiar_z":  limiares.LimiarZ,
+		"anomalia_min_votos": limiares.MinVotos,
+		"atualizado_em":      em,
+	})
+}
+
+func (r *ParedaoRepository) AtualizarVotacao(ctx context.Context, id domain.ParedaoID, modo domain.ModoVotacao, pesos domain.PesosModalidade, polaridade domain.Polaridade, em time.Time) error {
+	return r.atualizarColunas(ctx, id, "votacao", map[string]any{
+		"modo_votacao":  string(modo),
+		"polaridade":    string(domain.Paredao{Polaridade: polaridade}.PolaridadeEfetiva()),
+		"peso_unico":    pesos.Unico,
+		"peso_torcida":  pesos.Torcida,
+		"atualizado_em": em,
+	})
+}
+
+func (r *ParedaoRepository) AtualizarVisibilidade(ctx context.Context, id domain.ParedaoID, politica domain.PoliticaVisibilidade, em time.Time) error {
+	modo := politica.Modo
+	if modo == "" {
+		modo = domain.VisibilidadePublica
+	}
+	return r.atualizarColunas(ctx, id, "visibilidade", map[string]any{
+		"visibilidade_modo":           string(modo),
+		"visibilidade_atraso_minutos": politica.AtrasoMinutos,
+		"atualizado_em":               em,
+	})
+}
+
+// atualizarColunas grava apenas as colunas informadas, para que um ajuste administrativo não sobrescreva
+// ativo, fim ou anulado gravados em paralelo pelo ciclo de vida do paredão.
+func (r *ParedaoRepository) atualizarColunas(ctx context.Context, id domain.ParedaoID, oQue string, colunas map[string]any) error {
+	res := r.db.WithContext(ctx).Model(&paredaoModel{}).Where("id = ?", id).Updates(colunas)
+	if res.Error != nil {
+		return fmt.Errorf("gorm paredao: atualizar %s: %w", oQue, res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
 func (r *ParedaoRepository) FindByID(ctx context.Context, id domain.ParedaoID) (domain.Paredao, error) {
 	var model paredaoModel
 	if err := r.db.WithContext(ctx).
@@ -137,4 +328,36 @@ func (r *ParedaoRepository) ListAtivos(ctx context.Context) ([]domain.Paredao, e
 	return result, nil
 }
 
+func (r *ParedaoRepository) ListComMarco(ctx context.Context, desde, ate time.Time) ([]domain.Paredao, error) {
+	var models []paredaoModel
+	if err := r.db.WithContext(ctx).
+		Where("(inicio > ? AND inicio <= ?) OR (fim > ? AND fim <= ?)", desde, ate, desde, ate).
+		Order("inicio ASC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("gorm paredao: listar com marco: %w", err)
+	}
+
+	result := make([]domain.Paredao, len(models))
+	for i, model := range models {
+		result[i] = model.toDomain(false)
+	}
+	return result, nil
+}
+
+func (r *ParedaoRepository) ListByGrupo(ctx context.Context, grupo domain.ParedaoID) ([]domain.Paredao, error) {
+	var models []paredaoModel
+	if err := r.db.WithContext(ctx).
+		Where("grupo_id = ?", grupo).
+		Order("etapa ASC").
+		Find(&models).Error; err != nil {
+		return nil, fmt.Errorf("gorm paredao: listar etapas: %w", err)
+	}
+
+	result := make([]domain.Paredao, len(models))
+	for i, model := range models {
+		result[i] = model.toDomain(false)
+	}
+	return result, nil
+}
+
 var _ domain.ParedaoRepository = (*ParedaoRepository)(nil)
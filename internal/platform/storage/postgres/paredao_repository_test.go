@@ -15,11 +15,12 @@ import (
 )
 
 func setupPostgres(t *testing.T) *gorm.DB {
-	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{TranslateError: true})
 	require.NoError(t, err)
 
 	// Aplicar migrations no banco de teste
-	err = db.AutoMigrate(&domain.Paredao{}, &domain.Participante{}, &domain.Voto{})
+	err = db.AutoMigrate(&domain.Paredao{}, &domain.Participante{}, &domain.Voto{}, &domain.CadeiaVotos{}, &domain.Checkpoint{}, &domain.Virada{},
+		&domain.Webhook{}, &domain.EventoParedao{}, &domain.EntregaWebhook{})
 	require.NoError(t, err)
 
 	t.Cleanup(func() {
@@ -282,3 +283,217 @@ func TestParedaoRepository_Update_QuandoExiste_DeveAtualizarComSucesso(t *testin
 	assert.Equal(t, "Descrição atualizada", encontrado.Descricao)
 	assert.False(t, encontrado.Ativo)
 }
+
+func TestParedaoRepository_AtualizarAntifraude_QuandoFinalizadoEmParalelo_NaoDeveReabrir(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewParedaoRepository(db)
+
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	now := time.Now()
+
+	paredao := domain.Paredao{
+		ID:     domain.ParedaoID(gen.New()),
+		Nome:   "Paredão Final",
+		Inicio: now.Add(-1 * time.Hour),
+		Fim:    now.Add(24 * time.Hour),
+		Ativo:  true,
+	}
+	require.NoError(t, repo.Create(ctx, paredao))
+
+	// Arrange: o paredão é finalizado depois que o admin já leu a versão aberta
+	finalizado := paredao
+	finalizado.Ativo = false
+	finalizado.Fim = now
+	require.NoError(t, repo.Update(ctx, finalizado))
+
+	// Act
+	politica := domain.PoliticaAntifraude{
+		Limite:         5,
+		JanelaSegundos: 300,
+		Algoritmo:      domain.AlgoritmoJanelaDeslizante,
+		ExigeCaptcha:   true,
+		EscopoBloqueio: domain.EscopoGlobal,
+	}
+	require.NoError(t, repo.AtualizarAntifraude(ctx, paredao.ID, politica, now))
+
+	// Assert
+	encontrado, err := repo.FindByID(ctx, paredao.ID)
+	require.NoError(t, err)
+	assert.Equal(t, politica, encontrado.Antifraude)
+	assert.False(t, encontrado.Ativo)
+	assert.WithinDuration(t, now, encontrado.Fim, time.Second)
+
+	err = repo.AtualizarAntifraude(ctx, domain.ParedaoID(gen.New()), politica, now)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
+
+func TestParedaoRepository_Update_QuandoLimiaresMudaramEmParalelo_NaoDeveDesfazer(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewParedaoRepository(db)
+
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	now := time.Now()
+
+	paredao := domain.Paredao{ID: domain.ParedaoID(gen.New()), Nome: "Paredão", Inicio: now.Add(-time.Hour), Fim: now.Add(time.Hour), Ativo: true}
+	require.NoError(t, repo.Create(ctx, paredao))
+
+	// Arrange: o admin troca os limiares enquanto a finalização trabalha com a leitura anterior
+	limiares := domain.LimiaresAnomalia{LimiarZ: 6, MinVotos: 500}
+	require.NoError(t, repo.AtualizarLimiares(ctx, paredao.ID, limiares, now))
+
+	// Act
+	finalizado := paredao
+	finalizado.Ativo = false
+	finalizado.Fim = now
+	require.NoError(t, repo.Update(ctx, finalizado))
+
+	// Assert
+	encontrado, err := repo.FindByID(ctx, paredao.ID)
+	require.NoError(t, err)
+	assert.Equal(t, limiares, encontrado.Anomalia)
+	assert.False(t, encontrado.Ativo)
+}
+
+func TestParedaoRepository_AtualizarVotacao_QuandoFinalizado_DeveManterEncerrado(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewParedaoRepository(db)
+
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	now := time.Now()
+
+	paredao := domain.Paredao{ID: domain.ParedaoID(gen.New()), Nome: "Paredão", Inicio: now.Add(-time.Hour), Fim: now.Add(time.Hour), Ativo: true}
+	require.NoError(t, repo.Create(ctx, paredao))
+	finalizado := paredao
+	finalizado.Ativo = false
+	require.NoError(t, repo.Update(ctx, finalizado))
+
+	// Act
+	pesos := domain.PesosModalidade{Unico: 0.7, Torcida: 0.3}
+	require.NoError(t, repo.AtualizarVotacao(ctx, paredao.ID, domain.ModoMisto, pesos, domain.PolaridadeSalvar, now))
+
+	// Assert
+	encontrado, err := repo.FindByID(ctx, paredao.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ModoMisto, encontrado.ModoVotacao)
+	assert.Equal(t, domain.PolaridadeSalvar, encontrado.Polaridade)
+	assert.Equal(t, pesos, encontrado.Pesos)
+	assert.False(t, encontrado.Ativo)
+}
+
+func TestParedaoRepository_AtualizarVisibilidade_QuandoAnulado_DeveManterAnulacao(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewParedaoRepository(db)
+
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	now := time.Now()
+
+	paredao := domain.Paredao{ID: domain.ParedaoID(gen.New()), Nome: "Paredão", Inicio: now.Add(-time.Hour), Fim: now.Add(time.Hour), Ativo: true}
+	require.NoError(t, repo.Create(ctx, paredao))
+	anulado := paredao
+	anulado.Ativo = false
+	anulado.Anulado = true
+	require.NoError(t, repo.Update(ctx, anulado))
+
+	// Act
+	politica := domain.PoliticaVisibilidade{Modo: domain.VisibilidadeAtrasada, AtrasoMinutos: 15}
+	require.NoError(t, repo.AtualizarVisibilidade(ctx, paredao.ID, politica, now))
+
+	// Assert
+	encontrado, err := repo.FindByID(ctx, paredao.ID)
+	require.NoError(t, err)
+	assert.Equal(t, politica, encontrado.Visibilidade)
+	assert.True(t, encontrado.Anulado)
+	assert.False(t, encontrado.Ativo)
+}
+
+func TestParedaoRepository_ListByGrupo_QuandoExistemEtapas_DeveRetornarEmOrdem(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewParedaoRepository(db)
+
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	now := time.Now()
+
+	// Arrange: etapa 2 gravada antes da 1 e um paredão simples que não pertence ao grupo
+	grupo := domain.ParedaoID(gen.New())
+	principal := domain.Paredao{ID: domain.ParedaoID(gen.New()), Nome: "Paredão", GrupoID: grupo, Etapa: 2, EtapaNome: "Paredão", Inicio: now.Add(time.Hour), Fim: now.Add(48 * time.Hour)}
+	bateVolta := domain.Paredao{ID: grupo, Nome: "Paredão", GrupoID: grupo, Etapa: 1, EtapaNome: "Bate-volta", Inicio: now, Fim: now.Add(time.Hour), Ativo: true}
+	simples := domain.Paredao{ID: domain.ParedaoID(gen.New()), Nome: "Outro", Inicio: now, Fim: now.Add(time.Hour), Ativo: true}
+	for _, p := range []domain.Paredao{principal, bateVolta, simples} {
+		require.NoError(t, repo.Create(ctx, p))
+	}
+
+	// Act
+	etapas, err := repo.ListByGrupo(ctx, grupo)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, etapas, 2)
+	assert.Equal(t, bateVolta.ID, etapas[0].ID)
+	assert.Equal(t, "Bate-volta", etapas[0].EtapaNome)
+	assert.Equal(t, 2, etapas[1].Etapa)
+
+	encontrado, err := repo.FindByID(ctx, simples.ID)
+	require.NoError(t, err)
+	assert.Empty(t, encontrado.GrupoID)
+	assert.Equal(t, 1, encontrado.Etapa)
+}
+
+func TestParedaoRepository_CreateEtapas_QuandoUmaEtapaFalha_NaoDeveGravarNenhuma(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewParedaoRepository(db)
+
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	now := time.Now()
+
+	// Arrange: a segunda etapa repete o ID da primeira e viola a chave primária
+	grupo := domain.ParedaoID(gen.New())
+	bateVolta := domain.Paredao{ID: grupo, Nome: "Paredão", GrupoID: grupo, Etapa: 1, Inicio: now, Fim: now.Add(time.Hour), Ativo: true,
+		Participantes: []domain.Participante{{ID: domain.ParticipanteID(gen.New()), Nome: "Ana"}}}
+	principal := domain.Paredao{ID: grupo, Nome: "Paredão", GrupoID: grupo, Etapa: 2, Inicio: now.Add(time.Hour), Fim: now.Add(48 * time.Hour)}
+
+	// Act
+	err := repo.CreateEtapas(ctx, []domain.Paredao{bateVolta, principal})
+
+	// Assert
+	require.Error(t, err)
+	_, err = repo.FindByID(ctx, grupo)
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	participantes, err := NewParticipanteRepository(db).ListByParedao(ctx, grupo)
+	require.NoError(t, err)
+	assert.Empty(t, participantes)
+}
+
+func TestParedaoRepository_AbrirEtapa_QuandoJaAberta_NaoDeveDuplicarParticipantes(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewParedaoRepository(db)
+
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	now := time.Now()
+
+	grupo := domain.ParedaoID(gen.New())
+	principal := domain.Paredao{ID: domain.ParedaoID(gen.New()), Nome: "Paredão", GrupoID: grupo, Etapa: 2, Inicio: now, Fim: now.Add(48 * time.Hour)}
+	require.NoError(t, repo.CreateEtapas(ctx, []domain.Paredao{principal}))
+	novos := func() []domain.Participante {
+		return []domain.Participante{
+			{ID: domain.ParticipanteID(gen.New()), Nome: "Ana", OrigemID: "ana"},
+			{ID: domain.ParticipanteID(gen.New()), Nome: "Bia", OrigemID: "bia"},
+		}
+	}
+
+	// Act: duas finalizações da etapa anterior tentam abrir a mesma etapa
+	require.NoError(t, repo.AbrirEtapa(ctx, principal, novos()))
+	require.NoError(t, repo.AbrirEtapa(ctx, principal, novos()))
+
+	// Assert
+	aberta, err := repo.FindByID(ctx, principal.ID)
+	require.NoError(t, err)
+	assert.True(t, aberta.Ativo)
+	assert.Len(t, aberta.Participantes, 2)
+}
@@ -20,12 +20,15 @@ func NewParticipanteRepository(db *gorm.DB) *ParticipanteRepository {
 }
 
 type participanteModel struct {
-	ID           string    `gorm:"column:id;primaryKey"`
-	ParedaoID    string    `gorm:"column:paredao_id;index"`
-	Nome         string    `gorm:"column:nome"`
-	FotoURL      string    `gorm:"column:foto_url"`
-	CriadoEm     time.Time `gorm:"column:criado_em"`
-	AtualizadoEm time.Time `gorm:"column:atualizado_em"`
+	ID           string     `gorm:"column:id;primaryKey"`
+	ParedaoID    string     `gorm:"column:paredao_id;index"`
+	Nome         string     `gorm:"column:nome"`
+	FotoURL      string     `gorm:"column:foto_url"`
+	OrigemID     *string    `gorm:"column:origem_id"`
+	Retirada     string     `gorm:"column:retirada"`
+	RetiradoEm   *time.Time `gorm:"column:retirado_em"`
+	CriadoEm     time.Time  `gorm:"column:criado_em"`
+	AtualizadoEm time.Time  `gorm:"column:atualizado_em"`
 }
 
 func (participanteModel) TableName() string {
@@ -33,25 +36,38 @@ func (participanteModel) TableName() string {
 }
 
 func (m participanteModel) toDomain() domain.Participante {
-	return domain.Participante{
+	p := domain.Participante{
 		ID:           domain.ParticipanteID(m.ID),
 		ParedaoID:    domain.ParedaoID(m.ParedaoID),
 		Nome:         m.Nome,
 		FotoURL:      m.FotoURL,
+		Retirada:     domain.PoliticaRetirada(m.Retirada),
+		RetiradoEm:   m.RetiradoEm,
 		CriadoEm:     m.CriadoEm,
 		AtualizadoEm: m.AtualizadoEm,
 	}
+	if m.OrigemID != nil {
+		p.OrigemID = domain.ParticipanteID(*m.OrigemID)
+	}
+	return p
 }
 
 func fromDomainParticipante(p domain.Participante) participanteModel {
-	return participanteModel{
+	model := participanteModel{
 		ID:           string(p.ID),
 		ParedaoID:    string(p.ParedaoID),
 		Nome:         p.Nome,
 		FotoURL:      p.FotoURL,
+		Retirada:     string(p.Retirada),
+		RetiradoEm:   p.RetiradoEm,
 		CriadoEm:     p.CriadoEm,
 		AtualizadoEm: p.AtualizadoEm,
 	}
+	if p.OrigemID != "" {
+		origem := string(p.OrigemID)
+		model.OrigemID = &origem
+	}
+	return model
 }
 
 func (r *ParticipanteRepository) BulkCreate(ctx context.Context, paredaoID domain.ParedaoID, participantes []domain.Participante) error {
@@ -91,4 +107,21 @@ func (r *ParticipanteRepository) ListByParedao(ctx context.Context, paredaoID do
 	return result, nil
 }
 
+// Update grava apenas os campos mutáveis; paredão e origem do participante não mudam depois de criados.
+func (r *ParticipanteRepository) Update(ctx context.Context, p domain.Participante) error {
+	model := fromDomainParticipante(p)
+	if err := r.db.WithContext(ctx).Model(&participanteModel{}).
+		Where("id = ?", model.ID).
+		Updates(map[string]any{
+			"nome":          model.Nome,
+			"foto_url":      model.FotoURL,
+			"retirada":      model.Retirada,
+			"retirado_em":   model.RetiradoEm,
+			"atualizado_em": model.AtualizadoEm,
+		}).Error; err != nil {
+		return fmt.Errorf("gorm participante: atualizar: %w", err)
+	}
+	return nil
+}
+
 var _ domain.ParticipanteRepository = (*ParticipanteRepository)(nil)
@@ -227,3 +227,38 @@ func TestParticipanteRepository_ListByParedao_QuandoMultiplosParedoes_DeveRetorn
 	assert.Equal(t, "Participante P1-B", listados1[1].Nome)
 	assert.Equal(t, "Participante P2-A", listados2[0].Nome)
 }
+
+func TestParticipanteRepository_Update_QuandoRetirado_DevePersistirPolitica(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewParticipanteRepository(db)
+
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	paredaoID := domain.ParedaoID(gen.New())
+	now := time.Now()
+
+	participante := domain.Participante{
+		ID:        domain.ParticipanteID(gen.New()),
+		ParedaoID: paredaoID,
+		Nome:      "Alice Melo",
+		CriadoEm:  now,
+	}
+	require.NoError(t, repo.BulkCreate(ctx, paredaoID, []domain.Participante{participante}))
+
+	// Act
+	retiradoEm := now.Add(time.Minute)
+	participante.Retirada = domain.RetiradaManter
+	participante.RetiradoEm = &retiradoEm
+	participante.AtualizadoEm = retiradoEm
+	err := repo.Update(ctx, participante)
+
+	// Assert
+	require.NoError(t, err)
+	listados, err := repo.ListByParedao(ctx, paredaoID)
+	require.NoError(t, err)
+	require.Len(t, listados, 1)
+	assert.True(t, listados[0].Retirado())
+	assert.Equal(t, domain.RetiradaManter, listados[0].Retirada)
+	require.NotNil(t, listados[0].RetiradoEm)
+	assert.WithinDuration(t, retiradoEm, *listados[0].RetiradoEm, time.Second)
+}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// ViradaRepository guarda as trocas de liderança detectadas pelo worker.
+type ViradaRepository struct {
+	db *gorm.DB
+}
+
+func NewViradaRepository(db *gorm.DB) *ViradaRepository {
+	return &ViradaRepository{db: db}
+}
+
+var _ domain.ViradaRepository = (*ViradaRepository)(nil)
+
+// Registrar usa a chave (paredao_id, ordem): se dois workers enxergarem a mesma virada, só o primeiro grava.
+func (r *ViradaRepository) Registrar(ctx context.Context, virada domain.Virada) (bool, error) {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&virada)
+	if res.Error != nil {
+		return false, fmt.Errorf("gorm viradas: registrar: %w", res.Error)
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func (r *ViradaRepository) Ultima(ctx context.Context, paredaoID domain.ParedaoID) (domain.Virada, error) {
+	var virada domain.Virada
+	if err := r.db.WithContext(ctx).
+		Where("paredao_id = ?", paredaoID).
+		Order("ordem DESC").
+		First(&virada).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Virada{}, domain.ErrNotFound
+		}
+		return domain.Virada{}, fmt.Errorf("gorm viradas: ultima: %w", err)
+	}
+	return virada, nil
+}
+
+func (r *ViradaRepository) Listar(ctx context.Context, paredaoID domain.ParedaoID) ([]domain.Virada, error) {
+	var viradas []domain.Virada
+	if err := r.db.WithContext(ctx).
+		Where("paredao_id = ?", paredaoID).
+		Order("ordem").
+		Find(&viradas).Error; err != nil {
+		return nil, fmt.Errorf("gorm viradas: listar: %w", err)
+	}
+	return viradas, nil
+}
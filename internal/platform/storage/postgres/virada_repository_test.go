@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/ids"
+)
+
+func TestViradaRepository_QuandoMesmaOrdemRegistradaDuasVezes_DeveGravarSoAPrimeira(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewViradaRepository(db)
+	ctx := context.Background()
+	paredaoID := domain.ParedaoID(ids.NewGenerator().New())
+
+	// Arrange
+	_, err := repo.Ultima(ctx, paredaoID)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+
+	primeira := domain.Virada{ParedaoID: paredaoID, Ordem: 1, OcorridaEm: time.Now(), NovoLider: "a", Margem: 2.5, TotalVotos: 100}
+	ok, err := repo.Registrar(ctx, primeira)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// Act: dois workers enxergam a mesma troca de liderança
+	segunda := domain.Virada{ParedaoID: paredaoID, Ordem: 2, OcorridaEm: time.Now(), LiderAnterior: "a", NovoLider: "b", Margem: 0.5, TotalVotos: 180}
+	ok, err = repo.Registrar(ctx, segunda)
+	require.NoError(t, err)
+	repetida, err := repo.Registrar(ctx, segunda)
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, ok)
+	assert.False(t, repetida)
+	ultima, err := repo.Ultima(ctx, paredaoID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ParticipanteID("b"), ultima.NovoLider)
+	viradas, err := repo.Listar(ctx, paredaoID)
+	require.NoError(t, err)
+	require.Len(t, viradas, 2)
+	assert.Equal(t, 1, viradas[0].Ordem)
+	assert.Equal(t, domain.ParticipanteID("a"), viradas[1].LiderAnterior)
+}
@@ -2,21 +2,44 @@ package postgres
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/auditoria"
 )
 
 // VotoRepository guarda votos e expõe consultas agregadas próprias do Postgres.
 type VotoRepository struct {
-	db *gorm.DB
+	db   *gorm.DB
+	fuso *time.Location
 }
 
-func NewVotoRepository(db *gorm.DB) *VotoRepository {
-	return &VotoRepository{db: db}
+// OpcaoVoto ajusta o VotoRepository na construção.
+type OpcaoVoto func(*VotoRepository)
+
+// ComFuso define o fuso dos relatórios: as horas e os intervalos das séries começam no relógio desse fuso,
+// e os instantes devolvidos já vêm nele. O nome precisa ser IANA (ex.: America/Sao_Paulo), porque vai
+// para o AT TIME ZONE do Postgres. Sem a opção, vale UTC.
+func ComFuso(fuso *time.Location) OpcaoVoto {
+	return func(r *VotoRepository) {
+		if fuso != nil {
+			r.fuso = fuso
+		}
+	}
+}
+
+func NewVotoRepository(db *gorm.DB, opts ...OpcaoVoto) *VotoRepository {
+	r := &VotoRepository{db: db, fuso: time.UTC}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 type votoModel struct {
@@ -25,7 +48,11 @@ type votoModel struct {
 	ParticipanteID string    `gorm:"column:participante_id;index"`
 	OrigemIP       string    `gorm:"column:origem_ip"`
 	UserAgent      string    `gorm:"column:user_agent"`
+	EleitorID      string    `gorm:"column:eleitor_id"`
+	Modalidade     string    `gorm:"column:modalidade"`
 	CriadoEm       time.Time `gorm:"column:criado_em"`
+	Seq            int64     `gorm:"column:seq"`
+	Hash           string    `gorm:"column:hash"`
 }
 
 func (votoModel) TableName() string {
@@ -39,16 +66,107 @@ func fromDomainVoto(v domain.Voto) votoModel {
 		ParticipanteID: string(v.ParticipanteID),
 		OrigemIP:       v.OrigemIP,
 		UserAgent:      v.UserAgent,
+		EleitorID:      string(v.EleitorID),
+		Modalidade:     string(v.Modalidade),
 		CriadoEm:       v.CriadoEm,
 	}
 }
 
+func (m votoModel) toDomain() domain.Voto {
+	return domain.Voto{
+		ID:             domain.VotoID(m.ID),
+		ParedaoID:      domain.ParedaoID(m.ParedaoID),
+		ParticipanteID: domain.ParticipanteID(m.ParticipanteID),
+		OrigemIP:       m.OrigemIP,
+		UserAgent:      m.UserAgent,
+		EleitorID:      domain.EleitorID(m.EleitorID),
+		Modalidade:     domain.Modalidade(m.Modalidade),
+		CriadoEm:       m.CriadoEm,
+	}
+}
+
+// Registrar grava o voto e o anexa à cadeia de auditoria do paredão na mesma transação. A ponta da cadeia
+// é travada com SELECT ... FOR UPDATE, então gravações do mesmo paredão ficam serializadas.
 func (r *VotoRepository) Registrar(ctx context.Context, voto domain.Voto) error {
 	model := fromDomainVoto(voto)
-	if err := r.db.WithContext(ctx).Create(&model).Error; err != nil {
-		return fmt.Errorf("gorm votos: inserir: %w", err)
+	if model.Modalidade == "" {
+		model.Modalidade = string(domain.ModalidadeTorcida)
+	}
+	if model.CriadoEm.IsZero() {
+		model.CriadoEm = time.Now()
+	}
+	// O horário gravado precisa ser o mesmo que entra no hash.
+	model.CriadoEm = auditoria.Instante(model.CriadoEm)
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ponta, err := travarCadeia(tx, voto.ParedaoID)
+		if err != nil {
+			return err
+		}
+		cadeia, err := auditoria.Restaurar(ponta)
+		if err != nil {
+			return fmt.Errorf("gorm votos: cadeia do paredao %s: %w", voto.ParedaoID, err)
+		}
+		var elo auditoria.Hash
+		model.Seq, elo = cadeia.Anexar(auditoria.Folha(model.auditado()))
+		model.Hash = elo.String()
+
+		if err := tx.Create(&model).Error; err != nil {
+			// Com TranslateError o driver devolve ErrDuplicatedKey para a violação do índice de voto único.
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return domain.ErrVotoDuplicado
+			}
+			return fmt.Errorf("gorm votos: inserir: %w", err)
+		}
+		nova := cadeia.Ponta(voto.ParedaoID)
+		nova.AtualizadoEm = time.Now()
+		if err := tx.Save(&nova).Error; err != nil {
+			return fmt.Errorf("gorm votos: atualizar cadeia: %w", err)
+		}
+		return nil
+	})
+}
+
+// travarCadeia devolve a ponta da cadeia do paredão com a linha travada, criando-a no primeiro voto.
+func travarCadeia(tx *gorm.DB, paredaoID domain.ParedaoID) (domain.CadeiaVotos, error) {
+	var ponta domain.CadeiaVotos
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ponta, "paredao_id = ?", paredaoID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// Dois workers podem criar a ponta ao mesmo tempo; quem perde a corrida só trava a linha do outro.
+		nova := domain.CadeiaVotos{ParedaoID: paredaoID, AtualizadoEm: time.Now()}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&nova).Error; err != nil {
+			return ponta, fmt.Errorf("gorm votos: criar cadeia: %w", err)
+		}
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ponta, "paredao_id = ?", paredaoID).Error
 	}
-	return nil
+	if err != nil {
+		return ponta, fmt.Errorf("gorm votos: travar cadeia: %w", err)
+	}
+	return ponta, nil
+}
+
+func (m votoModel) auditado() domain.VotoAuditado {
+	return domain.VotoAuditado{
+		Seq:            m.Seq,
+		VotoID:         domain.VotoID(m.ID),
+		ParedaoID:      domain.ParedaoID(m.ParedaoID),
+		ParticipanteID: domain.ParticipanteID(m.ParticipanteID),
+		Modalidade:     domain.Modalidade(m.Modalidade),
+		CriadoEm:       m.CriadoEm,
+		Hash:           m.Hash,
+		OrigemIP:       m.OrigemIP,
+	}
+}
+
+func (r *VotoRepository) FindByID(ctx context.Context, id domain.VotoID) (domain.Voto, error) {
+	var model votoModel
+	if err := r.db.WithContext(ctx).First(&model, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.Voto{}, domain.ErrNotFound
+		}
+		return domain.Voto{}, fmt.Errorf("gorm votos: buscar id: %w", err)
+	}
+	return model.toDomain(), nil
 }
 
 func (r *VotoRepository) TotalPorParedao(ctx context.Context, id domain.ParedaoID) (int64, error) {
@@ -84,22 +202,77 @@ func (r *VotoRepository) TotalPorParticipante(ctx context.Context, paredaoID dom
 	return totais, nil
 }
 
+func (r *VotoRepository) TotalPorModalidade(ctx context.Context, paredaoID domain.ParedaoID) (map[domain.Modalidade]map[domain.ParticipanteID]int64, error) {
+	return r.totalPorModalidade(ctx, paredaoID, time.Time{})
+}
+
+func (r *VotoRepository) TotalPorModalidadeAte(ctx context.Context, paredaoID domain.ParedaoID, ate time.Time) (map[domain.Modalidade]map[domain.ParticipanteID]int64, error) {
+	return r.totalPorModalidade(ctx, paredaoID, ate)
+}
+
+// totalPorModalidade agrega por urna e participante; ate zerado dispensa o corte por horário.
+func (r *VotoRepository) totalPorModalidade(ctx context.Context, paredaoID domain.ParedaoID, ate time.Time) (map[domain.Modalidade]map[domain.ParticipanteID]int64, error) {
+	type resultado struct {
+		Modalidade     string
+		ParticipanteID string
+		Total          int64
+	}
+	query := r.db.WithContext(ctx).
+		Model(&votoModel{}).
+		Select("modalidade as modalidade, participante_id as participante_id, COUNT(*) as total").
+		Where("paredao_id = ?", paredaoID)
+	if !ate.IsZero() {
+		query = query.Where("criado_em <= ?", ate)
+	}
+	var res []resultado
+	if err := query.
+		Group("modalidade, participante_id").
+		Scan(&res).Error; err != nil {
+		return nil, fmt.Errorf("gorm votos: total modalidade: %w", err)
+	}
+
+	totais := make(map[domain.Modalidade]map[domain.ParticipanteID]int64)
+	for _, item := range res {
+		modalidade := domain.Modalidade(item.Modalidade)
+		if totais[modalidade] == nil {
+			totais[modalidade] = make(map[domain.ParticipanteID]int64)
+		}
+		totais[modalidade][domain.ParticipanteID(item.ParticipanteID)] = item.Total
+	}
+	return totais, nil
+}
+
 func (r *VotoRepository) TotalPorHora(ctx context.Context, paredaoID domain.ParedaoID) ([]domain.ParcialHora, error) {
+	return r.totalPorHora(ctx, paredaoID, time.Time{})
+}
+
+func (r *VotoRepository) TotalPorHoraAte(ctx context.Context, paredaoID domain.ParedaoID, ate time.Time) ([]domain.ParcialHora, error) {
+	return r.totalPorHora(ctx, paredaoID, ate)
+}
+
+func (r *VotoRepository) totalPorHora(ctx context.Context, paredaoID domain.ParedaoID, ate time.Time) ([]domain.ParcialHora, error) {
 	type resultado struct {
 		Hora  time.Time
 		Total int64
 	}
 
+	// Sem corte, usamos um limite que nenhum voto alcança para manter uma única consulta.
+	limite := ate
+	if limite.IsZero() {
+		limite = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+
 	var res []resultado
 	if err := r.db.WithContext(ctx).
-		// Usamos SQL cru para aproveitar o `date_trunc` do Postgres sem montar lógica manual.
+		// Usamos SQL cru para aproveitar o `date_trunc` do Postgres sem montar lógica manual. O truncamento
+		// acontece no relógio do fuso dos relatórios, e não no da sessão do banco.
 		Raw(`
-            SELECT date_trunc('hour', criado_em) AS hora, COUNT(*) AS total
+            SELECT date_trunc('hour', criado_em AT TIME ZONE @fuso) AT TIME ZONE @fuso AS hora, COUNT(*) AS total
             FROM votos
-            WHERE paredao_id = ?
+            WHERE paredao_id = @paredao AND criado_em <= @limite
             GROUP BY hora
             ORDER BY hora ASC
-        `, paredaoID).
+        `, sql.Named("fuso", r.fuso.String()), sql.Named("paredao", paredaoID), sql.Named("limite", limite)).
 		Scan(&res).Error; err != nil {
 		return nil, fmt.Errorf("gorm votos: total hora: %w", err)
 	}
@@ -108,11 +281,68 @@ func (r *VotoRepository) TotalPorHora(ctx context.Context, paredaoID domain.Pare
 	for i, item := range res {
 		parciais[i] = domain.ParcialHora{
 			ParedaoID: domain.ParedaoID(paredaoID),
-			Hora:      item.Hora,
+			Hora:      item.Hora.In(r.fuso),
 			Total:     item.Total,
 		}
 	}
 	return parciais, nil
 }
 
+func (r *VotoRepository) TotalPorIntervalo(ctx context.Context, paredaoID domain.ParedaoID, intervalo time.Duration) ([]domain.ParcialIntervalo, error) {
+	return r.totalPorIntervalo(ctx, paredaoID, intervalo, time.Time{})
+}
+
+func (r *VotoRepository) TotalPorIntervaloAte(ctx context.Context, paredaoID domain.ParedaoID, intervalo time.Duration, ate time.Time) ([]domain.ParcialIntervalo, error) {
+	return r.totalPorIntervalo(ctx, paredaoID, intervalo, ate)
+}
+
+// totalPorIntervalo arredonda para baixo, no múltiplo do intervalo, o epoch do horário de parede de cada
+// voto no fuso dos relatórios, o que vale para qualquer duração em segundos sem depender do date_bin do
+// Postgres 14. O início volta a ser um instante interpretando esse horário no mesmo fuso.
+func (r *VotoRepository) totalPorIntervalo(ctx context.Context, paredaoID domain.ParedaoID, intervalo time.Duration, ate time.Time) ([]domain.ParcialIntervalo, error) {
+	type resultado struct {
+		ParticipanteID string
+		Modalidade     string
+		Inicio         time.Time
+		Total          int64
+	}
+
+	segundos := int64(intervalo / time.Second)
+	if segundos <= 0 {
+		return nil, fmt.Errorf("gorm votos: intervalo %s invalido", intervalo)
+	}
+	limite := ate
+	if limite.IsZero() {
+		limite = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+	}
+
+	var res []resultado
+	if err := r.db.WithContext(ctx).
+		Raw(`
+            SELECT participante_id, modalidade,
+                   (to_timestamp(floor(extract(epoch FROM criado_em AT TIME ZONE @fuso) / @segundos) * @segundos)
+                       AT TIME ZONE 'UTC') AT TIME ZONE @fuso AS inicio,
+                   COUNT(*) AS total
+            FROM votos
+            WHERE paredao_id = @paredao AND criado_em <= @limite
+            GROUP BY participante_id, modalidade, inicio
+            ORDER BY inicio ASC, participante_id ASC, modalidade ASC
+        `, sql.Named("fuso", r.fuso.String()), sql.Named("segundos", segundos), sql.Named("paredao", paredaoID), sql.Named("limite", limite)).
+		Scan(&res).Error; err != nil {
+		return nil, fmt.Errorf("gorm votos: total intervalo: %w", err)
+	}
+
+	parciais := make([]domain.ParcialIntervalo, len(res))
+	for i, item := range res {
+		parciais[i] = domain.ParcialIntervalo{
+			ParedaoID:      paredaoID,
+			ParticipanteID: domain.ParticipanteID(item.ParticipanteID),
+			Modalidade:     domain.Modalidade(item.Modalidade),
+			Inicio:         item.Inicio.In(r.fuso),
+			Total:          item.Total,
+		}
+	}
+	return parciais, nil
+}
+
 var _ domain.VotoRepository = (*VotoRepository)(nil)
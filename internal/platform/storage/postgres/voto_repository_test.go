@@ -280,3 +280,121 @@ func TestVotoRepository_MultiplosParedoes_QuandoVotosEmParedoesDiferentes_DeveIs
 	assert.Equal(t, int64(2), total1)
 	assert.Equal(t, int64(3), total2)
 }
+
+func TestVotoRepository_Registrar_QuandoVotoUnicoRepetido_DeveRetornarErroDuplicado(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewVotoRepository(db)
+
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	paredaoID := domain.ParedaoID(gen.New())
+
+	novoVoto := func(modalidade domain.Modalidade) domain.Voto {
+		return domain.Voto{
+			ID:             domain.VotoID(gen.New()),
+			ParedaoID:      paredaoID,
+			ParticipanteID: domain.ParticipanteID(gen.New()),
+			EleitorID:      "conta-1",
+			Modalidade:     modalidade,
+			CriadoEm:       time.Now(),
+		}
+	}
+
+	// Act
+	require.NoError(t, repo.Registrar(ctx, novoVoto(domain.ModalidadeUnico)))
+	errDuplicado := repo.Registrar(ctx, novoVoto(domain.ModalidadeUnico))
+	// Votos de torcida da mesma conta não entram no índice único.
+	require.NoError(t, repo.Registrar(ctx, novoVoto(domain.ModalidadeTorcida)))
+	require.NoError(t, repo.Registrar(ctx, novoVoto(domain.ModalidadeTorcida)))
+
+	// Assert
+	assert.ErrorIs(t, errDuplicado, domain.ErrVotoDuplicado)
+	total, err := repo.TotalPorParedao(ctx, paredaoID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), total)
+}
+
+func TestVotoRepository_TotalPorModalidade_QuandoExistemVotos_DeveSepararUrnas(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewVotoRepository(db)
+
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	paredaoID := domain.ParedaoID(gen.New())
+	alice := domain.ParticipanteID(gen.New())
+	bruno := domain.ParticipanteID(gen.New())
+
+	votos := []domain.Voto{
+		{ParticipanteID: alice, Modalidade: domain.ModalidadeUnico, EleitorID: "c1"},
+		{ParticipanteID: alice, Modalidade: domain.ModalidadeUnico, EleitorID: "c2"},
+		{ParticipanteID: bruno, Modalidade: domain.ModalidadeTorcida},
+		{ParticipanteID: alice, Modalidade: domain.ModalidadeTorcida},
+		{ParticipanteID: bruno, Modalidade: domain.ModalidadeTorcida},
+	}
+	for _, voto := range votos {
+		voto.ID = domain.VotoID(gen.New())
+		voto.ParedaoID = paredaoID
+		voto.CriadoEm = time.Now()
+		require.NoError(t, repo.Registrar(ctx, voto))
+	}
+
+	// Act
+	totais, err := repo.TotalPorModalidade(ctx, paredaoID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, map[domain.Modalidade]map[domain.ParticipanteID]int64{
+		domain.ModalidadeUnico:   {alice: 2},
+		domain.ModalidadeTorcida: {alice: 1, bruno: 2},
+	}, totais)
+}
+
+func TestVotoRepository_TotalPorModalidadeAte_QuandoHaVotosDepoisDoCorte_DeveIgnorarOsPosteriores(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewVotoRepository(db)
+
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	paredaoID := domain.ParedaoID(gen.New())
+	alice := domain.ParticipanteID(gen.New())
+	corte := time.Date(2024, 1, 1, 21, 0, 0, 0, time.UTC)
+
+	for _, criadoEm := range []time.Time{corte.Add(-time.Minute), corte.Add(time.Minute)} {
+		voto := domain.Voto{
+			ID:             domain.VotoID(gen.New()),
+			ParedaoID:      paredaoID,
+			ParticipanteID: alice,
+			Modalidade:     domain.ModalidadeTorcida,
+			CriadoEm:       criadoEm,
+		}
+		require.NoError(t, repo.Registrar(ctx, voto))
+	}
+
+	// Act
+	totais, err := repo.TotalPorModalidadeAte(ctx, paredaoID, corte)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), totais[domain.ModalidadeTorcida][alice])
+}
+
+func TestNewVotoRepository_ComFuso(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+
+	casos := []struct {
+		nome     string
+		opts     []OpcaoVoto
+		esperado *time.Location
+	}{
+		{nome: "sem opcao vale UTC", esperado: time.UTC},
+		{nome: "fuso nil mantem UTC", opts: []OpcaoVoto{ComFuso(nil)}, esperado: time.UTC},
+		{nome: "fuso informado", opts: []OpcaoVoto{ComFuso(saoPaulo)}, esperado: saoPaulo},
+	}
+	for _, c := range casos {
+		t.Run(c.nome, func(t *testing.T) {
+			repo := NewVotoRepository(nil, c.opts...)
+			assert.Equal(t, c.esperado, repo.fuso)
+		})
+	}
+}
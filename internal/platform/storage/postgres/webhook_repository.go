@@ -0,0 +1,209 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/ids"
+)
+
+// WebhookRepository guarda assinaturas, eventos e a fila de entregas. A fila é a própria tabela de
+// entregas: o worker reserva as vencidas com SKIP LOCKED, então várias réplicas dividem o trabalho.
+type WebhookRepository struct {
+	db  *gorm.DB
+	ids *ids.Generator
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db, ids: ids.DefaultGenerator()}
+}
+
+var _ domain.WebhookRepository = (*WebhookRepository)(nil)
+
+func (r *WebhookRepository) Criar(ctx context.Context, webhook domain.Webhook) error {
+	if err := r.db.WithContext(ctx).Create(&webhook).Error; err != nil {
+		return fmt.Errorf("gorm webhooks: criar: %w", err)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) Listar(ctx context.Context) ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	if err := r.db.WithContext(ctx).Order("criado_em").Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("gorm webhooks: listar: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) Desativar(ctx context.Context, id domain.WebhookID) error {
+	res := r.db.WithContext(ctx).Model(&domain.Webhook{}).Where("id = ?", id).Update("ativo", false)
+	if res.Error != nil {
+		return fmt.Errorf("gorm webhooks: desativar: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// Publicar grava o evento e as entregas na mesma transação; a chave única do evento descarta repetições.
+func (r *WebhookRepository) Publicar(ctx context.Context, evento domain.EventoParedao) (bool, error) {
+	publicado := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "chave"}}, DoNothing: true}).Create(&evento)
+		if res.Error != nil {
+			return fmt.Errorf("gorm webhooks: publicar evento: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		publicado = true
+
+		var ativos []domain.Webhook
+		if err := tx.Where("ativo = ?", true).Find(&ativos).Error; err != nil {
+			return fmt.Errorf("gorm webhooks: assinantes: %w", err)
+		}
+		var entregas []domain.EntregaWebhook
+		for _, w := range ativos {
+			if !w.Assina(evento.Tipo) {
+				continue
+			}
+			entregas = append(entregas, domain.EntregaWebhook{
+				ID:               domain.EntregaID(r.ids.New()),
+				WebhookID:        w.ID,
+				EventoID:         evento.ID,
+				Tipo:             evento.Tipo,
+				Status:           domain.EntregaPendente,
+				ProximaTentativa: evento.OcorridoEm,
+				CriadaEm:         evento.OcorridoEm,
+			})
+		}
+		if len(entregas) == 0 {
+			return nil
+		}
+		if err := tx.Create(&entregas).Error; err != nil {
+			return fmt.Errorf("gorm webhooks: agendar entregas: %w", err)
+		}
+		return nil
+	})
+	return publicado, err
+}
+
+func (r *WebhookRepository) ReservarEntregas(ctx context.Context, agora time.Time, reserva time.Duration, limite int) ([]domain.EntregaAgendada, error) {
+	var agendadas []domain.EntregaAgendada
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var entregas []domain.EntregaWebhook
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND proxima_tentativa <= ?", domain.EntregaPendente, agora).
+			Order("proxima_tentativa").
+			Limit(limite).
+			Find(&entregas).Error; err != nil {
+			return fmt.Errorf("gorm webhooks: reservar entregas: %w", err)
+		}
+		if len(entregas) == 0 {
+			return nil
+		}
+
+		// O Postgres guarda microssegundos: truncar aqui deixa o valor devolvido igual ao gravado.
+		reservadaAte := agora.Add(reserva).Truncate(time.Microsecond)
+		idsEntregas := make([]domain.EntregaID, len(entregas))
+		idsWebhooks := make([]domain.WebhookID, 0, len(entregas))
+		idsEventos := make([]domain.EventoID, 0, len(entregas))
+		for i, e := range entregas {
+			idsEntregas[i] = e.ID
+			idsWebhooks = append(idsWebhooks, e.WebhookID)
+			idsEventos = append(idsEventos, e.EventoID)
+		}
+		if err := tx.Model(&domain.EntregaWebhook{}).
+			Where("id IN ?", idsEntregas).
+			Update("proxima_tentativa", reservadaAte).Error; err != nil {
+			return fmt.Errorf("gorm webhooks: reservar entregas: %w", err)
+		}
+
+		var webhooks []domain.Webhook
+		if err := tx.Where("id IN ?", idsWebhooks).Find(&webhooks).Error; err != nil {
+			return fmt.Errorf("gorm webhooks: destinos: %w", err)
+		}
+		var eventos []domain.EventoParedao
+		if err := tx.Where("id IN ?", idsEventos).Find(&eventos).Error; err != nil {
+			return fmt.Errorf("gorm webhooks: eventos: %w", err)
+		}
+		destinos := make(map[domain.WebhookID]domain.Webhook, len(webhooks))
+		for _, w := range webhooks {
+			destinos[w.ID] = w
+		}
+		porID := make(map[domain.EventoID]domain.EventoParedao, len(eventos))
+		for _, e := range eventos {
+			porID[e.ID] = e
+		}
+		for _, e := range entregas {
+			e.ProximaTentativa = reservadaAte
+			destino := destinos[e.WebhookID]
+			agendadas = append(agendadas, domain.EntregaAgendada{
+				Entrega: e,
+				URL:     destino.URL,
+				Segredo: destino.Segredo,
+				Evento:  porID[e.EventoID],
+			})
+		}
+		return nil
+	})
+	return agendadas, err
+}
+
+func (r *WebhookRepository) RegistrarTentativa(ctx context.Context, entrega domain.EntregaWebhook, reservadaAte time.Time) error {
+	res := r.db.WithContext(ctx).
+		Where("proxima_tentativa = ?", reservadaAte).
+		Select("status", "tentativas", "proxima_tentativa", "ultimo_status_http", "ultimo_erro", "entregue_em").
+		Updates(&entrega)
+	if res.Error != nil {
+		return fmt.Errorf("gorm webhooks: registrar tentativa: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return domain.ErrReservaVencida
+	}
+	return nil
+}
+
+func (r *WebhookRepository) Entregas(ctx context.Context, id domain.WebhookID, limite int) ([]domain.EntregaWebhook, error) {
+	var entregas []domain.EntregaWebhook
+	if err := r.db.WithContext(ctx).
+		Where("webhook_id = ?", id).
+		Order("criada_em DESC, id DESC").
+		Limit(limite).
+		Find(&entregas).Error; err != nil {
+		return nil, fmt.Errorf("gorm webhooks: entregas: %w", err)
+	}
+	return entregas, nil
+}
+
+func (r *WebhookRepository) Reenviar(ctx context.Context, id domain.WebhookID, entregaID domain.EntregaID, agora time.Time) (domain.EntregaWebhook, error) {
+	var entrega domain.EntregaWebhook
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&entrega, "id = ? AND webhook_id = ?", entregaID, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return domain.ErrNotFound
+			}
+			return fmt.Errorf("gorm webhooks: reenviar: %w", err)
+		}
+		entrega.Status = domain.EntregaPendente
+		entrega.Tentativas = 0
+		entrega.ProximaTentativa = agora
+		entrega.EntregueEm = nil
+		if err := tx.Select("status", "tentativas", "proxima_tentativa", "entregue_em").Updates(&entrega).Error; err != nil {
+			return fmt.Errorf("gorm webhooks: reenviar: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return domain.EntregaWebhook{}, err
+	}
+	return entrega, nil
+}
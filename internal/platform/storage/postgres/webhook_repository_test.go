@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+	"github.com/marcelojr/desafio-globo/internal/platform/ids"
+)
+
+func TestWebhookRepository_QuandoEventoPublicado_DeveAgendarSoParaAssinantesAtivos(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewWebhookRepository(db)
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	agora := time.Now().UTC().Truncate(time.Second)
+
+	// Arrange: um assinante de viradas, um de finalização e um de viradas desativado
+	viradas := domain.Webhook{ID: domain.WebhookID(gen.New()), URL: "https://a.example/hook", Segredo: "s1", Eventos: []domain.TipoEvento{domain.EventoVirada}, Ativo: true, CriadoEm: agora}
+	finalizacao := domain.Webhook{ID: domain.WebhookID(gen.New()), URL: "https://b.example/hook", Segredo: "s2", Eventos: []domain.TipoEvento{domain.EventoParedaoFinalizado}, Ativo: true, CriadoEm: agora}
+	inativo := domain.Webhook{ID: domain.WebhookID(gen.New()), URL: "https://c.example/hook", Segredo: "s3", Eventos: []domain.TipoEvento{domain.EventoVirada}, Ativo: true, CriadoEm: agora}
+	for _, w := range []domain.Webhook{viradas, finalizacao, inativo} {
+		require.NoError(t, repo.Criar(ctx, w))
+	}
+	require.NoError(t, repo.Desativar(ctx, inativo.ID))
+	require.ErrorIs(t, repo.Desativar(ctx, "inexistente"), domain.ErrNotFound)
+
+	evento := domain.EventoParedao{
+		ID: domain.EventoID(gen.New()), Chave: "paredao.virada:p1:2", Tipo: domain.EventoVirada,
+		ParedaoID: "p1", OcorridoEm: agora, Dados: json.RawMessage(`{"novo_lider":"b"}`),
+	}
+
+	// Act
+	publicado, err := repo.Publicar(ctx, evento)
+	require.NoError(t, err)
+	repetido, err := repo.Publicar(ctx, domain.EventoParedao{ID: domain.EventoID(gen.New()), Chave: evento.Chave, Tipo: evento.Tipo, ParedaoID: "p1", OcorridoEm: agora, Dados: evento.Dados})
+	require.NoError(t, err)
+	agendadas, err := repo.ReservarEntregas(ctx, agora, time.Minute, 10)
+	require.NoError(t, err)
+	reservadas, err := repo.ReservarEntregas(ctx, agora, time.Minute, 10)
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, publicado)
+	assert.False(t, repetido)
+	require.Len(t, agendadas, 1)
+	assert.Equal(t, viradas.ID, agendadas[0].Entrega.WebhookID)
+	assert.Equal(t, "https://a.example/hook", agendadas[0].URL)
+	assert.Equal(t, "s1", agendadas[0].Segredo)
+	assert.JSONEq(t, `{"novo_lider":"b"}`, string(agendadas[0].Evento.Dados))
+	assert.Empty(t, reservadas, "entrega reservada não volta antes do fim da reserva")
+}
+
+func TestWebhookRepository_QuandoEntregaFalhaEReenviada_DeveVoltarParaAFila(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewWebhookRepository(db)
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	agora := time.Now().UTC().Truncate(time.Second)
+
+	webhook := domain.Webhook{ID: domain.WebhookID(gen.New()), URL: "https://a.example/hook", Segredo: "s", Eventos: []domain.TipoEvento{domain.EventoParedaoAberto}, Ativo: true, CriadoEm: agora}
+	require.NoError(t, repo.Criar(ctx, webhook))
+	_, err := repo.Publicar(ctx, domain.EventoParedao{ID: domain.EventoID(gen.New()), Chave: "paredao.aberto:p1:", Tipo: domain.EventoParedaoAberto, ParedaoID: "p1", OcorridoEm: agora, Dados: json.RawMessage(`{}`)})
+	require.NoError(t, err)
+	agendadas, err := repo.ReservarEntregas(ctx, agora, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, agendadas, 1)
+
+	// Arrange: as tentativas acabaram
+	entrega := agendadas[0].Entrega
+	entrega.Status = domain.EntregaFalhou
+	entrega.Tentativas = 8
+	entrega.UltimoStatusHTTP = 500
+	entrega.UltimoErro = "webhook: status inesperado 500"
+	require.NoError(t, repo.RegistrarTentativa(ctx, entrega, agendadas[0].Entrega.ProximaTentativa))
+
+	// Act
+	_, err = repo.Reenviar(ctx, "outro", entrega.ID, agora)
+	require.ErrorIs(t, err, domain.ErrNotFound)
+	reenviada, err := repo.Reenviar(ctx, webhook.ID, entrega.ID, agora.Add(time.Hour))
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, domain.EntregaPendente, reenviada.Status)
+	assert.Zero(t, reenviada.Tentativas)
+	registro, err := repo.Entregas(ctx, webhook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, registro, 1)
+	assert.Equal(t, 500, registro[0].UltimoStatusHTTP)
+	assert.Equal(t, domain.EntregaPendente, registro[0].Status)
+	agendadas, err = repo.ReservarEntregas(ctx, agora.Add(time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Len(t, agendadas, 1)
+}
+
+func TestWebhookRepository_QuandoReservaVenceuEOutroWorkerAssumiu_NaoDeveGravarTentativaAntiga(t *testing.T) {
+	db := setupPostgres(t)
+	repo := NewWebhookRepository(db)
+	ctx := context.Background()
+	gen := ids.NewGenerator()
+	agora := time.Now().UTC().Truncate(time.Second)
+
+	webhook := domain.Webhook{ID: domain.WebhookID(gen.New()), URL: "https://a.example/hook", Segredo: "s", Eventos: []domain.TipoEvento{domain.EventoParedaoAberto}, Ativo: true, CriadoEm: agora}
+	require.NoError(t, repo.Criar(ctx, webhook))
+	_, err := repo.Publicar(ctx, domain.EventoParedao{ID: domain.EventoID(gen.New()), Chave: "paredao.aberto:p1:", Tipo: domain.EventoParedaoAberto, ParedaoID: "p1", OcorridoEm: agora, Dados: json.RawMessage(`{}`)})
+	require.NoError(t, err)
+
+	// Arrange: o primeiro worker reserva por um minuto e demora; o segundo reserva depois que a reserva vence
+	primeira, err := repo.ReservarEntregas(ctx, agora, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, primeira, 1)
+	assert.Equal(t, agora.Add(time.Minute), primeira[0].Entrega.ProximaTentativa.UTC())
+	segunda, err := repo.ReservarEntregas(ctx, agora.Add(2*time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, segunda, 1)
+
+	// Act
+	atrasada := primeira[0].Entrega
+	atrasada.Status = domain.EntregaPendente
+	atrasada.Tentativas = 1
+	atrasada.ProximaTentativa = agora.Add(time.Hour)
+	errAtrasada := repo.RegistrarTentativa(ctx, atrasada, primeira[0].Entrega.ProximaTentativa)
+	atual := segunda[0].Entrega
+	atual.Status = domain.EntregaEntregue
+	atual.Tentativas = 1
+	errAtual := repo.RegistrarTentativa(ctx, atual, segunda[0].Entrega.ProximaTentativa)
+
+	// Assert
+	assert.ErrorIs(t, errAtrasada, domain.ErrReservaVencida)
+	require.NoError(t, errAtual)
+	registro, err := repo.Entregas(ctx, webhook.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, registro, 1)
+	assert.Equal(t, domain.EntregaEntregue, registro[0].Status)
+}
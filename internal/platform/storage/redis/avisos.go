@@ -0,0 +1,73 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// intervaloPingAvisos é o silêncio máximo no canal antes de testarmos a conexão com um PING.
+const intervaloPingAvisos = 15 * time.Second
+
+// AvisosParciais usa Pub/Sub do Redis para avisar as réplicas da API que um paredão recebeu votos.
+// A mensagem é só o ID do paredão: cada réplica recalcula as parciais por conta própria.
+type AvisosParciais struct {
+	client *redis.Client
+	canal  string
+}
+
+func NewAvisosParciais(client *redis.Client, canal string) *AvisosParciais {
+	if canal == "" {
+		canal = "parciais:atualizadas"
+	}
+	return &AvisosParciais{client: client, canal: canal}
+}
+
+var _ domain.AvisosParciais = (*AvisosParciais)(nil)
+
+func (a *AvisosParciais) Publicar(ctx context.Context, paredaoID domain.ParedaoID) error {
+	if err := a.client.Publish(ctx, a.canal, string(paredaoID)).Err(); err != nil {
+		return fmt.Errorf("redis avisos: publicar %s: %w", paredaoID, err)
+	}
+	return nil
+}
+
+func (a *AvisosParciais) Escutar(ctx context.Context, conectado func(), receber func(domain.ParedaoID)) error {
+	ps := a.client.Subscribe(ctx, a.canal)
+	defer ps.Close()
+	// A leitura bloqueante do go-redis não observa ctx; fechar a inscrição a destrava no cancelamento.
+	parar := context.AfterFunc(ctx, func() { _ = ps.Close() })
+	defer parar()
+
+	// Receive direto (em vez de ps.Channel) para que uma queda chegue a quem escuta, que então cai no polling.
+	if _, err := ps.Receive(ctx); err != nil {
+		return fmt.Errorf("redis avisos: inscrever em %s: %w", a.canal, err)
+	}
+	conectado()
+
+	for {
+		msg, err := ps.ReceiveTimeout(ctx, intervaloPingAvisos)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				if err := ps.Ping(ctx); err != nil {
+					return fmt.Errorf("redis avisos: ping: %w", err)
+				}
+				continue
+			}
+			return fmt.Errorf("redis avisos: receber: %w", err)
+		}
+		if m, ok := msg.(*redis.Message); ok {
+			receber(domain.ParedaoID(m.Payload))
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+func TestAvisosParciais_Escutar_QuandoPublicado_DeveEntregarParedao(t *testing.T) {
+	client, _ := setupRedis(t)
+	avisos := NewAvisosParciais(client, "parciais")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conectado := make(chan struct{})
+	recebidos := make(chan domain.ParedaoID, 1)
+	fim := make(chan error, 1)
+	go func() {
+		fim <- avisos.Escutar(ctx, func() { close(conectado) }, func(id domain.ParedaoID) { recebidos <- id })
+	}()
+
+	select {
+	case <-conectado:
+	case <-time.After(time.Second):
+		t.Fatal("inscricao nao confirmada")
+	}
+
+	// Act
+	require.NoError(t, avisos.Publicar(ctx, "par-1"))
+
+	// Assert
+	select {
+	case id := <-recebidos:
+		assert.Equal(t, domain.ParedaoID("par-1"), id)
+	case <-time.After(time.Second):
+		t.Fatal("aviso nao chegou")
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-fim, context.Canceled)
+}
+
+func TestAvisosParciais_Escutar_QuandoRedisCai_DeveRetornarErro(t *testing.T) {
+	client, mr := setupRedis(t)
+	avisos := NewAvisosParciais(client, "parciais")
+
+	conectado := make(chan struct{})
+	fim := make(chan error, 1)
+	go func() {
+		fim <- avisos.Escutar(context.Background(), func() { close(conectado) }, func(domain.ParedaoID) {})
+	}()
+	<-conectado
+
+	// Act
+	mr.Close()
+
+	// Assert
+	select {
+	case err := <-fim:
+		assert.Error(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("queda do redis nao foi percebida")
+	}
+}
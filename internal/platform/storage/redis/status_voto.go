@@ -0,0 +1,63 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// StatusVotos guarda o andamento de cada voto enfileirado em JSON com TTL curto: passado o prazo, a
+// consulta recorre ao Postgres.
+type StatusVotos struct {
+	client *redis.Client
+	prefix string
+	ttl    time.Duration
+}
+
+func NewStatusVotos(client *redis.Client, prefix string, ttl time.Duration) *StatusVotos {
+	if prefix == "" {
+		prefix = "voto-status"
+	}
+	if ttl <= 0 {
+		ttl = time.Hour
+	}
+	return &StatusVotos{client: client, prefix: prefix, ttl: ttl}
+}
+
+var _ domain.StatusVotos = (*StatusVotos)(nil)
+
+func (s *StatusVotos) Salvar(ctx context.Context, status domain.StatusVoto) error {
+	payload, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("redis status voto: serializar: %w", err)
+	}
+	if err := s.client.Set(ctx, s.key(status.VotoID), payload, s.ttl).Err(); err != nil {
+		return fmt.Errorf("redis status voto: salvar %s: %w", status.VotoID, err)
+	}
+	return nil
+}
+
+func (s *StatusVotos) Obter(ctx context.Context, id domain.VotoID) (domain.StatusVoto, error) {
+	payload, err := s.client.Get(ctx, s.key(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return domain.StatusVoto{}, domain.ErrNotFound
+	}
+	if err != nil {
+		return domain.StatusVoto{}, fmt.Errorf("redis status voto: obter %s: %w", id, err)
+	}
+	var status domain.StatusVoto
+	if err := json.Unmarshal(payload, &status); err != nil {
+		return domain.StatusVoto{}, fmt.Errorf("redis status voto: decodificar %s: %w", id, err)
+	}
+	return status, nil
+}
+
+func (s *StatusVotos) key(id domain.VotoID) string {
+	return fmt.Sprintf("%s:%s", s.prefix, id)
+}
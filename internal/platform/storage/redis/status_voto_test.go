@@ -0,0 +1,42 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+func TestStatusVotos_SalvarEObter_QuandoAtualizado_DeveManterUltimoEstadoComTTL(t *testing.T) {
+	client, mr := setupRedis(t)
+	repo := NewStatusVotos(client, "voto-status", 10*time.Minute)
+	ctx := context.Background()
+
+	// Act
+	require.NoError(t, repo.Salvar(ctx, domain.StatusVoto{VotoID: "v1", ParedaoID: "p1", Estado: domain.VotoEnfileirado}))
+	require.NoError(t, repo.Salvar(ctx, domain.StatusVoto{VotoID: "v1", ParedaoID: "p1", Estado: domain.VotoProcessado}))
+	status, err := repo.Obter(ctx, "v1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, domain.VotoProcessado, status.Estado)
+	assert.Equal(t, domain.ParedaoID("p1"), status.ParedaoID)
+	assert.Greater(t, mr.TTL("voto-status:v1"), time.Duration(0))
+}
+
+func TestStatusVotos_Obter_QuandoExpirado_DeveRetornarErrNotFound(t *testing.T) {
+	client, mr := setupRedis(t)
+	repo := NewStatusVotos(client, "voto-status", time.Minute)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Salvar(ctx, domain.StatusVoto{VotoID: "v1", Estado: domain.VotoEnfileirado}))
+	mr.FastForward(2 * time.Minute)
+
+	_, err := repo.Obter(ctx, "v1")
+
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+}
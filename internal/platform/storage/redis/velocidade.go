@@ -0,0 +1,120 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// Velocidade guarda as janelas de votos e a linha de base usadas pela detecção de anomalias.
+type Velocidade struct {
+	client   *redis.Client
+	prefix   string
+	retencao time.Duration
+}
+
+// NewVelocidade recebe a retenção das chaves de janela; ela precisa cobrir algumas janelas para permitir o fechamento tardio.
+func NewVelocidade(client *redis.Client, prefix string, retencao time.Duration) *Velocidade {
+	if prefix == "" {
+		prefix = "velocidade"
+	}
+	if retencao <= 0 {
+		// EXPIRE 0 apagaria o contador na hora; sem retenção válida ficamos com dez janelas do padrão.
+		retencao = 10 * time.Minute
+	}
+	return &Velocidade{
+		client:   client,
+		prefix:   prefix,
+		retencao: retencao,
+	}
+}
+
+func (v *Velocidade) Incrementar(ctx context.Context, serie string, janela int64) (int64, error) {
+	key := v.janelaKey(serie, janela)
+	// Pipeline mantém incremento e expiração no mesmo round-trip do hot path do worker.
+	pipe := v.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, v.retencao)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("redis velocidade: incrementar %s: %w", key, err)
+	}
+	return incr.Val(), nil
+}
+
+func (v *Velocidade) Obter(ctx context.Context, serie string, janela int64) (int64, error) {
+	val, err := v.client.Get(ctx, v.janelaKey(serie, janela)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("redis velocidade: obter janela: %w", err)
+	}
+	return val, nil
+}
+
+func (v *Velocidade) ReivindicarFechamento(ctx context.Context, serie string, janela int64) (bool, error) {
+	key := fmt.Sprintf("%s:%s:fechada:%d", v.prefix, serie, janela)
+	ok, err := v.client.SetNX(ctx, key, 1, v.retencao).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis velocidade: reivindicar fechamento: %w", err)
+	}
+	return ok, nil
+}
+
+func (v *Velocidade) ObterLinhaBase(ctx context.Context, serie string) (domain.LinhaBase, error) {
+	valores, err := v.client.HGetAll(ctx, v.linhaKey(serie)).Result()
+	if err != nil {
+		return domain.LinhaBase{}, fmt.Errorf("redis velocidade: obter linha base: %w", err)
+	}
+	if len(valores) == 0 {
+		return domain.LinhaBase{}, nil
+	}
+
+	var linha domain.LinhaBase
+	if linha.Media, err = strconv.ParseFloat(valores["media"], 64); err != nil {
+		return domain.LinhaBase{}, fmt.Errorf("redis velocidade: media invalida: %w", err)
+	}
+	if linha.Variancia, err = strconv.ParseFloat(valores["variancia"], 64); err != nil {
+		return domain.LinhaBase{}, fmt.Errorf("redis velocidade: variancia invalida: %w", err)
+	}
+	if linha.Amostras, err = strconv.ParseInt(valores["amostras"], 10, 64); err != nil {
+		return domain.LinhaBase{}, fmt.Errorf("redis velocidade: amostras invalidas: %w", err)
+	}
+	if linha.UltimaJanela, err = strconv.ParseInt(valores["ultima_janela"], 10, 64); err != nil {
+		return domain.LinhaBase{}, fmt.Errorf("redis velocidade: ultima janela invalida: %w", err)
+	}
+	return linha, nil
+}
+
+func (v *Velocidade) SalvarLinhaBase(ctx context.Context, serie string, linha domain.LinhaBase) error {
+	key := v.linhaKey(serie)
+	pipe := v.client.TxPipeline()
+	pipe.HSet(ctx, key,
+		"media", strconv.FormatFloat(linha.Media, 'f', -1, 64),
+		"variancia", strconv.FormatFloat(linha.Variancia, 'f', -1, 64),
+		"amostras", linha.Amostras,
+		"ultima_janela", linha.UltimaJanela,
+	)
+	// A linha de base sobrevive ao paredão por alguns dias; depois disso não serve mais de referência.
+	pipe.Expire(ctx, key, 7*24*time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("redis velocidade: salvar linha base: %w", err)
+	}
+	return nil
+}
+
+func (v *Velocidade) janelaKey(serie string, janela int64) string {
+	return fmt.Sprintf("%s:%s:janela:%d", v.prefix, serie, janela)
+}
+
+func (v *Velocidade) linhaKey(serie string) string {
+	return fmt.Sprintf("%s:%s:linha", v.prefix, serie)
+}
+
+var _ domain.JanelasVelocidade = (*Velocidade)(nil)
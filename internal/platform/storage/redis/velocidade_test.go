@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+func TestVelocidade_IncrementarEObter_QuandoMesmaJanela_DeveAcumularComTTL(t *testing.T) {
+	client, mr := setupRedis(t)
+	repo := NewVelocidade(client, "velocidade", 10*time.Minute)
+
+	ctx := context.Background()
+
+	// Act
+	_, err := repo.Incrementar(ctx, "paredao:1", 100)
+	require.NoError(t, err)
+	total, err := repo.Incrementar(ctx, "paredao:1", 100)
+	require.NoError(t, err)
+
+	valor, err := repo.Obter(ctx, "paredao:1", 100)
+	require.NoError(t, err)
+	vazia, err := repo.Obter(ctx, "paredao:1", 101)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, int64(2), valor)
+	assert.Equal(t, int64(0), vazia)
+	assert.Greater(t, mr.TTL("velocidade:paredao:1:janela:100"), time.Duration(0))
+}
+
+func TestVelocidade_ReivindicarFechamento_QuandoJaReivindicada_DeveNegar(t *testing.T) {
+	client, _ := setupRedis(t)
+	repo := NewVelocidade(client, "velocidade", time.Minute)
+
+	ctx := context.Background()
+
+	primeiro, err := repo.ReivindicarFechamento(ctx, "paredao:1", 7)
+	require.NoError(t, err)
+	segundo, err := repo.ReivindicarFechamento(ctx, "paredao:1", 7)
+
+	assert.NoError(t, err)
+	assert.True(t, primeiro)
+	assert.False(t, segundo)
+}
+
+func TestVelocidade_LinhaBase_QuandoSalva_DeveRetornarMesmosValores(t *testing.T) {
+	client, _ := setupRedis(t)
+	repo := NewVelocidade(client, "velocidade", time.Minute)
+
+	ctx := context.Background()
+	linha := domain.LinhaBase{Media: 12.5, Variancia: 3.25, Amostras: 8, UltimaJanela: 28000000}
+
+	vazia, err := repo.ObterLinhaBase(ctx, "paredao:1")
+	require.NoError(t, err)
+	require.NoError(t, repo.SalvarLinhaBase(ctx, "paredao:1", linha))
+	salva, err := repo.ObterLinhaBase(ctx, "paredao:1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, domain.LinhaBase{}, vazia)
+	assert.Equal(t, linha, salva)
+}
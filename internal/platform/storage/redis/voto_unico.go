@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/marcelojr/desafio-globo/internal/domain"
+)
+
+// ReservaVotoUnico usa SETNX para que só o primeiro voto único de cada conta siga para a fila.
+type ReservaVotoUnico struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewReservaVotoUnico(client *redis.Client, prefix string) *ReservaVotoUnico {
+	if prefix == "" {
+		prefix = "voto-unico"
+	}
+	return &ReservaVotoUnico{client: client, prefix: prefix}
+}
+
+func (r *ReservaVotoUnico) Reservar(ctx context.Context, paredaoID domain.ParedaoID, eleitorID domain.EleitorID, validade time.Duration) (bool, error) {
+	ok, err := r.client.SetNX(ctx, r.key(paredaoID, eleitorID), 1, validade).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis voto unico: reservar: %w", err)
+	}
+	return ok, nil
+}
+
+func (r *ReservaVotoUnico) Liberar(ctx context.Context, paredaoID domain.ParedaoID, eleitorID domain.EleitorID) error {
+	if err := r.client.Del(ctx, r.key(paredaoID, eleitorID)).Err(); err != nil {
+		return fmt.Errorf("redis voto unico: liberar: %w", err)
+	}
+	return nil
+}
+
+func (r *ReservaVotoUnico) key(paredaoID domain.ParedaoID, eleitorID domain.EleitorID) string {
+	return fmt.Sprintf("%s:%s:%s", r.prefix, paredaoID, eleitorID)
+}
+
+var _ domain.ReservaVotoUnico = (*ReservaVotoUnico)(nil)
@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservaVotoUnico_Reservar_QuandoContaJaVotou_DeveNegar(t *testing.T) {
+	client, mr := setupRedis(t)
+	reserva := NewReservaVotoUnico(client, "voto-unico")
+
+	ctx := context.Background()
+
+	// Act
+	primeiro, err := reserva.Reservar(ctx, "paredao-1", "conta-1", time.Hour)
+	require.NoError(t, err)
+	segundo, err := reserva.Reservar(ctx, "paredao-1", "conta-1", time.Hour)
+	require.NoError(t, err)
+	outroParedao, err := reserva.Reservar(ctx, "paredao-2", "conta-1", time.Hour)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, primeiro)
+	assert.False(t, segundo)
+	assert.True(t, outroParedao)
+	assert.Greater(t, mr.TTL("voto-unico:paredao-1:conta-1"), time.Duration(0))
+}
+
+func TestReservaVotoUnico_Liberar_QuandoReservada_DevePermitirNovaReserva(t *testing.T) {
+	client, _ := setupRedis(t)
+	reserva := NewReservaVotoUnico(client, "voto-unico")
+
+	ctx := context.Background()
+	_, err := reserva.Reservar(ctx, "paredao-1", "conta-1", time.Hour)
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, reserva.Liberar(ctx, "paredao-1", "conta-1"))
+	ok, err := reserva.Reservar(ctx, "paredao-1", "conta-1", time.Hour)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
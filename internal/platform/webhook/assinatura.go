@@ -0,0 +1,94 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cabeçalhos das entregas assinadas. O destinatário confere a assinatura e usa o ID da entrega para
+// descartar repetições, já que uma entrega pode chegar mais de uma vez.
+const (
+	CabecalhoAssinatura = "X-Webhook-Assinatura"
+	CabecalhoEntrega    = "X-Webhook-Id"
+	CabecalhoEvento     = "X-Webhook-Evento"
+)
+
+var ErrAssinaturaInvalida = errors.New("webhook: assinatura invalida")
+
+// Assinar monta o cabeçalho "t=<unix>,v1=<hex>", com o HMAC-SHA256 do segredo sobre "<unix>.<corpo>".
+// O instante entra na mensagem para que o destinatário possa recusar reenvios antigos capturados.
+func Assinar(segredo string, instante time.Time, corpo []byte) string {
+	t := strconv.FormatInt(instante.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(segredo, t, corpo))
+}
+
+// Verificar confere o cabeçalho de Assinar e recusa assinaturas mais velhas (ou mais novas) que a tolerância.
+func Verificar(segredo, cabecalho string, corpo []byte, agora time.Time, tolerancia time.Duration) error {
+	var t, v1 string
+	for _, parte := range strings.Split(cabecalho, ",") {
+		chave, valor, _ := strings.Cut(parte, "=")
+		switch chave {
+		case "t":
+			t = valor
+		case "v1":
+			v1 = valor
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return ErrAssinaturaInvalida
+	}
+	recebida, err := hex.DecodeString(v1)
+	if err != nil || !hmac.Equal(recebida, mac(segredo, t, corpo)) {
+		return ErrAssinaturaInvalida
+	}
+	if d := agora.Sub(time.Unix(unix, 0)); d > tolerancia || d < -tolerancia {
+		return fmt.Errorf("%w: fora da tolerancia de %s", ErrAssinaturaInvalida, tolerancia)
+	}
+	return nil
+}
+
+func mac(segredo, t string, corpo []byte) []byte {
+	h := hmac.New(sha256.New, []byte(segredo))
+	h.Write([]byte(t))
+	h.Write([]byte("."))
+	h.Write(corpo)
+	return h.Sum(nil)
+}
+
+// EnviarAssinado faz o POST do corpo já serializado com a assinatura e os cabeçalhos informados. Devolve o
+// status HTTP quando houve resposta (zero em falha de rede) e erro para qualquer status fora da faixa 2xx.
+func (c *Cliente) EnviarAssinado(ctx context.Context, url, segredo string, cabecalhos map[string]string, corpo []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(corpo))
+	if err != nil {
+		return 0, fmt.Errorf("webhook: montar requisicao: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range cabecalhos {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set(CabecalhoAssinatura, Assinar(segredo, time.Now(), corpo))
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook: enviar: %w", err)
+	}
+	defer resp.Body.Close()
+	// Esvazia o corpo para reaproveitar a conexão.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook: status inesperado %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
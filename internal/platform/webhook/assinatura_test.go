@@ -0,0 +1,44 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssinar_QuandoConferidaComMesmoSegredo_DeveAceitar(t *testing.T) {
+	agora := time.Date(2024, 11, 20, 22, 0, 0, 0, time.UTC)
+	corpo := []byte(`{"tipo":"paredao.aberto"}`)
+
+	cabecalho := Assinar("segredo", agora, corpo)
+
+	assert.NoError(t, Verificar("segredo", cabecalho, corpo, agora.Add(time.Minute), 5*time.Minute))
+	assert.ErrorIs(t, Verificar("outro", cabecalho, corpo, agora, 5*time.Minute), ErrAssinaturaInvalida)
+	assert.ErrorIs(t, Verificar("segredo", cabecalho, []byte(`{}`), agora, 5*time.Minute), ErrAssinaturaInvalida)
+	assert.ErrorIs(t, Verificar("segredo", cabecalho, corpo, agora.Add(time.Hour), 5*time.Minute), ErrAssinaturaInvalida)
+}
+
+func TestEnviarAssinado_QuandoDestinoResponde_DeveAssinarEDevolverStatus(t *testing.T) {
+	var recebido http.Header
+	var corpo []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recebido = r.Header.Clone()
+		corpo, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	status, err := NewCliente(time.Second).EnviarAssinado(context.Background(), srv.URL, "segredo",
+		map[string]string{CabecalhoEntrega: "ent-1"}, []byte(`{"ok":true}`))
+
+	require.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "ent-1", recebido.Get(CabecalhoEntrega))
+	assert.NoError(t, Verificar("segredo", recebido.Get(CabecalhoAssinatura), corpo, time.Now(), time.Minute))
+}
@@ -0,0 +1,48 @@
+// Pacote webhook envia notificações JSON para endpoints HTTP externos (alertas, eventos do paredão).
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Cliente faz POSTs JSON com timeout curto para não travar o processamento de votos.
+type Cliente struct {
+	http *http.Client
+}
+
+func NewCliente(timeout time.Duration) *Cliente {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &Cliente{http: &http.Client{Timeout: timeout}}
+}
+
+// Enviar serializa o payload e considera falha qualquer resposta fora da faixa 2xx.
+func (c *Cliente) Enviar(ctx context.Context, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("webhook: serializar payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: montar requisicao: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: enviar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook: status inesperado %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,378 @@
+// Pacote websocket implementa o subconjunto do RFC 6455 usado pela API: handshake HTTP/1.1, mensagens de
+// texto/binárias (com fragmentação), ping/pong e fechamento. Não há suporte a extensões nem a compressão.
+package websocket
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Tipos de mensagem (opcodes de dados do RFC 6455).
+const (
+	MensagemTexto   = 1
+	MensagemBinaria = 2
+)
+
+const (
+	opContinuacao = 0x0
+	opFechar      = 0x8
+	opPing        = 0x9
+	opPong        = 0xA
+
+	// guidHandshake é a constante do RFC 6455 concatenada à chave do cliente no Sec-WebSocket-Accept.
+	guidHandshake = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	limiteLeituraPadrao     = 64 << 10
+	maxPayloadControle      = 125
+	tamanhoCodigoFechamento = 2
+	fechamentoSemCodigo     = 1005
+	prazoEscritaFechamento  = time.Second
+)
+
+// Códigos de fechamento usados pela API.
+const (
+	FechamentoNormal         = 1000
+	FechamentoSaindo         = 1001
+	FechamentoProtocolo      = 1002
+	FechamentoDadosInvalidos = 1007
+	FechamentoViolacao       = 1008
+	FechamentoMuitoGrande    = 1009
+	FechamentoErroInterno    = 1011
+)
+
+var (
+	// ErrMensagemGrande indica que o par enviou uma mensagem acima do limite de leitura.
+	ErrMensagemGrande = errors.New("websocket: mensagem acima do limite")
+	// ErrProtocolo indica um quadro malformado ou fora do protocolo.
+	ErrProtocolo = errors.New("websocket: violacao de protocolo")
+	// ErrUTF8Invalido indica mensagem de texto ou motivo de fechamento que não é UTF-8 válido.
+	ErrUTF8Invalido = errors.New("websocket: texto com UTF-8 invalido")
+	// ErrFechada indica que a conexão já foi fechada.
+	ErrFechada = errors.New("websocket: conexao fechada")
+)
+
+// ErroFechamento é devolvido pela leitura quando o par encerra a conexão com um quadro de fechamento.
+type ErroFechamento struct {
+	Codigo int
+	Motivo string
+}
+
+func (e *ErroFechamento) Error() string {
+	return fmt.Sprintf("websocket: fechada pelo par (%d %s)", e.Codigo, e.Motivo)
+}
+
+// Conn é uma conexão WebSocket. Leitura deve ficar numa única goroutine; escritas podem ser concorrentes.
+type Conn struct {
+	rede     net.Conn
+	leitor   *bufio.Reader
+	servidor bool
+
+	limiteLeitura int64
+	aoPong        func()
+
+	escrita sync.Mutex
+	fechada bool
+}
+
+func novaConn(rede net.Conn, leitor *bufio.Reader, servidor bool) *Conn {
+	return &Conn{rede: rede, leitor: leitor, servidor: servidor, limiteLeitura: limiteLeituraPadrao}
+}
+
+// DefinirLimiteLeitura ajusta o maior tamanho aceito para uma mensagem completa.
+func (c *Conn) DefinirLimiteLeitura(n int64) {
+	c.limiteLeitura = n
+}
+
+// DefinirPrazoLeitura repassa o deadline à conexão de rede; zero remove o prazo.
+func (c *Conn) DefinirPrazoLeitura(t time.Time) error {
+	return c.rede.SetReadDeadline(t)
+}
+
+// AoReceberPong registra uma função chamada na goroutine de leitura a cada pong recebido.
+func (c *Conn) AoReceberPong(f func()) {
+	c.aoPong = f
+}
+
+// EnderecoRemoto devolve o endereço do par na conexão de rede.
+func (c *Conn) EnderecoRemoto() net.Addr {
+	return c.rede.RemoteAddr()
+}
+
+// LerMensagem devolve a próxima mensagem de dados, remontando fragmentos e respondendo pings no caminho.
+func (c *Conn) LerMensagem() (int, []byte, error) {
+	var (
+		tipo    int
+		payload []byte
+	)
+	for {
+		q, err := c.lerQuadro()
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch q.opcode {
+		case opPing:
+			if err := c.escreverQuadro(opPong, q.payload, time.Now().Add(prazoEscritaFechamento)); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			if c.aoPong != nil {
+				c.aoPong()
+			}
+			continue
+		case opFechar:
+			return 0, nil, c.responderFechamento(q.payload)
+		case MensagemTexto, MensagemBinaria:
+			if tipo != 0 {
+				return 0, nil, c.falhar(FechamentoProtocolo, ErrProtocolo)
+			}
+			tipo = int(q.opcode)
+		case opContinuacao:
+			if tipo == 0 {
+				return 0, nil, c.falhar(FechamentoProtocolo, ErrProtocolo)
+			}
+		default:
+			return 0, nil, c.falhar(FechamentoProtocolo, ErrProtocolo)
+		}
+
+		if int64(len(payload)+len(q.payload)) > c.limiteLeitura {
+			return 0, nil, c.falhar(FechamentoMuitoGrande, ErrMensagemGrande)
+		}
+		payload = append(payload, q.payload...)
+		if q.fim {
+			// Texto só é validado inteiro: um fragmento pode terminar no meio de um caractere.
+			if tipo == MensagemTexto && !utf8.Valid(payload) {
+				return 0, nil, c.falhar(FechamentoDadosInvalidos, ErrUTF8Invalido)
+			}
+			return tipo, payload, nil
+		}
+	}
+}
+
+// EscreverMensagem envia uma mensagem de dados num único quadro.
+func (c *Conn) EscreverMensagem(tipo int, payload []byte, prazo time.Time) error {
+	if tipo != MensagemTexto && tipo != MensagemBinaria {
+		return fmt.Errorf("websocket: tipo de mensagem invalido %d", tipo)
+	}
+	return c.escreverQuadro(byte(tipo), payload, prazo)
+}
+
+// Ping envia um quadro de ping; a resposta chega pelo callback de AoReceberPong.
+func (c *Conn) Ping(prazo time.Time) error {
+	return c.escreverQuadro(opPing, nil, prazo)
+}
+
+// Fechar envia o quadro de fechamento com código e motivo e encerra a conexão de rede. Motivos longos
+// são cortados sem partir um caractere, para o par não recusar o quadro por UTF-8 inválido.
+func (c *Conn) Fechar(codigo int, motivo string) error {
+	for len(motivo) > maxPayloadControle-tamanhoCodigoFechamento {
+		_, tamanho := utf8.DecodeLastRuneInString(motivo)
+		motivo = motivo[:len(motivo)-tamanho]
+	}
+	payload := make([]byte, tamanhoCodigoFechamento, tamanhoCodigoFechamento+len(motivo))
+	binary.BigEndian.PutUint16(payload, uint16(codigo))
+	payload = append(payload, motivo...)
+	err := c.escreverQuadro(opFechar, payload, time.Now().Add(prazoEscritaFechamento))
+
+	c.escrita.Lock()
+	c.fechada = true
+	c.escrita.Unlock()
+	if errors.Is(err, ErrFechada) {
+		return nil
+	}
+	return errors.Join(err, c.rede.Close())
+}
+
+// responderFechamento ecoa o código recebido, como pede o RFC, e devolve o erro que encerra a leitura.
+// Um quadro de fechamento com um byte só, código reservado ou motivo fora do UTF-8 é falha de protocolo.
+func (c *Conn) responderFechamento(payload []byte) error {
+	codigo, motivo := fechamentoSemCodigo, ""
+	switch {
+	case len(payload) == 1:
+		return c.falhar(FechamentoProtocolo, ErrProtocolo)
+	case len(payload) >= tamanhoCodigoFechamento:
+		codigo = int(binary.BigEndian.Uint16(payload))
+		if !codigoFechamentoValido(codigo) {
+			return c.falhar(FechamentoProtocolo, ErrProtocolo)
+		}
+		if !utf8.Valid(payload[tamanhoCodigoFechamento:]) {
+			return c.falhar(FechamentoDadosInvalidos, ErrUTF8Invalido)
+		}
+		motivo = string(payload[tamanhoCodigoFechamento:])
+	}
+	resposta := payload
+	if len(resposta) > tamanhoCodigoFechamento {
+		resposta = resposta[:tamanhoCodigoFechamento]
+	}
+	_ = c.escreverQuadro(opFechar, resposta, time.Now().Add(prazoEscritaFechamento))
+	c.escrita.Lock()
+	c.fechada = true
+	c.escrita.Unlock()
+	_ = c.rede.Close()
+	return &ErroFechamento{Codigo: codigo, Motivo: motivo}
+}
+
+// codigoFechamentoValido aceita os códigos que podem trafegar num quadro: os definidos pelo RFC 6455 e
+// registrados na IANA (1005, 1006 e 1015 são só para uso local) e a faixa 3000-4999 de bibliotecas e aplicações.
+func codigoFechamentoValido(codigo int) bool {
+	switch {
+	case codigo >= 1000 && codigo <= 1003, codigo >= 1007 && codigo <= 1014:
+		return true
+	default:
+		return codigo >= 3000 && codigo <= 4999
+	}
+}
+
+func (c *Conn) falhar(codigo int, err error) error {
+	_ = c.Fechar(codigo, err.Error())
+	return err
+}
+
+type quadro struct {
+	fim     bool
+	opcode  byte
+	payload []byte
+}
+
+func (c *Conn) lerQuadro() (quadro, error) {
+	var cabecalho [2]byte
+	if _, err := io.ReadFull(c.leitor, cabecalho[:]); err != nil {
+		return quadro{}, err
+	}
+
+	q := quadro{fim: cabecalho[0]&0x80 != 0, opcode: cabecalho[0] & 0x0F}
+	if cabecalho[0]&0x70 != 0 {
+		// Bits RSV só valem com extensões negociadas, e não negociamos nenhuma.
+		return quadro{}, c.falhar(FechamentoProtocolo, ErrProtocolo)
+	}
+	mascarado := cabecalho[1]&0x80 != 0
+	if mascarado != c.servidor {
+		// Cliente sempre mascara; servidor nunca.
+		return quadro{}, c.falhar(FechamentoProtocolo, ErrProtocolo)
+	}
+
+	tamanho := int64(cabecalho[1] & 0x7F)
+	switch tamanho {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.leitor, ext[:]); err != nil {
+			return quadro{}, err
+		}
+		tamanho = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.leitor, ext[:]); err != nil {
+			return quadro{}, err
+		}
+		tamanho = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+
+	controle := q.opcode >= opFechar
+	if controle && (tamanho > maxPayloadControle || !q.fim) {
+		return quadro{}, c.falhar(FechamentoProtocolo, ErrProtocolo)
+	}
+	if tamanho < 0 || tamanho > c.limiteLeitura {
+		return quadro{}, c.falhar(FechamentoMuitoGrande, ErrMensagemGrande)
+	}
+
+	var mascara [4]byte
+	if mascarado {
+		if _, err := io.ReadFull(c.leitor, mascara[:]); err != nil {
+			return quadro{}, err
+		}
+	}
+	q.payload = make([]byte, tamanho)
+	if _, err := io.ReadFull(c.leitor, q.payload); err != nil {
+		return quadro{}, err
+	}
+	if mascarado {
+		aplicarMascara(q.payload, mascara)
+	}
+	return q, nil
+}
+
+func (c *Conn) escreverQuadro(opcode byte, payload []byte, prazo time.Time) error {
+	return c.escreverQuadroFragmento(opcode, payload, true, prazo)
+}
+
+func (c *Conn) escreverQuadroFragmento(opcode byte, payload []byte, fim bool, prazo time.Time) error {
+	c.escrita.Lock()
+	defer c.escrita.Unlock()
+	if c.fechada {
+		return ErrFechada
+	}
+
+	buf := make([]byte, 0, 14+len(payload))
+	if fim {
+		opcode |= 0x80
+	}
+	buf = append(buf, opcode)
+
+	var bitMascara byte
+	if !c.servidor {
+		bitMascara = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		buf = append(buf, bitMascara|byte(n))
+	case n <= 0xFFFF:
+		buf = append(buf, bitMascara|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, bitMascara|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+
+	if c.servidor {
+		buf = append(buf, payload...)
+	} else {
+		var mascara [4]byte
+		if _, err := rand.Read(mascara[:]); err != nil {
+			return err
+		}
+		buf = append(buf, mascara[:]...)
+		inicio := len(buf)
+		buf = append(buf, payload...)
+		aplicarMascara(buf[inicio:], mascara)
+	}
+
+	if err := c.rede.SetWriteDeadline(prazo); err != nil {
+		return err
+	}
+	_, err := c.rede.Write(buf)
+	return err
+}
+
+func aplicarMascara(b []byte, mascara [4]byte) {
+	for i := range b {
+		b[i] ^= mascara[i%4]
+	}
+}
+
+func chaveAceite(chave string) string {
+	h := sha1.New()
+	h.Write([]byte(chave + guidHandshake))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// contemToken verifica se um cabeçalho com lista separada por vírgulas contém o token (sem diferenciar caixa).
+func contemToken(valor, token string) bool {
+	for _, parte := range strings.Split(valor, ",") {
+		if strings.EqualFold(strings.TrimSpace(parte), token) {
+			return true
+		}
+	}
+	return false
+}
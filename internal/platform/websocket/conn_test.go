@@ -0,0 +1,208 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// servidorEco devolve cada mensagem recebida e registra o erro que encerrou a leitura.
+func servidorEco(t *testing.T, limite int64) (string, <-chan error) {
+	t.Helper()
+	fim := make(chan error, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := Atualizar(w, r, nil)
+		if err != nil {
+			fim <- err
+			return
+		}
+		if limite > 0 {
+			conn.DefinirLimiteLeitura(limite)
+		}
+		for {
+			tipo, payload, err := conn.LerMensagem()
+			if err != nil {
+				fim <- err
+				return
+			}
+			if err := conn.EscreverMensagem(tipo, payload, time.Now().Add(time.Second)); err != nil {
+				fim <- err
+				return
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http"), fim
+}
+
+func conectar(t *testing.T, destino string) *Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	conn, _, err := Conectar(ctx, destino, nil)
+	require.NoError(t, err)
+	require.NoError(t, conn.DefinirPrazoLeitura(time.Now().Add(2*time.Second)))
+	return conn
+}
+
+func TestConn_QuandoMensagemEnviada_DeveReceberEco(t *testing.T) {
+	destino, fim := servidorEco(t, 1<<20)
+	conn := conectar(t, destino)
+	conn.DefinirLimiteLeitura(1 << 20)
+
+	// Act
+	for _, msg := range []string{"oi", strings.Repeat("a", 300), strings.Repeat("b", 70000)} {
+		require.NoError(t, conn.EscreverMensagem(MensagemTexto, []byte(msg), time.Now().Add(time.Second)))
+		tipo, payload, err := conn.LerMensagem()
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, MensagemTexto, tipo)
+		assert.Equal(t, msg, string(payload))
+	}
+
+	require.NoError(t, conn.Fechar(FechamentoNormal, "tchau"))
+	var fechamento *ErroFechamento
+	require.ErrorAs(t, <-fim, &fechamento)
+	assert.Equal(t, FechamentoNormal, fechamento.Codigo)
+	assert.Equal(t, "tchau", fechamento.Motivo)
+}
+
+func TestConn_QuandoFragmentadaEComPingNoMeio_DeveRemontarMensagem(t *testing.T) {
+	destino, _ := servidorEco(t, 0)
+	conn := conectar(t, destino)
+
+	pongs := 0
+	conn.AoReceberPong(func() { pongs++ })
+
+	// Act
+	prazo := time.Now().Add(time.Second)
+	require.NoError(t, conn.escreverQuadroFragmento(MensagemTexto, []byte("par"), false, prazo))
+	require.NoError(t, conn.escreverQuadro(opPing, []byte("x"), prazo))
+	require.NoError(t, conn.escreverQuadroFragmento(opContinuacao, []byte("edao"), true, prazo))
+	require.NoError(t, conn.Ping(prazo))
+	require.NoError(t, conn.EscreverMensagem(MensagemTexto, []byte("fim"), prazo))
+
+	// Assert
+	_, payload, err := conn.LerMensagem()
+	require.NoError(t, err)
+	assert.Equal(t, "paredao", string(payload))
+	_, payload, err = conn.LerMensagem()
+	require.NoError(t, err)
+	assert.Equal(t, "fim", string(payload))
+	assert.Equal(t, 2, pongs)
+}
+
+func TestConn_QuandoMensagemAcimaDoLimite_DeveFecharCom1009(t *testing.T) {
+	destino, fim := servidorEco(t, 16)
+	conn := conectar(t, destino)
+
+	// Act
+	require.NoError(t, conn.EscreverMensagem(MensagemTexto, []byte(strings.Repeat("x", 17)), time.Now().Add(time.Second)))
+
+	// Assert
+	assert.ErrorIs(t, <-fim, ErrMensagemGrande)
+	_, _, err := conn.LerMensagem()
+	var fechamento *ErroFechamento
+	require.ErrorAs(t, err, &fechamento)
+	assert.Equal(t, FechamentoMuitoGrande, fechamento.Codigo)
+}
+
+func TestAtualizar_QuandoRequisicaoSemUpgrade_DeveRecusar(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+
+	_, err := Atualizar(w, r, nil)
+
+	assert.ErrorIs(t, err, ErrHandshake)
+	assert.Equal(t, http.StatusUpgradeRequired, w.Code)
+}
+
+func TestChaveAceite_QuandoExemploDoRFC_DeveConferir(t *testing.T) {
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", chaveAceite("dGhlIHNhbXBsZSBub25jZQ=="))
+}
+
+func TestConn_QuandoTextoComUTF8Invalido_DeveFecharCom1007(t *testing.T) {
+	destino, fim := servidorEco(t, 0)
+	conn := conectar(t, destino)
+
+	// Act: o caractere inválido chega partido em dois fragmentos
+	prazo := time.Now().Add(time.Second)
+	require.NoError(t, conn.escreverQuadroFragmento(MensagemTexto, []byte("ok \xc3"), false, prazo))
+	require.NoError(t, conn.escreverQuadroFragmento(opContinuacao, []byte("\x28"), true, prazo))
+
+	// Assert
+	assert.ErrorIs(t, <-fim, ErrUTF8Invalido)
+	_, _, err := conn.LerMensagem()
+	var fechamento *ErroFechamento
+	require.ErrorAs(t, err, &fechamento)
+	assert.Equal(t, FechamentoDadosInvalidos, fechamento.Codigo)
+}
+
+func TestConn_QuandoFechamentoInvalido_DeveResponderComErroDeProtocolo(t *testing.T) {
+	casos := map[string]struct {
+		payload []byte
+		erro    error
+		codigo  int
+	}{
+		"um byte so":           {payload: []byte{0x03}, erro: ErrProtocolo, codigo: FechamentoProtocolo},
+		"codigo reservado":     {payload: []byte{0x03, 0xED}, erro: ErrProtocolo, codigo: FechamentoProtocolo},
+		"codigo fora da faixa": {payload: []byte{0x13, 0x88}, erro: ErrProtocolo, codigo: FechamentoProtocolo},
+		"motivo invalido":      {payload: []byte{0x03, 0xE8, 0xff}, erro: ErrUTF8Invalido, codigo: FechamentoDadosInvalidos},
+	}
+	for nome, caso := range casos {
+		t.Run(nome, func(t *testing.T) {
+			destino, fim := servidorEco(t, 0)
+			conn := conectar(t, destino)
+
+			// Act
+			require.NoError(t, conn.escreverQuadro(opFechar, caso.payload, time.Now().Add(time.Second)))
+
+			// Assert
+			assert.ErrorIs(t, <-fim, caso.erro)
+			_, _, err := conn.LerMensagem()
+			var fechamento *ErroFechamento
+			require.ErrorAs(t, err, &fechamento)
+			assert.Equal(t, caso.codigo, fechamento.Codigo)
+		})
+	}
+}
+
+func TestConn_QuandoMotivoLongo_DeveCortarSemPartirCaractere(t *testing.T) {
+	destino, fim := servidorEco(t, 0)
+	conn := conectar(t, destino)
+
+	// Act
+	require.NoError(t, conn.Fechar(FechamentoNormal, strings.Repeat("ã", 100)))
+
+	// Assert
+	var fechamento *ErroFechamento
+	require.ErrorAs(t, <-fim, &fechamento)
+	assert.Equal(t, strings.Repeat("ã", 61), fechamento.Motivo)
+}
+
+func TestOrigensPermitidas(t *testing.T) {
+	verificar := OrigensPermitidas([]string{"https://gshow.globo.com/", " https://App.Exemplo.com "})
+	casos := map[string]bool{
+		"":                          true,
+		"http://api.bbb":            true,
+		"https://gshow.globo.com":   true,
+		"https://app.exemplo.com":   true,
+		"http://gshow.globo.com":    false,
+		"https://malicioso.example": false,
+		"null":                      false,
+	}
+	for origem, esperado := range casos {
+		r := httptest.NewRequest(http.MethodGet, "http://api.bbb/ws", nil)
+		if origem != "" {
+			r.Header.Set("Origin", origem)
+		}
+		assert.Equal(t, esperado, verificar(r), origem)
+	}
+}
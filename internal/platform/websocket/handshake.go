@@ -0,0 +1,155 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrHandshake indica que a requisição não é um pedido de upgrade válido ou que o servidor recusou o upgrade.
+var ErrHandshake = errors.New("websocket: handshake invalido")
+
+// VerificarOrigem decide se o cabeçalho Origin de um navegador é aceito; nil aceita qualquer origem.
+type VerificarOrigem func(r *http.Request) bool
+
+// OrigensPermitidas aceita a própria origem do servidor e as da lista (esquema e host, como em
+// "https://gshow.globo.com"). Sem cabeçalho Origin o pedido não vem de um navegador e passa: a proteção
+// é contra uma página de outro site abrindo a conexão com os cookies e o IP do visitante.
+func OrigensPermitidas(lista []string) VerificarOrigem {
+	permitidas := make(map[string]bool, len(lista))
+	for _, origem := range lista {
+		if origem = strings.TrimSpace(origem); origem != "" {
+			permitidas[strings.ToLower(strings.TrimSuffix(origem, "/"))] = true
+		}
+	}
+	return func(r *http.Request) bool {
+		origem := r.Header.Get("Origin")
+		if origem == "" {
+			return true
+		}
+		u, err := url.Parse(origem)
+		if err != nil || u.Host == "" {
+			return false
+		}
+		if strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		return permitidas[strings.ToLower(u.Scheme+"://"+u.Host)]
+	}
+}
+
+// Atualizar conclui o handshake do lado do servidor e assume a conexão de rede. Em caso de erro a resposta
+// HTTP já foi escrita.
+func Atualizar(w http.ResponseWriter, r *http.Request, origem VerificarOrigem) (*Conn, error) {
+	switch {
+	case r.Method != http.MethodGet:
+		http.Error(w, "metodo nao suportado", http.StatusMethodNotAllowed)
+		return nil, fmt.Errorf("%w: metodo %s", ErrHandshake, r.Method)
+	case !contemToken(r.Header.Get("Connection"), "upgrade") || !contemToken(r.Header.Get("Upgrade"), "websocket"):
+		http.Error(w, "upgrade para websocket obrigatorio", http.StatusUpgradeRequired)
+		return nil, fmt.Errorf("%w: sem upgrade", ErrHandshake)
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "versao de websocket nao suportada", http.StatusBadRequest)
+		return nil, fmt.Errorf("%w: versao %q", ErrHandshake, r.Header.Get("Sec-WebSocket-Version"))
+	}
+	chave := r.Header.Get("Sec-WebSocket-Key")
+	if decodificada, err := base64.StdEncoding.DecodeString(chave); err != nil || len(decodificada) != 16 {
+		http.Error(w, "Sec-WebSocket-Key invalida", http.StatusBadRequest)
+		return nil, fmt.Errorf("%w: chave invalida", ErrHandshake)
+	}
+	if origem != nil && !origem(r) {
+		http.Error(w, "origem nao permitida", http.StatusForbidden)
+		return nil, fmt.Errorf("%w: origem %q", ErrHandshake, r.Header.Get("Origin"))
+	}
+
+	rede, buf, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "upgrade nao suportado", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: hijack: %w", err)
+	}
+	// Limpa deadlines herdados do servidor HTTP; a partir daqui quem usa a Conn controla os prazos.
+	_ = rede.SetDeadline(time.Time{})
+
+	resposta := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + chaveAceite(chave) + "\r\n\r\n"
+	if _, err := buf.WriteString(resposta); err != nil {
+		rede.Close()
+		return nil, fmt.Errorf("websocket: escrever handshake: %w", err)
+	}
+	if err := buf.Flush(); err != nil {
+		rede.Close()
+		return nil, fmt.Errorf("websocket: escrever handshake: %w", err)
+	}
+	return novaConn(rede, buf.Reader, true), nil
+}
+
+// Conectar abre uma conexão do lado do cliente, só para ws:// (sem TLS); usado por testes e ferramentas.
+func Conectar(ctx context.Context, destino string, cabecalhos http.Header) (*Conn, *http.Response, error) {
+	u, err := url.Parse(destino)
+	if err != nil {
+		return nil, nil, fmt.Errorf("websocket: url: %w", err)
+	}
+	if u.Scheme != "ws" {
+		return nil, nil, fmt.Errorf("websocket: esquema %q nao suportado", u.Scheme)
+	}
+
+	var d net.Dialer
+	rede, err := d.DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("websocket: conectar: %w", err)
+	}
+
+	segredo := make([]byte, 16)
+	if _, err := rand.Read(segredo); err != nil {
+		rede.Close()
+		return nil, nil, err
+	}
+	chave := base64.StdEncoding.EncodeToString(segredo)
+
+	u.Scheme = "http"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		rede.Close()
+		return nil, nil, err
+	}
+	for nome, valores := range cabecalhos {
+		req.Header[nome] = valores
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", chave)
+
+	if prazo, ok := ctx.Deadline(); ok {
+		_ = rede.SetDeadline(prazo)
+	}
+	if err := req.Write(rede); err != nil {
+		rede.Close()
+		return nil, nil, fmt.Errorf("websocket: enviar handshake: %w", err)
+	}
+	leitor := bufio.NewReader(rede)
+	resp, err := http.ReadResponse(leitor, req)
+	if err != nil {
+		rede.Close()
+		return nil, nil, fmt.Errorf("websocket: ler handshake: %w", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		!strings.EqualFold(resp.Header.Get("Upgrade"), "websocket") ||
+		resp.Header.Get("Sec-WebSocket-Accept") != chaveAceite(chave) {
+		rede.Close()
+		return nil, resp, fmt.Errorf("%w: status %d", ErrHandshake, resp.StatusCode)
+	}
+	_ = rede.SetDeadline(time.Time{})
+	return novaConn(rede, leitor, false), resp, nil
+}